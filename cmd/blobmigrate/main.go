@@ -0,0 +1,62 @@
+// Command blobmigrate copies stored media from one blob backend to another,
+// e.g. from the local uploads directory into an S3 bucket. Both backends are
+// configured from the usual environment (STORAGE_DIR, S3_*). Objects already
+// present in the destination are skipped, so it is safe to re-run.
+package main
+
+import (
+	"context"
+	"flag"
+	"os"
+	"os/signal"
+
+	"linkbio/internal/config"
+	"linkbio/internal/pkg/logger"
+	"linkbio/internal/storage"
+)
+
+func main() {
+	from := flag.String("from", "local", `source backend ("local" or "s3")`)
+	to := flag.String("to", "s3", `destination backend ("local" or "s3")`)
+	prefix := flag.String("prefix", "", "only copy keys with this prefix")
+	flag.Parse()
+
+	cfg, err := config.Load()
+	if err != nil {
+		panic("failed to load config: " + err.Error())
+	}
+	log := logger.New(cfg.LogLevel)
+
+	if *from == *to {
+		log.Error("source and destination must differ", "backend", *from)
+		os.Exit(2)
+	}
+
+	src, err := open(cfg, *from)
+	if err != nil {
+		log.Error("failed to open source", "error", err)
+		os.Exit(1)
+	}
+	dst, err := open(cfg, *to)
+	if err != nil {
+		log.Error("failed to open destination", "error", err)
+		os.Exit(1)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	n, err := storage.Migrate(ctx, src, dst, *prefix)
+	if err != nil {
+		log.Error("migration failed", "copied", n, "error", err)
+		os.Exit(1)
+	}
+	log.Info("migration complete", "from", *from, "to", *to, "copied", n)
+}
+
+// open builds the named backend from the shared configuration
+func open(cfg *config.Config, backend string) (storage.Blob, error) {
+	opts := cfg.StorageOptions()
+	opts.Backend = backend
+	return storage.Open(opts)
+}
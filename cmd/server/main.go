@@ -7,6 +7,7 @@ import (
 	"os/signal"
 	"syscall"
 	"time"
+	_ "time/tzdata" // event links accept any IANA time zone, even without one installed
 
 	"linkbio/internal/config"
 	"linkbio/internal/pkg/logger"
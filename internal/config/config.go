@@ -1,7 +1,13 @@
 package config
 
 import (
+	"net/url"
 	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"linkbio/internal/storage"
 
 	"github.com/joho/godotenv"
 )
@@ -9,11 +15,42 @@ import (
 // Config holds all application configuration
 type Config struct {
 	Port          string
+	BaseURL       string // public origin used in QR codes and absolute links
 	Env           string
 	LogLevel      string
 	DatabasePath  string
-	SessionSecret    string
-	SessionEncKey    string
+	SessionSecret string
+	SessionEncKey string
+
+	// Extra hostnames served as the main site alongside BASE_URL's host,
+	// e.g. an internal name the load balancer health-checks. Any other
+	// Host must be a verified custom domain.
+	AllowedHosts []string
+
+	// Uploaded media. StorageBackend is "local" (files under StorageDir)
+	// or "s3" (any S3-compatible service, e.g. MinIO).
+	StorageBackend string
+	StorageDir     string
+	S3Endpoint     string
+	S3Bucket       string
+	S3Region       string
+	S3AccessKey    string
+	S3SecretKey    string
+
+	// Username changes: old handles stay reserved for their previous owner
+	// for UsernameHold, and each account may change at most
+	// UsernameChangeLimit times per UsernameChangeWindow
+	UsernameHold         time.Duration
+	UsernameChangeLimit  int
+	UsernameChangeWindow time.Duration
+
+	// Link health checker (interval 0 disables it)
+	LinkCheckInterval   time.Duration
+	LinkCheckHideBroken bool
+
+	// Sites allowed to frame the /embed widget, as CSP frame-ancestors
+	// sources like https://example.com. Empty allows any site.
+	EmbedFrameAncestors []string
 }
 
 // Load reads configuration from environment variables
@@ -21,16 +58,61 @@ func Load() (*Config, error) {
 	// Load .env file (ignore error if not exists)
 	_ = godotenv.Load()
 
+	port := getEnv("PORT", "8080")
+
 	return &Config{
-		Port:          getEnv("PORT", "8080"),
+		Port:          port,
+		BaseURL:       strings.TrimRight(getEnv("BASE_URL", "http://localhost:"+port), "/"),
 		Env:           getEnv("ENV", "development"),
 		LogLevel:      getEnv("LOG_LEVEL", "INFO"),
 		DatabasePath:  getEnv("DATABASE_PATH", "./data/linkbio.db"),
-		SessionSecret:    getEnv("SESSION_SECRET", "change-me-in-production"),
-		SessionEncKey:    getEnv("SESSION_ENCRYPTION_KEY", ""),
+		SessionSecret: getEnv("SESSION_SECRET", "change-me-in-production"),
+		SessionEncKey: getEnv("SESSION_ENCRYPTION_KEY", ""),
+		AllowedHosts:  getEnvList("ALLOWED_HOSTS"),
+
+		StorageBackend: getEnv("STORAGE_BACKEND", "local"),
+		StorageDir:     getEnv("STORAGE_DIR", "./data/uploads"),
+		S3Endpoint:     getEnv("S3_ENDPOINT", ""),
+		S3Bucket:       getEnv("S3_BUCKET", ""),
+		S3Region:       getEnv("S3_REGION", "us-east-1"),
+		S3AccessKey:    getEnv("S3_ACCESS_KEY", ""),
+		S3SecretKey:    getEnv("S3_SECRET_KEY", ""),
+
+		UsernameHold:         getEnvDuration("USERNAME_HOLD", 90*24*time.Hour),
+		UsernameChangeLimit:  getEnvInt("USERNAME_CHANGE_LIMIT", 2),
+		UsernameChangeWindow: getEnvDuration("USERNAME_CHANGE_WINDOW", 30*24*time.Hour),
+
+		LinkCheckInterval:   getEnvDuration("LINK_CHECK_INTERVAL", 6*time.Hour),
+		LinkCheckHideBroken: getEnvBool("LINK_CHECK_HIDE_BROKEN", false),
+
+		EmbedFrameAncestors: getEnvList("EMBED_FRAME_ANCESTORS"),
 	}, nil
 }
 
+// PrimaryHosts lists the hostnames that serve the main site
+func (c *Config) PrimaryHosts() []string {
+	hosts := append([]string{}, c.AllowedHosts...)
+	if u, err := url.Parse(c.BaseURL); err == nil && u.Hostname() != "" {
+		hosts = append(hosts, strings.ToLower(u.Hostname()))
+	}
+	return hosts
+}
+
+// StorageOptions describes the configured blob backend
+func (c *Config) StorageOptions() storage.Options {
+	return storage.Options{
+		Backend: c.StorageBackend,
+		Dir:     c.StorageDir,
+		S3: storage.S3Options{
+			Endpoint:  c.S3Endpoint,
+			Bucket:    c.S3Bucket,
+			Region:    c.S3Region,
+			AccessKey: c.S3AccessKey,
+			SecretKey: c.S3SecretKey,
+		},
+	}
+}
+
 // IsDevelopment returns true if running in development mode
 func (c *Config) IsDevelopment() bool {
 	return c.Env == "development"
@@ -49,3 +131,43 @@ func getEnv(key, fallback string) string {
 	return fallback
 }
 
+// getEnvDuration parses a duration like "30m" or returns fallback
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	if val := os.Getenv(key); val != "" {
+		if d, err := time.ParseDuration(val); err == nil {
+			return d
+		}
+	}
+	return fallback
+}
+
+// getEnvInt parses an integer or returns fallback
+func getEnvInt(key string, fallback int) int {
+	if val := os.Getenv(key); val != "" {
+		if n, err := strconv.Atoi(val); err == nil {
+			return n
+		}
+	}
+	return fallback
+}
+
+// getEnvBool parses a boolean like "true" or "1" or returns fallback
+func getEnvBool(key string, fallback bool) bool {
+	if val := os.Getenv(key); val != "" {
+		if b, err := strconv.ParseBool(val); err == nil {
+			return b
+		}
+	}
+	return fallback
+}
+
+// getEnvList splits a comma-separated variable, dropping empty entries
+func getEnvList(key string) []string {
+	var list []string
+	for _, v := range strings.Split(os.Getenv(key), ",") {
+		if v = strings.ToLower(strings.TrimSpace(v)); v != "" {
+			list = append(list, v)
+		}
+	}
+	return list
+}
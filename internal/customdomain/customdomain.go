@@ -0,0 +1,148 @@
+// Package customdomain validates hostnames creators bring to the site and
+// proves they control them with a DNS TXT record.
+//
+// To verify example.com the creator publishes
+//
+//	_linkbio-verify.example.com  TXT  "linkbio-verify=<token>"
+//
+// where the token is generated when the domain is added.
+package customdomain
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net"
+	"strings"
+)
+
+// RecordPrefix is prepended to the domain to form the TXT record name
+const RecordPrefix = "_linkbio-verify."
+
+// ValuePrefix starts the expected TXT record value
+const ValuePrefix = "linkbio-verify="
+
+// Errors returned by Verify
+var (
+	ErrRecordNotFound = errors.New("verification record not found")
+	ErrTokenMismatch  = errors.New("verification record does not match")
+)
+
+// Resolver looks up TXT records. *net.Resolver satisfies it; tests supply
+// a fake.
+type Resolver interface {
+	LookupTXT(ctx context.Context, name string) ([]string, error)
+}
+
+// Verifier checks domain ownership records
+type Verifier struct {
+	resolver Resolver
+}
+
+// NewVerifier creates a Verifier. A nil resolver uses the system resolver.
+func NewVerifier(resolver Resolver) *Verifier {
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+	return &Verifier{resolver: resolver}
+}
+
+// RecordName is the TXT record name for a domain
+func RecordName(domain string) string {
+	return RecordPrefix + domain
+}
+
+// RecordValue is the TXT record value for a token
+func RecordValue(token string) string {
+	return ValuePrefix + token
+}
+
+// NewToken returns a random verification token
+func NewToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// Verify succeeds when the domain publishes the token. A missing record is
+// ErrRecordNotFound; records that exist but carry another value are
+// ErrTokenMismatch. Other resolver failures are returned as-is.
+func (v *Verifier) Verify(ctx context.Context, domain, token string) error {
+	records, err := v.resolver.LookupTXT(ctx, RecordName(domain))
+	if err != nil {
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+			return ErrRecordNotFound
+		}
+		return err
+	}
+	if len(records) == 0 {
+		return ErrRecordNotFound
+	}
+
+	want := RecordValue(token)
+	for _, r := range records {
+		if strings.TrimSpace(r) == want {
+			return nil
+		}
+	}
+	return ErrTokenMismatch
+}
+
+// Normalize lowercases a hostname, drops a trailing dot and checks it is a
+// plausible public domain: at least two labels of letters, digits and
+// hyphens, no IP addresses, no ports. Internationalized names must be given
+// in their xn-- form. Messages are suitable for showing to the user.
+func Normalize(domain string) (string, error) {
+	d := strings.TrimSuffix(strings.ToLower(strings.TrimSpace(domain)), ".")
+
+	switch {
+	case d == "":
+		return "", errors.New("Enter a domain like links.example.com")
+	case strings.Contains(d, "://") || strings.ContainsAny(d, "/:?#@"):
+		return "", errors.New("Enter just the domain, without https:// or a path")
+	case len(d) > 253:
+		return "", errors.New("Domain is too long")
+	case net.ParseIP(d) != nil:
+		return "", errors.New("IP addresses cannot be used as custom domains")
+	}
+
+	labels := strings.Split(d, ".")
+	if len(labels) < 2 {
+		return "", errors.New("Enter a full domain like links.example.com")
+	}
+	for _, l := range labels {
+		if !validLabel(l) {
+			return "", errors.New("Domain contains an invalid name")
+		}
+	}
+	if tld := labels[len(labels)-1]; strings.Trim(tld, "0123456789") == "" {
+		return "", errors.New("Domain must end in a real top-level domain")
+	}
+	return d, nil
+}
+
+// validLabel checks one dot-separated part of a hostname
+func validLabel(l string) bool {
+	if len(l) == 0 || len(l) > 63 || l[0] == '-' || l[len(l)-1] == '-' {
+		return false
+	}
+	for i := 0; i < len(l); i++ {
+		c := l[i]
+		if (c < 'a' || c > 'z') && (c < '0' || c > '9') && c != '-' {
+			return false
+		}
+	}
+	return true
+}
+
+// Hostname strips the port from a Host header value and normalizes case
+func Hostname(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.TrimSuffix(strings.ToLower(host), ".")
+}
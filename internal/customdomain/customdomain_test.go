@@ -0,0 +1,109 @@
+package customdomain
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+)
+
+// fakeResolver serves TXT records from a map
+type fakeResolver map[string][]string
+
+func (f fakeResolver) LookupTXT(ctx context.Context, name string) ([]string, error) {
+	records, ok := f[name]
+	if !ok {
+		return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+	}
+	return records, nil
+}
+
+// failingResolver simulates a resolver outage
+type failingResolver struct{}
+
+func (failingResolver) LookupTXT(ctx context.Context, name string) ([]string, error) {
+	return nil, &net.DNSError{Err: "server misbehaving", Name: name, IsTemporary: true}
+}
+
+func TestVerify(t *testing.T) {
+	v := NewVerifier(fakeResolver{
+		"_linkbio-verify.good.example":  {"v=spf1 -all", "linkbio-verify=abc123"},
+		"_linkbio-verify.wrong.example": {"linkbio-verify=other"},
+		"_linkbio-verify.empty.example": {},
+	})
+	ctx := context.Background()
+
+	if err := v.Verify(ctx, "good.example", "abc123"); err != nil {
+		t.Errorf("Verify(good) error = %v", err)
+	}
+	if err := v.Verify(ctx, "wrong.example", "abc123"); err != ErrTokenMismatch {
+		t.Errorf("Verify(wrong) error = %v, want ErrTokenMismatch", err)
+	}
+	if err := v.Verify(ctx, "empty.example", "abc123"); err != ErrRecordNotFound {
+		t.Errorf("Verify(empty) error = %v, want ErrRecordNotFound", err)
+	}
+	if err := v.Verify(ctx, "missing.example", "abc123"); err != ErrRecordNotFound {
+		t.Errorf("Verify(missing) error = %v, want ErrRecordNotFound", err)
+	}
+
+	err := NewVerifier(failingResolver{}).Verify(ctx, "good.example", "abc123")
+	var dnsErr *net.DNSError
+	if !errors.As(err, &dnsErr) {
+		t.Errorf("resolver failure = %v, want the DNS error passed through", err)
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	good := map[string]string{
+		"links.example.com":    "links.example.com",
+		" Links.Example.COM. ": "links.example.com",
+		"xn--bcher-kva.de":     "xn--bcher-kva.de",
+		"a-b.c0.io":            "a-b.c0.io",
+	}
+	for in, want := range good {
+		got, err := Normalize(in)
+		if err != nil || got != want {
+			t.Errorf("Normalize(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+
+	bad := []string{
+		"",
+		"localhost",
+		"https://links.example.com",
+		"links.example.com/path",
+		"links.example.com:8080",
+		"192.168.1.1",
+		"-bad.example.com",
+		"bad-.example.com",
+		"under_score.example.com",
+		"bücher.de",
+		"example.123",
+		"a..b.com",
+	}
+	for _, in := range bad {
+		if got, err := Normalize(in); err == nil {
+			t.Errorf("Normalize(%q) = %q, want error", in, got)
+		}
+	}
+
+	// The message never repeats what was typed
+	if _, err := Normalize("<img src=x onerror=alert(1)>.com"); err == nil || strings.Contains(err.Error(), "<") {
+		t.Errorf("Normalize(markup) error = %v", err)
+	}
+}
+
+func TestHostname(t *testing.T) {
+	cases := map[string]string{
+		"Links.Example.com:443": "links.example.com",
+		"links.example.com":     "links.example.com",
+		"links.example.com.":    "links.example.com",
+		"[::1]:8080":            "::1",
+	}
+	for in, want := range cases {
+		if got := Hostname(in); got != want {
+			t.Errorf("Hostname(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
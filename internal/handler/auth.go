@@ -1,12 +1,16 @@
 package handler
 
 import (
+	"fmt"
+	"html"
 	"net/http"
 
+	"linkbio/internal/i18n"
 	"linkbio/internal/model"
 	"linkbio/internal/pkg/response"
 	"linkbio/internal/pkg/templates"
 	"linkbio/internal/repository"
+	"linkbio/internal/username"
 
 	"log/slog"
 
@@ -20,21 +24,23 @@ type AuthHandler struct {
 	resp      *response.Responder
 	store     *sessions.CookieStore
 	userRepo  *repository.UserRepository
+	usernames usernameRules
 }
 
 // NewAuthHandler creates a new AuthHandler
 func NewAuthHandler(deps *Dependencies) *AuthHandler {
 	return &AuthHandler{
-		log:      deps.Log,
-		resp:     deps.Responder,
-		store:    deps.Store,
-		userRepo: deps.UserRepo,
+		log:       deps.Log,
+		resp:      deps.Responder,
+		store:     deps.Store,
+		userRepo:  deps.UserRepo,
+		usernames: newUsernameRules(deps.Config),
 	}
 }
 
 // LoginPage renders the login page
 func (h *AuthHandler) LoginPage(w http.ResponseWriter, r *http.Request) {
-	if err := templates.Render(w, "login.html", nil); err != nil {
+	if err := templates.Render(w, i18n.FromContext(r.Context()), "login.html", nil); err != nil {
 		h.log.Error("template error", "error", err)
 		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 	}
@@ -43,7 +49,7 @@ func (h *AuthHandler) LoginPage(w http.ResponseWriter, r *http.Request) {
 // Login handles user login
 func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 	if err := r.ParseForm(); err != nil {
-		h.resp.Error(w, http.StatusBadRequest, "Invalid form data")
+		h.resp.Error(w, r, http.StatusBadRequest, "Invalid form data")
 		return
 	}
 
@@ -51,7 +57,7 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 	password := r.FormValue("password")
 
 	if email == "" || password == "" {
-		h.resp.Error(w, http.StatusBadRequest, "Email and password are required")
+		h.resp.Error(w, r, http.StatusBadRequest, "Email and password are required")
 		return
 	}
 
@@ -59,17 +65,17 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 	user, err := h.userRepo.GetByEmail(r.Context(), email)
 	if err != nil {
 		h.log.Error("database error", "error", err)
-		h.resp.Error(w, http.StatusInternalServerError, "Something went wrong")
+		h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
 		return
 	}
 	if user == nil {
-		h.resp.Error(w, http.StatusUnauthorized, "Invalid email or password")
+		h.resp.Error(w, r, http.StatusUnauthorized, "Invalid email or password")
 		return
 	}
 
 	// Verify password
 	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
-		h.resp.Error(w, http.StatusUnauthorized, "Invalid email or password")
+		h.resp.Error(w, r, http.StatusUnauthorized, "Invalid email or password")
 		return
 	}
 
@@ -77,9 +83,10 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 	session, _ := h.store.Get(r, "session")
 	session.Values["user_id"] = user.ID
 	session.Values["username"] = user.Username
+	session.Values["locale"] = user.Locale
 	if err := session.Save(r, w); err != nil {
 		h.log.Error("session save error", "error", err)
-		h.resp.Error(w, http.StatusInternalServerError, "Something went wrong")
+		h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
 		return
 	}
 
@@ -91,7 +98,7 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 
 // RegisterPage renders the registration page
 func (h *AuthHandler) RegisterPage(w http.ResponseWriter, r *http.Request) {
-	if err := templates.Render(w, "register.html", nil); err != nil {
+	if err := templates.Render(w, i18n.FromContext(r.Context()), "register.html", nil); err != nil {
 		h.log.Error("template error", "error", err)
 		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 	}
@@ -100,35 +107,45 @@ func (h *AuthHandler) RegisterPage(w http.ResponseWriter, r *http.Request) {
 // Register handles user registration
 func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 	if err := r.ParseForm(); err != nil {
-		h.resp.Error(w, http.StatusBadRequest, "Invalid form data")
+		h.resp.Error(w, r, http.StatusBadRequest, "Invalid form data")
 		return
 	}
 
-	username := r.FormValue("username")
 	email := r.FormValue("email")
 	password := r.FormValue("password")
 
-	if username == "" || email == "" || password == "" {
-		h.resp.Error(w, http.StatusBadRequest, "All fields are required")
+	if r.FormValue("username") == "" || email == "" || password == "" {
+		h.resp.Error(w, r, http.StatusBadRequest, "All fields are required")
 		return
 	}
 
 	if len(password) < 6 {
-		h.resp.Error(w, http.StatusBadRequest, "Password must be at least 6 characters")
+		h.resp.Error(w, r, http.StatusBadRequest, "Password must be at least 6 characters")
 		return
 	}
 
-	// Check if username exists
-	existing, _ := h.userRepo.GetByUsername(r.Context(), username)
-	if existing != nil {
-		h.resp.Error(w, http.StatusConflict, "Username already taken")
+	name, err := username.Normalize(r.FormValue("username"))
+	if err != nil {
+		h.resp.Invalid(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	// Check if username exists or was recently released
+	available, err := h.usernames.available(r.Context(), h.userRepo, name, 0)
+	if err != nil {
+		h.log.Error("database error", "error", err)
+		h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+	if !available {
+		h.resp.Error(w, r, http.StatusConflict, "Username already taken")
 		return
 	}
 
 	// Check if email exists
-	existing, _ = h.userRepo.GetByEmail(r.Context(), email)
+	existing, _ := h.userRepo.GetByEmail(r.Context(), email)
 	if existing != nil {
-		h.resp.Error(w, http.StatusConflict, "Email already registered")
+		h.resp.Error(w, r, http.StatusConflict, "Email already registered")
 		return
 	}
 
@@ -136,22 +153,22 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
 		h.log.Error("password hash error", "error", err)
-		h.resp.Error(w, http.StatusInternalServerError, "Something went wrong")
+		h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
 		return
 	}
 
 	// Create user
 	user := &model.User{
-		Username:     username,
+		Username:     name,
 		Email:        email,
 		PasswordHash: string(hash),
-		DisplayName:  username,
+		DisplayName:  name,
 		Theme:        "light",
 	}
 
 	if err := h.userRepo.Create(r.Context(), user); err != nil {
 		h.log.Error("user creation error", "error", err)
-		h.resp.Error(w, http.StatusInternalServerError, "Something went wrong")
+		h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
 		return
 	}
 
@@ -161,12 +178,41 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 	session, _ := h.store.Get(r, "session")
 	session.Values["user_id"] = user.ID
 	session.Values["username"] = user.Username
+	session.Values["locale"] = user.Locale
 	session.Save(r, w)
 
 	// HTMX redirect
 	h.resp.HXRedirect(w, "/dashboard")
 }
 
+// CheckUsername validates a username as it is typed on the register form.
+// Problems come back as errors so the form shows them like any other; an
+// acceptable name gets a short confirmation.
+func (h *AuthHandler) CheckUsername(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Get("username") == "" {
+		return
+	}
+
+	name, err := username.Normalize(r.URL.Query().Get("username"))
+	if err != nil {
+		h.resp.Invalid(w, r, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	available, err := h.usernames.available(r.Context(), h.userRepo, name, 0)
+	if err != nil {
+		h.log.Error("database error", "error", err)
+		h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+	if !available {
+		h.resp.Error(w, r, http.StatusConflict, "Username already taken")
+		return
+	}
+
+	fmt.Fprintf(w, `<p class="text-sm text-green-400">%s</p>`, html.EscapeString(i18n.FromContext(r.Context()).T("/u/%s is available", name)))
+}
+
 // Logout handles user logout
 func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
 	session, _ := h.store.Get(r, "session")
@@ -301,6 +301,79 @@ func TestAuthHandler_Register_MissingFields(t *testing.T) {
 	}
 }
 
+func TestAuthHandler_Register_UsernamePolicy(t *testing.T) {
+	h, userRepo := setupAuthHandler(t)
+	ctx := context.Background()
+
+	userRepo.Create(ctx, &model.User{Username: "existing", Email: "existing@test.com", PasswordHash: "hash", Theme: "light"})
+
+	tests := []struct {
+		username string
+		status   int
+	}{
+		{"admin", http.StatusBadRequest},
+		{"Dashboard", http.StatusBadRequest},
+		{"john doe", http.StatusBadRequest},
+		{"a/b", http.StatusBadRequest},
+		{"аdmin", http.StatusBadRequest}, // Cyrillic а
+		{"EXISTING", http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.username, func(t *testing.T) {
+			form := url.Values{"username": {tt.username}, "email": {"new@test.com"}, "password": {"password123"}}
+			req := httptest.NewRequest(http.MethodPost, "/auth/register", strings.NewReader(form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			rec := httptest.NewRecorder()
+
+			h.Register(rec, req)
+
+			if rec.Code != tt.status {
+				t.Errorf("Status = %d, want %d (%s)", rec.Code, tt.status, rec.Body.String())
+			}
+		})
+	}
+
+	// Mixed case is stored lowercase
+	form := url.Values{"username": {"NewUser"}, "email": {"new@test.com"}, "password": {"password123"}}
+	req := httptest.NewRequest(http.MethodPost, "/auth/register", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	h.Register(httptest.NewRecorder(), req)
+
+	user, _ := userRepo.GetByEmail(ctx, "new@test.com")
+	if user == nil || user.Username != "newuser" {
+		t.Errorf("registered user = %+v, want username newuser", user)
+	}
+}
+
+func TestAuthHandler_CheckUsername(t *testing.T) {
+	h, userRepo := setupAuthHandler(t)
+	userRepo.Create(context.Background(), &model.User{Username: "existing", Email: "existing@test.com", PasswordHash: "hash", Theme: "light"})
+
+	tests := []struct {
+		username string
+		status   int
+		body     string
+	}{
+		{"", http.StatusOK, ""},
+		{"Fresh", http.StatusOK, "/u/fresh is available"},
+		{"Existing", http.StatusConflict, "Username already taken"},
+		{"api", http.StatusUnprocessableEntity, "reserved"},
+		{"a b", http.StatusUnprocessableEntity, "letters a-z"},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/auth/username?"+url.Values{"username": {tt.username}}.Encode(), nil)
+		rec := httptest.NewRecorder()
+
+		h.CheckUsername(rec, req)
+
+		if rec.Code != tt.status || !strings.Contains(rec.Body.String(), tt.body) {
+			t.Errorf("CheckUsername(%q) = %d %q, want %d containing %q", tt.username, rec.Code, rec.Body.String(), tt.status, tt.body)
+		}
+	}
+}
+
 func TestAuthHandler_Logout(t *testing.T) {
 	h, _ := setupAuthHandler(t)
 
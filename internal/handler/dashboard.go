@@ -1,9 +1,13 @@
 package handler
 
 import (
-	"html/template"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"unicode/utf8"
 
+	"linkbio/internal/i18n"
 	"linkbio/internal/middleware"
 	"linkbio/internal/model"
 	"linkbio/internal/pkg/response"
@@ -13,12 +17,21 @@ import (
 	"log/slog"
 )
 
+// Dashboard link search paging
+const (
+	linkSearchPageSize = 20
+	linkSearchMaxPage  = 500
+	linkSearchMaxQuery = 100 // characters of search text used
+)
+
 // DashboardHandler handles dashboard endpoints
 type DashboardHandler struct {
 	log           *slog.Logger
 	resp          *response.Responder
 	userRepo      *repository.UserRepository
 	linkRepo      *repository.LinkRepository
+	pageRepo      *repository.PageRepository
+	tagRepo       *repository.TagRepository
 	analyticsRepo *repository.AnalyticsRepository
 }
 
@@ -29,18 +42,80 @@ func NewDashboardHandler(deps *Dependencies) *DashboardHandler {
 		resp:          deps.Responder,
 		userRepo:      deps.UserRepo,
 		linkRepo:      deps.LinkRepo,
+		pageRepo:      deps.PageRepo,
+		tagRepo:       deps.TagRepo,
 		analyticsRepo: deps.AnalyticsRepo,
 	}
 }
 
 // DashboardData holds data for the dashboard template
 type DashboardData struct {
-	User      *model.User
-	Links     []model.Link
-	Analytics *model.AnalyticsSummary
+	User        *model.User
+	Links       []LinkCard // on the selected page
+	BrokenLinks int        // across all pages
+	Analytics   *model.AnalyticsSummary
+
+	// Pages are the profile's sub-pages; Page is the one whose links are
+	// listed, nil for the main page
+	Pages []model.Page
+	Page  *model.Page
+
+	// Tags are the creator's link tags, each with its number of links
+	Tags      []model.Tag
+	TagColors []model.TagColor
+
+	// Search holds the link search form and, while searching, its results
+	Search LinkResults
+}
+
+// LinkResults holds data for the link search form and results partial.
+// Search covers all pages; PageID only keeps the selected page tab in
+// result URLs, 0 for the main page.
+type LinkResults struct {
+	Query  string
+	Status string
+	TagID  int64
+	Links  []LinkCard
+	PageID int64
+
+	// Page is 1-based; PrevPage and NextPage are 0 at either end
+	Page     int
+	PrevPage int
+	NextPage int
+}
+
+// Searching reports whether a search or filter is set, so results replace
+// the sortable list
+func (s LinkResults) Searching() bool {
+	return s.Query != "" || s.Status != "" || s.TagID != 0
+}
+
+// URL is the dashboard address showing these results
+func (s LinkResults) URL() string {
+	v := url.Values{}
+	if s.PageID != 0 {
+		v.Set("page", strconv.FormatInt(s.PageID, 10))
+	}
+	if s.Query != "" {
+		v.Set("q", s.Query)
+	}
+	if s.Status != "" {
+		v.Set("status", s.Status)
+	}
+	if s.TagID != 0 {
+		v.Set("tag", strconv.FormatInt(s.TagID, 10))
+	}
+	if s.Page > 1 {
+		v.Set("p", strconv.Itoa(s.Page))
+	}
+	if len(v) == 0 {
+		return "/dashboard"
+	}
+	return "/dashboard?" + v.Encode()
 }
 
-// Index renders the dashboard
+// Index renders the dashboard. ?page= lists a sub-page's links by ID; the
+// link search parameters of Links show its results instead.
 func (h *DashboardHandler) Index(w http.ResponseWriter, r *http.Request) {
 	userID := middleware.UserIDFromContext(r.Context())
 	username := middleware.UsernameFromContext(r.Context())
@@ -48,14 +123,29 @@ func (h *DashboardHandler) Index(w http.ResponseWriter, r *http.Request) {
 	user, err := h.userRepo.GetByID(r.Context(), userID)
 	if err != nil {
 		h.log.Error("database error", "error", err)
-		h.resp.Error(w, http.StatusInternalServerError, "Something went wrong")
+		h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
 		return
 	}
 
 	links, err := h.linkRepo.GetByUserID(r.Context(), userID)
 	if err != nil {
 		h.log.Error("database error", "error", err)
-		h.resp.Error(w, http.StatusInternalServerError, "Something went wrong")
+		h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+
+	opts, err := loadCardOptions(r.Context(), h.pageRepo, h.tagRepo, userID)
+	if err != nil {
+		h.log.Error("database error", "error", err)
+		h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+	page, _ := pageByID(opts.pages, r.URL.Query().Get("page"))
+
+	search, err := h.searchLinks(r, userID, opts, page)
+	if err != nil {
+		h.log.Error("database error", "error", err)
+		h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
 		return
 	}
 
@@ -68,18 +158,115 @@ func (h *DashboardHandler) Index(w http.ResponseWriter, r *http.Request) {
 
 	h.log.Debug("dashboard loaded", "user_id", userID, "username", username, "links_count", len(links))
 
+	broken := 0
+	var cards []LinkCard
+	for _, l := range links {
+		if l.IsBroken() {
+			broken++
+		}
+		if l.OnPage(pageID(page)) {
+			cards = append(cards, opts.card(l))
+		}
+	}
+
 	data := DashboardData{
-		User:      user,
-		Links:     links,
-		Analytics: analytics,
+		User:        user,
+		Links:       cards,
+		BrokenLinks: broken,
+		Analytics:   analytics,
+		Pages:       opts.pages,
+		Page:        page,
+		Tags:        opts.tags,
+		TagColors:   model.TagColors,
+		Search:      search,
 	}
 
-	if err := templates.Render(w, "dashboard.html", data); err != nil {
+	if err := templates.Render(w, i18n.FromContext(r.Context()), "dashboard.html", data); err != nil {
 		h.log.Error("template error", "error", err)
 		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 	}
 }
 
+// Links returns the link search results partial for search-as-you-type.
+// ?q= searches titles and URLs, ?status= keeps active or hidden links,
+// ?tag= keeps links carrying a tag by ID and ?p= pages through the results. The address bar follows along.
+func (h *DashboardHandler) Links(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.UserIDFromContext(r.Context())
+
+	opts, err := loadCardOptions(r.Context(), h.pageRepo, h.tagRepo, userID)
+	if err != nil {
+		h.log.Error("database error", "error", err)
+		h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+	page, _ := pageByID(opts.pages, r.URL.Query().Get("page"))
+
+	results, err := h.searchLinks(r, userID, opts, page)
+	if err != nil {
+		h.log.Error("database error", "error", err)
+		h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+
+	w.Header().Set("HX-Push-Url", results.URL())
+	if err := templates.RenderPartial(w, i18n.FromContext(r.Context()), "link_results.html", results); err != nil {
+		h.log.Error("template error", "error", err)
+	}
+}
+
+// searchLinks runs the link search the request asks for. Nothing is
+// queried unless a search or filter is set.
+func (h *DashboardHandler) searchLinks(r *http.Request, userID int64, opts cardOptions, page *model.Page) (LinkResults, error) {
+	q := r.URL.Query()
+	results := LinkResults{Query: strings.TrimSpace(q.Get("q")), Page: 1}
+	if utf8.RuneCountInString(results.Query) > linkSearchMaxQuery {
+		results.Query = string([]rune(results.Query)[:linkSearchMaxQuery])
+	}
+	if s := q.Get("status"); s == model.LinkStatusActive || s == model.LinkStatusInactive {
+		results.Status = s
+	}
+	if id, err := strconv.ParseInt(q.Get("tag"), 10, 64); err == nil {
+		for _, t := range opts.tags {
+			if t.ID == id {
+				results.TagID = id
+			}
+		}
+	}
+	if page != nil {
+		results.PageID = page.ID
+	}
+	if n, err := strconv.Atoi(q.Get("p")); err == nil && n > 1 {
+		results.Page = min(n, linkSearchMaxPage)
+	}
+	if !results.Searching() {
+		results.Page = 1
+		return results, nil
+	}
+
+	// One extra row tells whether there is a next page
+	links, err := h.linkRepo.Search(r.Context(), userID, repository.LinkQuery{
+		Text:   results.Query,
+		Status: results.Status,
+		TagID:  results.TagID,
+		Limit:  linkSearchPageSize + 1,
+		Offset: (results.Page - 1) * linkSearchPageSize,
+	})
+	if err != nil {
+		return LinkResults{}, err
+	}
+	if len(links) > linkSearchPageSize {
+		links = links[:linkSearchPageSize]
+		if results.Page < linkSearchMaxPage {
+			results.NextPage = results.Page + 1
+		}
+	}
+	results.PrevPage = results.Page - 1
+	for _, l := range links {
+		results.Links = append(results.Links, opts.card(l))
+	}
+	return results, nil
+}
+
 // Stats returns the stats partial HTML for HTMX polling
 func (h *DashboardHandler) Stats(w http.ResponseWriter, r *http.Request) {
 	userID := middleware.UserIDFromContext(r.Context())
@@ -90,14 +277,8 @@ func (h *DashboardHandler) Stats(w http.ResponseWriter, r *http.Request) {
 		analytics = nil
 	}
 
-	tmpl, err := template.ParseFiles("web/templates/partials/stats.html")
-	if err != nil {
+	if err := templates.RenderPartial(w, i18n.FromContext(r.Context()), "stats.html", analytics); err != nil {
 		h.log.Error("template error", "error", err)
 		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
-		return
-	}
-
-	if err := tmpl.Execute(w, analytics); err != nil {
-		h.log.Error("template error", "error", err)
 	}
 }
@@ -0,0 +1,140 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"linkbio/internal/middleware"
+	"linkbio/internal/model"
+	"linkbio/internal/pkg/response"
+	"linkbio/internal/repository"
+	"linkbio/internal/testutil"
+)
+
+func TestDashboardHandler_Stats(t *testing.T) {
+	testutil.ChdirRoot(t)
+
+	db := testutil.TestDB(t)
+	log := testutil.TestLogger()
+	userRepo := repository.NewUserRepository(db)
+	analyticsRepo := repository.NewAnalyticsRepository(db)
+	ctx := context.Background()
+
+	user := &model.User{Username: "stats", Email: "stats@test.com", PasswordHash: "hash", Theme: "light"}
+	if err := userRepo.Create(ctx, user); err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	analyticsRepo.Record(ctx, &model.Analytics{UserID: user.ID, EventType: "page_view", Source: model.SourceQR})
+
+	h := &DashboardHandler{log: log, resp: response.New(log), userRepo: userRepo, analyticsRepo: analyticsRepo}
+	req := httptest.NewRequest(http.MethodGet, "/dashboard/stats", nil)
+	req = req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, user.ID))
+	rec := httptest.NewRecorder()
+	h.Stats(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d", rec.Code)
+	}
+	if body := rec.Body.String(); !strings.Contains(body, "Total Clicks") || !strings.Contains(body, "QR code") {
+		t.Errorf("stats partial missing totals or sources: %s", body)
+	}
+}
+
+func TestDashboardHandler_Links(t *testing.T) {
+	testutil.ChdirRoot(t)
+
+	db := testutil.TestDB(t)
+	log := testutil.TestLogger()
+	userRepo := repository.NewUserRepository(db)
+	linkRepo := repository.NewLinkRepository(db)
+	pageRepo := repository.NewPageRepository(db)
+	tagRepo := repository.NewTagRepository(db)
+	analyticsRepo := repository.NewAnalyticsRepository(db)
+	ctx := context.Background()
+
+	user := &model.User{Username: "manylinks", Email: "many@test.com", PasswordHash: "hash", Theme: "light"}
+	if err := userRepo.Create(ctx, user); err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	for i := 1; i <= linkSearchPageSize+1; i++ {
+		link := &model.Link{UserID: user.ID, Title: fmt.Sprintf("Episode %02d", i), URL: fmt.Sprintf("https://podcast.example.com/%d", i), IsActive: i != 1}
+		if err := linkRepo.Create(ctx, link); err != nil {
+			t.Fatalf("failed to create link: %v", err)
+		}
+	}
+	shop := &model.Link{UserID: user.ID, Title: "Shop", URL: "https://shop.example.com", IsActive: true}
+	linkRepo.Create(ctx, shop)
+	sponsored := &model.Tag{UserID: user.ID, Name: "sponsored", Color: "amber"}
+	if err := tagRepo.Create(ctx, sponsored); err != nil {
+		t.Fatalf("failed to create tag: %v", err)
+	}
+	tagRepo.SetLinkTags(ctx, user.ID, shop.ID, []int64{sponsored.ID})
+
+	h := &DashboardHandler{log: log, resp: response.New(log), userRepo: userRepo, linkRepo: linkRepo, pageRepo: pageRepo, tagRepo: tagRepo, analyticsRepo: analyticsRepo}
+	get := func(handle http.HandlerFunc, target string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		req = req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, user.ID))
+		rec := httptest.NewRecorder()
+		handle(rec, req)
+		return rec
+	}
+	cards := func(body string) int { return strings.Count(body, "data-link-id=") }
+
+	rec := get(h.Links, "/dashboard/links?q=episode")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d", rec.Code)
+	}
+	if got := rec.Header().Get("HX-Push-Url"); got != "/dashboard?q=episode" {
+		t.Errorf("HX-Push-Url = %q", got)
+	}
+	body := rec.Body.String()
+	if cards(body) != linkSearchPageSize || !strings.Contains(body, "p=2") || strings.Contains(body, "Shop") {
+		t.Errorf("first page should hold %d episodes and link to the next, got %d", linkSearchPageSize, cards(body))
+	}
+	if body := get(h.Links, "/dashboard/links?q=episode&p=2").Body.String(); cards(body) != 1 {
+		t.Errorf("second page holds %d links, want 1", cards(body))
+	}
+
+	if body := get(h.Links, "/dashboard/links?q=episode&status=inactive").Body.String(); cards(body) != 1 || !strings.Contains(body, "Episode 01") {
+		t.Errorf("status filter not applied: %d links", cards(body))
+	}
+	if body := get(h.Links, "/dashboard/links?q=shop.example").Body.String(); cards(body) != 1 || !strings.Contains(body, "Shop") {
+		t.Error("URL search did not find the shop link")
+	}
+	tag := "tag=" + strconv.FormatInt(sponsored.ID, 10)
+	rec = get(h.Links, "/dashboard/links?q=&"+tag)
+	if body := rec.Body.String(); cards(body) != 1 || !strings.Contains(body, "Shop") {
+		t.Errorf("tag filter not applied: %d links", cards(body))
+	}
+	if got := rec.Header().Get("HX-Push-Url"); got != "/dashboard?"+tag {
+		t.Errorf("HX-Push-Url = %q", got)
+	}
+	if body := get(h.Links, "/dashboard/links?q=episode&"+tag).Body.String(); !strings.Contains(body, "No links match") {
+		t.Error("tag filter not combined with the search")
+	}
+	// Someone else's tag, or none at all, filters nothing
+	if rec := get(h.Links, "/dashboard/links?tag=9999"); rec.Header().Get("HX-Push-Url") != "/dashboard" {
+		t.Errorf("unknown tag kept: %q", rec.Header().Get("HX-Push-Url"))
+	}
+
+	if body := get(h.Links, "/dashboard/links?q=nothing").Body.String(); !strings.Contains(body, "No links match") {
+		t.Error("empty results not reported")
+	}
+
+	// Clearing the search returns to the sortable list
+	rec = get(h.Links, "/dashboard/links?q=&status=")
+	if rec.Header().Get("HX-Push-Url") != "/dashboard" || cards(rec.Body.String()) != 0 {
+		t.Errorf("cleared search: push = %q, %d links", rec.Header().Get("HX-Push-Url"), cards(rec.Body.String()))
+	}
+
+	// A shared search address opens with its results
+	body = get(h.Index, "/dashboard?q=shop").Body.String()
+	if !strings.Contains(body, `value="shop"`) || !strings.Contains(body, "searching: true") {
+		t.Error("dashboard did not open on the search results")
+	}
+}
@@ -0,0 +1,260 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+	"strconv"
+	"time"
+
+	"linkbio/internal/customdomain"
+	"linkbio/internal/i18n"
+	"linkbio/internal/middleware"
+	"linkbio/internal/model"
+	"linkbio/internal/pkg/response"
+	"linkbio/internal/pkg/templates"
+	"linkbio/internal/repository"
+
+	"log/slog"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// DomainHandler manages custom domains and routes requests that arrive on
+// them
+type DomainHandler struct {
+	log        *slog.Logger
+	resp       *response.Responder
+	domainRepo *repository.DomainRepository
+	verifier   *customdomain.Verifier
+	primary    map[string]bool
+}
+
+// NewDomainHandler creates a new DomainHandler
+func NewDomainHandler(deps *Dependencies) *DomainHandler {
+	primary := make(map[string]bool)
+	for _, host := range deps.Config.PrimaryHosts() {
+		primary[host] = true
+	}
+	return &DomainHandler{
+		log:        deps.Log,
+		resp:       deps.Responder,
+		domainRepo: deps.DomainRepo,
+		verifier:   customdomain.NewVerifier(deps.Resolver),
+		primary:    primary,
+	}
+}
+
+// domainContextKey carries the custom domain a request arrived on
+type domainContextKey struct{}
+
+// customDomainFromContext returns the verified domain serving the request,
+// or nil on the main site
+func customDomainFromContext(ctx context.Context) *model.CustomDomain {
+	d, _ := ctx.Value(domainContextKey{}).(*model.CustomDomain)
+	return d
+}
+
+// DomainView is a domain with the DNS record the owner must publish
+type DomainView struct {
+	model.CustomDomain
+	RecordName  string
+	RecordValue string
+}
+
+// domainViews prepares domains for templates
+func domainViews(domains []model.CustomDomain) []DomainView {
+	views := make([]DomainView, len(domains))
+	for i, d := range domains {
+		views[i] = DomainView{
+			CustomDomain: d,
+			RecordName:   customdomain.RecordName(d.Domain),
+			RecordValue:  customdomain.RecordValue(d.Token),
+		}
+	}
+	return views
+}
+
+// Route sends requests for verified custom domains to custom, with the
+// owner's username set as the "username" URL parameter. Requests for the
+// main site's hosts continue down the chain; any other host is rejected.
+// /health answers on every host so load balancers can probe by IP.
+func (h *DomainHandler) Route(custom http.Handler) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			host := customdomain.Hostname(r.Host)
+			if h.primary[host] || r.URL.Path == "/health" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			d, err := h.domainRepo.GetVerified(r.Context(), host)
+			if err != nil {
+				h.log.Error("database error", "error", err)
+				h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
+				return
+			}
+			if d == nil {
+				h.resp.Error(w, r, http.StatusMisdirectedRequest, "Unknown host")
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), domainContextKey{}, d)
+			if rctx := chi.RouteContext(ctx); rctx != nil {
+				rctx.URLParams.Add("username", d.Username)
+			}
+			custom.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// Add claims a domain for the current user and shows the TXT record to
+// publish
+func (h *DomainHandler) Add(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.UserIDFromContext(r.Context())
+	if userID == 0 {
+		h.resp.Error(w, r, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		h.resp.Error(w, r, http.StatusBadRequest, "Invalid form data")
+		return
+	}
+
+	domain, err := customdomain.Normalize(r.FormValue("domain"))
+	if err != nil {
+		h.resp.Invalid(w, r, http.StatusUnprocessableEntity, err)
+		return
+	}
+	if h.primary[domain] {
+		h.resp.Error(w, r, http.StatusUnprocessableEntity, "That domain belongs to this site")
+		return
+	}
+
+	existing, err := h.domainRepo.GetByUserAndDomain(r.Context(), userID, domain)
+	if err != nil {
+		h.log.Error("database error", "error", err)
+		h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+	if existing != nil {
+		h.resp.Errorf(w, r, http.StatusConflict, "You have already added %s", domain)
+		return
+	}
+
+	token, err := customdomain.NewToken()
+	if err != nil {
+		h.log.Error("token error", "error", err)
+		h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+
+	d := &model.CustomDomain{UserID: userID, Domain: domain, Token: token}
+	if err := h.domainRepo.Create(r.Context(), d); err != nil {
+		h.log.Error("domain create error", "error", err)
+		h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+
+	h.log.Info("custom domain added", "user_id", userID, "domain", domain)
+	h.respond(w, r, userID, i18n.FromContext(r.Context()).T("Add the TXT record below, then press Verify"))
+}
+
+// Verify checks the domain's TXT record
+func (h *DomainHandler) Verify(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.UserIDFromContext(r.Context())
+	d, ok := h.owned(w, r, userID)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	if err := h.verifier.Verify(ctx, d.Domain, d.Token); err != nil {
+		switch {
+		case errors.Is(err, customdomain.ErrRecordNotFound):
+			h.resp.Errorf(w, r, http.StatusUnprocessableEntity, "No TXT record found at %s yet. DNS changes can take a while to appear.", customdomain.RecordName(d.Domain))
+		case errors.Is(err, customdomain.ErrTokenMismatch):
+			h.resp.Errorf(w, r, http.StatusUnprocessableEntity, "The TXT record at %s has a different value", customdomain.RecordName(d.Domain))
+		default:
+			h.log.Warn("domain lookup failed", "domain", d.Domain, "error", err)
+			h.resp.Error(w, r, http.StatusBadGateway, "Could not look up the domain. Try again shortly.")
+		}
+		return
+	}
+
+	verified, err := h.domainRepo.MarkVerified(r.Context(), d.ID)
+	if err != nil {
+		h.log.Error("database error", "error", err)
+		h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+	if !verified {
+		h.resp.Errorf(w, r, http.StatusConflict, "%s is already connected to another profile", d.Domain)
+		return
+	}
+
+	h.log.Info("custom domain verified", "user_id", userID, "domain", d.Domain)
+	h.respond(w, r, userID, i18n.FromContext(r.Context()).T("%s now serves your profile. Point it at this site with a CNAME record.", d.Domain))
+}
+
+// Delete removes a domain claim
+func (h *DomainHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.UserIDFromContext(r.Context())
+	d, ok := h.owned(w, r, userID)
+	if !ok {
+		return
+	}
+
+	if err := h.domainRepo.Delete(r.Context(), d.ID, userID); err != nil {
+		h.log.Error("database error", "error", err)
+		h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+
+	h.log.Info("custom domain removed", "user_id", userID, "domain", d.Domain)
+	h.respond(w, r, userID, i18n.FromContext(r.Context()).T("%s removed", d.Domain))
+}
+
+// owned loads the domain named by the URL and checks the current user owns
+// it, writing the error response if not
+func (h *DomainHandler) owned(w http.ResponseWriter, r *http.Request, userID int64) (*model.CustomDomain, bool) {
+	if userID == 0 {
+		h.resp.Error(w, r, http.StatusUnauthorized, "Unauthorized")
+		return nil, false
+	}
+
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		h.resp.Error(w, r, http.StatusBadRequest, "Invalid domain ID")
+		return nil, false
+	}
+
+	d, err := h.domainRepo.GetByID(r.Context(), id)
+	if err != nil || d == nil || d.UserID != userID {
+		h.resp.Error(w, r, http.StatusNotFound, "Domain not found")
+		return nil, false
+	}
+	return d, true
+}
+
+// respond writes a feedback message and refreshes the domain list
+// out-of-band
+func (h *DomainHandler) respond(w http.ResponseWriter, r *http.Request, userID int64, message string) {
+	domains, err := h.domainRepo.ListByUser(r.Context(), userID)
+	if err != nil {
+		h.log.Error("database error", "error", err)
+	}
+
+	fmt.Fprintf(w, `<div class="rounded-xl px-4 py-3 text-sm bg-green-50 dark:bg-green-900/20 text-green-700 dark:text-green-400 animate-slide-in">%s</div>`, html.EscapeString(message))
+
+	fmt.Fprint(w, `<div hx-swap-oob="innerHTML:#domain-list">`)
+	if err := templates.RenderPartial(w, i18n.FromContext(r.Context()), "domains.html", domainViews(domains)); err != nil {
+		h.log.Error("template error", "error", err)
+	}
+	fmt.Fprint(w, `</div>`)
+}
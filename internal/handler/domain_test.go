@@ -0,0 +1,237 @@
+package handler
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"linkbio/internal/customdomain"
+	"linkbio/internal/middleware"
+	"linkbio/internal/model"
+	"linkbio/internal/pkg/response"
+	"linkbio/internal/repository"
+	"linkbio/internal/testutil"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// fakeTXT serves TXT records from a map
+type fakeTXT map[string][]string
+
+func (f fakeTXT) LookupTXT(ctx context.Context, name string) ([]string, error) {
+	if records, ok := f[name]; ok {
+		return records, nil
+	}
+	return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+}
+
+type domainFixture struct {
+	h          *DomainHandler
+	dns        fakeTXT
+	userRepo   *repository.UserRepository
+	linkRepo   *repository.LinkRepository
+	domainRepo *repository.DomainRepository
+	draftRepo  *repository.DraftRepository
+	user       *model.User
+	router     http.Handler
+}
+
+func setupDomainHandler(t *testing.T) *domainFixture {
+	t.Helper()
+
+	db := testutil.TestDB(t)
+	log := testutil.TestLogger()
+	f := &domainFixture{
+		dns:        fakeTXT{},
+		userRepo:   repository.NewUserRepository(db),
+		linkRepo:   repository.NewLinkRepository(db),
+		domainRepo: repository.NewDomainRepository(db),
+		draftRepo:  repository.NewDraftRepository(db),
+	}
+
+	f.user = &model.User{Username: "brand", Email: "brand@test.com", PasswordHash: "hash", DisplayName: "Brand", Theme: "light"}
+	if err := f.userRepo.Create(context.Background(), f.user); err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+
+	f.h = &DomainHandler{
+		log:        log,
+		resp:       response.New(log),
+		domainRepo: f.domainRepo,
+		verifier:   customdomain.NewVerifier(f.dns),
+		primary:    map[string]bool{"linkbio.test": true},
+	}
+
+	// Mirror router.New: the domain middleware in front of the main site,
+	// with a separate router for custom domains
+	profile := &ProfileHandler{
+		log:           log,
+		resp:          response.New(log),
+		userRepo:      f.userRepo,
+		linkRepo:      f.linkRepo,
+		analyticsRepo: repository.NewAnalyticsRepository(db),
+		domainRepo:    f.domainRepo,
+		socialRepo:    repository.NewSocialRepository(db),
+		pageRepo:      repository.NewPageRepository(db),
+	}
+	links := &LinkHandler{
+		log:           log,
+		resp:          response.New(log),
+		linkRepo:      f.linkRepo,
+		analyticsRepo: repository.NewAnalyticsRepository(db),
+		userRepo:      f.userRepo,
+		lock:          profileLock{userRepo: f.userRepo},
+	}
+	custom := chi.NewRouter()
+	custom.Get("/", profile.Show)
+	custom.Get("/click/{id}", links.Click)
+
+	r := chi.NewRouter()
+	r.Use(f.h.Route(custom))
+	r.Get("/health", func(w http.ResponseWriter, r *http.Request) { w.Write([]byte("ok")) })
+	r.Get("/", func(w http.ResponseWriter, r *http.Request) { w.Write([]byte("home")) })
+	r.Get("/click/{id}", links.Click)
+	f.router = r
+
+	return f
+}
+
+// authed attaches the fixture user to a request
+func (f *domainFixture) authed(req *http.Request) *http.Request {
+	return req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, f.user.ID))
+}
+
+// call runs a domain API handler with the {id} parameter set
+func (f *domainFixture) call(handler http.HandlerFunc, method string, id int64, form url.Values) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "/api/v1/domains", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", strconv.FormatInt(id, 10))
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+	rec := httptest.NewRecorder()
+	handler(rec, f.authed(req))
+	return rec
+}
+
+func (f *domainFixture) get(host, path string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.Host = host
+	rec := httptest.NewRecorder()
+	f.router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestDomainHandler_AddAndVerify(t *testing.T) {
+	testutil.ChdirRoot(t)
+	f := setupDomainHandler(t)
+
+	rec := f.call(f.h.Add, http.MethodPost, 0, url.Values{"domain": {"Links.Brand.Example."}})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Add status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	d, _ := f.domainRepo.GetByUserAndDomain(context.Background(), f.user.ID, "links.brand.example")
+	if d == nil || d.Token == "" {
+		t.Fatalf("domain was not stored normalized: %+v", d)
+	}
+	if !strings.Contains(rec.Body.String(), "_linkbio-verify.links.brand.example") ||
+		!strings.Contains(rec.Body.String(), "linkbio-verify="+d.Token) {
+		t.Error("response does not show the TXT record to publish")
+	}
+
+	if rec := f.call(f.h.Add, http.MethodPost, 0, url.Values{"domain": {"links.brand.example"}}); rec.Code != http.StatusConflict {
+		t.Errorf("duplicate Add status = %d, want %d", rec.Code, http.StatusConflict)
+	}
+	for _, bad := range []string{"https://x.example/", "linkbio.test", "localhost"} {
+		if rec := f.call(f.h.Add, http.MethodPost, 0, url.Values{"domain": {bad}}); rec.Code != http.StatusUnprocessableEntity {
+			t.Errorf("Add(%q) status = %d, want %d", bad, rec.Code, http.StatusUnprocessableEntity)
+		}
+	}
+
+	// No record yet, then a wrong one, then the right one
+	if rec := f.call(f.h.Verify, http.MethodPost, d.ID, nil); rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Verify without record status = %d", rec.Code)
+	}
+	f.dns["_linkbio-verify.links.brand.example"] = []string{"linkbio-verify=nope"}
+	if rec := f.call(f.h.Verify, http.MethodPost, d.ID, nil); rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Verify with wrong record status = %d", rec.Code)
+	}
+	f.dns["_linkbio-verify.links.brand.example"] = []string{customdomain.RecordValue(d.Token)}
+	if rec := f.call(f.h.Verify, http.MethodPost, d.ID, nil); rec.Code != http.StatusOK {
+		t.Fatalf("Verify status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	if got, _ := f.domainRepo.GetVerified(context.Background(), "links.brand.example"); got == nil {
+		t.Error("domain is not verified")
+	}
+}
+
+func TestDomainHandler_VerifyRejectsOtherUsersDomain(t *testing.T) {
+	f := setupDomainHandler(t)
+
+	other := &model.User{Username: "other", Email: "other@test.com", PasswordHash: "hash", Theme: "light"}
+	f.userRepo.Create(context.Background(), other)
+	d := &model.CustomDomain{UserID: other.ID, Domain: "other.example", Token: "t"}
+	f.domainRepo.Create(context.Background(), d)
+
+	if rec := f.call(f.h.Verify, http.MethodPost, d.ID, nil); rec.Code != http.StatusNotFound {
+		t.Errorf("Verify status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+	if rec := f.call(f.h.Delete, http.MethodDelete, d.ID, nil); rec.Code != http.StatusNotFound {
+		t.Errorf("Delete status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
+
+func TestDomainHandler_Route(t *testing.T) {
+	testutil.ChdirRoot(t)
+	f := setupDomainHandler(t)
+	ctx := context.Background()
+
+	verified := &model.CustomDomain{UserID: f.user.ID, Domain: "links.brand.example", Token: "t"}
+	f.domainRepo.Create(ctx, verified)
+	f.domainRepo.MarkVerified(ctx, verified.ID)
+	f.domainRepo.Create(ctx, &model.CustomDomain{UserID: f.user.ID, Domain: "pending.example", Token: "t"})
+
+	own := &model.Link{UserID: f.user.ID, Title: "Own", URL: "https://own.example", IsActive: true}
+	f.linkRepo.Create(ctx, own)
+	other := &model.User{Username: "other", Email: "other@test.com", PasswordHash: "hash", Theme: "light"}
+	f.userRepo.Create(ctx, other)
+	foreign := &model.Link{UserID: other.ID, Title: "Foreign", URL: "https://foreign.example", IsActive: true}
+	f.linkRepo.Create(ctx, foreign)
+	f.draftRepo.Publish(ctx, f.user.ID, time.Now())
+	f.draftRepo.Publish(ctx, other.ID, time.Now())
+
+	if rec := f.get("linkbio.test:8080", "/"); rec.Body.String() != "home" {
+		t.Errorf("primary host served %q", rec.Body.String())
+	}
+
+	rec := f.get("Links.Brand.Example", "/")
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "@brand") {
+		t.Errorf("custom domain status = %d, want the brand profile", rec.Code)
+	}
+
+	if rec := f.get("links.brand.example", "/click/"+strconv.FormatInt(own.ID, 10)); rec.Code != http.StatusTemporaryRedirect {
+		t.Errorf("own link click status = %d", rec.Code)
+	}
+	if rec := f.get("links.brand.example", "/click/"+strconv.FormatInt(foreign.ID, 10)); rec.Code != http.StatusNotFound {
+		t.Errorf("foreign link click status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+	// The main site still redirects any link
+	if rec := f.get("linkbio.test", "/click/"+strconv.FormatInt(foreign.ID, 10)); rec.Code != http.StatusTemporaryRedirect {
+		t.Errorf("main site click status = %d", rec.Code)
+	}
+
+	for _, host := range []string{"pending.example", "evil.example", "127.0.0.1:8080"} {
+		if rec := f.get(host, "/"); rec.Code != http.StatusMisdirectedRequest {
+			t.Errorf("host %s status = %d, want %d", host, rec.Code, http.StatusMisdirectedRequest)
+		}
+	}
+	if rec := f.get("127.0.0.1:8080", "/health"); rec.Code != http.StatusOK {
+		t.Errorf("/health on an unknown host status = %d", rec.Code)
+	}
+}
@@ -0,0 +1,156 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"linkbio/internal/middleware"
+	"linkbio/internal/model"
+	"linkbio/internal/pkg/ical"
+	"linkbio/internal/pkg/vcard"
+	"linkbio/internal/social"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Download serves the file behind one of the current user's contact or
+// event links as it stands in the draft, for the dashboard preview
+func (h *LinkHandler) Download(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.UserIDFromContext(r.Context())
+	if userID == 0 {
+		h.resp.Error(w, r, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	linkID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		h.resp.Error(w, r, http.StatusBadRequest, "Invalid link ID")
+		return
+	}
+
+	link, err := h.linkRepo.GetByID(r.Context(), linkID)
+	if err != nil || link == nil || link.UserID != userID || !link.IsDownload() {
+		h.resp.Error(w, r, http.StatusNotFound, "Link not found")
+		return
+	}
+
+	w.Header().Set("Cache-Control", "private, no-store")
+	h.download(w, r, link, false)
+}
+
+// download writes the vCard or iCalendar file for a contact or event link.
+// published picks the profile visitors see over the creator's draft.
+func (h *LinkHandler) download(w http.ResponseWriter, r *http.Request, link *model.Link, published bool) {
+	get := h.userRepo.GetByID
+	if published {
+		get = h.userRepo.GetPublishedByID
+	}
+	user, err := get(r.Context(), link.UserID)
+	if err != nil || user == nil {
+		h.log.Error("database error", "error", err)
+		h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+
+	profileURL, err := canonicalProfileURL(r.Context(), h.domainRepo, h.baseURL, user)
+	if err != nil {
+		h.log.Error("database error", "error", err)
+		h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+
+	var data []byte
+	var contentType, filename string
+	switch link.Kind {
+	case model.LinkKindContact:
+		socials, err := h.socialRepo.ListByUser(r.Context(), user.ID)
+		if err != nil {
+			h.log.Error("database error", "error", err)
+			h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
+			return
+		}
+		data = contactCard(user, socials, profileURL, h.baseURL).Bytes()
+		contentType, filename = vcard.ContentType, user.Username+".vcf"
+	case model.LinkKindEvent:
+		data = calendarEvent(link, profileURL, h.baseURL, time.Now()).Bytes()
+		contentType, filename = ical.ContentType, fmt.Sprintf("event-%d.ics", link.ID)
+	default:
+		h.resp.Error(w, r, http.StatusNotFound, "Link not found")
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	w.Write(data)
+}
+
+// contactCard builds a vCard from the creator's profile: their name, bio,
+// avatar, profile address, email icon and other social icons
+func contactCard(user *model.User, socials []model.SocialProfile, profileURL, baseURL string) vcard.Card {
+	card := vcard.Card{
+		Name: user.DisplayName,
+		Note: user.Bio,
+		URL:  profileURL,
+	}
+	if card.Name == "" {
+		card.Name = user.Username
+	}
+	if user.AvatarURL != "" {
+		card.PhotoURL = absoluteURL(baseURL, user.AvatarURL)
+	}
+	for _, s := range socials {
+		if s.Platform == social.Email {
+			card.Email = s.Handle
+			continue
+		}
+		if u := s.URL(); u != "" {
+			card.Socials = append(card.Socials, vcard.Social{Type: s.Platform, URL: u})
+		}
+	}
+	return card
+}
+
+// calendarEvent builds the iCalendar event for an event link. The UID is
+// derived from the link so re-downloading updates the same calendar entry.
+func calendarEvent(link *model.Link, profileURL, baseURL string, now time.Time) ical.Event {
+	event := ical.Event{
+		UID:      fmt.Sprintf("link-%d@%s", link.ID, hostOf(baseURL)),
+		Summary:  link.Title,
+		Location: link.Location,
+		URL:      profileURL,
+		Stamp:    now,
+	}
+	if link.StartsAt != nil {
+		event.Start = *link.StartsAt
+	}
+	if link.EndsAt != nil {
+		event.End = *link.EndsAt
+	}
+	return event
+}
+
+// absoluteURL resolves ref, which may be a site path like /media/x.jpg,
+// against baseURL
+func absoluteURL(baseURL, ref string) string {
+	b, err := url.Parse(baseURL)
+	if err != nil {
+		return ref
+	}
+	u, err := url.Parse(ref)
+	if err != nil {
+		return ref
+	}
+	return b.ResolveReference(u).String()
+}
+
+// hostOf is the host name of a URL, or the URL itself if it has none
+func hostOf(raw string) string {
+	if u, err := url.Parse(raw); err == nil && u.Hostname() != "" {
+		return u.Hostname()
+	}
+	return strings.TrimSuffix(raw, "/")
+}
@@ -0,0 +1,175 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"linkbio/internal/middleware"
+	"linkbio/internal/model"
+	"linkbio/internal/pkg/response"
+	"linkbio/internal/repository"
+	"linkbio/internal/social"
+	"linkbio/internal/testutil"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestLinkHandler_Downloads(t *testing.T) {
+	testutil.ChdirRoot(t)
+
+	db := testutil.TestDB(t)
+	log := testutil.TestLogger()
+	userRepo := repository.NewUserRepository(db)
+	linkRepo := repository.NewLinkRepository(db)
+	socialRepo := repository.NewSocialRepository(db)
+	analyticsRepo := repository.NewAnalyticsRepository(db)
+	ctx := context.Background()
+
+	user := &model.User{Username: "closer", Email: "closer@test.com", PasswordHash: "hash", DisplayName: "Sam Closer, Sales", Bio: "Deals; demos", AvatarURL: "/media/sam.jpg", Theme: "light"}
+	if err := userRepo.Create(ctx, user); err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	socialRepo.Save(ctx, &model.SocialProfile{UserID: user.ID, Platform: social.Email, Handle: "sam@example.com"})
+	socialRepo.Save(ctx, &model.SocialProfile{UserID: user.ID, Platform: social.GitHub, Handle: "samcloser"})
+
+	h := &LinkHandler{
+		log:           log,
+		resp:          response.New(log),
+		linkRepo:      linkRepo,
+		analyticsRepo: analyticsRepo,
+		pageRepo:      repository.NewPageRepository(db),
+		tagRepo:       repository.NewTagRepository(db),
+		domainRepo:    repository.NewDomainRepository(db),
+		socialRepo:    socialRepo,
+		userRepo:      userRepo,
+		lock:          profileLock{userRepo: userRepo},
+		baseURL:       "https://linkbio.test",
+	}
+	r := chi.NewRouter()
+	r.Post("/api/v1/links", h.Create)
+	r.Get("/api/v1/links/{id}/download", h.Download)
+	r.Get("/click/{id}", h.Click)
+
+	signedIn := func(req *http.Request) *http.Request {
+		ctx := context.WithValue(req.Context(), middleware.UserIDKey, user.ID)
+		return req.WithContext(context.WithValue(ctx, middleware.UsernameKey, user.Username))
+	}
+	create := func(form url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/links", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, signedIn(req))
+		return rec
+	}
+	get := func(req *http.Request) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		return rec
+	}
+
+	invalid := []url.Values{
+		{"kind": {"event"}, "starts_at": {"2026-06-01T19:00"}},
+		{"kind": {"event"}, "title": {"Launch"}},
+		{"kind": {"event"}, "title": {"Launch"}, "starts_at": {"2026-06-01T19:00"}, "ends_at": {"2026-06-01T18:00"}},
+		{"kind": {"event"}, "title": {"Launch"}, "starts_at": {"2026-06-01T19:00"}, "time_zone": {"Mars/Olympus"}},
+		{"kind": {"podcast"}, "title": {"Episode"}},
+		{"title": {"No address"}},
+	}
+	for _, form := range invalid {
+		if rec := create(form); rec.Code != http.StatusUnprocessableEntity {
+			t.Errorf("create(%v) status = %d, want 422", form, rec.Code)
+		}
+	}
+
+	if rec := create(url.Values{"kind": {"contact"}}); rec.Code != http.StatusOK {
+		t.Fatalf("create contact status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	rec := create(url.Values{
+		"kind":      {"event"},
+		"title":     {"Launch party, v2"},
+		"starts_at": {"2026-06-01T19:00"},
+		"ends_at":   {"2026-06-01T22:30"},
+		"location":  {"Pier 70; San Francisco"},
+		"time_zone": {"America/Los_Angeles"},
+	})
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "Pier 70") {
+		t.Fatalf("create event status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+
+	links, _ := linkRepo.GetByUserID(ctx, user.ID)
+	if len(links) != 2 {
+		t.Fatalf("links = %+v, want 2", links)
+	}
+	contact, event := links[0], links[1]
+	if contact.Kind != model.LinkKindContact || event.Kind != model.LinkKindEvent || event.URL != "" {
+		t.Fatalf("links = %+v", links)
+	}
+	if want := time.Date(2026, 6, 2, 2, 0, 0, 0, time.UTC); event.StartsAt == nil || !event.StartsAt.Equal(want) {
+		t.Errorf("StartsAt = %v, want %v", event.StartsAt, want)
+	}
+	clickPath := func(l model.Link) string { return "/click/" + strconv.FormatInt(l.ID, 10) }
+
+	// Visitors can't download a link before it is published; its owner can
+	if rec := get(httptest.NewRequest(http.MethodGet, clickPath(event), nil)); rec.Code != http.StatusNotFound {
+		t.Errorf("unpublished click status = %d", rec.Code)
+	}
+	rec = get(signedIn(httptest.NewRequest(http.MethodGet, "/api/v1/links/"+strconv.FormatInt(event.ID, 10)+"/download", nil)))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "BEGIN:VEVENT") {
+		t.Errorf("owner download status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+
+	repository.NewDraftRepository(db).Publish(ctx, user.ID, time.Now())
+
+	rec = get(httptest.NewRequest(http.MethodGet, clickPath(contact), nil))
+	body := rec.Body.String()
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "text/vcard; charset=utf-8" {
+		t.Fatalf("contact status = %d, Content-Type = %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	if got := rec.Header().Get("Content-Disposition"); got != `attachment; filename="closer.vcf"` {
+		t.Errorf("contact Content-Disposition = %q", got)
+	}
+	for _, want := range []string{
+		`FN:Sam Closer\, Sales` + "\r\n",
+		`NOTE:Deals\; demos` + "\r\n",
+		"URL:https://linkbio.test/u/closer\r\n",
+		"PHOTO;VALUE=uri:https://linkbio.test/media/sam.jpg\r\n",
+		"EMAIL;TYPE=INTERNET:sam@example.com\r\n",
+		"X-SOCIALPROFILE;TYPE=github:https://github.com/samcloser\r\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("vCard missing %q:\n%s", want, body)
+		}
+	}
+
+	rec = get(httptest.NewRequest(http.MethodGet, clickPath(event), nil))
+	body = rec.Body.String()
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "text/calendar; charset=utf-8" {
+		t.Fatalf("event status = %d, Content-Type = %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	for _, want := range []string{
+		"UID:link-" + strconv.FormatInt(event.ID, 10) + "@linkbio.test\r\n",
+		"DTSTART:20260602T020000Z\r\n",
+		"DTEND:20260602T053000Z\r\n",
+		`SUMMARY:Launch party\, v2` + "\r\n",
+		`LOCATION:Pier 70\; San Francisco` + "\r\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("calendar missing %q:\n%s", want, body)
+		}
+	}
+
+	// Downloads count as clicks
+	for i := 0; i < 100; i++ {
+		if summary, _ := analyticsRepo.GetSummary(ctx, user.ID, 1); summary != nil && summary.TotalClicks == 2 {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Error("downloads were not recorded as clicks")
+}
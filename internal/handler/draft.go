@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"linkbio/internal/i18n"
+	"linkbio/internal/middleware"
+	"linkbio/internal/model"
+	"linkbio/internal/pkg/response"
+	"linkbio/internal/pkg/templates"
+	"linkbio/internal/repository"
+	"linkbio/internal/storage"
+
+	"log/slog"
+)
+
+// draftChanged is the HX-Trigger event that refreshes the draft bar after
+// an edit
+const draftChanged = "draftChanged"
+
+// DraftHandler publishes and reverts a creator's staged profile changes
+type DraftHandler struct {
+	log       *slog.Logger
+	resp      *response.Responder
+	draftRepo *repository.DraftRepository
+	mediaRepo *repository.MediaRepository
+	blob      storage.Blob
+	feeds     *feedCache // set by New
+}
+
+// NewDraftHandler creates a new DraftHandler
+func NewDraftHandler(deps *Dependencies) *DraftHandler {
+	return &DraftHandler{
+		log:       deps.Log,
+		resp:      deps.Responder,
+		draftRepo: deps.DraftRepo,
+		mediaRepo: deps.MediaRepo,
+		blob:      deps.Blob,
+	}
+}
+
+// DraftBarData holds data for the draft bar partial
+type DraftBarData struct {
+	model.DraftStatus
+	Message string
+}
+
+// Bar renders the dashboard's publish bar
+func (h *DraftHandler) Bar(w http.ResponseWriter, r *http.Request) {
+	h.renderBar(w, r, "")
+}
+
+// Publish makes the draft live
+func (h *DraftHandler) Publish(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.UserIDFromContext(r.Context())
+	if userID == 0 {
+		h.resp.Error(w, r, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	keys, err := h.draftRepo.Publish(r.Context(), userID, time.Now())
+	if err != nil {
+		h.log.Error("publish error", "user_id", userID, "error", err)
+		h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+	deleteUnreferenced(r.Context(), h.log, h.blob, h.mediaRepo, keys)
+	h.feeds.forget(userID)
+
+	h.log.Info("profile published", "user_id", userID)
+
+	h.renderBar(w, r, "Your changes are live")
+}
+
+// Revert discards the draft. The page reloads since every list on it may
+// have changed.
+func (h *DraftHandler) Revert(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.UserIDFromContext(r.Context())
+	if userID == 0 {
+		h.resp.Error(w, r, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	keys, err := h.draftRepo.Revert(r.Context(), userID)
+	if err != nil {
+		h.log.Error("revert error", "user_id", userID, "error", err)
+		h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+	deleteUnreferenced(r.Context(), h.log, h.blob, h.mediaRepo, keys)
+
+	h.log.Info("draft reverted", "user_id", userID)
+
+	w.Header().Set("HX-Refresh", "true")
+	w.WriteHeader(http.StatusOK)
+}
+
+// renderBar writes the draft bar for the signed-in user
+func (h *DraftHandler) renderBar(w http.ResponseWriter, r *http.Request, message string) {
+	userID := middleware.UserIDFromContext(r.Context())
+
+	status, err := h.draftRepo.Status(r.Context(), userID)
+	if err != nil {
+		h.log.Error("database error", "error", err)
+		h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+
+	if err := templates.RenderPartial(w, i18n.FromContext(r.Context()), "draft_bar.html", DraftBarData{DraftStatus: status, Message: message}); err != nil {
+		h.log.Error("template error", "error", err)
+	}
+}
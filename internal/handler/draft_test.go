@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"linkbio/internal/middleware"
+	"linkbio/internal/model"
+	"linkbio/internal/pkg/response"
+	"linkbio/internal/repository"
+	"linkbio/internal/storage"
+	"linkbio/internal/testutil"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestDraftHandler_PreviewAndPublish(t *testing.T) {
+	testutil.ChdirRoot(t)
+
+	db := testutil.TestDB(t)
+	log := testutil.TestLogger()
+	userRepo := repository.NewUserRepository(db)
+	linkRepo := repository.NewLinkRepository(db)
+	ctx := context.Background()
+
+	user := &model.User{Username: "stager", Email: "stager@test.com", PasswordHash: "hash", DisplayName: "Stager", Theme: "light"}
+	if err := userRepo.Create(ctx, user); err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+
+	profile := &ProfileHandler{
+		log:           log,
+		resp:          response.New(log),
+		userRepo:      userRepo,
+		linkRepo:      linkRepo,
+		analyticsRepo: repository.NewAnalyticsRepository(db),
+		domainRepo:    repository.NewDomainRepository(db),
+		socialRepo:    repository.NewSocialRepository(db),
+		pageRepo:      repository.NewPageRepository(db),
+	}
+	drafts := &DraftHandler{
+		log:       log,
+		resp:      response.New(log),
+		draftRepo: repository.NewDraftRepository(db),
+		mediaRepo: repository.NewMediaRepository(db),
+		blob:      storage.NewLocal(t.TempDir()),
+		feeds:     newFeedCache(time.Minute),
+	}
+	drafts.feeds.set("feed.xml:stager", feedEntry{userID: user.ID})
+	r := chi.NewRouter()
+	r.Get("/u/{username}", profile.Show)
+	r.Get("/dashboard/preview", profile.Preview)
+	r.Get("/dashboard/draft", drafts.Bar)
+	r.Post("/api/v1/publish", drafts.Publish)
+	r.Post("/api/v1/publish/revert", drafts.Revert)
+
+	do := func(method, path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req = req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, user.ID))
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		return rec
+	}
+
+	linkRepo.Create(ctx, &model.Link{UserID: user.ID, Title: "Campaign", URL: "https://campaign.example", IsActive: true})
+	user.DisplayName = "Staged Name"
+	userRepo.Update(ctx, user)
+
+	if body := do(http.MethodGet, "/u/stager").Body.String(); strings.Contains(body, "Campaign") || strings.Contains(body, "Staged Name") {
+		t.Error("public profile shows unpublished changes")
+	}
+
+	rec := do(http.MethodGet, "/dashboard/preview")
+	body := rec.Body.String()
+	if !strings.Contains(body, "Campaign") || !strings.Contains(body, "Staged Name") {
+		t.Error("preview does not show the draft")
+	}
+	if !strings.Contains(body, `href="https://campaign.example"`) {
+		t.Error("preview links go through the click tracker")
+	}
+	if rec.Header().Get("X-Robots-Tag") != "noindex" || rec.Header().Get("Cache-Control") != "no-store" {
+		t.Errorf("preview headers = %v", rec.Header())
+	}
+
+	if body := do(http.MethodGet, "/dashboard/draft").Body.String(); !strings.Contains(body, "1 link and profile details") {
+		t.Errorf("draft bar = %q", body)
+	}
+
+	if rec := do(http.MethodPost, "/api/v1/publish"); rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "Your changes are live") {
+		t.Fatalf("publish status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	body = do(http.MethodGet, "/u/stager").Body.String()
+	if !strings.Contains(body, "Campaign") || !strings.Contains(body, "Staged Name") {
+		t.Error("public profile does not show published changes")
+	}
+	if body := do(http.MethodGet, "/dashboard/draft").Body.String(); strings.TrimSpace(body) != "" {
+		t.Errorf("draft bar after publish = %q", body)
+	}
+	if _, ok := drafts.feeds.get("feed.xml:stager"); ok {
+		t.Error("publish kept the user's cached feed")
+	}
+
+	// Reverting drops a staged edit and reloads the dashboard
+	user.DisplayName = "Scrapped"
+	userRepo.Update(ctx, user)
+	if rec := do(http.MethodPost, "/api/v1/publish/revert"); rec.Header().Get("HX-Refresh") != "true" {
+		t.Errorf("revert headers = %v", rec.Header())
+	}
+	if u, _ := userRepo.GetByID(ctx, user.ID); u.DisplayName != "Staged Name" {
+		t.Errorf("display name after revert = %q", u.DisplayName)
+	}
+}
@@ -0,0 +1,284 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"linkbio/internal/i18n"
+	"linkbio/internal/model"
+	"linkbio/internal/pkg/ogimage"
+	"linkbio/internal/pkg/response"
+	"linkbio/internal/pkg/templates"
+	"linkbio/internal/repository"
+	"linkbio/internal/theme"
+
+	"log/slog"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Size of the embed iframe suggested by the oEmbed endpoint, shrunk to fit
+// the consumer's maxwidth and maxheight
+const (
+	embedWidth  = 400
+	embedHeight = 600
+)
+
+// EmbedHandler serves the compact profile widget other sites put in an
+// iframe, and the oEmbed endpoint that hands out its markup
+type EmbedHandler struct {
+	log             *slog.Logger
+	resp            *response.Responder
+	userRepo        *repository.UserRepository
+	linkRepo        *repository.LinkRepository
+	analyticsRepo   *repository.AnalyticsRepository
+	domainRepo      *repository.DomainRepository
+	socialRepo      *repository.SocialRepository
+	baseURL         string
+	frameAncestors  string
+	hideBrokenLinks bool
+	locales         *i18n.Bundle
+}
+
+// NewEmbedHandler creates a new EmbedHandler
+func NewEmbedHandler(deps *Dependencies) *EmbedHandler {
+	ancestors := "*"
+	if len(deps.Config.EmbedFrameAncestors) > 0 {
+		ancestors = strings.Join(deps.Config.EmbedFrameAncestors, " ")
+	}
+	return &EmbedHandler{
+		log:             deps.Log,
+		resp:            deps.Responder,
+		userRepo:        deps.UserRepo,
+		linkRepo:        deps.LinkRepo,
+		analyticsRepo:   deps.AnalyticsRepo,
+		domainRepo:      deps.DomainRepo,
+		socialRepo:      deps.SocialRepo,
+		baseURL:         deps.Config.BaseURL,
+		frameAncestors:  ancestors,
+		hideBrokenLinks: deps.Config.LinkCheckHideBroken,
+		locales:         deps.Locales,
+	}
+}
+
+// EmbedData holds data for the embed template
+type EmbedData struct {
+	User     *model.User
+	Links    []model.Link
+	Socials  []model.SocialProfile
+	ThemeCSS template.CSS
+	Source   string // src parameter appended to click URLs
+
+	// ProfileURL opens the full profile; Locked profiles only link to it
+	ProfileURL string
+	Locked     bool
+}
+
+// Show serves /embed/{username}: the main page's links in a compact layout
+// meant for an iframe. Links open in a new tab and visits count as
+// embedded-widget traffic. Password-protected profiles only offer a link to
+// the full profile, since the unlock cookie would be third-party here.
+func (h *EmbedHandler) Show(w http.ResponseWriter, r *http.Request) {
+	username := chi.URLParam(r, "username")
+
+	user, err := h.userRepo.GetPublishedByUsername(r.Context(), username)
+	if err != nil {
+		h.log.Error("database error", "error", err)
+		h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+	if user == nil {
+		renamed, err := h.userRepo.GetByPreviousUsername(r.Context(), username)
+		if err != nil {
+			h.log.Error("database error", "error", err)
+			h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
+			return
+		}
+		if renamed != nil {
+			http.Redirect(w, r, "/embed/"+url.PathEscape(renamed.Username), http.StatusMovedPermanently)
+			return
+		}
+		h.resp.Error(w, r, http.StatusNotFound, "Profile not found")
+		return
+	}
+	r = withProfileLocale(r, h.locales, user)
+
+	data := EmbedData{
+		User:       user,
+		ThemeCSS:   theme.Resolve(user.Theme, user.ThemeConfig).CSS(),
+		Source:     model.SourceEmbed,
+		ProfileURL: h.baseURL + "/u/" + url.PathEscape(user.Username),
+		Locked:     user.Visibility() == model.VisibilityPassword,
+	}
+
+	if !data.Locked {
+		data.Links, err = profileLinks(r.Context(), h.linkRepo, user.ID, nil, h.hideBrokenLinks)
+		if err != nil {
+			h.log.Error("database error", "error", err)
+			h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
+			return
+		}
+		data.Socials, err = h.socialRepo.ListByUser(r.Context(), user.ID)
+		if err != nil {
+			h.log.Error("database error", "error", err)
+			h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
+			return
+		}
+
+		// ⚠️ context.Background(): r.Context() is cancelled once the
+		// response is sent
+		event := &model.Analytics{
+			UserID:    user.ID,
+			EventType: "page_view",
+			Source:    model.SourceEmbed,
+			Referrer:  r.Referer(),
+			UserAgent: r.UserAgent(),
+		}
+		go func() {
+			h.analyticsRepo.Record(context.Background(), event)
+		}()
+	}
+
+	// The widget may be framed by the configured sites; the full profile
+	// is where search engines should go
+	w.Header().Set("Content-Security-Policy", "frame-ancestors "+h.frameAncestors)
+	w.Header().Set("X-Robots-Tag", "noindex")
+
+	if err := templates.Render(w, i18n.FromContext(r.Context()), "embed.html", data); err != nil {
+		h.log.Error("template error", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
+
+// OEmbedResponse is a "rich" oEmbed response (https://oembed.com)
+type OEmbedResponse struct {
+	Type            string `json:"type"`
+	Version         string `json:"version"`
+	Title           string `json:"title,omitempty"`
+	AuthorName      string `json:"author_name,omitempty"`
+	AuthorURL       string `json:"author_url,omitempty"`
+	ProviderName    string `json:"provider_name"`
+	ProviderURL     string `json:"provider_url"`
+	CacheAge        int    `json:"cache_age"`
+	ThumbnailURL    string `json:"thumbnail_url,omitempty"`
+	ThumbnailWidth  int    `json:"thumbnail_width,omitempty"`
+	ThumbnailHeight int    `json:"thumbnail_height,omitempty"`
+	HTML            string `json:"html"`
+	Width           int    `json:"width"`
+	Height          int    `json:"height"`
+}
+
+// OEmbed serves /oembed?url=... for profile URLs on the main site or a
+// verified custom domain. Only the JSON format is offered; as the spec
+// asks, other formats get 501, unknown URLs 404 and password-protected
+// profiles 401.
+func (h *EmbedHandler) OEmbed(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if format := q.Get("format"); format != "" && format != "json" {
+		h.resp.Error(w, r, http.StatusNotImplemented, "Only the json format is supported")
+		return
+	}
+
+	username, err := h.profileUsername(r.Context(), q.Get("url"))
+	if err != nil {
+		h.log.Error("database error", "error", err)
+		h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+	var user *model.User
+	if username != "" {
+		user, err = h.userRepo.GetPublishedByUsername(r.Context(), username)
+		if err != nil {
+			h.log.Error("database error", "error", err)
+			h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
+			return
+		}
+	}
+	if user == nil {
+		h.resp.Error(w, r, http.StatusNotFound, "Profile not found")
+		return
+	}
+	if user.Visibility() == model.VisibilityPassword {
+		h.resp.Error(w, r, http.StatusUnauthorized, "This profile is password-protected")
+		return
+	}
+
+	links, err := profileLinks(r.Context(), h.linkRepo, user.ID, nil, h.hideBrokenLinks)
+	if err != nil {
+		h.log.Error("database error", "error", err)
+		h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+	canonical, err := canonicalProfileURL(r.Context(), h.domainRepo, h.baseURL, user)
+	if err != nil {
+		h.log.Error("database error", "error", err)
+		h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+
+	width := fitDimension(embedWidth, q.Get("maxwidth"))
+	height := fitDimension(embedHeight, q.Get("maxheight"))
+	title := user.DisplayName
+	if title == "" {
+		title = user.Username
+	}
+	src := h.baseURL + "/embed/" + url.PathEscape(user.Username)
+
+	h.resp.JSON(w, http.StatusOK, OEmbedResponse{
+		Type:            "rich",
+		Version:         "1.0",
+		Title:           title,
+		AuthorName:      title,
+		AuthorURL:       canonical,
+		ProviderName:    "LinkBio",
+		ProviderURL:     h.baseURL + "/",
+		CacheAge:        3600,
+		ThumbnailURL:    shareImageURL(h.baseURL, user, links),
+		ThumbnailWidth:  ogimage.Width,
+		ThumbnailHeight: ogimage.Height,
+		HTML: fmt.Sprintf(`<iframe src="%s" width="%d" height="%d" title="%s" style="border:0;border-radius:16px;max-width:100%%" loading="lazy"></iframe>`,
+			html.EscapeString(src), width, height, html.EscapeString(title)),
+		Width:  width,
+		Height: height,
+	})
+}
+
+// profileUsername returns the username whose profile rawURL points at, or
+// "" if it is not a profile URL this site serves
+func (h *EmbedHandler) profileUsername(ctx context.Context, rawURL string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return "", nil
+	}
+	host := strings.ToLower(u.Hostname())
+	path := strings.Trim(u.Path, "/")
+
+	if base, err := url.Parse(h.baseURL); err == nil && strings.EqualFold(base.Hostname(), host) {
+		parts := strings.Split(path, "/")
+		if len(parts) >= 2 && (parts[0] == "u" || parts[0] == "embed") {
+			return parts[1], nil
+		}
+		return "", nil
+	}
+
+	// A custom domain serves one profile at its root and sub-pages below
+	d, err := h.domainRepo.GetVerified(ctx, host)
+	if err != nil || d == nil {
+		return "", err
+	}
+	return d.Username, nil
+}
+
+// fitDimension returns size, shrunk to the consumer's limit if it gave one
+func fitDimension(size int, limit string) int {
+	if n, err := strconv.Atoi(limit); err == nil && n > 0 && n < size {
+		return n
+	}
+	return size
+}
@@ -0,0 +1,154 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"linkbio/internal/model"
+	"linkbio/internal/pkg/response"
+	"linkbio/internal/repository"
+	"linkbio/internal/testutil"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestEmbedHandler(t *testing.T) {
+	testutil.ChdirRoot(t)
+
+	db := testutil.TestDB(t)
+	log := testutil.TestLogger()
+	userRepo := repository.NewUserRepository(db)
+	linkRepo := repository.NewLinkRepository(db)
+	domainRepo := repository.NewDomainRepository(db)
+	analyticsRepo := repository.NewAnalyticsRepository(db)
+	ctx := context.Background()
+
+	user := &model.User{Username: "widget", Email: "widget@test.com", PasswordHash: "hash", DisplayName: "Wid <Get>", Theme: "light"}
+	if err := userRepo.Create(ctx, user); err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	link := &model.Link{UserID: user.ID, Title: "Shop", URL: "https://shop.test", IsActive: true}
+	if err := linkRepo.Create(ctx, link); err != nil {
+		t.Fatalf("failed to create link: %v", err)
+	}
+	if _, err := repository.NewDraftRepository(db).Publish(ctx, user.ID, time.Now()); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	locked := &model.User{Username: "vault", Email: "vault@test.com", PasswordHash: "hash", Theme: "light", ProfilePasswordHash: "x"}
+	if err := userRepo.Create(ctx, locked); err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	domain := &model.CustomDomain{UserID: user.ID, Domain: "links.widget.test", Token: "t"}
+	if err := domainRepo.Create(ctx, domain); err != nil {
+		t.Fatalf("failed to create domain: %v", err)
+	}
+	domainRepo.MarkVerified(ctx, domain.ID)
+
+	h := &EmbedHandler{
+		log:            log,
+		resp:           response.New(log),
+		userRepo:       userRepo,
+		linkRepo:       linkRepo,
+		analyticsRepo:  analyticsRepo,
+		domainRepo:     domainRepo,
+		socialRepo:     repository.NewSocialRepository(db),
+		baseURL:        "https://linkbio.test",
+		frameAncestors: "https://blog.test",
+	}
+	r := chi.NewRouter()
+	r.Get("/embed/{username}", h.Show)
+	r.Get("/oembed", h.OEmbed)
+
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	t.Run("widget", func(t *testing.T) {
+		rec := get("/embed/widget")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d", rec.Code)
+		}
+		if got := rec.Header().Get("Content-Security-Policy"); got != "frame-ancestors https://blog.test" {
+			t.Errorf("Content-Security-Policy = %q", got)
+		}
+		body := rec.Body.String()
+		if !strings.Contains(body, "/click/"+strconv.FormatInt(link.ID, 10)+"?src=embed") {
+			t.Error("links do not count as embed traffic")
+		}
+		if !strings.Contains(body, `<base target="_blank">`) {
+			t.Error("links open inside the iframe")
+		}
+
+		// The view is recorded under the embed source
+		var summary *model.AnalyticsSummary
+		for i := 0; i < 100; i++ {
+			if summary, _ = analyticsRepo.GetSummary(ctx, user.ID, 1); summary.TotalViews > 0 {
+				break
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		if len(summary.Sources) != 1 || summary.Sources[0].Source != model.SourceEmbed || summary.Sources[0].Views != 1 {
+			t.Errorf("Sources = %+v, want one embed view", summary.Sources)
+		}
+
+		if rec := get("/embed/vault"); rec.Code != http.StatusOK || strings.Contains(rec.Body.String(), "/click/") ||
+			!strings.Contains(rec.Body.String(), "password-protected") {
+			t.Errorf("locked widget status = %d, want only a link to the profile", rec.Code)
+		}
+		if rec := get("/embed/nobody"); rec.Code != http.StatusNotFound {
+			t.Errorf("unknown user status = %d, want 404", rec.Code)
+		}
+	})
+
+	t.Run("oembed", func(t *testing.T) {
+		oembed := func(query url.Values) *httptest.ResponseRecorder {
+			return get("/oembed?" + query.Encode())
+		}
+
+		for _, profileURL := range []string{"https://linkbio.test/u/widget", "https://linkbio.test/u/widget/shop?x=1", "https://links.widget.test/"} {
+			rec := oembed(url.Values{"url": {profileURL}, "maxwidth": {"320"}})
+			if rec.Code != http.StatusOK {
+				t.Fatalf("oembed(%s) status = %d", profileURL, rec.Code)
+			}
+			var resp OEmbedResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("invalid JSON: %v", err)
+			}
+			if resp.Type != "rich" || resp.Version != "1.0" || resp.Width != 320 || resp.Height != embedHeight {
+				t.Errorf("oembed(%s) = %+v", profileURL, resp)
+			}
+			if !strings.Contains(resp.HTML, `src="https://linkbio.test/embed/widget"`) || !strings.Contains(resp.HTML, "Wid &lt;Get&gt;") {
+				t.Errorf("html = %s", resp.HTML)
+			}
+			if resp.AuthorURL != "https://links.widget.test/" || resp.ThumbnailURL == "" {
+				t.Errorf("author_url = %q, thumbnail_url = %q", resp.AuthorURL, resp.ThumbnailURL)
+			}
+		}
+
+		tests := []struct {
+			query url.Values
+			want  int
+		}{
+			{url.Values{"url": {"https://linkbio.test/u/widget"}, "format": {"xml"}}, http.StatusNotImplemented},
+			{url.Values{"url": {"https://linkbio.test/u/nobody"}}, http.StatusNotFound},
+			{url.Values{"url": {"https://elsewhere.test/u/widget"}}, http.StatusNotFound},
+			{url.Values{"url": {"https://linkbio.test/dashboard"}}, http.StatusNotFound},
+			{url.Values{}, http.StatusNotFound},
+			{url.Values{"url": {"https://linkbio.test/u/vault"}}, http.StatusUnauthorized},
+		}
+		for _, tt := range tests {
+			if rec := oembed(tt.query); rec.Code != tt.want {
+				t.Errorf("oembed(%v) status = %d, want %d", tt.query, rec.Code, tt.want)
+			}
+		}
+	})
+}
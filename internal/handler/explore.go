@@ -0,0 +1,144 @@
+package handler
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"linkbio/internal/i18n"
+	"linkbio/internal/model"
+	"linkbio/internal/pkg/response"
+	"linkbio/internal/pkg/templates"
+	"linkbio/internal/repository"
+
+	"log/slog"
+)
+
+// Explore directory paging. Deep pages are cut off; searching narrows
+// things down faster.
+const (
+	explorePageSize = 24
+	exploreMaxPage  = 50
+	exploreMaxQuery = 100 // characters of search text used
+)
+
+// ExploreHandler serves the public directory of opted-in profiles
+type ExploreHandler struct {
+	log           *slog.Logger
+	resp          *response.Responder
+	directoryRepo *repository.DirectoryRepository
+}
+
+// NewExploreHandler creates a new ExploreHandler
+func NewExploreHandler(deps *Dependencies) *ExploreHandler {
+	return &ExploreHandler{
+		log:           deps.Log,
+		resp:          deps.Responder,
+		directoryRepo: deps.DirectoryRepo,
+	}
+}
+
+// ExploreData holds data for the explore page and its results partial
+type ExploreData struct {
+	Query      string
+	Category   string
+	Categories []model.Category
+	Profiles   []model.DirectoryProfile
+
+	// Page is 1-based; PrevPage and NextPage are 0 at either end
+	Page     int
+	PrevPage int
+	NextPage int
+}
+
+// Page renders /explore. ?q= searches, ?category= filters and ?page=
+// pages through the results.
+func (h *ExploreHandler) Page(w http.ResponseWriter, r *http.Request) {
+	data, err := h.results(r)
+	if err != nil {
+		h.log.Error("database error", "error", err)
+		h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+
+	if err := templates.Render(w, i18n.FromContext(r.Context()), "explore.html", data); err != nil {
+		h.log.Error("template error", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
+
+// Search renders just the results for search-as-you-type, taking the same
+// parameters as Page. The address bar follows along so results can be
+// shared.
+func (h *ExploreHandler) Search(w http.ResponseWriter, r *http.Request) {
+	data, err := h.results(r)
+	if err != nil {
+		h.log.Error("database error", "error", err)
+		h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+
+	w.Header().Set("HX-Push-Url", exploreURL(data.Query, data.Category, data.Page))
+	if err := templates.RenderPartial(w, i18n.FromContext(r.Context()), "explore_results.html", data); err != nil {
+		h.log.Error("template error", "error", err)
+	}
+}
+
+// results runs the search the request asks for
+func (h *ExploreHandler) results(r *http.Request) (ExploreData, error) {
+	q := r.URL.Query()
+	data := ExploreData{
+		Query:      strings.TrimSpace(q.Get("q")),
+		Categories: model.Categories,
+		Page:       1,
+	}
+	if utf8.RuneCountInString(data.Query) > exploreMaxQuery {
+		data.Query = string([]rune(data.Query)[:exploreMaxQuery])
+	}
+	if c := q.Get("category"); model.CategoryName(c) != "" {
+		data.Category = c
+	}
+	if n, err := strconv.Atoi(q.Get("page")); err == nil && n > 1 {
+		data.Page = min(n, exploreMaxPage)
+	}
+
+	// One extra row tells whether there is a next page
+	profiles, err := h.directoryRepo.Search(r.Context(), repository.DirectoryQuery{
+		Text:     data.Query,
+		Category: data.Category,
+		Limit:    explorePageSize + 1,
+		Offset:   (data.Page - 1) * explorePageSize,
+	})
+	if err != nil {
+		return ExploreData{}, err
+	}
+	if len(profiles) > explorePageSize {
+		profiles = profiles[:explorePageSize]
+		if data.Page < exploreMaxPage {
+			data.NextPage = data.Page + 1
+		}
+	}
+	data.PrevPage = data.Page - 1
+	data.Profiles = profiles
+	return data, nil
+}
+
+// exploreURL is the address of one page of directory results
+func exploreURL(query, category string, page int) string {
+	v := url.Values{}
+	if query != "" {
+		v.Set("q", query)
+	}
+	if category != "" {
+		v.Set("category", category)
+	}
+	if page > 1 {
+		v.Set("page", strconv.Itoa(page))
+	}
+	if len(v) == 0 {
+		return "/explore"
+	}
+	return "/explore?" + v.Encode()
+}
@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"linkbio/internal/model"
+	"linkbio/internal/pkg/response"
+	"linkbio/internal/repository"
+	"linkbio/internal/testutil"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestExploreHandler(t *testing.T) {
+	testutil.ChdirRoot(t)
+
+	db := testutil.TestDB(t)
+	log := testutil.TestLogger()
+	userRepo := repository.NewUserRepository(db)
+	ctx := context.Background()
+
+	create := func(username, bio, category string, listed, unlisted bool) {
+		t.Helper()
+		u := &model.User{Username: username, Email: username + "@test.com", PasswordHash: "hash", DisplayName: username, Bio: bio, Theme: "light"}
+		if err := userRepo.Create(ctx, u); err != nil {
+			t.Fatalf("failed to create user: %v", err)
+		}
+		u.Listed, u.Category, u.HideFromSearch = listed, category, unlisted
+		if err := userRepo.Update(ctx, u); err != nil {
+			t.Fatalf("failed to update user: %v", err)
+		}
+	}
+	for i := 1; i <= explorePageSize; i++ {
+		create(fmt.Sprintf("maker%02d", i), "Handmade pottery", "art", true, false)
+	}
+	create("drummer", "Drums and pottery", "music", true, false)
+	create("secret", "Pottery in private", "art", false, false)
+	create("unlisted", "Pottery, unlisted", "art", true, true)
+
+	h := &ExploreHandler{log: log, resp: response.New(log), directoryRepo: repository.NewDirectoryRepository(db)}
+	r := chi.NewRouter()
+	r.Get("/explore", h.Page)
+	r.Get("/explore/search", h.Search)
+
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	rec := get("/explore")
+	body := rec.Body.String()
+	if rec.Code != http.StatusOK || !strings.Contains(body, `id="explore-results"`) {
+		t.Fatalf("status = %d", rec.Code)
+	}
+	if strings.Count(body, `href="/u/`) != explorePageSize || !strings.Contains(body, "/u/drummer") {
+		t.Errorf("first page should show %d profiles, newest first", explorePageSize)
+	}
+	if !strings.Contains(body, "page=2") || strings.Contains(body, "/u/secret") || strings.Contains(body, "/u/unlisted") {
+		t.Error("first page has no next link, or shows profiles that did not opt in")
+	}
+	if body := get("/explore?page=2").Body.String(); !strings.Contains(body, "/u/maker01") || strings.Count(body, `href="/u/`) != 1 {
+		t.Error("second page should hold the oldest profile")
+	}
+
+	rec = get("/explore/search?q=drum+pot&category=")
+	if got := rec.Header().Get("HX-Push-Url"); got != "/explore?q=drum+pot" {
+		t.Errorf("HX-Push-Url = %q", got)
+	}
+	body = rec.Body.String()
+	if strings.Contains(body, "<html") || !strings.Contains(body, "/u/drummer") || strings.Count(body, `href="/u/`) != 1 {
+		t.Errorf("search partial = %s", body)
+	}
+
+	body = get("/explore/search?q=pottery&category=music").Body.String()
+	if !strings.Contains(body, "/u/drummer") || strings.Contains(body, "/u/maker") {
+		t.Error("category filter not applied")
+	}
+	if body := get("/explore/search?q=pottery&category=music&page=2").Body.String(); !strings.Contains(body, "No profiles match") {
+		t.Error("past the last page should show no results")
+	}
+	if body := get("/explore/search?q=private").Body.String(); strings.Contains(body, "/u/secret") {
+		t.Error("search found a profile that did not opt in")
+	}
+}
@@ -0,0 +1,271 @@
+package handler
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"linkbio/internal/i18n"
+	"linkbio/internal/model"
+	"linkbio/internal/pkg/feed"
+	"linkbio/internal/pkg/response"
+	"linkbio/internal/repository"
+
+	"log/slog"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// feedTTL is how long a rendered feed is served from memory. Readers are
+// told to wait as long before polling again.
+const feedTTL = 5 * time.Minute
+
+// feedSweepSize is how many feeds may be cached before expired ones are
+// swept
+const feedSweepSize = 10000
+
+// FeedHandler serves a profile's links as Atom and JSON feeds
+type FeedHandler struct {
+	log             *slog.Logger
+	resp            *response.Responder
+	userRepo        *repository.UserRepository
+	linkRepo        *repository.LinkRepository
+	domainRepo      *repository.DomainRepository
+	baseURL         string
+	hideBrokenLinks bool
+	locales         *i18n.Bundle
+	cache           *feedCache
+}
+
+// NewFeedHandler creates a new FeedHandler
+func NewFeedHandler(deps *Dependencies) *FeedHandler {
+	return &FeedHandler{
+		log:             deps.Log,
+		resp:            deps.Responder,
+		userRepo:        deps.UserRepo,
+		linkRepo:        deps.LinkRepo,
+		domainRepo:      deps.DomainRepo,
+		baseURL:         deps.Config.BaseURL,
+		hideBrokenLinks: deps.Config.LinkCheckHideBroken,
+		locales:         deps.Locales,
+		cache:           newFeedCache(feedTTL),
+	}
+}
+
+// Atom serves /u/{username}/feed.xml
+func (h *FeedHandler) Atom(w http.ResponseWriter, r *http.Request) {
+	h.serve(w, r, "feed.xml", feed.AtomContentType, feed.Feed.Atom)
+}
+
+// JSON serves /u/{username}/feed.json
+func (h *FeedHandler) JSON(w http.ResponseWriter, r *http.Request) {
+	h.serve(w, r, "feed.json", feed.JSONContentType, feed.Feed.JSON)
+}
+
+// serve answers from the cache when it can, so conditional GETs from
+// polling readers cost no database query. Publishing and saving settings
+// forget the user's cached feeds, so a profile locked since its feed was
+// cached stops serving it. Feeds list the main page's active links, newest
+// first.
+func (h *FeedHandler) serve(w http.ResponseWriter, r *http.Request, name, contentType string, render func(feed.Feed, string) ([]byte, error)) {
+	username := chi.URLParam(r, "username")
+	key := name + ":" + strings.ToLower(username)
+
+	entry, ok := h.cache.get(key)
+	if !ok {
+		user, err := h.userRepo.GetPublishedByUsername(r.Context(), username)
+		if err != nil {
+			h.log.Error("database error", "error", err)
+			h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
+			return
+		}
+		if user == nil {
+			if ok, err := redirectRenamed(w, r, h.userRepo, username, "/"+name); ok || err != nil {
+				if err != nil {
+					h.log.Error("database error", "error", err)
+					h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
+				}
+				return
+			}
+			h.resp.Error(w, r, http.StatusNotFound, "Profile not found")
+			return
+		}
+		// Protected profiles keep their links private
+		if user.Visibility() == model.VisibilityPassword {
+			h.resp.Error(w, r, http.StatusNotFound, "Profile not found")
+			return
+		}
+
+		f, err := h.build(r, user)
+		if err != nil {
+			h.log.Error("database error", "error", err)
+			h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
+			return
+		}
+		body, err := render(f, strings.TrimSuffix(f.HomeURL, "/")+"/"+name)
+		if err != nil {
+			h.log.Error("feed error", "user_id", user.ID, "error", err)
+			h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
+			return
+		}
+		sum := sha256.Sum256(body)
+		entry = h.cache.set(key, feedEntry{userID: user.ID, body: body, etag: `"` + hex.EncodeToString(sum[:16]) + `"`})
+	}
+
+	// private keeps shared proxies from holding on to a feed the creator
+	// may lock or change
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Cache-Control", "private, max-age="+strconv.Itoa(int(feedTTL.Seconds())))
+	w.Header().Set("ETag", entry.etag)
+	http.ServeContent(w, r, "", entry.modified, bytes.NewReader(entry.body))
+}
+
+// build collects user's feed. IDs are tag URIs minted from the user's and
+// links' IDs, so they survive username changes and edits.
+func (h *FeedHandler) build(r *http.Request, user *model.User) (feed.Feed, error) {
+	links, err := profileLinks(r.Context(), h.linkRepo, user.ID, nil, h.hideBrokenLinks)
+	if err != nil {
+		return feed.Feed{}, err
+	}
+	home, err := canonicalProfileURL(r.Context(), h.domainRepo, h.baseURL, user)
+	if err != nil {
+		return feed.Feed{}, err
+	}
+
+	sort.SliceStable(links, func(i, j int) bool {
+		if !links[i].CreatedAt.Equal(links[j].CreatedAt) {
+			return links[i].CreatedAt.After(links[j].CreatedAt)
+		}
+		return links[i].ID > links[j].ID
+	})
+
+	host := hostOf(h.baseURL)
+	l := h.locales.Localizer(user.ProfileLocale)
+	name := user.DisplayName
+	if name == "" {
+		name = user.Username
+	}
+	f := feed.Feed{
+		ID:        feed.TagURI(host, user.CreatedAt, "users/"+strconv.FormatInt(user.ID, 10)),
+		Title:     name,
+		Subtitle:  user.Bio,
+		HomeURL:   home,
+		Author:    name,
+		AuthorURL: home,
+		Updated:   user.CreatedAt,
+	}
+	if l != nil {
+		f.Language = l.Lang()
+	}
+	if user.AvatarURL != "" {
+		f.Icon = absoluteURL(h.baseURL, user.AvatarURL)
+	}
+
+	for _, link := range links {
+		item := feed.Item{
+			ID:        feed.TagURI(host, link.CreatedAt, "links/"+strconv.FormatInt(link.ID, 10)),
+			Title:     link.Title,
+			URL:       h.baseURL + "/click/" + strconv.FormatInt(link.ID, 10) + "?src=" + url.QueryEscape(model.SourceFeed),
+			Summary:   link.Description,
+			Published: link.CreatedAt,
+		}
+		switch {
+		case link.Kind == model.LinkKindEvent:
+			item.Summary = l.Date(link.LocalStart()) + " · " + link.LocalStart().Format("15:04")
+			if link.Location != "" {
+				item.Summary += " · " + link.Location
+			}
+		case item.Title == "" && link.IsDownload():
+			item.Title = l.T("Add to contacts")
+		case item.Title == "":
+			item.Title = link.URL
+		}
+		if link.CreatedAt.After(f.Updated) {
+			f.Updated = link.CreatedAt
+		}
+		f.Items = append(f.Items, item)
+	}
+	return f, nil
+}
+
+// feedEntry is a rendered feed of the user userID. modified is when its
+// content last changed, which removing a link does too, so it is tracked
+// here rather than taken from the feed.
+type feedEntry struct {
+	userID   int64
+	body     []byte
+	etag     string
+	modified time.Time
+	expires  time.Time
+}
+
+// feedCache keeps rendered feeds in memory for a while. Publishing and
+// saving settings forget the user's feeds; state is per server process, so
+// other processes catch up within the TTL.
+type feedCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]feedEntry
+	now     func() time.Time
+}
+
+// newFeedCache creates a feedCache
+func newFeedCache(ttl time.Duration) *feedCache {
+	return &feedCache{ttl: ttl, entries: make(map[string]feedEntry), now: time.Now}
+}
+
+// get returns the entry for key unless it has expired
+func (c *feedCache) get(key string) (feedEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok || !c.now().Before(e.expires) {
+		return feedEntry{}, false
+	}
+	return e, true
+}
+
+// set stores e under key and returns it with its times filled in. A
+// re-render with the same content keeps the previous modification time.
+// Expired entries are swept first when the cache has grown large.
+func (c *feedCache) set(key string, e feedEntry) feedEntry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	e.modified = now
+	if old, ok := c.entries[key]; ok && old.etag == e.etag {
+		e.modified = old.modified
+	}
+	if len(c.entries) >= feedSweepSize {
+		for k, old := range c.entries {
+			if !now.Before(old.expires) {
+				delete(c.entries, k)
+			}
+		}
+	}
+	e.expires = now.Add(c.ttl)
+	c.entries[key] = e
+	return e
+}
+
+// forget drops every cached feed of the user, under any username they
+// were requested by. A nil cache has nothing to forget.
+func (c *feedCache) forget(userID int64) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for k, e := range c.entries {
+		if e.userID == userID {
+			delete(c.entries, k)
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"linkbio/internal/model"
+	"linkbio/internal/pkg/response"
+	"linkbio/internal/repository"
+	"linkbio/internal/testutil"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestFeedHandler(t *testing.T) {
+	testutil.ChdirRoot(t)
+
+	db := testutil.TestDB(t)
+	log := testutil.TestLogger()
+	userRepo := repository.NewUserRepository(db)
+	linkRepo := repository.NewLinkRepository(db)
+	ctx := context.Background()
+
+	user := &model.User{Username: "poster", Email: "poster@test.com", PasswordHash: "hash", DisplayName: "Poster", Theme: "light"}
+	if err := userRepo.Create(ctx, user); err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	var links []*model.Link
+	for _, title := range []string{"Oldest", "Hidden", "Newest"} {
+		link := &model.Link{UserID: user.ID, Title: title, URL: "https://example.com/" + title, IsActive: title != "Hidden"}
+		if err := linkRepo.Create(ctx, link); err != nil {
+			t.Fatalf("failed to create link: %v", err)
+		}
+		links = append(links, link)
+	}
+	if _, err := repository.NewDraftRepository(db).Publish(ctx, user.ID, time.Now()); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+
+	h := &FeedHandler{
+		log:        log,
+		resp:       response.New(log),
+		userRepo:   userRepo,
+		linkRepo:   linkRepo,
+		domainRepo: repository.NewDomainRepository(db),
+		baseURL:    "https://linkbio.test",
+		cache:      newFeedCache(time.Minute),
+	}
+	r := chi.NewRouter()
+	r.Get("/u/{username}/feed.xml", h.Atom)
+	r.Get("/u/{username}/feed.json", h.JSON)
+
+	get := func(path string, header http.Header) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := get("/u/poster/feed.json", nil)
+	if rec.Code != http.StatusOK || !strings.HasPrefix(rec.Header().Get("Content-Type"), "application/feed+json") {
+		t.Fatalf("feed.json status = %d, Content-Type = %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	var doc struct {
+		FeedURL string `json:"feed_url"`
+		Items   []struct {
+			ID    string `json:"id"`
+			Title string `json:"title"`
+			URL   string `json:"url"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if doc.FeedURL != "https://linkbio.test/u/poster/feed.json" {
+		t.Errorf("feed_url = %q", doc.FeedURL)
+	}
+	if len(doc.Items) != 2 || doc.Items[0].Title != "Newest" || doc.Items[1].Title != "Oldest" {
+		t.Fatalf("items = %+v, want active links newest first", doc.Items)
+	}
+	newest := strconv.FormatInt(links[2].ID, 10)
+	if !strings.HasPrefix(doc.Items[0].ID, "tag:linkbio.test,") || !strings.HasSuffix(doc.Items[0].ID, ":links/"+newest) {
+		t.Errorf("id = %q, want a tag URI for the link", doc.Items[0].ID)
+	}
+	if doc.Items[0].URL != "https://linkbio.test/click/"+newest+"?src=feed" {
+		t.Errorf("url = %q, want a tracked click", doc.Items[0].URL)
+	}
+
+	atom := get("/u/poster/feed.xml", nil)
+	if atom.Code != http.StatusOK || !strings.Contains(atom.Body.String(), `<feed xmlns="http://www.w3.org/2005/Atom">`) {
+		t.Fatalf("feed.xml status = %d, body = %s", atom.Code, atom.Body.String())
+	}
+	etag, modified := atom.Header().Get("ETag"), atom.Header().Get("Last-Modified")
+	if etag == "" || modified == "" {
+		t.Fatalf("ETag = %q, Last-Modified = %q", etag, modified)
+	}
+	if cc := atom.Header().Get("Cache-Control"); !strings.HasPrefix(cc, "private") {
+		t.Errorf("Cache-Control = %q, want private so proxies don't keep it", cc)
+	}
+
+	// Polls are answered from memory without reading the links again
+	if _, err := db.Exec(`DELETE FROM links`); err != nil {
+		t.Fatalf("delete links: %v", err)
+	}
+	if rec := get("/u/poster/feed.xml", http.Header{"If-None-Match": {etag}}); rec.Code != http.StatusNotModified {
+		t.Errorf("If-None-Match status = %d, want 304", rec.Code)
+	}
+	if rec := get("/u/poster/feed.xml", http.Header{"If-Modified-Since": {modified}}); rec.Code != http.StatusNotModified {
+		t.Errorf("If-Modified-Since status = %d, want 304", rec.Code)
+	}
+	if rec := get("/u/poster/feed.xml", http.Header{"If-None-Match": {`"stale"`}}); rec.Code != http.StatusOK || rec.Body.String() != atom.Body.String() {
+		t.Errorf("stale ETag status = %d, want the cached feed", rec.Code)
+	}
+
+	// Locking the profile forgets its feeds, which then stay hidden
+	user.ProfilePasswordHash = "hash"
+	if err := userRepo.Update(ctx, user); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	h.cache.forget(user.ID)
+	if rec := get("/u/poster/feed.xml", http.Header{"If-None-Match": {etag}}); rec.Code != http.StatusNotFound {
+		t.Errorf("locked profile's feed status = %d, want 404", rec.Code)
+	}
+
+	// Unlocked again, the feed is rendered afresh
+	user.ProfilePasswordHash = ""
+	userRepo.Update(ctx, user)
+	if rec := get("/u/poster/feed.xml", nil); rec.Code != http.StatusOK || strings.Contains(rec.Body.String(), "Newest") {
+		t.Errorf("rebuilt feed status = %d, want it without the deleted links", rec.Code)
+	}
+
+	// Cached polls don't touch the database at all
+	db.Close()
+	if rec := get("/u/poster/feed.xml", nil); rec.Code != http.StatusOK {
+		t.Errorf("poll without a database status = %d, want the cached feed", rec.Code)
+	}
+}
+
+func TestFeedHandler_NotFound(t *testing.T) {
+	testutil.ChdirRoot(t)
+
+	db := testutil.TestDB(t)
+	log := testutil.TestLogger()
+	userRepo := repository.NewUserRepository(db)
+
+	locked := &model.User{Username: "private", Email: "private@test.com", PasswordHash: "hash", Theme: "light", ProfilePasswordHash: "x"}
+	if err := userRepo.Create(context.Background(), locked); err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+
+	h := &FeedHandler{log: log, resp: response.New(log), userRepo: userRepo, cache: newFeedCache(time.Minute)}
+	r := chi.NewRouter()
+	r.Get("/u/{username}/feed.xml", h.Atom)
+
+	for _, path := range []string{"/u/private/feed.xml", "/u/nobody/feed.xml"} {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("GET %s status = %d, want 404", path, rec.Code)
+		}
+	}
+}
+
+func TestFeedCache(t *testing.T) {
+	now := time.Date(2026, 3, 14, 12, 0, 0, 0, time.UTC)
+	c := newFeedCache(time.Minute)
+	c.now = func() time.Time { return now }
+
+	first := c.set("a", feedEntry{etag: `"1"`})
+	now = now.Add(2 * time.Minute)
+	if _, ok := c.get("a"); ok {
+		t.Error("get() returned an expired entry")
+	}
+	if e := c.set("a", feedEntry{etag: `"1"`}); !e.modified.Equal(first.modified) {
+		t.Error("unchanged content got a new modification time")
+	}
+	if e := c.set("a", feedEntry{etag: `"2"`}); !e.modified.Equal(now) {
+		t.Error("changed content kept the old modification time")
+	}
+	if _, ok := c.get("a"); !ok {
+		t.Error("get() missed a fresh entry")
+	}
+
+	c.set("feed.xml:poster", feedEntry{userID: 1})
+	c.set("feed.json:Poster", feedEntry{userID: 1})
+	c.set("feed.xml:other", feedEntry{userID: 11})
+	c.forget(1)
+	if _, ok := c.get("feed.json:Poster"); ok {
+		t.Error("forget() kept one of the user's feeds")
+	}
+	if _, ok := c.get("feed.xml:other"); !ok {
+		t.Error("forget() dropped another user's feed")
+	}
+	var none *feedCache
+	none.forget(1)
+}
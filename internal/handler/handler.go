@@ -3,8 +3,13 @@ package handler
 import (
 	"log/slog"
 
+	"linkbio/internal/config"
+	"linkbio/internal/customdomain"
+	"linkbio/internal/i18n"
 	"linkbio/internal/pkg/response"
+	"linkbio/internal/preview"
 	"linkbio/internal/repository"
+	"linkbio/internal/storage"
 
 	"github.com/gorilla/sessions"
 )
@@ -15,26 +20,69 @@ type Handler struct {
 	Link      *LinkHandler
 	Profile   *ProfileHandler
 	Dashboard *DashboardHandler
+	Media     *MediaHandler
+	QR        *QRHandler
+	OG        *OGHandler
+	Settings  *SettingsHandler
+	Domain    *DomainHandler
+	Social    *SocialHandler
+	Page      *PageHandler
+	Tag       *TagHandler
+	Draft     *DraftHandler
+	SEO       *SEOHandler
+	Embed     *EmbedHandler
+	Feed      *FeedHandler
+	Explore   *ExploreHandler
 	Health    *HealthHandler
 }
 
 // Dependencies for handlers
 type Dependencies struct {
-	Log         *slog.Logger
-	Responder   *response.Responder
-	Store       *sessions.CookieStore
-	UserRepo    *repository.UserRepository
-	LinkRepo    *repository.LinkRepository
+	Config        *config.Config
+	Log           *slog.Logger
+	Responder     *response.Responder
+	Store         *sessions.CookieStore
+	UserRepo      *repository.UserRepository
+	LinkRepo      *repository.LinkRepository
 	AnalyticsRepo *repository.AnalyticsRepository
+	MediaRepo     *repository.MediaRepository
+	DomainRepo    *repository.DomainRepository
+	SocialRepo    *repository.SocialRepository
+	PageRepo      *repository.PageRepository
+	TagRepo       *repository.TagRepository
+	DraftRepo     *repository.DraftRepository
+	DirectoryRepo *repository.DirectoryRepository
+	Blob          storage.Blob
+	Previewer     *preview.Fetcher
+	Resolver      customdomain.Resolver // nil uses the system resolver
+	Locales       *i18n.Bundle
 }
 
 // New creates all handlers
 func New(deps *Dependencies) *Handler {
-	return &Handler{
+	h := &Handler{
 		Auth:      NewAuthHandler(deps),
 		Link:      NewLinkHandler(deps),
 		Profile:   NewProfileHandler(deps),
 		Dashboard: NewDashboardHandler(deps),
+		Media:     NewMediaHandler(deps),
+		QR:        NewQRHandler(deps),
+		OG:        NewOGHandler(deps),
+		Settings:  NewSettingsHandler(deps),
+		Domain:    NewDomainHandler(deps),
+		Social:    NewSocialHandler(deps),
+		Page:      NewPageHandler(deps),
+		Tag:       NewTagHandler(deps),
+		Draft:     NewDraftHandler(deps),
+		SEO:       NewSEOHandler(deps),
+		Embed:     NewEmbedHandler(deps),
+		Feed:      NewFeedHandler(deps),
+		Explore:   NewExploreHandler(deps),
 		Health:    NewHealthHandler(deps.Log),
 	}
+
+	// Publishing and saving settings drop the user's cached feeds
+	h.Draft.feeds = h.Feed.cache
+	h.Settings.feeds = h.Feed.cache
+	return h
 }
@@ -3,15 +3,24 @@ package handler
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"html/template"
+	"html"
 	"net/http"
+	"net/url"
+	"slices"
 	"strconv"
+	"strings"
+	"time"
 
+	"linkbio/internal/i18n"
 	"linkbio/internal/middleware"
 	"linkbio/internal/model"
 	"linkbio/internal/pkg/response"
+	"linkbio/internal/pkg/templates"
+	"linkbio/internal/preview"
 	"linkbio/internal/repository"
+	"linkbio/internal/storage"
 
 	"log/slog"
 
@@ -20,87 +29,183 @@ import (
 
 // LinkHandler handles link CRUD endpoints
 type LinkHandler struct {
-	log      *slog.Logger
-	resp     *response.Responder
-	linkRepo *repository.LinkRepository
+	log           *slog.Logger
+	resp          *response.Responder
+	userRepo      *repository.UserRepository
+	linkRepo      *repository.LinkRepository
 	analyticsRepo *repository.AnalyticsRepository
+	mediaRepo     *repository.MediaRepository
+	pageRepo      *repository.PageRepository
+	tagRepo       *repository.TagRepository
+	domainRepo    *repository.DomainRepository
+	socialRepo    *repository.SocialRepository
+	lock          profileLock
+	blob          storage.Blob
+	previewer     *preview.Fetcher // nil disables metadata fetching
+	locales       *i18n.Bundle
+	baseURL       string
 }
 
 // NewLinkHandler creates a new LinkHandler
 func NewLinkHandler(deps *Dependencies) *LinkHandler {
 	return &LinkHandler{
-		log:      deps.Log,
-		resp:     deps.Responder,
-		linkRepo: deps.LinkRepo,
+		log:           deps.Log,
+		resp:          deps.Responder,
+		userRepo:      deps.UserRepo,
+		linkRepo:      deps.LinkRepo,
 		analyticsRepo: deps.AnalyticsRepo,
+		mediaRepo:     deps.MediaRepo,
+		pageRepo:      deps.PageRepo,
+		tagRepo:       deps.TagRepo,
+		domainRepo:    deps.DomainRepo,
+		socialRepo:    deps.SocialRepo,
+		lock:          newProfileLock(deps),
+		blob:          deps.Blob,
+		previewer:     deps.Previewer,
+		locales:       deps.Locales,
+		baseURL:       deps.Config.BaseURL,
 	}
 }
 
+// LinkCard holds data for the link partial. Pages offers the pages the
+// link can be moved to, and is empty when the creator has no sub-pages.
+// Tags offers the creator's tags, and is empty when they have none.
+type LinkCard struct {
+	model.Link
+	Pages []PageOption
+	Tags  []TagOption
+}
+
+// PageOption is one choice in the link form's page picker
+type PageOption struct {
+	ID       int64 // 0 for the main page
+	Title    string
+	Selected bool
+}
+
+// TagOption is one choice in a link's tag picker
+type TagOption struct {
+	model.Tag
+	Selected bool
+}
+
+// cardOptions are what link cards offer to choose from: the creator's
+// pages and tags, and which tags each link carries
+type cardOptions struct {
+	pages  []model.Page
+	tags   []model.Tag
+	tagged map[int64][]int64 // link ID to tag IDs
+}
+
+// loadCardOptions fetches the card options for a user's links
+func loadCardOptions(ctx context.Context, pageRepo *repository.PageRepository, tagRepo *repository.TagRepository, userID int64) (cardOptions, error) {
+	pages, err := pageRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return cardOptions{}, err
+	}
+	tags, err := tagRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return cardOptions{}, err
+	}
+	tagged, err := tagRepo.TagIDsByLink(ctx, userID)
+	if err != nil {
+		return cardOptions{}, err
+	}
+	return cardOptions{pages: pages, tags: tags, tagged: tagged}, nil
+}
+
+// card prepares a link for the link partial
+func (o cardOptions) card(link model.Link) LinkCard {
+	card := LinkCard{Link: link}
+	for _, t := range o.tags {
+		card.Tags = append(card.Tags, TagOption{Tag: t, Selected: slices.Contains(o.tagged[link.ID], t.ID)})
+	}
+	if len(o.pages) == 0 {
+		return card
+	}
+	card.Pages = append(card.Pages, PageOption{Title: "Main page", Selected: link.PageID == nil})
+	for _, p := range o.pages {
+		card.Pages = append(card.Pages, PageOption{ID: p.ID, Title: p.Title, Selected: link.OnPage(&p.ID)})
+	}
+	return card
+}
+
 // Create adds a new link
 func (h *LinkHandler) Create(w http.ResponseWriter, r *http.Request) {
 	userID := middleware.UserIDFromContext(r.Context())
 	if userID == 0 {
-		h.resp.Error(w, http.StatusUnauthorized, "Unauthorized")
+		h.resp.Error(w, r, http.StatusUnauthorized, "Unauthorized")
 		return
 	}
 
 	if err := r.ParseForm(); err != nil {
-		h.resp.Error(w, http.StatusBadRequest, "Invalid form data")
-		return
-	}
-
-	title := r.FormValue("title")
-	url := r.FormValue("url")
-
-	if title == "" || url == "" {
-		h.resp.Error(w, http.StatusBadRequest, "Title and URL are required")
+		h.resp.Error(w, r, http.StatusBadRequest, "Invalid form data")
 		return
 	}
 
 	link := &model.Link{
 		UserID:   userID,
-		Title:    title,
-		URL:      url,
+		Kind:     r.FormValue("kind"),
+		Title:    strings.TrimSpace(r.FormValue("title")),
 		Icon:     r.FormValue("icon"),
 		IsActive: true,
 	}
+	if link.Kind == "" {
+		link.Kind = model.LinkKindURL
+	}
+	if err := applyKindFields(r, link); err != nil {
+		h.resp.Invalid(w, r, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	opts, err := loadCardOptions(r.Context(), h.pageRepo, h.tagRepo, userID)
+	if err != nil {
+		h.log.Error("database error", "error", err)
+		h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+	page, ok := pageByID(opts.pages, r.FormValue("page_id"))
+	if !ok {
+		h.resp.Error(w, r, http.StatusUnprocessableEntity, "Page not found")
+		return
+	}
+
+	link.PageID = pageID(page)
 
 	if err := h.linkRepo.Create(r.Context(), link); err != nil {
 		h.log.Error("link creation error", "error", err)
-		h.resp.Error(w, http.StatusInternalServerError, "Failed to create link")
+		h.resp.Error(w, r, http.StatusInternalServerError, "Failed to create link")
 		return
 	}
 
 	h.log.Info("link created", "link_id", link.ID, "user_id", userID)
+	w.Header().Set("HX-Trigger", draftChanged)
+
+	if link.Kind == model.LinkKindURL {
+		h.fetchPreview(link.ID, link.URL)
+	}
 
 	// Return the new link as HTML partial for HTMX
-	tmpl, err := template.ParseFiles("web/templates/partials/link.html")
-	if err != nil {
+	if err := templates.RenderPartial(w, i18n.FromContext(r.Context()), "link.html", opts.card(*link)); err != nil {
 		h.log.Error("template error", "error", err)
-		h.resp.Error(w, http.StatusInternalServerError, "Template error")
+		h.resp.Error(w, r, http.StatusInternalServerError, "Template error")
 		return
 	}
-	tmpl.Execute(w, link)
-
-	// OOB: update link count badge
-	count, _ := h.linkRepo.CountByUserID(r.Context(), userID)
-	fmt.Fprintf(w, `<span id="link-count" hx-swap-oob="true" class="ml-2 px-2 py-0.5 text-xs font-medium rounded-full bg-indigo-100 dark:bg-indigo-900/30 text-indigo-600 dark:text-indigo-400">%d</span>`, count)
 
-	// OOB: hide empty state
-	fmt.Fprint(w, `<div id="empty-state" hx-swap-oob="outerHTML" style="display:none"></div>`)
+	h.writeListState(w, r, userID, link.PageID)
 }
 
 // Update modifies an existing link
 func (h *LinkHandler) Update(w http.ResponseWriter, r *http.Request) {
 	userID := middleware.UserIDFromContext(r.Context())
 	if userID == 0 {
-		h.resp.Error(w, http.StatusUnauthorized, "Unauthorized")
+		h.resp.Error(w, r, http.StatusUnauthorized, "Unauthorized")
 		return
 	}
 
 	linkID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
 	if err != nil {
-		h.resp.Error(w, http.StatusBadRequest, "Invalid link ID")
+		h.resp.Error(w, r, http.StatusBadRequest, "Invalid link ID")
 		return
 	}
 
@@ -108,92 +213,213 @@ func (h *LinkHandler) Update(w http.ResponseWriter, r *http.Request) {
 	link, err := h.linkRepo.GetByID(r.Context(), linkID)
 	if err != nil {
 		h.log.Error("database error", "error", err)
-		h.resp.Error(w, http.StatusInternalServerError, "Something went wrong")
+		h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
 		return
 	}
 	if link == nil || link.UserID != userID {
-		h.resp.Error(w, http.StatusNotFound, "Link not found")
+		h.resp.Error(w, r, http.StatusNotFound, "Link not found")
 		return
 	}
 
 	if err := r.ParseForm(); err != nil {
-		h.resp.Error(w, http.StatusBadRequest, "Invalid form data")
+		h.resp.Error(w, r, http.StatusBadRequest, "Invalid form data")
 		return
 	}
 
-	link.Title = r.FormValue("title")
-	link.URL = r.FormValue("url")
+	oldURL := link.URL
+
+	link.Title = strings.TrimSpace(r.FormValue("title"))
 	link.Icon = r.FormValue("icon")
 	link.IsActive = r.FormValue("is_active") == "on" || r.FormValue("is_active") == "true"
+	link.IsFeatured = r.FormValue("is_featured") == "on" || r.FormValue("is_featured") == "true"
+	link.IsSensitive = r.FormValue("is_sensitive") == "on" || r.FormValue("is_sensitive") == "true"
+	if err := applyKindFields(r, link); err != nil {
+		h.resp.Invalid(w, r, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	// The page picker is only shown once the creator has sub-pages
+	opts, err := loadCardOptions(r.Context(), h.pageRepo, h.tagRepo, userID)
+	if err != nil {
+		h.log.Error("database error", "error", err)
+		h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+	oldPage := link.PageID
+	if _, ok := r.Form["page_id"]; ok {
+		page, ok := pageByID(opts.pages, r.FormValue("page_id"))
+		if !ok {
+			h.resp.Error(w, r, http.StatusUnprocessableEntity, "Page not found")
+			return
+		}
+		link.PageID = pageID(page)
+	}
 
 	if err := h.linkRepo.Update(r.Context(), link); err != nil {
 		h.log.Error("link update error", "error", err)
-		h.resp.Error(w, http.StatusInternalServerError, "Failed to update link")
+		h.resp.Error(w, r, http.StatusInternalServerError, "Failed to update link")
 		return
 	}
 
+	// Typing over an uploaded icon that was never published orphans it
+	releaseLinkIcons(r.Context(), h.log, h.blob, h.mediaRepo, userID)
+
 	h.log.Info("link updated", "link_id", link.ID, "user_id", userID)
+	w.Header().Set("HX-Trigger", draftChanged)
+
+	if link.Kind == model.LinkKindURL && link.URL != oldURL {
+		h.fetchPreview(link.ID, link.URL)
+	}
 
-	tmpl, _ := template.ParseFiles("web/templates/partials/link.html")
-	tmpl.Execute(w, link)
+	// The dashboard lists one page at a time, so a moved link leaves the list
+	if !link.OnPage(oldPage) {
+		h.writeListState(w, r, userID, oldPage)
+		return
+	}
+
+	if err := templates.RenderPartial(w, i18n.FromContext(r.Context()), "link.html", opts.card(*link)); err != nil {
+		h.log.Error("template error", "error", err)
+	}
+}
+
+// SetTags replaces the tags on a link from the tag_id form values. Tags
+// are not drafted, so this applies right away.
+func (h *LinkHandler) SetTags(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.UserIDFromContext(r.Context())
+	if userID == 0 {
+		h.resp.Error(w, r, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	linkID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		h.resp.Error(w, r, http.StatusBadRequest, "Invalid link ID")
+		return
+	}
+
+	link, err := h.linkRepo.GetByID(r.Context(), linkID)
+	if err != nil {
+		h.log.Error("database error", "error", err)
+		h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+	if link == nil || link.UserID != userID {
+		h.resp.Error(w, r, http.StatusNotFound, "Link not found")
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		h.resp.Error(w, r, http.StatusBadRequest, "Invalid form data")
+		return
+	}
+	var tagIDs []int64
+	for _, v := range r.Form["tag_id"] {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			h.resp.Error(w, r, http.StatusBadRequest, "Invalid tag ID")
+			return
+		}
+		tagIDs = append(tagIDs, id)
+	}
+
+	if err := h.tagRepo.SetLinkTags(r.Context(), userID, linkID, tagIDs); err != nil {
+		h.log.Error("database error", "error", err)
+		h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+
+	opts, err := loadCardOptions(r.Context(), h.pageRepo, h.tagRepo, userID)
+	if err != nil {
+		h.log.Error("database error", "error", err)
+		h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+
+	h.log.Info("link tagged", "link_id", linkID, "user_id", userID, "tags", len(tagIDs))
+
+	if err := templates.RenderPartial(w, i18n.FromContext(r.Context()), "link.html", opts.card(*link)); err != nil {
+		h.log.Error("template error", "error", err)
+	}
 }
 
 // Delete removes a link
 func (h *LinkHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	userID := middleware.UserIDFromContext(r.Context())
 	if userID == 0 {
-		h.resp.Error(w, http.StatusUnauthorized, "Unauthorized")
+		h.resp.Error(w, r, http.StatusUnauthorized, "Unauthorized")
 		return
 	}
 
 	linkID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
 	if err != nil {
-		h.resp.Error(w, http.StatusBadRequest, "Invalid link ID")
+		h.resp.Error(w, r, http.StatusBadRequest, "Invalid link ID")
 		return
 	}
 
 	// Verify ownership
 	link, err := h.linkRepo.GetByID(r.Context(), linkID)
 	if err != nil || link == nil || link.UserID != userID {
-		h.resp.Error(w, http.StatusNotFound, "Link not found")
+		h.resp.Error(w, r, http.StatusNotFound, "Link not found")
+		return
+	}
+
+	// Blobs owned by the link are looked up first; its media rows go with it.
+	// A published link only leaves the draft, so its blobs stay referenced
+	// until the removal is published.
+	mediaKeys, err := h.mediaRepo.KeysByLink(r.Context(), linkID)
+	if err != nil {
+		h.log.Error("database error", "error", err)
+		h.resp.Error(w, r, http.StatusInternalServerError, "Failed to delete link")
 		return
 	}
 
 	if err := h.linkRepo.Delete(r.Context(), linkID); err != nil {
 		h.log.Error("link delete error", "error", err)
-		h.resp.Error(w, http.StatusInternalServerError, "Failed to delete link")
+		h.resp.Error(w, r, http.StatusInternalServerError, "Failed to delete link")
 		return
 	}
 
+	deleteUnreferenced(r.Context(), h.log, h.blob, h.mediaRepo, mediaKeys)
+
 	h.log.Info("link deleted", "link_id", linkID, "user_id", userID)
+	w.Header().Set("HX-Trigger", draftChanged)
 
-	// OOB: update link count badge
-	count, _ := h.linkRepo.CountByUserID(r.Context(), userID)
+	h.writeListState(w, r, userID, link.PageID)
+}
+
+// writeListState updates the dashboard's link count badge and empty state
+// out-of-band after links were added to or removed from a page's list
+func (h *LinkHandler) writeListState(w http.ResponseWriter, r *http.Request, userID int64, pageID *int64) {
+	count, _ := h.linkRepo.CountByPage(r.Context(), userID, pageID)
 	fmt.Fprintf(w, `<span id="link-count" hx-swap-oob="true" class="ml-2 px-2 py-0.5 text-xs font-medium rounded-full bg-indigo-100 dark:bg-indigo-900/30 text-indigo-600 dark:text-indigo-400">%d</span>`, count)
 
-	// OOB: restore empty state if no links remain
+	// Restore the empty state if no links remain, hide it otherwise
 	if count == 0 {
-		fmt.Fprint(w, `<div id="empty-state" hx-swap-oob="outerHTML" class="p-12 text-center"><div class="w-16 h-16 mx-auto mb-4 rounded-2xl bg-gray-100 dark:bg-gray-800 flex items-center justify-center"><svg class="w-8 h-8 text-gray-400" fill="none" stroke="currentColor" viewBox="0 0 24 24"><path stroke-linecap="round" stroke-linejoin="round" stroke-width="2" d="M13.828 10.172a4 4 0 00-5.656 0l-4 4a4 4 0 105.656 5.656l1.102-1.101m-.758-4.899a4 4 0 005.656 0l4-4a4 4 0 00-5.656-5.656l-1.1 1.1"/></svg></div><h3 class="text-lg font-medium text-gray-900 dark:text-white mb-1">No links yet</h3><p class="text-gray-500 dark:text-gray-400">Add your first link to get started</p></div>`)
+		l := i18n.FromContext(r.Context())
+		fmt.Fprintf(w, `<div id="empty-state" hx-swap-oob="outerHTML" class="p-12 text-center"><div class="w-16 h-16 mx-auto mb-4 rounded-2xl bg-gray-100 dark:bg-gray-800 flex items-center justify-center"><svg class="w-8 h-8 text-gray-400" fill="none" stroke="currentColor" viewBox="0 0 24 24"><path stroke-linecap="round" stroke-linejoin="round" stroke-width="2" d="M13.828 10.172a4 4 0 00-5.656 0l-4 4a4 4 0 105.656 5.656l1.102-1.101m-.758-4.899a4 4 0 005.656 0l4-4a4 4 0 00-5.656-5.656l-1.1 1.1"/></svg></div><h3 class="text-lg font-medium text-gray-900 dark:text-white mb-1">%s</h3><p class="text-gray-500 dark:text-gray-400">%s</p></div>`,
+			html.EscapeString(l.T("No links yet")), html.EscapeString(l.T("Add your first link to get started")))
+		return
 	}
+	fmt.Fprint(w, `<div id="empty-state" hx-swap-oob="outerHTML" style="display:none"></div>`)
 }
 
 // Reorder updates link positions
 func (h *LinkHandler) Reorder(w http.ResponseWriter, r *http.Request) {
 	userID := middleware.UserIDFromContext(r.Context())
 	if userID == 0 {
-		h.resp.Error(w, http.StatusUnauthorized, "Unauthorized")
+		h.resp.Error(w, r, http.StatusUnauthorized, "Unauthorized")
 		return
 	}
 
 	var positions map[int64]int
 	if err := json.NewDecoder(r.Body).Decode(&positions); err != nil {
-		h.resp.Error(w, http.StatusBadRequest, "Invalid request body")
+		h.resp.Error(w, r, http.StatusBadRequest, "Invalid request body")
 		return
 	}
 
 	if err := h.linkRepo.UpdatePositions(r.Context(), userID, positions); err != nil {
 		h.log.Error("reorder error", "error", err)
-		h.resp.Error(w, http.StatusInternalServerError, "Failed to reorder links")
+		h.resp.Error(w, r, http.StatusInternalServerError, "Failed to reorder links")
 		return
 	}
 
@@ -202,26 +428,191 @@ func (h *LinkHandler) Reorder(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 }
 
-// Click records a link click
+// Click records a link click and redirects to the link. Sensitive links
+// show a warning first; the click only counts once the visitor confirms.
 func (h *LinkHandler) Click(w http.ResponseWriter, r *http.Request) {
+	link := h.clickTarget(w, r)
+	if link == nil {
+		return
+	}
+
+	// src=qr marks scans of a printed link QR code
+	source := model.NormalizeSource(r.URL.Query().Get("src"))
+
+	if link.IsSensitive {
+		h.record(&model.Analytics{
+			UserID:    link.UserID,
+			LinkID:    &link.ID,
+			EventType: "interstitial_view",
+			Source:    source,
+			PageID:    link.PageID,
+			Referrer:  r.Referer(),
+			UserAgent: r.UserAgent(),
+		})
+
+		// The warning is part of the profile, in the profile's language
+		if owner, err := h.userRepo.GetByID(r.Context(), link.UserID); err == nil && owner != nil {
+			r = withProfileLocale(r, h.locales, owner)
+		}
+
+		w.Header().Set("X-Robots-Tag", "noindex")
+		data := InterstitialData{Link: link, Domain: linkDomain(link.URL), Source: source}
+		if err := templates.Render(w, i18n.FromContext(r.Context()), "interstitial.html", data); err != nil {
+			h.log.Error("template error", "error", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	h.follow(w, r, link, source, http.StatusTemporaryRedirect)
+}
+
+// Confirm follows a sensitive link after the visitor accepted the warning
+func (h *LinkHandler) Confirm(w http.ResponseWriter, r *http.Request) {
+	link := h.clickTarget(w, r)
+	if link == nil {
+		return
+	}
+	h.follow(w, r, link, model.NormalizeSource(r.FormValue("src")), http.StatusSeeOther)
+}
+
+// InterstitialData holds data for the sensitive link warning
+type InterstitialData struct {
+	Link   *model.Link
+	Domain string
+	Source string
+}
+
+// clickTarget loads the published link a click is for, writing an error
+// or a redirect to the unlock form and returning nil if it can't be followed
+func (h *LinkHandler) clickTarget(w http.ResponseWriter, r *http.Request) *model.Link {
 	linkID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
 	if err != nil {
-		h.resp.Error(w, http.StatusBadRequest, "Invalid link ID")
-		return
+		h.resp.Error(w, r, http.StatusBadRequest, "Invalid link ID")
+		return nil
 	}
 
-	link, err := h.linkRepo.GetByID(r.Context(), linkID)
+	// Visitors follow the published link, whatever the draft says
+	link, err := h.linkRepo.GetPublishedByID(r.Context(), linkID)
 	if err != nil || link == nil {
-		h.resp.Error(w, http.StatusNotFound, "Link not found")
+		h.resp.Error(w, r, http.StatusNotFound, "Link not found")
+		return nil
+	}
+
+	// A custom domain only redirects its owner's links
+	if d := customDomainFromContext(r.Context()); d != nil && d.UserID != link.UserID {
+		h.resp.Error(w, r, http.StatusNotFound, "Link not found")
+		return nil
+	}
+
+	// Links on a password-protected profile need it unlocked first
+	if ok, err := h.lock.guardClick(w, r, link.UserID); !ok {
+		if err != nil {
+			h.log.Error("database error", "error", err)
+			h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
+		}
+		return nil
+	}
+
+	return link
+}
+
+// follow records the click and redirects to the link, or serves the file
+// for contact and event links. The click counts for the page the link was
+// published on at the time.
+func (h *LinkHandler) follow(w http.ResponseWriter, r *http.Request, link *model.Link, source string, status int) {
+	h.record(&model.Analytics{
+		UserID:    link.UserID,
+		LinkID:    &link.ID,
+		EventType: "link_click",
+		Source:    source,
+		PageID:    link.PageID,
+		Referrer:  r.Referer(),
+		UserAgent: r.UserAgent(),
+	})
+
+	if link.IsDownload() {
+		h.download(w, r, link, true)
 		return
 	}
 
+	// Redirect to the actual URL
+	http.Redirect(w, r, link.URL, status)
+}
+
+// applyKindFields reads the form fields that depend on the link's kind.
+// Contact links have nothing but a title; events need a name and a time.
+// Only plain links have a URL or can be marked sensitive.
+func applyKindFields(r *http.Request, link *model.Link) error {
+	switch link.Kind {
+	case model.LinkKindURL:
+		// Title may be left empty; the preview fetch fills it from og:title
+		link.URL = strings.TrimSpace(r.FormValue("url"))
+		if link.URL == "" {
+			return errors.New("URL is required")
+		}
+		return nil
+	case model.LinkKindContact:
+		link.URL, link.IsSensitive = "", false
+		return nil
+	case model.LinkKindEvent:
+		link.URL, link.IsSensitive = "", false
+		if link.Title == "" {
+			return errors.New("Event name is required")
+		}
+		return model.EventRequest{
+			Start:    r.FormValue("starts_at"),
+			End:      r.FormValue("ends_at"),
+			Location: r.FormValue("location"),
+			TimeZone: r.FormValue("time_zone"),
+		}.Apply(link)
+	}
+	return errors.New("Choose a link type")
+}
+
+// record stores an analytics event in the background
+func (h *LinkHandler) record(event *model.Analytics) {
+	// Not r.Context(): it is cancelled as soon as the response is sent
 	go func() {
-		if err := h.analyticsRepo.RecordLinkClick(context.Background(), link.UserID, linkID, r.Referer(), r.UserAgent()); err != nil {
-			h.log.Error("failed to record click", "link_id", linkID, "error", err)
+		if err := h.analyticsRepo.Record(context.Background(), event); err != nil {
+			h.log.Error("failed to record click", "link_id", *event.LinkID, "event", event.EventType, "error", err)
 		}
 	}()
+}
 
-	// Redirect to the actual URL
-	http.Redirect(w, r, link.URL, http.StatusTemporaryRedirect)
+// linkDomain is the host a link points at, shown on the warning page
+func linkDomain(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.Hostname() == "" {
+		return raw
+	}
+	return strings.TrimPrefix(u.Hostname(), "www.")
+}
+
+// fetchPreview loads the page's Open Graph metadata in the background and
+// stores it on the link. Empty titles and icons are filled in; anything the
+// creator typed is left alone.
+func (h *LinkHandler) fetchPreview(linkID int64, url string) {
+	if h.previewer == nil {
+		return
+	}
+
+	go func() {
+		// Not r.Context(): it is cancelled as soon as the response is sent
+		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancel()
+
+		meta, err := h.previewer.Fetch(ctx, url)
+		if err != nil {
+			h.log.Debug("link preview fetch failed", "link_id", linkID, "error", err)
+			return
+		}
+
+		if err := h.linkRepo.ApplyPreview(ctx, linkID, url, meta.Title, meta.Favicon, meta.Description, meta.Image); err != nil {
+			h.log.Error("failed to store link preview", "link_id", linkID, "error", err)
+			return
+		}
+
+		h.log.Debug("link preview stored", "link_id", linkID, "title", meta.Title)
+	}()
 }
@@ -0,0 +1,149 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"linkbio/internal/model"
+	"linkbio/internal/pkg/response"
+	"linkbio/internal/repository"
+	"linkbio/internal/testutil"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestLinkHandler_SensitiveInterstitial(t *testing.T) {
+	testutil.ChdirRoot(t)
+
+	db := testutil.TestDB(t)
+	log := testutil.TestLogger()
+	userRepo := repository.NewUserRepository(db)
+	linkRepo := repository.NewLinkRepository(db)
+	analyticsRepo := repository.NewAnalyticsRepository(db)
+	ctx := context.Background()
+
+	user := &model.User{Username: "careful", Email: "careful@test.com", PasswordHash: "hash", DisplayName: "Careful", Theme: "light"}
+	if err := userRepo.Create(ctx, user); err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	link := &model.Link{UserID: user.ID, Title: "Spoilers", URL: "https://www.spoilers.example/finale", IsActive: true, IsSensitive: true}
+	linkRepo.Create(ctx, link)
+	repository.NewDraftRepository(db).Publish(ctx, user.ID, time.Now())
+
+	h := &LinkHandler{
+		log:           log,
+		resp:          response.New(log),
+		linkRepo:      linkRepo,
+		analyticsRepo: analyticsRepo,
+		userRepo:      userRepo,
+		lock:          profileLock{userRepo: userRepo},
+	}
+	r := chi.NewRouter()
+	r.Get("/click/{id}", h.Click)
+	r.Post("/click/{id}", h.Confirm)
+	path := "/click/" + strconv.FormatInt(link.ID, 10)
+
+	// The warning page names the destination instead of redirecting
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path+"?src=qr", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, Location = %q", rec.Code, rec.Header().Get("Location"))
+	}
+	body := rec.Body.String()
+	if !strings.Contains(body, "spoilers.example") || !strings.Contains(body, `action="`+path+`"`) {
+		t.Errorf("interstitial missing destination or confirm form:\n%s", body)
+	}
+	if !strings.Contains(body, `name="src" value="qr"`) {
+		t.Error("interstitial drops the click source")
+	}
+
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader("src=qr"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	if rec.Code != http.StatusSeeOther || rec.Header().Get("Location") != link.URL {
+		t.Fatalf("confirm status = %d, Location = %q", rec.Code, rec.Header().Get("Location"))
+	}
+
+	// Both events are recorded in the background
+	var summary *model.AnalyticsSummary
+	for i := 0; i < 100; i++ {
+		summary, _ = analyticsRepo.GetSummary(ctx, user.ID, 1)
+		if summary != nil && len(summary.Warnings) > 0 && summary.Warnings[0].Continued > 0 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if len(summary.Warnings) != 1 || summary.Warnings[0].Views != 1 || summary.Warnings[0].Continued != 1 {
+		t.Errorf("Warnings = %+v, want 1 view and 1 continued", summary.Warnings)
+	}
+	if summary.TotalClicks != 1 {
+		t.Errorf("TotalClicks = %d, want only the confirmed click", summary.TotalClicks)
+	}
+	if len(summary.Sources) != 1 || summary.Sources[0].Source != model.SourceQR {
+		t.Errorf("Sources = %+v, want the qr source kept through the warning", summary.Sources)
+	}
+}
+
+func TestLinkHandler_Click_RecordsPage(t *testing.T) {
+	db := testutil.TestDB(t)
+	log := testutil.TestLogger()
+	userRepo := repository.NewUserRepository(db)
+	linkRepo := repository.NewLinkRepository(db)
+	pageRepo := repository.NewPageRepository(db)
+	draftRepo := repository.NewDraftRepository(db)
+	analyticsRepo := repository.NewAnalyticsRepository(db)
+	ctx := context.Background()
+
+	user := &model.User{Username: "merchy", Email: "merchy@test.com", PasswordHash: "hash", Theme: "light"}
+	if err := userRepo.Create(ctx, user); err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	merch := &model.Page{UserID: user.ID, Slug: "merch", Title: "Merch"}
+	pageRepo.Create(ctx, merch)
+	link := &model.Link{UserID: user.ID, PageID: &merch.ID, Title: "Shirt", URL: "https://shirt.example", IsActive: true}
+	linkRepo.Create(ctx, link)
+	draftRepo.Publish(ctx, user.ID, time.Now())
+
+	// Moving the link in the draft doesn't change where visitors click it
+	link.PageID = nil
+	linkRepo.Update(ctx, link)
+
+	h := &LinkHandler{
+		log:           log,
+		resp:          response.New(log),
+		linkRepo:      linkRepo,
+		analyticsRepo: analyticsRepo,
+		userRepo:      userRepo,
+		lock:          profileLock{userRepo: userRepo},
+	}
+	r := chi.NewRouter()
+	r.Get("/click/{id}", h.Click)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/click/"+strconv.FormatInt(link.ID, 10), nil))
+	if rec.Code != http.StatusTemporaryRedirect {
+		t.Fatalf("status = %d", rec.Code)
+	}
+
+	var summary *model.AnalyticsSummary
+	for i := 0; i < 100; i++ {
+		summary, _ = analyticsRepo.GetSummary(ctx, user.ID, 1)
+		if summary != nil && summary.TotalClicks > 0 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// Publishing the move afterwards leaves the click on the merch page
+	draftRepo.Publish(ctx, user.ID, time.Now())
+	summary, _ = analyticsRepo.GetSummary(ctx, user.ID, 1)
+	if len(summary.Pages) != 1 || summary.Pages[0].PageID == nil || *summary.Pages[0].PageID != merch.ID || summary.Pages[0].Clicks != 1 {
+		t.Errorf("Pages = %+v, want the click on merch", summary.Pages)
+	}
+}
@@ -0,0 +1,304 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"io"
+	"net/http"
+	"strconv"
+
+	"linkbio/internal/i18n"
+	"linkbio/internal/middleware"
+	"linkbio/internal/model"
+	"linkbio/internal/pkg/imaging"
+	"linkbio/internal/pkg/response"
+	"linkbio/internal/pkg/templates"
+	"linkbio/internal/repository"
+	"linkbio/internal/storage"
+
+	"log/slog"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// maxImageBytes caps the upload size of avatars and link icons before
+// decoding
+const maxImageBytes = 5 << 20
+
+// iconSize is the square size uploaded link icons are stored at, a few
+// times the size they are shown at
+const iconSize = 96
+
+// avatarSizes are the square sizes generated for every upload. The first one
+// is what User.AvatarURL points at.
+var avatarSizes = []int{400, 128}
+
+// MediaHandler handles avatar and link icon uploads and serves stored media
+type MediaHandler struct {
+	log       *slog.Logger
+	resp      *response.Responder
+	userRepo  *repository.UserRepository
+	linkRepo  *repository.LinkRepository
+	pageRepo  *repository.PageRepository
+	tagRepo   *repository.TagRepository
+	mediaRepo *repository.MediaRepository
+	blob      storage.Blob
+}
+
+// NewMediaHandler creates a new MediaHandler
+func NewMediaHandler(deps *Dependencies) *MediaHandler {
+	return &MediaHandler{
+		log:       deps.Log,
+		resp:      deps.Responder,
+		userRepo:  deps.UserRepo,
+		linkRepo:  deps.LinkRepo,
+		pageRepo:  deps.PageRepo,
+		tagRepo:   deps.TagRepo,
+		mediaRepo: deps.MediaRepo,
+		blob:      deps.Blob,
+	}
+}
+
+// UploadAvatar accepts a multipart image, crops and resizes it and sets it
+// as the user's avatar
+func (h *MediaHandler) UploadAvatar(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.UserIDFromContext(r.Context())
+	if userID == 0 {
+		h.resp.Error(w, r, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	img, size, ok := h.readImage(w, r, "avatar")
+	if !ok {
+		return
+	}
+
+	user, err := h.userRepo.GetByID(r.Context(), userID)
+	if err != nil || user == nil {
+		h.log.Error("database error", "error", err)
+		h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+
+	oldKeys, err := h.mediaRepo.KeysByUser(r.Context(), userID, "avatar")
+	if err != nil {
+		h.log.Error("database error", "error", err)
+		h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+
+	// Decoding dropped all metadata, so the re-encoded files carry no EXIF
+	square := imaging.CenterCrop(img)
+	var media []*model.Media
+	for _, size := range avatarSizes {
+		out, err := imaging.EncodeJPEG(imaging.Resize(square, size, size), 85)
+		if err != nil {
+			h.log.Error("avatar encode error", "error", err)
+			h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
+			return
+		}
+		key := storage.Key("avatars", out, ".jpg")
+		if err := h.blob.Put(r.Context(), key, bytes.NewReader(out), "image/jpeg"); err != nil {
+			h.log.Error("avatar write error", "error", err)
+			h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
+			return
+		}
+		media = append(media, &model.Media{Key: key, ContentType: "image/jpeg", Size: int64(len(out))})
+	}
+
+	if err := h.mediaRepo.Replace(r.Context(), userID, "avatar", media); err != nil {
+		h.log.Error("database error", "error", err)
+		h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+
+	user.AvatarURL = media[0].URL()
+	if err := h.userRepo.Update(r.Context(), user); err != nil {
+		h.log.Error("user update error", "error", err)
+		h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+
+	deleteUnreferenced(r.Context(), h.log, h.blob, h.mediaRepo, oldKeys)
+
+	h.log.Info("avatar uploaded", "user_id", userID, "bytes", size)
+
+	if err := templates.RenderPartial(w, i18n.FromContext(r.Context()), "avatar.html", user); err != nil {
+		h.log.Error("template error", "error", err)
+	}
+}
+
+// UploadIcon accepts a multipart image, crops it square and sets it as a
+// link's icon. Like typing an icon, this changes the draft.
+func (h *MediaHandler) UploadIcon(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.UserIDFromContext(r.Context())
+	if userID == 0 {
+		h.resp.Error(w, r, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	linkID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		h.resp.Error(w, r, http.StatusBadRequest, "Invalid link ID")
+		return
+	}
+
+	link, err := h.linkRepo.GetByID(r.Context(), linkID)
+	if err != nil {
+		h.log.Error("database error", "error", err)
+		h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+	if link == nil || link.UserID != userID {
+		h.resp.Error(w, r, http.StatusNotFound, "Link not found")
+		return
+	}
+
+	img, size, ok := h.readImage(w, r, "icon")
+	if !ok {
+		return
+	}
+
+	// PNG keeps the transparency most logos rely on
+	out, err := imaging.EncodePNG(imaging.Resize(imaging.CenterCrop(img), iconSize, iconSize))
+	if err != nil {
+		h.log.Error("icon encode error", "error", err)
+		h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+	key := storage.Key("icons", out, ".png")
+	if err := h.blob.Put(r.Context(), key, bytes.NewReader(out), "image/png"); err != nil {
+		h.log.Error("icon write error", "error", err)
+		h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+
+	media := &model.Media{UserID: userID, LinkID: &link.ID, Kind: "icon", Key: key, ContentType: "image/png", Size: int64(len(out))}
+	if err := h.mediaRepo.Create(r.Context(), media); err != nil {
+		h.log.Error("database error", "error", err)
+		h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+
+	opts, err := loadCardOptions(r.Context(), h.pageRepo, h.tagRepo, userID)
+	if err != nil {
+		h.log.Error("database error", "error", err)
+		h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+
+	link.Icon = media.URL()
+	if err := h.linkRepo.Update(r.Context(), link); err != nil {
+		h.log.Error("link update error", "error", err)
+		h.resp.Error(w, r, http.StatusInternalServerError, "Failed to update link")
+		return
+	}
+
+	// A previous unpublished upload is now shown nowhere
+	releaseLinkIcons(r.Context(), h.log, h.blob, h.mediaRepo, userID)
+
+	h.log.Info("link icon uploaded", "link_id", link.ID, "user_id", userID, "bytes", size)
+	w.Header().Set("HX-Trigger", draftChanged)
+
+	if err := templates.RenderPartial(w, i18n.FromContext(r.Context()), "link.html", opts.card(*link)); err != nil {
+		h.log.Error("template error", "error", err)
+	}
+}
+
+// readImage decodes the image uploaded in a multipart field, returning it
+// with the upload's size. It answers the request itself when it returns
+// false.
+func (h *MediaHandler) readImage(w http.ResponseWriter, r *http.Request, field string) (image.Image, int, bool) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxImageBytes+1024)
+	if err := r.ParseMultipartForm(maxImageBytes); err != nil {
+		h.resp.Error(w, r, http.StatusRequestEntityTooLarge, "Image must be smaller than 5 MB")
+		return nil, 0, false
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	file, _, err := r.FormFile(field)
+	if err != nil {
+		h.resp.Error(w, r, http.StatusBadRequest, "Choose an image to upload")
+		return nil, 0, false
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		h.resp.Error(w, r, http.StatusBadRequest, "Could not read upload")
+		return nil, 0, false
+	}
+
+	// The content type is sniffed from the bytes; the client's header is ignored
+	img, err := imaging.Decode(data)
+	if errors.Is(err, imaging.ErrUnsupportedType) {
+		h.resp.Error(w, r, http.StatusUnsupportedMediaType, "Please upload a JPEG, PNG or GIF image")
+		return nil, 0, false
+	}
+	if err != nil {
+		h.resp.Error(w, r, http.StatusBadRequest, "That image could not be processed")
+		return nil, 0, false
+	}
+	return img, len(data), true
+}
+
+// Serve streams a stored blob with long-lived cache headers. Keys are
+// content hashes, so a URL's bytes never change.
+func (h *MediaHandler) Serve(w http.ResponseWriter, r *http.Request) {
+	key := chi.URLParam(r, "*")
+	if !storage.ValidKey(key) {
+		http.NotFound(w, r)
+		return
+	}
+
+	body, info, err := h.blob.Get(r.Context(), key)
+	if errors.Is(err, storage.ErrNotFound) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		h.log.Error("media read error", "key", key, "error", err)
+		http.Error(w, "Something went wrong", http.StatusInternalServerError)
+		return
+	}
+	defer body.Close()
+
+	if info.ContentType != "" {
+		w.Header().Set("Content-Type", info.ContentType)
+	}
+	if info.Size > 0 {
+		w.Header().Set("Content-Length", strconv.FormatInt(info.Size, 10))
+	}
+	w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	io.Copy(w, body)
+}
+
+// deleteUnreferenced removes the blobs among keys that no media row points at
+// any more. Call it once the owning record (a user, link or replaced upload)
+// is gone; failures are logged and leave the blob behind.
+func deleteUnreferenced(ctx context.Context, log *slog.Logger, blob storage.Blob, mediaRepo *repository.MediaRepository, keys []string) {
+	orphans, err := mediaRepo.Unreferenced(ctx, keys)
+	if err != nil {
+		log.Warn("failed to find unreferenced media", "error", err)
+		return
+	}
+	for _, key := range orphans {
+		if err := blob.Delete(ctx, key); err != nil {
+			log.Warn("failed to delete media", "key", key, "error", err)
+		}
+	}
+}
+
+// releaseLinkIcons drops a user's uploaded link icons that neither the
+// draft nor the live profile shows any more, deleting unreferenced blobs
+func releaseLinkIcons(ctx context.Context, log *slog.Logger, blob storage.Blob, mediaRepo *repository.MediaRepository, userID int64) {
+	keys, err := mediaRepo.ReleaseLinkIcons(ctx, userID)
+	if err != nil {
+		log.Warn("failed to release link icons", "user_id", userID, "error", err)
+		return
+	}
+	deleteUnreferenced(ctx, log, blob, mediaRepo, keys)
+}
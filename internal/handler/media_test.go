@@ -0,0 +1,284 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"linkbio/internal/middleware"
+	"linkbio/internal/model"
+	"linkbio/internal/pkg/response"
+	"linkbio/internal/repository"
+	"linkbio/internal/storage"
+	"linkbio/internal/testutil"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func setupMediaHandler(t *testing.T) (*MediaHandler, *repository.UserRepository, *repository.DraftRepository, *model.User) {
+	t.Helper()
+
+	db := testutil.TestDB(t)
+	log := testutil.TestLogger()
+
+	userRepo := repository.NewUserRepository(db)
+	user := &model.User{
+		Username:     "avataruser",
+		Email:        "avatar@test.com",
+		PasswordHash: "hash",
+		DisplayName:  "Avatar User",
+		Theme:        "light",
+	}
+	if err := userRepo.Create(context.Background(), user); err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+
+	h := &MediaHandler{
+		log:       log,
+		resp:      response.New(log),
+		userRepo:  userRepo,
+		linkRepo:  repository.NewLinkRepository(db),
+		pageRepo:  repository.NewPageRepository(db),
+		tagRepo:   repository.NewTagRepository(db),
+		mediaRepo: repository.NewMediaRepository(db),
+		blob:      storage.NewLocal(t.TempDir()),
+	}
+
+	return h, userRepo, repository.NewDraftRepository(db), user
+}
+
+// newMediaRouter mounts Serve the way router.New does, so the wildcard resolves
+func newMediaRouter(h *MediaHandler) http.Handler {
+	r := chi.NewRouter()
+	r.Get("/media/*", h.Serve)
+	return r
+}
+
+// avatarRequest builds an authenticated multipart upload
+func avatarRequest(t *testing.T, userID int64, data []byte) *http.Request {
+	t.Helper()
+	return uploadRequest(t, "/api/v1/avatar", "avatar", userID, data)
+}
+
+// uploadRequest builds an authenticated multipart upload of one file field
+func uploadRequest(t *testing.T, path, field string, userID int64, data []byte) *http.Request {
+	t.Helper()
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	part, _ := mw.CreateFormFile(field, "photo.png") // name deliberately lies
+	part.Write(data)
+	mw.Close()
+
+	req := httptest.NewRequest(http.MethodPost, path, &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	ctx := context.WithValue(req.Context(), middleware.UserIDKey, userID)
+	return req.WithContext(ctx)
+}
+
+func TestMediaHandler_UploadAvatar(t *testing.T) {
+	testutil.ChdirRoot(t)
+	h, userRepo, _, user := setupMediaHandler(t)
+
+	var img bytes.Buffer
+	src := image.NewRGBA(image.Rect(0, 0, 800, 500))
+	for x := 0; x < 800; x++ {
+		src.Set(x, 250, color.RGBA{R: 255, A: 255})
+	}
+	jpeg.Encode(&img, src, nil)
+
+	rec := httptest.NewRecorder()
+	h.UploadAvatar(rec, avatarRequest(t, user.ID, img.Bytes()))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+
+	updated, _ := userRepo.GetByID(context.Background(), user.ID)
+	if !strings.HasPrefix(updated.AvatarURL, "/media/avatars/") {
+		t.Fatalf("AvatarURL = %q, want a /media/avatars/ path", updated.AvatarURL)
+	}
+
+	// Every size was stored as a square JPEG under a content-addressed key
+	keys, _ := h.mediaRepo.KeysByUser(context.Background(), user.ID, "avatar")
+	if len(keys) != len(avatarSizes) {
+		t.Fatalf("stored %d avatar blobs, want %d", len(keys), len(avatarSizes))
+	}
+	sizes := map[int]bool{}
+	for _, key := range keys {
+		rc, _, err := h.blob.Get(context.Background(), key)
+		if err != nil {
+			t.Fatalf("blob %s not stored: %v", key, err)
+		}
+		cfg, err := jpeg.DecodeConfig(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatalf("blob %s is not a JPEG: %v", key, err)
+		}
+		if cfg.Width != cfg.Height {
+			t.Errorf("blob %s is %dx%d, want square", key, cfg.Width, cfg.Height)
+		}
+		sizes[cfg.Width] = true
+	}
+	for _, size := range avatarSizes {
+		if !sizes[size] {
+			t.Errorf("no %dpx avatar stored", size)
+		}
+	}
+
+	// Replacing the avatar deletes the old blobs
+	src.Set(0, 0, color.RGBA{G: 255, A: 255})
+	img.Reset()
+	jpeg.Encode(&img, src, nil)
+	rec = httptest.NewRecorder()
+	h.UploadAvatar(rec, avatarRequest(t, user.ID, img.Bytes()))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("second upload Status = %d", rec.Code)
+	}
+	stored, _ := h.blob.List(context.Background(), "avatars/")
+	if len(stored) != len(avatarSizes) {
+		t.Errorf("%d blobs left after replacing avatar, want %d", len(stored), len(avatarSizes))
+	}
+}
+
+func TestMediaHandler_UploadAvatar_RejectsNonImage(t *testing.T) {
+	h, userRepo, _, user := setupMediaHandler(t)
+
+	rec := httptest.NewRecorder()
+	h.UploadAvatar(rec, avatarRequest(t, user.ID, []byte("<svg onload=alert(1)></svg>")))
+
+	if rec.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("Status = %d, want %d", rec.Code, http.StatusUnsupportedMediaType)
+	}
+
+	updated, _ := userRepo.GetByID(context.Background(), user.ID)
+	if updated.AvatarURL != "" {
+		t.Errorf("AvatarURL = %q, want unchanged", updated.AvatarURL)
+	}
+}
+
+func TestMediaHandler_UploadIcon(t *testing.T) {
+	testutil.ChdirRoot(t)
+	h, _, drafts, user := setupMediaHandler(t)
+	ctx := context.Background()
+
+	link := &model.Link{UserID: user.ID, Title: "Shop", URL: "https://shop.example", IsActive: true}
+	if err := h.linkRepo.Create(ctx, link); err != nil {
+		t.Fatalf("failed to create link: %v", err)
+	}
+
+	r := chi.NewRouter()
+	r.Post("/api/v1/links/{id}/icon", h.UploadIcon)
+	path := "/api/v1/links/" + strconv.FormatInt(link.ID, 10) + "/icon"
+
+	// upload sends a differently colored logo each time, so each gets its own key
+	upload := func(c color.Color) string {
+		t.Helper()
+		src := image.NewRGBA(image.Rect(0, 0, 300, 200))
+		src.Set(150, 100, c)
+		var img bytes.Buffer
+		png.Encode(&img, src)
+
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, uploadRequest(t, path, "icon", user.ID, img.Bytes()))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+		}
+		updated, _ := h.linkRepo.GetByID(ctx, link.ID)
+		key, ok := strings.CutPrefix(updated.Icon, "/media/icons/")
+		if !ok {
+			t.Fatalf("Icon = %q, want a /media/icons/ path", updated.Icon)
+		}
+		if !strings.Contains(rec.Body.String(), updated.Icon) {
+			t.Error("response should be the link card showing the new icon")
+		}
+		return "icons/" + key
+	}
+	stored := func(key string) bool {
+		rc, _, err := h.blob.Get(ctx, key)
+		if err != nil {
+			return false
+		}
+		rc.Close()
+		return true
+	}
+
+	first := upload(color.RGBA{R: 255, A: 255})
+	rc, _, err := h.blob.Get(ctx, first)
+	if err != nil {
+		t.Fatalf("icon not stored: %v", err)
+	}
+	cfg, err := png.DecodeConfig(rc)
+	rc.Close()
+	if err != nil || cfg.Width != iconSize || cfg.Height != iconSize {
+		t.Errorf("stored icon is %dx%d (%v), want a %dpx square PNG", cfg.Width, cfg.Height, err, iconSize)
+	}
+
+	// Replacing an unpublished icon deletes it straight away
+	second := upload(color.RGBA{G: 255, A: 255})
+	if stored(first) {
+		t.Error("unpublished icon should be deleted once replaced")
+	}
+
+	// A published icon stays while the live profile shows it...
+	if _, err := drafts.Publish(ctx, user.ID, time.Now()); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	third := upload(color.RGBA{B: 255, A: 255})
+	if !stored(second) || !stored(third) {
+		t.Fatal("published and drafted icons should both be kept")
+	}
+
+	// ...and goes once the replacement is published
+	keys, err := drafts.Publish(ctx, user.ID, time.Now())
+	if err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	deleteUnreferenced(ctx, h.log, h.blob, h.mediaRepo, keys)
+	if stored(second) {
+		t.Error("replaced icon should be deleted on publish")
+	}
+	if !stored(third) {
+		t.Error("published icon should be kept")
+	}
+}
+
+func TestMediaHandler_Serve_CacheHeaders(t *testing.T) {
+	h, _, _, _ := setupMediaHandler(t)
+
+	h.blob.Put(context.Background(), "avatars/abc.jpg", strings.NewReader("jpeg"), "image/jpeg")
+
+	req := httptest.NewRequest(http.MethodGet, "/media/avatars/abc.jpg", nil)
+	rec := httptest.NewRecorder()
+	newMediaRouter(h).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if cc := rec.Header().Get("Cache-Control"); !strings.Contains(cc, "max-age=31536000") {
+		t.Errorf("Cache-Control = %q, want a year-long max-age", cc)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "image/jpeg" {
+		t.Errorf("Content-Type = %q, want image/jpeg", ct)
+	}
+
+	// Directories and paths outside the store are never served
+	for _, path := range []string{"/media/avatars/", "/media/avatars/../../go.mod"} {
+		req = httptest.NewRequest(http.MethodGet, path, nil)
+		rec = httptest.NewRecorder()
+		newMediaRouter(h).ServeHTTP(rec, req)
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("%s Status = %d, want %d", path, rec.Code, http.StatusNotFound)
+		}
+	}
+}
@@ -0,0 +1,251 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"linkbio/internal/model"
+	"linkbio/internal/pkg/imaging"
+	"linkbio/internal/pkg/ogimage"
+	"linkbio/internal/pkg/response"
+	"linkbio/internal/repository"
+	"linkbio/internal/storage"
+	"linkbio/internal/theme"
+
+	"log/slog"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// ogPrefix is where rendered share images are cached in blob storage, one
+// directory per user: og/{userID}/{hash}.png
+const ogPrefix = "og"
+
+// OGHandler renders the Open Graph share image for a profile
+type OGHandler struct {
+	log             *slog.Logger
+	resp            *response.Responder
+	userRepo        *repository.UserRepository
+	linkRepo        *repository.LinkRepository
+	mediaRepo       *repository.MediaRepository
+	blob            storage.Blob
+	hideBrokenLinks bool
+}
+
+// NewOGHandler creates a new OGHandler
+func NewOGHandler(deps *Dependencies) *OGHandler {
+	return &OGHandler{
+		log:             deps.Log,
+		resp:            deps.Responder,
+		userRepo:        deps.UserRepo,
+		linkRepo:        deps.LinkRepo,
+		mediaRepo:       deps.MediaRepo,
+		blob:            deps.Blob,
+		hideBrokenLinks: deps.Config.LinkCheckHideBroken,
+	}
+}
+
+// Profile serves /u/{username}/og.png. Images are cached under the hash of
+// everything drawn on them, so editing the profile or its links produces a
+// new image and the previous one is deleted.
+func (h *OGHandler) Profile(w http.ResponseWriter, r *http.Request) {
+	username := chi.URLParam(r, "username")
+	user, err := h.userRepo.GetPublishedByUsername(r.Context(), username)
+	if err != nil {
+		h.log.Error("database error", "error", err)
+		h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+	if user == nil {
+		if ok, err := redirectRenamed(w, r, h.userRepo, username, "/og.png"); ok || err != nil {
+			if err != nil {
+				h.log.Error("database error", "error", err)
+				h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
+			}
+			return
+		}
+		h.resp.Error(w, r, http.StatusNotFound, "Profile not found")
+		return
+	}
+	// Share cards show link titles, which a protected profile keeps private
+	if user.Visibility() == model.VisibilityPassword {
+		h.resp.Error(w, r, http.StatusNotFound, "Profile not found")
+		return
+	}
+
+	links, err := profileLinks(r.Context(), h.linkRepo, user.ID, nil, h.hideBrokenLinks)
+	if err != nil {
+		h.log.Error("database error", "error", err)
+		h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+
+	card := shareCard(user, links)
+	hash := card.Hash()
+	etag := `"` + hash + `"`
+
+	// Versioned URLs (?v=hash, as used in og:image) never change content
+	if r.URL.Query().Get("v") == hash {
+		w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	} else {
+		w.Header().Set("Cache-Control", "public, max-age=300")
+	}
+	w.Header().Set("ETag", etag)
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	data, err := h.cached(r.Context(), user, card, hash)
+	if err != nil {
+		h.log.Error("og image error", "user_id", user.ID, "error", err)
+		h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	w.Write(data)
+}
+
+// cached returns the stored image for hash, rendering and storing it on a
+// miss. Storing a new image releases the user's older one.
+func (h *OGHandler) cached(ctx context.Context, user *model.User, card ogimage.Card, hash string) ([]byte, error) {
+	dir := ogPrefix + "/" + strconv.FormatInt(user.ID, 10) + "/"
+	key := dir + hash + ".png"
+
+	body, _, err := h.blob.Get(ctx, key)
+	if err == nil {
+		defer body.Close()
+		return io.ReadAll(body)
+	}
+	if !errors.Is(err, storage.ErrNotFound) {
+		return nil, err
+	}
+
+	data, err := ogimage.Render(card, h.avatar(ctx, user))
+	if err != nil {
+		return nil, err
+	}
+	if err := h.blob.Put(ctx, key, bytes.NewReader(data), "image/png"); err != nil {
+		// Serving the fresh render still beats failing the request
+		h.log.Warn("failed to cache og image", "key", key, "error", err)
+		return data, nil
+	}
+
+	// The user owns one share image at a time, recorded like any other media
+	stale, err := h.mediaRepo.KeysByUser(ctx, user.ID, "og")
+	if err != nil {
+		h.log.Warn("failed to list og images", "user_id", user.ID, "error", err)
+		return data, nil
+	}
+	media := []*model.Media{{Key: key, ContentType: "image/png", Size: int64(len(data))}}
+	if err := h.mediaRepo.Replace(ctx, user.ID, "og", media); err != nil {
+		h.log.Warn("failed to record og image", "key", key, "error", err)
+		return data, nil
+	}
+	deleteUnreferenced(ctx, h.log, h.blob, h.mediaRepo, stale)
+	return data, nil
+}
+
+// avatar loads the user's uploaded avatar. Avatars hosted elsewhere are not
+// fetched; the card shows the initial instead.
+func (h *OGHandler) avatar(ctx context.Context, user *model.User) image.Image {
+	key, ok := strings.CutPrefix(user.AvatarURL, "/media/")
+	if !ok || !storage.ValidKey(key) {
+		return nil
+	}
+
+	body, _, err := h.blob.Get(ctx, key)
+	if err != nil {
+		h.log.Warn("failed to read avatar", "key", key, "error", err)
+		return nil
+	}
+	defer body.Close()
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil
+	}
+	img, err := imaging.Decode(data)
+	if err != nil {
+		h.log.Warn("failed to decode avatar", "key", key, "error", err)
+		return nil
+	}
+	return img
+}
+
+// shareCard describes a profile's share image
+func shareCard(user *model.User, links []model.Link) ogimage.Card {
+	name := user.DisplayName
+	if name == "" {
+		name = user.Username
+	}
+
+	card := ogimage.Card{
+		Title:    name,
+		Subtitle: "@" + user.Username,
+		Footer:   "LinkBio",
+		Initial:  string([]rune(name)[:1]),
+		Style:    shareStyle(theme.Resolve(user.Theme, user.ThemeConfig)),
+	}
+	if strings.HasPrefix(user.AvatarURL, "/media/") {
+		card.AvatarRef = user.AvatarURL
+	}
+	for _, l := range links {
+		if len(card.Items) == ogimage.MaxItems {
+			break
+		}
+		card.Items = append(card.Items, l.Title)
+	}
+	return card
+}
+
+// shareStyle maps a profile theme onto share image colors
+func shareStyle(t theme.Theme) ogimage.Style {
+	s := ogimage.Style{
+		Background:   ogimage.ParseHex(t.Background.From),
+		BackgroundTo: ogimage.ParseHex(t.Background.From),
+		Text:         ogimage.ParseHex(t.Text),
+		Muted:        ogimage.ParseHex(t.Muted),
+		Button:       ogimage.ParseHex(t.Button.Color),
+		ButtonText:   ogimage.ParseHex(t.Button.Text),
+	}
+	if t.Background.Type == theme.BackgroundGradient {
+		s.BackgroundTo = ogimage.ParseHex(t.Background.To)
+		s.Angle = t.Background.Angle
+	}
+	switch t.Button.Style {
+	case theme.ButtonOutline:
+		s.Outline = true
+	case theme.ButtonSoft:
+		s.Button.A = 46 // matches the 18% tint in theme CSS
+	}
+	return s
+}
+
+// shareImageURL is the versioned og:image address for a profile
+func shareImageURL(baseURL string, user *model.User, links []model.Link) string {
+	return baseURL + "/u/" + url.PathEscape(user.Username) + "/og.png?v=" + shareCard(user, links).Hash()
+}
+
+// profileLinks returns the links shown on one of a user's public pages,
+// pageID being nil for the main page
+func profileLinks(ctx context.Context, linkRepo *repository.LinkRepository, userID int64, pageID *int64, hideBroken bool) ([]model.Link, error) {
+	links, err := linkRepo.GetActiveByUserID(ctx, userID, pageID)
+	if err != nil {
+		return nil, err
+	}
+	// Drop links the health checker found dead
+	if hideBroken {
+		links = withoutBroken(links)
+	}
+	return links, nil
+}
@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"linkbio/internal/model"
+	"linkbio/internal/pkg/response"
+	"linkbio/internal/repository"
+	"linkbio/internal/storage"
+	"linkbio/internal/testutil"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func setupOGHandler(t *testing.T) (*OGHandler, *repository.DraftRepository, storage.Blob, *model.User, http.Handler) {
+	t.Helper()
+
+	db := testutil.TestDB(t)
+	log := testutil.TestLogger()
+	blob := storage.NewLocal(t.TempDir())
+	h := &OGHandler{
+		log:       log,
+		resp:      response.New(log),
+		userRepo:  repository.NewUserRepository(db),
+		linkRepo:  repository.NewLinkRepository(db),
+		mediaRepo: repository.NewMediaRepository(db),
+		blob:      blob,
+	}
+
+	user := &model.User{Username: "sharer", Email: "sharer@test.com", PasswordHash: "hash", DisplayName: "Sharer", Theme: "ocean"}
+	if err := h.userRepo.Create(context.Background(), user); err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+
+	r := chi.NewRouter()
+	r.Get("/u/{username}/og.png", h.Profile)
+	return h, repository.NewDraftRepository(db), blob, user, r
+}
+
+func TestOGHandler_Profile(t *testing.T) {
+	h, drafts, blob, user, r := setupOGHandler(t)
+	ctx := context.Background()
+	dir := "og/" + strconv.FormatInt(user.ID, 10) + "/"
+
+	get := func(path string, header http.Header) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := get("/u/sharer/og.png", nil)
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "image/png" {
+		t.Fatalf("status = %d, content type = %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	if _, err := png.Decode(bytes.NewReader(rec.Body.Bytes())); err != nil {
+		t.Fatalf("body is not a PNG: %v", err)
+	}
+	etag := rec.Header().Get("ETag")
+	keys, _ := blob.List(ctx, dir)
+	if len(keys) != 1 || !strings.Contains(keys[0], strings.Trim(etag, `"`)) {
+		t.Fatalf("cached keys = %v, etag = %s", keys, etag)
+	}
+
+	// Conditional and versioned requests
+	if rec := get("/u/sharer/og.png", http.Header{"If-None-Match": {etag}}); rec.Code != http.StatusNotModified {
+		t.Errorf("If-None-Match status = %d, want %d", rec.Code, http.StatusNotModified)
+	}
+	rec = get("/u/sharer/og.png?v="+strings.Trim(etag, `"`), nil)
+	if cc := rec.Header().Get("Cache-Control"); !strings.Contains(cc, "immutable") {
+		t.Errorf("versioned Cache-Control = %q", cc)
+	}
+	if cc := get("/u/sharer/og.png?v=stale", nil).Header().Get("Cache-Control"); strings.Contains(cc, "immutable") {
+		t.Errorf("stale version Cache-Control = %q", cc)
+	}
+
+	// A published profile edit and a new link each produce a new image and
+	// drop the old one
+	user.DisplayName = "Renamed Sharer"
+	h.userRepo.Update(ctx, user)
+	if rec := get("/u/sharer/og.png", nil); rec.Header().Get("ETag") != etag {
+		t.Error("ETag changed before the edit was published")
+	}
+	drafts.Publish(ctx, user.ID, time.Now())
+	rec = get("/u/sharer/og.png", nil)
+	if rec.Header().Get("ETag") == etag {
+		t.Error("ETag unchanged after a profile update")
+	}
+	etag = rec.Header().Get("ETag")
+
+	h.linkRepo.Create(ctx, &model.Link{UserID: user.ID, Title: "Portfolio", URL: "https://example.com", IsActive: true})
+	drafts.Publish(ctx, user.ID, time.Now())
+	rec = get("/u/sharer/og.png", nil)
+	if rec.Header().Get("ETag") == etag {
+		t.Error("ETag unchanged after adding a link")
+	}
+	keys, _ = blob.List(ctx, dir)
+	if len(keys) != 1 || !strings.Contains(keys[0], strings.Trim(rec.Header().Get("ETag"), `"`)) {
+		t.Errorf("cached keys = %v, want only the current image", keys)
+	}
+
+	if rec := get("/u/nobody/og.png", nil); rec.Code != http.StatusNotFound {
+		t.Errorf("unknown user status = %d", rec.Code)
+	}
+}
+
+func TestShareImageURL(t *testing.T) {
+	user := &model.User{Username: "sharer", DisplayName: "Sharer", Theme: "light"}
+	a := shareImageURL("https://linkbio.test", user, nil)
+	if !strings.HasPrefix(a, "https://linkbio.test/u/sharer/og.png?v=") {
+		t.Errorf("shareImageURL = %q", a)
+	}
+
+	user.Theme = "dark"
+	if b := shareImageURL("https://linkbio.test", user, nil); b == a {
+		t.Error("URL did not change with the theme")
+	}
+}
@@ -0,0 +1,252 @@
+package handler
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"linkbio/internal/i18n"
+	"linkbio/internal/middleware"
+	"linkbio/internal/model"
+	"linkbio/internal/pkg/response"
+	"linkbio/internal/pkg/templates"
+	"linkbio/internal/repository"
+
+	"log/slog"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// PageHandler manages a profile's sub-pages
+type PageHandler struct {
+	log      *slog.Logger
+	resp     *response.Responder
+	pageRepo *repository.PageRepository
+}
+
+// NewPageHandler creates a new PageHandler
+func NewPageHandler(deps *Dependencies) *PageHandler {
+	return &PageHandler{
+		log:      deps.Log,
+		resp:     deps.Responder,
+		pageRepo: deps.PageRepo,
+	}
+}
+
+// PageView is a page with the address visitors open it at
+type PageView struct {
+	model.Page
+	Path string
+}
+
+// pageViews prepares pages for templates
+func pageViews(username string, pages []model.Page) []PageView {
+	views := make([]PageView, len(pages))
+	for i, p := range pages {
+		views[i] = PageView{Page: p, Path: "/u/" + url.PathEscape(username) + "/" + p.Slug}
+	}
+	return views
+}
+
+// Create adds a page to the current user's profile
+func (h *PageHandler) Create(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.UserIDFromContext(r.Context())
+	if userID == 0 {
+		h.resp.Error(w, r, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		h.resp.Error(w, r, http.StatusBadRequest, "Invalid form data")
+		return
+	}
+
+	req := model.PageCreateRequest{Title: r.FormValue("title"), Slug: r.FormValue("slug")}
+	if err := req.Validate(); err != nil {
+		h.resp.Invalid(w, r, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	pages, err := h.pageRepo.ListByUser(r.Context(), userID)
+	if err != nil {
+		h.log.Error("database error", "error", err)
+		h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+	if len(pages) >= model.MaxPages {
+		h.resp.Errorf(w, r, http.StatusUnprocessableEntity, "You can have up to %d pages", model.MaxPages)
+		return
+	}
+	if _, ok := findPage(pages, req.Slug); ok {
+		h.resp.Errorf(w, r, http.StatusConflict, "You already have a page at /%s", req.Slug)
+		return
+	}
+
+	p := &model.Page{UserID: userID, Slug: req.Slug, Title: req.Title}
+	if err := h.pageRepo.Create(r.Context(), p); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			// Another request added the page since the check above
+			h.resp.Errorf(w, r, http.StatusConflict, "You already have a page at /%s", req.Slug)
+		default:
+			h.log.Error("database error", "error", err)
+			h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
+		}
+		return
+	}
+
+	h.log.Info("page created", "user_id", userID, "page_id", p.ID, "slug", p.Slug)
+	h.respond(w, r, userID, i18n.FromContext(r.Context()).T("%s added", p.Title))
+}
+
+// Delete removes a page from the draft; its links move to the main page
+func (h *PageHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.UserIDFromContext(r.Context())
+	if userID == 0 {
+		h.resp.Error(w, r, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		h.resp.Error(w, r, http.StatusBadRequest, "Invalid page ID")
+		return
+	}
+
+	p, err := h.pageRepo.GetByID(r.Context(), id)
+	if err != nil || p == nil || p.UserID != userID {
+		h.resp.Error(w, r, http.StatusNotFound, "Page not found")
+		return
+	}
+
+	if err := h.pageRepo.Delete(r.Context(), id, userID); err != nil {
+		h.log.Error("database error", "error", err)
+		h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+
+	h.log.Info("page removed", "user_id", userID, "page_id", id)
+	h.respond(w, r, userID, i18n.FromContext(r.Context()).T("%s removed. Its links move to your main page when you publish.", p.Title))
+}
+
+// Reorder updates page positions in the navigation
+func (h *PageHandler) Reorder(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.UserIDFromContext(r.Context())
+	if userID == 0 {
+		h.resp.Error(w, r, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var positions map[int64]int
+	if err := json.NewDecoder(r.Body).Decode(&positions); err != nil {
+		h.resp.Error(w, r, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := h.pageRepo.UpdatePositions(r.Context(), userID, positions); err != nil {
+		h.log.Error("reorder error", "error", err)
+		h.resp.Error(w, r, http.StatusInternalServerError, "Failed to reorder pages")
+		return
+	}
+
+	h.log.Info("pages reordered", "user_id", userID)
+
+	w.Header().Set("HX-Trigger", draftChanged)
+	w.WriteHeader(http.StatusOK)
+}
+
+// respond writes a feedback message, refreshes the page list out-of-band
+// and tells the draft bar to update
+func (h *PageHandler) respond(w http.ResponseWriter, r *http.Request, userID int64, message string) {
+	pages, err := h.pageRepo.ListByUser(r.Context(), userID)
+	if err != nil {
+		h.log.Error("database error", "error", err)
+	}
+
+	w.Header().Set("HX-Trigger", draftChanged)
+
+	fmt.Fprintf(w, `<div class="rounded-xl px-4 py-3 text-sm bg-green-50 dark:bg-green-900/20 text-green-700 dark:text-green-400 animate-slide-in">%s</div>`, html.EscapeString(message))
+
+	fmt.Fprint(w, `<div hx-swap-oob="innerHTML:#page-list">`)
+	if err := templates.RenderPartial(w, i18n.FromContext(r.Context()), "pages.html", pageViews(middleware.UsernameFromContext(r.Context()), pages)); err != nil {
+		h.log.Error("template error", "error", err)
+	}
+	fmt.Fprint(w, `</div>`)
+}
+
+// findPage looks a page up by its address. An empty slug is the main page,
+// reported as nil.
+func findPage(pages []model.Page, slug string) (*model.Page, bool) {
+	if slug == "" {
+		return nil, true
+	}
+	for i := range pages {
+		if pages[i].Slug == slug {
+			return &pages[i], true
+		}
+	}
+	return nil, false
+}
+
+// pageByID finds the page whose ID is raw, a form or query value. An empty
+// value is the main page, reported as nil; ok is false for an ID that isn't
+// one of pages.
+func pageByID(pages []model.Page, raw string) (*model.Page, bool) {
+	if raw == "" {
+		return nil, true
+	}
+	id, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return nil, false
+	}
+	for i := range pages {
+		if pages[i].ID == id {
+			return &pages[i], true
+		}
+	}
+	return nil, false
+}
+
+// pageID is the ID links and analytics store for p, nil for the main page
+func pageID(p *model.Page) *int64 {
+	if p == nil {
+		return nil
+	}
+	return &p.ID
+}
+
+// NavItem is one entry in a profile's page navigation
+type NavItem struct {
+	Title   string
+	URL     string
+	Current bool
+}
+
+// pageNav builds the navigation strip shown on profiles with sub-pages.
+// home is the main page's title and href gives the address of a page, nil
+// being the main page.
+func pageNav(pages []model.Page, current *model.Page, home string, href func(*model.Page) string) []NavItem {
+	if len(pages) == 0 {
+		return nil
+	}
+	nav := []NavItem{{Title: home, URL: href(nil), Current: current == nil}}
+	for i := range pages {
+		p := &pages[i]
+		nav = append(nav, NavItem{Title: p.Title, URL: href(p), Current: current != nil && current.ID == p.ID})
+	}
+	return nav
+}
+
+// subPath appends a page's address to the address of the main page
+func subPath(base string, p *model.Page) string {
+	if p == nil {
+		return base
+	}
+	return strings.TrimSuffix(base, "/") + "/" + p.Slug
+}
@@ -0,0 +1,189 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"linkbio/internal/middleware"
+	"linkbio/internal/model"
+	"linkbio/internal/pkg/response"
+	"linkbio/internal/repository"
+	"linkbio/internal/testutil"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestPageHandler_CreateAndDelete(t *testing.T) {
+	testutil.ChdirRoot(t)
+
+	db := testutil.TestDB(t)
+	log := testutil.TestLogger()
+	userRepo := repository.NewUserRepository(db)
+	pageRepo := repository.NewPageRepository(db)
+	linkRepo := repository.NewLinkRepository(db)
+	ctx := context.Background()
+
+	user := &model.User{Username: "paged", Email: "paged@test.com", PasswordHash: "hash", DisplayName: "Paged", Theme: "light"}
+	if err := userRepo.Create(ctx, user); err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+
+	h := &PageHandler{log: log, resp: response.New(log), pageRepo: pageRepo}
+	r := chi.NewRouter()
+	r.Post("/api/v1/pages", h.Create)
+	r.Delete("/api/v1/pages/{id}", h.Delete)
+
+	do := func(req *http.Request) *httptest.ResponseRecorder {
+		ctx := context.WithValue(req.Context(), middleware.UserIDKey, user.ID)
+		ctx = context.WithValue(ctx, middleware.UsernameKey, user.Username)
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req.WithContext(ctx))
+		return rec
+	}
+	create := func(title, slug string) *httptest.ResponseRecorder {
+		form := url.Values{"title": {title}, "slug": {slug}}
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/pages", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		return do(req)
+	}
+
+	// The address is derived from the title when left empty
+	rec := create("Summer Tour 2025!", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	if !strings.Contains(rec.Body.String(), "/u/paged/summer-tour-2025") {
+		t.Errorf("page list missing the new page:\n%s", rec.Body.String())
+	}
+
+	tests := []struct {
+		title, slug string
+		want        int
+	}{
+		{"Tour", "summer-tour-2025", http.StatusConflict},
+		{"Unlock", "", http.StatusUnprocessableEntity},
+		{"Merch", "Merch Store", http.StatusUnprocessableEntity},
+		{"", "merch", http.StatusUnprocessableEntity},
+		{"🎸", "", http.StatusUnprocessableEntity},
+	}
+	for _, tt := range tests {
+		if rec := create(tt.title, tt.slug); rec.Code != tt.want {
+			t.Errorf("create(%q, %q) status = %d, want %d", tt.title, tt.slug, rec.Code, tt.want)
+		}
+	}
+
+	pages, _ := pageRepo.ListByUser(ctx, user.ID)
+	if len(pages) != 1 {
+		t.Fatalf("pages = %+v, want 1", pages)
+	}
+	tour := pages[0]
+
+	// Removing the page moves its links to the main page
+	link := &model.Link{UserID: user.ID, PageID: &tour.ID, Title: "Tickets", URL: "https://tickets.example", IsActive: true}
+	linkRepo.Create(ctx, link)
+
+	rec = do(httptest.NewRequest(http.MethodDelete, "/api/v1/pages/"+strconv.FormatInt(tour.ID, 10), nil))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "No pages yet") {
+		t.Fatalf("delete status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	if got, _ := linkRepo.GetByID(ctx, link.ID); got == nil || got.PageID != nil {
+		t.Errorf("link after page delete = %+v, want it on the main page", got)
+	}
+}
+
+func TestProfileHandler_SubPages(t *testing.T) {
+	testutil.ChdirRoot(t)
+
+	db := testutil.TestDB(t)
+	log := testutil.TestLogger()
+	userRepo := repository.NewUserRepository(db)
+	linkRepo := repository.NewLinkRepository(db)
+	pageRepo := repository.NewPageRepository(db)
+	analyticsRepo := repository.NewAnalyticsRepository(db)
+	ctx := context.Background()
+
+	user := &model.User{Username: "band", Email: "band@test.com", PasswordHash: "hash", DisplayName: "The Band", Theme: "light"}
+	if err := userRepo.Create(ctx, user); err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	merch := &model.Page{UserID: user.ID, Slug: "merch", Title: "Merch"}
+	pageRepo.Create(ctx, merch)
+	linkRepo.Create(ctx, &model.Link{UserID: user.ID, Title: "New album", URL: "https://album.example", IsActive: true})
+	linkRepo.Create(ctx, &model.Link{UserID: user.ID, PageID: &merch.ID, Title: "Tour shirt", URL: "https://shirt.example", IsActive: true})
+	repository.NewDraftRepository(db).Publish(ctx, user.ID, time.Now())
+
+	h := &ProfileHandler{
+		log:           log,
+		resp:          response.New(log),
+		userRepo:      userRepo,
+		linkRepo:      linkRepo,
+		analyticsRepo: analyticsRepo,
+		domainRepo:    repository.NewDomainRepository(db),
+		socialRepo:    repository.NewSocialRepository(db),
+		pageRepo:      pageRepo,
+		lock:          profileLock{userRepo: userRepo},
+		baseURL:       "https://linkbio.test",
+	}
+	r := chi.NewRouter()
+	r.Get("/u/{username}", h.Show)
+	r.Get("/u/{username}/{page}", h.Show)
+
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	rec := get("/u/band")
+	body := rec.Body.String()
+	if rec.Code != http.StatusOK || !strings.Contains(body, "New album") || strings.Contains(body, "Tour shirt") {
+		t.Fatalf("main page status = %d, body:\n%s", rec.Code, body)
+	}
+	if !strings.Contains(body, `href="/u/band/merch"`) || !strings.Contains(body, `href="/u/band" aria-current="page"`) {
+		t.Error("main page navigation missing or wrong")
+	}
+
+	rec = get("/u/band/merch")
+	body = rec.Body.String()
+	if rec.Code != http.StatusOK || !strings.Contains(body, "Tour shirt") || strings.Contains(body, "New album") {
+		t.Fatalf("merch status = %d, body:\n%s", rec.Code, body)
+	}
+	if !strings.Contains(body, `href="/u/band/merch" aria-current="page"`) {
+		t.Error("merch page isn't marked current")
+	}
+	if !strings.Contains(body, `<link rel="canonical" href="https://linkbio.test/u/band/merch">`) {
+		t.Error("merch page canonical URL wrong")
+	}
+
+	if rec := get("/u/band/nope"); rec.Code != http.StatusNotFound {
+		t.Errorf("unknown page status = %d", rec.Code)
+	}
+
+	// Views are recorded per page in the background
+	var summary *model.AnalyticsSummary
+	for i := 0; i < 100; i++ {
+		summary, _ = analyticsRepo.GetSummary(ctx, user.ID, 1)
+		if summary != nil && summary.TotalViews == 2 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if len(summary.Pages) != 2 || summary.Pages[0].Views != 1 || summary.Pages[1].Label() != "Merch" || summary.Pages[1].Views != 1 {
+		t.Errorf("Pages = %+v, want one view each", summary.Pages)
+	}
+
+	// A page added since the last publish isn't live yet
+	pageRepo.Create(ctx, &model.Page{UserID: user.ID, Slug: "tour", Title: "Tour"})
+	if rec := get("/u/band/tour"); rec.Code != http.StatusNotFound {
+		t.Errorf("unpublished page status = %d, want 404", rec.Code)
+	}
+	if body := get("/u/band").Body.String(); strings.Contains(body, "/u/band/tour") {
+		t.Error("navigation links to an unpublished page")
+	}
+}
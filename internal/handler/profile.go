@@ -2,12 +2,21 @@ package handler
 
 import (
 	"context"
+	"html/template"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 
+	"linkbio/internal/i18n"
+	"linkbio/internal/middleware"
 	"linkbio/internal/model"
+	"linkbio/internal/pkg/ratelimit"
 	"linkbio/internal/pkg/response"
 	"linkbio/internal/pkg/templates"
 	"linkbio/internal/repository"
+	"linkbio/internal/seo"
+	"linkbio/internal/theme"
 
 	"log/slog"
 
@@ -16,58 +25,149 @@ import (
 
 // ProfileHandler handles public profile endpoints
 type ProfileHandler struct {
-	log           *slog.Logger
-	resp          *response.Responder
-	userRepo      *repository.UserRepository
-	linkRepo      *repository.LinkRepository
-	analyticsRepo *repository.AnalyticsRepository
+	log             *slog.Logger
+	resp            *response.Responder
+	userRepo        *repository.UserRepository
+	linkRepo        *repository.LinkRepository
+	analyticsRepo   *repository.AnalyticsRepository
+	domainRepo      *repository.DomainRepository
+	socialRepo      *repository.SocialRepository
+	pageRepo        *repository.PageRepository
+	lock            profileLock
+	unlockAttempts  *ratelimit.Limiter
+	baseURL         string
+	hideBrokenLinks bool
+	locales         *i18n.Bundle
 }
 
 // NewProfileHandler creates a new ProfileHandler
 func NewProfileHandler(deps *Dependencies) *ProfileHandler {
 	return &ProfileHandler{
-		log:           deps.Log,
-		resp:          deps.Responder,
-		userRepo:      deps.UserRepo,
-		linkRepo:      deps.LinkRepo,
-		analyticsRepo: deps.AnalyticsRepo,
+		log:             deps.Log,
+		resp:            deps.Responder,
+		userRepo:        deps.UserRepo,
+		linkRepo:        deps.LinkRepo,
+		analyticsRepo:   deps.AnalyticsRepo,
+		domainRepo:      deps.DomainRepo,
+		socialRepo:      deps.SocialRepo,
+		pageRepo:        deps.PageRepo,
+		lock:            newProfileLock(deps),
+		unlockAttempts:  ratelimit.New(unlockAttempts, unlockWindow),
+		baseURL:         deps.Config.BaseURL,
+		hideBrokenLinks: deps.Config.LinkCheckHideBroken,
+		locales:         deps.Locales,
 	}
 }
 
 // ProfileData holds data for the profile template
 type ProfileData struct {
-	User  *model.User
-	Links []model.Link
+	User     *model.User
+	Links    []model.Link
+	Socials  []model.SocialProfile
+	ThemeCSS template.CSS
+	SEO      seo.Meta
+
+	// Page is the sub-page shown, nil for the main page; Nav links the
+	// profile's pages and is empty when it has none
+	Page *model.Page
+	Nav  []NavItem
+
+	// OEmbedURL and the Atom and JSON feeds at FeedPath + ".xml" and
+	// ".json" are advertised for discovery; both are empty for
+	// password-protected profiles, which cannot be embedded or followed
+	OEmbedURL string
+	FeedPath  string
+
+	// Preview marks the creator's private view of their unpublished draft;
+	// Embedded previews sit in the dashboard's phone frame and drop the
+	// publish banner
+	Preview  bool
+	Embedded bool
 }
 
-// Show renders a user's public profile
+// Show renders a user's public profile, or one of its sub-pages
 func (h *ProfileHandler) Show(w http.ResponseWriter, r *http.Request) {
 	username := chi.URLParam(r, "username")
+	slug := chi.URLParam(r, "page")
 
-	user, err := h.userRepo.GetByUsername(r.Context(), username)
+	user, err := h.userRepo.GetPublishedByUsername(r.Context(), username)
 	if err != nil {
 		h.log.Error("database error", "error", err)
-		h.resp.Error(w, http.StatusInternalServerError, "Something went wrong")
+		h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
 		return
 	}
 	if user == nil {
-		h.resp.Error(w, http.StatusNotFound, "Profile not found")
+		suffix := ""
+		if slug != "" {
+			suffix = "/" + slug
+		}
+		if ok, err := redirectRenamed(w, r, h.userRepo, username, suffix); ok || err != nil {
+			if err != nil {
+				h.log.Error("database error", "error", err)
+				h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
+			}
+			return
+		}
+		h.resp.Error(w, r, http.StatusNotFound, "Profile not found")
+		return
+	}
+	r = withProfileLocale(r, h.locales, user)
+
+	if !h.lock.canView(r, user) {
+		h.renderLocked(w, r, user, http.StatusUnauthorized, "")
 		return
 	}
 
-	// Get active links
-	links, err := h.linkRepo.GetActiveByUserID(r.Context(), user.ID)
+	pages, err := h.pageRepo.ListPublished(r.Context(), user.ID)
 	if err != nil {
 		h.log.Error("database error", "error", err)
-		h.resp.Error(w, http.StatusInternalServerError, "Something went wrong")
+		h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+	page, ok := findPage(pages, slug)
+	if !ok {
+		h.resp.Error(w, r, http.StatusNotFound, "Page not found")
+		return
+	}
+
+	links, err := profileLinks(r.Context(), h.linkRepo, user.ID, pageID(page), h.hideBrokenLinks)
+	if err != nil {
+		h.log.Error("database error", "error", err)
+		h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+
+	// The share card always shows the main page
+	shareLinks := links
+	if page != nil {
+		shareLinks, err = profileLinks(r.Context(), h.linkRepo, user.ID, nil, h.hideBrokenLinks)
+		if err != nil {
+			h.log.Error("database error", "error", err)
+			h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
+			return
+		}
+	}
+
+	socials, err := h.socialRepo.ListByUser(r.Context(), user.ID)
+	if err != nil {
+		h.log.Error("database error", "error", err)
+		h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
 		return
 	}
 
 	// Record page view asynchronously
 	// ⚠️ Use context.Background(), NOT r.Context()!
 	// r.Context() gets cancelled after response is sent, killing the DB write.
+	event := &model.Analytics{
+		UserID:    user.ID,
+		EventType: "page_view",
+		Source:    model.NormalizeSource(r.URL.Query().Get("src")),
+		PageID:    pageID(page),
+		Referrer:  r.Referer(),
+		UserAgent: r.UserAgent(),
+	}
 	go func() {
-		h.analyticsRepo.RecordPageView(context.Background(), user.ID, r.Referer(), r.UserAgent())
+		h.analyticsRepo.Record(context.Background(), event)
 	}()
 
 	h.log.Info("profile data", "username", username, "user_id", user.ID, "links_count", len(links))
@@ -75,13 +175,143 @@ func (h *ProfileHandler) Show(w http.ResponseWriter, r *http.Request) {
 		h.log.Info("link", "index", i, "id", l.ID, "title", l.Title, "active", l.IsActive)
 	}
 
+	canonical, err := canonicalProfileURL(r.Context(), h.domainRepo, h.baseURL, user)
+	if err != nil {
+		h.log.Error("database error", "error", err)
+		h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+
+	meta := seo.Profile(user, links, subPath(canonical, page), shareImageURL(h.baseURL, user, shareLinks))
+	if page != nil {
+		meta.Title = page.Title + " · " + meta.Title
+	}
+
+	base := profilePath(r, user)
+	data := ProfileData{
+		User:     user,
+		Links:    links,
+		Socials:  socials,
+		ThemeCSS: theme.Resolve(user.Theme, user.ThemeConfig).CSS(),
+		SEO:      meta,
+		Page:     page,
+		Nav:      pageNav(pages, page, i18n.FromContext(r.Context()).T("Home"), func(p *model.Page) string { return subPath(base, p) }),
+	}
+	if user.Visibility() != model.VisibilityPassword {
+		data.OEmbedURL = h.baseURL + "/oembed?format=json&url=" + url.QueryEscape(meta.URL)
+		data.FeedPath = strings.TrimSuffix(base, "/") + "/feed"
+	}
+
+	if user.HideFromSearch {
+		w.Header().Set("X-Robots-Tag", "noindex")
+	}
+
+	if err := templates.Render(w, i18n.FromContext(r.Context()), "profile.html", data); err != nil {
+		h.log.Error("template error", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
+
+// Preview renders the signed-in user's draft the way their profile will look
+// once published. Links go straight to their targets and no page view is
+// recorded, so previews never show up in analytics. ?page= picks a
+// sub-page by ID and ?embed=1 renders it for the dashboard's live preview
+// pane.
+func (h *ProfileHandler) Preview(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.UserIDFromContext(r.Context())
+
+	user, err := h.userRepo.GetByID(r.Context(), userID)
+	if err != nil || user == nil {
+		h.log.Error("database error", "error", err)
+		h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+	r = withProfileLocale(r, h.locales, user)
+
+	pages, err := h.pageRepo.ListByUser(r.Context(), userID)
+	if err != nil {
+		h.log.Error("database error", "error", err)
+		h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+	page, _ := pageByID(pages, r.URL.Query().Get("page"))
+
+	links, err := h.linkRepo.GetActiveDraftByUserID(r.Context(), userID, pageID(page))
+	if err != nil {
+		h.log.Error("database error", "error", err)
+		h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+	if h.hideBrokenLinks {
+		links = withoutBroken(links)
+	}
+
+	socials, err := h.socialRepo.ListByUser(r.Context(), userID)
+	if err != nil {
+		h.log.Error("database error", "error", err)
+		h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+
+	meta := seo.Profile(user, links, subPath(h.baseURL+"/u/"+url.PathEscape(user.Username), page), "")
+	meta.NoIndex = true
+	if page != nil {
+		meta.Title = page.Title + " · " + meta.Title
+	}
+
+	embedded := r.URL.Query().Get("embed") == "1"
 	data := ProfileData{
-		User:  user,
-		Links: links,
+		User:     user,
+		Links:    links,
+		Socials:  socials,
+		ThemeCSS: theme.Resolve(user.Theme, user.ThemeConfig).CSS(),
+		SEO:      meta,
+		Page:     page,
+		Nav:      pageNav(pages, page, i18n.FromContext(r.Context()).T("Home"), func(p *model.Page) string { return previewPath(p, embedded) }),
+		Preview:  true,
+		Embedded: embedded,
 	}
 
-	if err := templates.Render(w, "profile.html", data); err != nil {
+	w.Header().Set("X-Robots-Tag", "noindex")
+	w.Header().Set("Cache-Control", "no-store")
+
+	if err := templates.Render(w, i18n.FromContext(r.Context()), "profile.html", data); err != nil {
 		h.log.Error("template error", "error", err)
 		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 	}
 }
+
+// withProfileLocale switches r to the language the creator chose for their
+// profile's buttons and notices. A supported ?lang= override still wins.
+func withProfileLocale(r *http.Request, locales *i18n.Bundle, user *model.User) *http.Request {
+	if !locales.Supported(user.ProfileLocale) || locales.Supported(r.URL.Query().Get("lang")) {
+		return r
+	}
+	return r.WithContext(i18n.NewContext(r.Context(), locales.Localizer(user.ProfileLocale)))
+}
+
+// previewPath is the address of p's draft preview, nil being the main page
+func previewPath(p *model.Page, embedded bool) string {
+	q := url.Values{}
+	if p != nil {
+		q.Set("page", strconv.FormatInt(p.ID, 10))
+	}
+	if embedded {
+		q.Set("embed", "1")
+	}
+	if len(q) == 0 {
+		return "/dashboard/preview"
+	}
+	return "/dashboard/preview?" + q.Encode()
+}
+
+// withoutBroken filters out links whose last health check failed
+func withoutBroken(links []model.Link) []model.Link {
+	kept := links[:0]
+	for _, l := range links {
+		if !l.IsBroken() {
+			kept = append(kept, l)
+		}
+	}
+	return kept
+}
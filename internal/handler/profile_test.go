@@ -0,0 +1,253 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"linkbio/internal/i18n"
+	"linkbio/internal/middleware"
+	"linkbio/internal/model"
+	"linkbio/internal/pkg/response"
+	"linkbio/internal/repository"
+	"linkbio/internal/social"
+	"linkbio/internal/testutil"
+	"linkbio/internal/theme"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestProfileHandler_Show_Theme(t *testing.T) {
+	testutil.ChdirRoot(t)
+
+	db := testutil.TestDB(t)
+	log := testutil.TestLogger()
+	userRepo := repository.NewUserRepository(db)
+
+	custom := theme.Theme{
+		Background: theme.Background{Type: theme.BackgroundSolid, From: "#123456"},
+		Text:       "#fefefe",
+		Muted:      "#cccccc",
+		Button:     theme.Button{Style: theme.ButtonShadow, Color: "#ffffff", Text: "#000000"},
+		Font:       "serif",
+		Radius:     "none",
+	}
+	user := &model.User{
+		Username:     "themed",
+		Email:        "themed@test.com",
+		PasswordHash: "hash",
+		DisplayName:  "Themed",
+		Theme:        theme.Custom,
+		ThemeConfig:  custom.JSON(),
+	}
+	if err := userRepo.Create(context.Background(), user); err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+
+	h := &ProfileHandler{
+		log:           log,
+		resp:          response.New(log),
+		userRepo:      userRepo,
+		linkRepo:      repository.NewLinkRepository(db),
+		analyticsRepo: repository.NewAnalyticsRepository(db),
+		domainRepo:    repository.NewDomainRepository(db),
+		socialRepo:    repository.NewSocialRepository(db),
+		pageRepo:      repository.NewPageRepository(db),
+	}
+	r := chi.NewRouter()
+	r.Get("/u/{username}", h.Show)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/u/themed", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d", rec.Code)
+	}
+	body := rec.Body.String()
+	if !strings.Contains(body, `<style>.lb-theme{`) || !strings.Contains(body, "background:#123456") {
+		t.Error("page does not include the compiled theme")
+	}
+	if !strings.Contains(body, `class="lb-theme`) {
+		t.Error("page has no theme scope element")
+	}
+}
+
+func TestProfileHandler_Show_Socials(t *testing.T) {
+	testutil.ChdirRoot(t)
+
+	db := testutil.TestDB(t)
+	log := testutil.TestLogger()
+	userRepo := repository.NewUserRepository(db)
+	socialRepo := repository.NewSocialRepository(db)
+	ctx := context.Background()
+
+	user := &model.User{Username: "socials", Email: "socials@test.com", PasswordHash: "hash", DisplayName: "Socials", Theme: "light"}
+	if err := userRepo.Create(ctx, user); err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	gh := &model.SocialProfile{UserID: user.ID, Platform: social.GitHub, Handle: "socials"}
+	mail := &model.SocialProfile{UserID: user.ID, Platform: social.Email, Handle: "socials@example.com"}
+	socialRepo.Save(ctx, gh)
+	socialRepo.Save(ctx, mail)
+
+	h := &ProfileHandler{
+		log:           log,
+		resp:          response.New(log),
+		userRepo:      userRepo,
+		linkRepo:      repository.NewLinkRepository(db),
+		analyticsRepo: repository.NewAnalyticsRepository(db),
+		domainRepo:    repository.NewDomainRepository(db),
+		socialRepo:    socialRepo,
+		pageRepo:      repository.NewPageRepository(db),
+	}
+	r := chi.NewRouter()
+	r.Get("/u/{username}", h.Show)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/u/socials", nil))
+	body := rec.Body.String()
+
+	ghAt := strings.Index(body, fmt.Sprintf(`href="/click/social/%d"`, gh.ID))
+	mailAt := strings.Index(body, fmt.Sprintf(`href="/click/social/%d"`, mail.ID))
+	if ghAt < 0 || mailAt < ghAt {
+		t.Fatalf("icons missing or out of order (github at %d, email at %d)", ghAt, mailAt)
+	}
+	if !strings.Contains(body, `aria-label="GitHub"`) {
+		t.Error("icon has no accessible name")
+	}
+	if strings.Contains(body[mailAt:mailAt+200], `target="_blank"`) {
+		t.Error("email icon opens a new tab")
+	}
+}
+
+func TestProfileHandler_Preview_Embedded(t *testing.T) {
+	testutil.ChdirRoot(t)
+
+	db := testutil.TestDB(t)
+	log := testutil.TestLogger()
+	userRepo := repository.NewUserRepository(db)
+	analyticsRepo := repository.NewAnalyticsRepository(db)
+	ctx := context.Background()
+
+	user := &model.User{Username: "framed", Email: "framed@test.com", PasswordHash: "hash", DisplayName: "Framed", Theme: "light"}
+	if err := userRepo.Create(ctx, user); err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+
+	h := &ProfileHandler{
+		log:           log,
+		resp:          response.New(log),
+		userRepo:      userRepo,
+		linkRepo:      repository.NewLinkRepository(db),
+		analyticsRepo: analyticsRepo,
+		domainRepo:    repository.NewDomainRepository(db),
+		socialRepo:    repository.NewSocialRepository(db),
+		pageRepo:      repository.NewPageRepository(db),
+	}
+	r := chi.NewRouter()
+	r.Get("/u/{username}", h.Show)
+	r.Get("/dashboard/preview", h.Preview)
+
+	get := func(path string) string {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req = req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, user.ID))
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		return rec.Body.String()
+	}
+
+	// A public visit is counted once it has been recorded in the background
+	get("/u/framed")
+	views := func() int {
+		summary, _ := analyticsRepo.GetSummary(ctx, user.ID, 1)
+		return summary.TotalViews
+	}
+	for i := 0; i < 100 && views() == 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if body := get("/dashboard/preview"); !strings.Contains(body, "Preview of your unpublished changes") {
+		t.Error("standalone preview has no banner")
+	}
+	body := get("/dashboard/preview?embed=1")
+	if strings.Contains(body, "Preview of your unpublished changes") {
+		t.Error("embedded preview shows the publish banner")
+	}
+	if !strings.Contains(body, "Framed") {
+		t.Error("embedded preview does not render the profile")
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	if n := views(); n != 1 {
+		t.Errorf("page views = %d, want previews not counted", n)
+	}
+}
+
+func TestProfileHandler_Show_Language(t *testing.T) {
+	testutil.ChdirRoot(t)
+
+	db := testutil.TestDB(t)
+	log := testutil.TestLogger()
+	userRepo := repository.NewUserRepository(db)
+	ctx := context.Background()
+
+	locales, err := i18n.Load(os.DirFS("web/locales"))
+	if err != nil {
+		t.Fatalf("failed to load locales: %v", err)
+	}
+
+	visitor := &model.User{Username: "visitorlang", Email: "visitorlang@test.com", PasswordHash: "hash", DisplayName: "Visitor", Theme: "light"}
+	creator := &model.User{Username: "creatorlang", Email: "creatorlang@test.com", PasswordHash: "hash", DisplayName: "Creator", Theme: "light", ProfileLocale: "en"}
+	for _, u := range []*model.User{visitor, creator} {
+		if err := userRepo.Create(ctx, u); err != nil {
+			t.Fatalf("failed to create user: %v", err)
+		}
+		userRepo.Update(ctx, u)
+	}
+
+	h := &ProfileHandler{
+		log:           log,
+		resp:          response.New(log),
+		userRepo:      userRepo,
+		linkRepo:      repository.NewLinkRepository(db),
+		analyticsRepo: repository.NewAnalyticsRepository(db),
+		domainRepo:    repository.NewDomainRepository(db),
+		socialRepo:    repository.NewSocialRepository(db),
+		pageRepo:      repository.NewPageRepository(db),
+		locales:       locales,
+	}
+	mw := middleware.New(log, "test-secret", "", locales)
+	r := chi.NewRouter()
+	r.Use(mw.Locale)
+	r.Get("/u/{username}", h.Show)
+
+	get := func(path, acceptLanguage string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Accept-Language", acceptLanguage)
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		return rec
+	}
+
+	tests := []struct {
+		name, path, acceptLanguage string
+		want, lang                 string
+	}{
+		{"browser language", "/u/visitorlang", "es-MX,es;q=0.9", "Aún no hay enlaces", "es"},
+		{"unsupported browser language", "/u/visitorlang", "de", "No links yet", "en"},
+		{"creator's language", "/u/creatorlang", "es", "No links yet", "en"},
+		{"explicit choice", "/u/creatorlang?lang=es", "en", "Aún no hay enlaces", "es"},
+	}
+	for _, tt := range tests {
+		rec := get(tt.path, tt.acceptLanguage)
+		body := rec.Body.String()
+		if rec.Code != http.StatusOK || !strings.Contains(body, tt.want) || !strings.Contains(body, `lang="`+tt.lang+`"`) {
+			t.Errorf("%s: status = %d, want %q in lang %q", tt.name, rec.Code, tt.want, tt.lang)
+		}
+	}
+}
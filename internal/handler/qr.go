@@ -0,0 +1,208 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"linkbio/internal/middleware"
+	"linkbio/internal/model"
+	"linkbio/internal/pkg/qr"
+	"linkbio/internal/pkg/response"
+	"linkbio/internal/repository"
+
+	"log/slog"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// QRHandler renders QR codes for profiles and links
+type QRHandler struct {
+	log      *slog.Logger
+	resp     *response.Responder
+	userRepo *repository.UserRepository
+	linkRepo *repository.LinkRepository
+	baseURL  string
+}
+
+// NewQRHandler creates a new QRHandler
+func NewQRHandler(deps *Dependencies) *QRHandler {
+	return &QRHandler{
+		log:      deps.Log,
+		resp:     deps.Responder,
+		userRepo: deps.UserRepo,
+		linkRepo: deps.LinkRepo,
+		baseURL:  deps.Config.BaseURL,
+	}
+}
+
+// qrRequest holds the rendering options parsed from the query string
+type qrRequest struct {
+	level    qr.Level
+	opts     qr.Options
+	download bool
+}
+
+// Profile renders a QR code pointing at a user's public profile
+func (h *QRHandler) Profile(w http.ResponseWriter, r *http.Request) {
+	req, err := parseQRRequest(r)
+	if err != nil {
+		h.resp.Invalid(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	username := chi.URLParam(r, "username")
+	user, err := h.userRepo.GetByUsername(r.Context(), username)
+	if err != nil {
+		h.log.Error("database error", "error", err)
+		h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+	if user == nil {
+		suffix := "/qr." + chi.URLParam(r, "format")
+		if ok, err := redirectRenamed(w, r, h.userRepo, username, suffix); ok || err != nil {
+			if err != nil {
+				h.log.Error("database error", "error", err)
+				h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
+			}
+			return
+		}
+		h.resp.Error(w, r, http.StatusNotFound, "Profile not found")
+		return
+	}
+
+	target := h.baseURL + "/u/" + user.Username + "?src=" + model.SourceQR
+	w.Header().Set("Cache-Control", "public, max-age=86400")
+	h.write(w, r, target, user.Username+"-qr", req)
+}
+
+// Link renders a QR code for one of the current user's links. It points at
+// the tracked /click redirect so scans are counted.
+func (h *QRHandler) Link(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.UserIDFromContext(r.Context())
+	if userID == 0 {
+		h.resp.Error(w, r, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	req, err := parseQRRequest(r)
+	if err != nil {
+		h.resp.Invalid(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	linkID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		h.resp.Error(w, r, http.StatusBadRequest, "Invalid link ID")
+		return
+	}
+
+	link, err := h.linkRepo.GetByID(r.Context(), linkID)
+	if err != nil || link == nil || link.UserID != userID {
+		h.resp.Error(w, r, http.StatusNotFound, "Link not found")
+		return
+	}
+
+	target := fmt.Sprintf("%s/click/%d?src=%s", h.baseURL, link.ID, model.SourceQR)
+	w.Header().Set("Cache-Control", "private, max-age=3600")
+	h.write(w, r, target, fmt.Sprintf("link-%d-qr", link.ID), req)
+}
+
+// write encodes target and responds in the format named by the route
+func (h *QRHandler) write(w http.ResponseWriter, r *http.Request, target, filename string, req qrRequest) {
+	code, err := qr.Encode(target, req.level)
+	if err != nil {
+		h.log.Error("qr encode error", "error", err)
+		h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+
+	format := chi.URLParam(r, "format")
+	if req.download {
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, filename, format))
+	}
+
+	if format == "svg" {
+		w.Header().Set("Content-Type", "image/svg+xml")
+		w.Write(code.SVG(req.opts))
+		return
+	}
+
+	data, err := code.PNG(req.opts)
+	if err != nil {
+		h.log.Error("qr render error", "error", err)
+		h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+	w.Header().Set("Content-Type", "image/png")
+	w.Write(data)
+}
+
+// parseQRRequest reads size, margin, fg, bg, ec and download from the query
+func parseQRRequest(r *http.Request) (qrRequest, error) {
+	q := r.URL.Query()
+	req := qrRequest{
+		level: qr.Medium,
+		opts:  qr.Options{Size: 512, Margin: 4},
+	}
+
+	if v := q.Get("size"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 64 || n > 2048 {
+			return req, errors.New("size must be between 64 and 2048")
+		}
+		req.opts.Size = n
+	}
+	if v := q.Get("margin"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 || n > 16 {
+			return req, errors.New("margin must be between 0 and 16")
+		}
+		req.opts.Margin = n
+	}
+	if v := q.Get("ec"); v != "" {
+		level, ok := qr.ParseLevel(v)
+		if !ok {
+			return req, errors.New("ec must be one of L, M, Q or H")
+		}
+		req.level = level
+	}
+	if v := q.Get("fg"); v != "" {
+		c, ok := parseHexColor(v)
+		if !ok {
+			return req, errors.New("fg must be a hex color like 1f2937")
+		}
+		req.opts.Foreground = c
+	}
+	if v := q.Get("bg"); v != "" {
+		if v == "transparent" {
+			req.opts.Background = color.Transparent
+		} else if c, ok := parseHexColor(v); ok {
+			req.opts.Background = c
+		} else {
+			return req, errors.New("bg must be a hex color or transparent")
+		}
+	}
+	req.download = q.Get("download") == "1"
+
+	return req, nil
+}
+
+// parseHexColor parses "rgb" or "rrggbb", with or without a leading #
+func parseHexColor(s string) (color.Color, bool) {
+	s = strings.TrimPrefix(s, "#")
+	if len(s) == 3 {
+		s = string([]byte{s[0], s[0], s[1], s[1], s[2], s[2]})
+	}
+	if len(s) != 6 {
+		return nil, false
+	}
+	n, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		return nil, false
+	}
+	return color.RGBA{R: uint8(n >> 16), G: uint8(n >> 8), B: uint8(n), A: 0xff}, true
+}
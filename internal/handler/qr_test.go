@@ -0,0 +1,148 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"linkbio/internal/middleware"
+	"linkbio/internal/model"
+	"linkbio/internal/pkg/response"
+	"linkbio/internal/repository"
+	"linkbio/internal/testutil"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func setupQRHandler(t *testing.T) (*QRHandler, *model.User, *model.Link) {
+	t.Helper()
+
+	db := testutil.TestDB(t)
+	log := testutil.TestLogger()
+	userRepo := repository.NewUserRepository(db)
+	linkRepo := repository.NewLinkRepository(db)
+
+	user := &model.User{Username: "qruser", Email: "qr@test.com", PasswordHash: "hash", Theme: "light"}
+	if err := userRepo.Create(context.Background(), user); err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	link := &model.Link{UserID: user.ID, Title: "Shop", URL: "https://shop.example", IsActive: true}
+	if err := linkRepo.Create(context.Background(), link); err != nil {
+		t.Fatalf("failed to create link: %v", err)
+	}
+
+	h := &QRHandler{
+		log:      log,
+		resp:     response.New(log),
+		userRepo: userRepo,
+		linkRepo: linkRepo,
+		baseURL:  "https://linkbio.test",
+	}
+	return h, user, link
+}
+
+// newQRRouter mounts the handlers on the same patterns router.New uses
+func newQRRouter(h *QRHandler, userID int64) http.Handler {
+	r := chi.NewRouter()
+	r.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			ctx := context.WithValue(req.Context(), middleware.UserIDKey, userID)
+			next.ServeHTTP(w, req.WithContext(ctx))
+		})
+	})
+	r.Get("/u/{username}/qr.{format:png|svg}", h.Profile)
+	r.Get("/api/v1/links/{id}/qr.{format:png|svg}", h.Link)
+	return r
+}
+
+func TestQRHandler_Profile(t *testing.T) {
+	h, user, _ := setupQRHandler(t)
+	router := newQRRouter(h, 0)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/u/qruser/qr.png?size=300&ec=H", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("png Status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "image/png" {
+		t.Errorf("Content-Type = %q, want image/png", ct)
+	}
+	img, err := png.Decode(bytes.NewReader(rec.Body.Bytes()))
+	if err != nil {
+		t.Fatalf("response is not a PNG: %v", err)
+	}
+	if w := img.Bounds().Dx(); w > 300 || w < 150 {
+		t.Errorf("PNG width = %d, want close to 300", w)
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/u/"+user.Username+"/qr.svg?fg=ff0000&bg=transparent&download=1", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("svg Status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if !strings.Contains(rec.Body.String(), `fill="#ff0000"`) {
+		t.Error("SVG does not use the requested foreground color")
+	}
+	if cd := rec.Header().Get("Content-Disposition"); !strings.Contains(cd, "qruser-qr.svg") {
+		t.Errorf("Content-Disposition = %q, want a qruser-qr.svg attachment", cd)
+	}
+}
+
+func TestQRHandler_Profile_Errors(t *testing.T) {
+	h, _, _ := setupQRHandler(t)
+	router := newQRRouter(h, 0)
+
+	tests := []struct {
+		path string
+		want int
+	}{
+		{"/u/nobody/qr.png", http.StatusNotFound},
+		{"/u/qruser/qr.png?size=10", http.StatusBadRequest},
+		{"/u/qruser/qr.png?margin=99", http.StatusBadRequest},
+		{"/u/qruser/qr.png?ec=X", http.StatusBadRequest},
+		{"/u/qruser/qr.svg?fg=red", http.StatusBadRequest},
+		{"/u/qruser/qr.gif", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if rec.Code != tt.want {
+			t.Errorf("%s Status = %d, want %d", tt.path, rec.Code, tt.want)
+		}
+	}
+}
+
+func TestQRHandler_Link_OwnerOnly(t *testing.T) {
+	h, user, link := setupQRHandler(t)
+	path := fmt.Sprintf("/api/v1/links/%d/qr.png", link.ID)
+
+	rec := httptest.NewRecorder()
+	newQRRouter(h, user.ID).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("owner Status = %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	rec = httptest.NewRecorder()
+	newQRRouter(h, user.ID+1).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("other user Status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
+
+func TestParseHexColor(t *testing.T) {
+	for _, s := range []string{"#1f2937", "1f2937", "fff", "#ABC"} {
+		if _, ok := parseHexColor(s); !ok {
+			t.Errorf("parseHexColor(%q) rejected a valid color", s)
+		}
+	}
+	for _, s := range []string{"", "red", "#12345", "gggggg", "#1234567"} {
+		if _, ok := parseHexColor(s); ok {
+			t.Errorf("parseHexColor(%q) accepted an invalid color", s)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"linkbio/internal/model"
+	"linkbio/internal/pkg/response"
+	"linkbio/internal/repository"
+	"linkbio/internal/seo"
+
+	"log/slog"
+)
+
+// SEOHandler serves sitemap.xml and robots.txt
+type SEOHandler struct {
+	log        *slog.Logger
+	resp       *response.Responder
+	userRepo   *repository.UserRepository
+	domainRepo *repository.DomainRepository
+	baseURL    string
+}
+
+// NewSEOHandler creates a new SEOHandler
+func NewSEOHandler(deps *Dependencies) *SEOHandler {
+	return &SEOHandler{
+		log:        deps.Log,
+		resp:       deps.Responder,
+		userRepo:   deps.UserRepo,
+		domainRepo: deps.DomainRepo,
+		baseURL:    deps.Config.BaseURL,
+	}
+}
+
+// Sitemap lists every profile that doesn't hide from search engines. On a
+// custom domain it lists just that domain's profile.
+func (h *SEOHandler) Sitemap(w http.ResponseWriter, r *http.Request) {
+	var locs []string
+	if d := customDomainFromContext(r.Context()); d != nil {
+		user, ok := h.domainUser(w, r, d)
+		if !ok {
+			return
+		}
+		if !user.HideFromSearch {
+			locs = append(locs, domainURL(h.baseURL, d.Domain))
+		}
+	} else {
+		names, err := h.userRepo.ListUsernames(r.Context(), false)
+		if err != nil {
+			h.log.Error("database error", "error", err)
+			h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
+			return
+		}
+		for _, name := range names {
+			locs = append(locs, h.baseURL+"/u/"+url.PathEscape(name))
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	if err := seo.WriteSitemap(w, locs); err != nil {
+		h.log.Error("sitemap error", "error", err)
+	}
+}
+
+// Robots keeps crawlers out of the app. On a custom domain whose owner hides
+// from search engines it keeps them out entirely.
+func (h *SEOHandler) Robots(w http.ResponseWriter, r *http.Request) {
+	var robots seo.Robots
+	if d := customDomainFromContext(r.Context()); d != nil {
+		user, ok := h.domainUser(w, r, d)
+		if !ok {
+			return
+		}
+		if user.HideFromSearch {
+			robots.Disallow = []string{"/"}
+		} else {
+			robots.Disallow = []string{"/click/"}
+			robots.Sitemap = domainURL(h.baseURL, d.Domain) + "sitemap.xml"
+		}
+	} else {
+		// Hidden profiles are not listed: robots.txt is public, and naming
+		// them would hand out the unlisted and password-protected pages.
+		// Their noindex header and meta tag keep them out of search instead.
+		robots.Disallow = []string{"/dashboard", "/api/", "/auth/", "/click/"}
+		robots.Sitemap = h.baseURL + "/sitemap.xml"
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	w.Write([]byte(robots.String()))
+}
+
+// domainUser loads the owner of a custom domain, writing the error response
+// if that fails
+func (h *SEOHandler) domainUser(w http.ResponseWriter, r *http.Request, d *model.CustomDomain) (*model.User, bool) {
+	user, err := h.userRepo.GetByID(r.Context(), d.UserID)
+	if err != nil || user == nil {
+		h.log.Error("database error", "error", err)
+		h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
+		return nil, false
+	}
+	return user, true
+}
+
+// domainURL is the root URL of a custom domain, using the same scheme as
+// the main site
+func domainURL(baseURL, domain string) string {
+	scheme := "https"
+	if u, err := url.Parse(baseURL); err == nil && u.Scheme != "" {
+		scheme = u.Scheme
+	}
+	return scheme + "://" + domain + "/"
+}
+
+// canonicalProfileURL is the address search engines should index for a
+// profile: its verified custom domain if it has one, otherwise /u/{username}
+func canonicalProfileURL(ctx context.Context, domainRepo *repository.DomainRepository, baseURL string, user *model.User) (string, error) {
+	domains, err := domainRepo.ListByUser(ctx, user.ID)
+	if err != nil {
+		return "", err
+	}
+	for _, d := range domains {
+		if d.IsVerified() {
+			return domainURL(baseURL, d.Domain), nil
+		}
+	}
+	return baseURL + "/u/" + url.PathEscape(user.Username), nil
+}
@@ -0,0 +1,151 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"linkbio/internal/model"
+	"linkbio/internal/pkg/response"
+	"linkbio/internal/repository"
+	"linkbio/internal/testutil"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type seoFixture struct {
+	h          *SEOHandler
+	profile    *ProfileHandler
+	userRepo   *repository.UserRepository
+	domainRepo *repository.DomainRepository
+}
+
+func setupSEOHandler(t *testing.T) *seoFixture {
+	t.Helper()
+
+	db := testutil.TestDB(t)
+	log := testutil.TestLogger()
+	f := &seoFixture{
+		userRepo:   repository.NewUserRepository(db),
+		domainRepo: repository.NewDomainRepository(db),
+	}
+	f.h = &SEOHandler{
+		log:        log,
+		resp:       response.New(log),
+		userRepo:   f.userRepo,
+		domainRepo: f.domainRepo,
+		baseURL:    "https://linkbio.test",
+	}
+	f.profile = &ProfileHandler{
+		log:           log,
+		resp:          response.New(log),
+		userRepo:      f.userRepo,
+		linkRepo:      repository.NewLinkRepository(db),
+		analyticsRepo: repository.NewAnalyticsRepository(db),
+		domainRepo:    f.domainRepo,
+		socialRepo:    repository.NewSocialRepository(db),
+		pageRepo:      repository.NewPageRepository(db),
+		baseURL:       "https://linkbio.test",
+	}
+
+	ctx := context.Background()
+	f.userRepo.Create(ctx, &model.User{Username: "public", Email: "public@test.com", PasswordHash: "hash", DisplayName: "Public Person", Bio: "Hello <world>", Theme: "light"})
+	f.userRepo.Create(ctx, &model.User{Username: "hidden", Email: "hidden@test.com", PasswordHash: "hash", DisplayName: "Hidden", Theme: "light", HideFromSearch: true})
+	f.userRepo.Create(ctx, &model.User{Username: "locked", Email: "locked@test.com", PasswordHash: "hash", DisplayName: "Locked", Theme: "light", HideFromSearch: true, ProfilePasswordHash: "hash"})
+	return f
+}
+
+func TestSEOHandler_SitemapAndRobots(t *testing.T) {
+	f := setupSEOHandler(t)
+
+	rec := httptest.NewRecorder()
+	f.h.Sitemap(rec, httptest.NewRequest(http.MethodGet, "/sitemap.xml", nil))
+	body := rec.Body.String()
+	if !strings.Contains(body, "<loc>https://linkbio.test/u/public</loc>") {
+		t.Errorf("sitemap misses the public profile:\n%s", body)
+	}
+	if strings.Contains(body, "/u/hidden") || strings.Contains(body, "/u/locked") {
+		t.Error("sitemap lists a profile hidden from search")
+	}
+
+	rec = httptest.NewRecorder()
+	f.h.Robots(rec, httptest.NewRequest(http.MethodGet, "/robots.txt", nil))
+	body = rec.Body.String()
+	for _, want := range []string{"Disallow: /dashboard\n", "Sitemap: https://linkbio.test/sitemap.xml\n"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("robots.txt misses %q:\n%s", want, body)
+		}
+	}
+	if strings.Contains(body, "/u/public") {
+		t.Error("robots.txt blocks a public profile")
+	}
+	// Listing unlisted profiles in a public file would defeat the point, and
+	// would hand out targets for guessing profile passwords
+	for _, name := range []string{"hidden", "locked"} {
+		if strings.Contains(body, name) {
+			t.Errorf("robots.txt names the %s profile:\n%s", name, body)
+		}
+	}
+}
+
+func TestSEOHandler_CustomDomainRobots(t *testing.T) {
+	f := setupSEOHandler(t)
+	hidden, _ := f.userRepo.GetByUsername(context.Background(), "hidden")
+
+	d := &model.CustomDomain{UserID: hidden.ID, Domain: "hidden.example", Username: "hidden"}
+	req := httptest.NewRequest(http.MethodGet, "/robots.txt", nil)
+	req = req.WithContext(context.WithValue(req.Context(), domainContextKey{}, d))
+
+	rec := httptest.NewRecorder()
+	f.h.Robots(rec, req)
+	if body := rec.Body.String(); !strings.Contains(body, "Disallow: /\n") {
+		t.Errorf("custom domain robots.txt for a hidden profile:\n%s", body)
+	}
+}
+
+func TestProfileHandler_Show_SEO(t *testing.T) {
+	testutil.ChdirRoot(t)
+	f := setupSEOHandler(t)
+
+	r := chi.NewRouter()
+	r.Get("/u/{username}", f.profile.Show)
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	rec := get("/u/public")
+	body := rec.Body.String()
+	for _, want := range []string{
+		`<link rel="canonical" href="https://linkbio.test/u/public">`,
+		`<meta property="og:title" content="Public Person (@public)">`,
+		`<meta name="twitter:card" content="summary_large_image">`,
+		`<meta property="og:image" content="https://linkbio.test/u/public/og.png?v=`,
+		`<script type="application/ld+json">{"@context":"https://schema.org","@type":"ProfilePage"`,
+		`"description":"Hello \u003cworld\u003e"`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("profile page misses %s", want)
+		}
+	}
+	if strings.Contains(body, `content="noindex"`) || rec.Header().Get("X-Robots-Tag") != "" {
+		t.Error("public profile is marked noindex")
+	}
+
+	rec = get("/u/hidden")
+	if !strings.Contains(rec.Body.String(), `<meta name="robots" content="noindex">`) || rec.Header().Get("X-Robots-Tag") != "noindex" {
+		t.Error("hidden profile is not marked noindex")
+	}
+
+	// A verified custom domain becomes the canonical address
+	public, _ := f.userRepo.GetByUsername(context.Background(), "public")
+	d := &model.CustomDomain{UserID: public.ID, Domain: "public.example", Token: "t"}
+	f.domainRepo.Create(context.Background(), d)
+	f.domainRepo.MarkVerified(context.Background(), d.ID)
+	if body := get("/u/public").Body.String(); !strings.Contains(body, `<link rel="canonical" href="https://public.example/">`) {
+		t.Error("canonical URL does not use the verified custom domain")
+	}
+}
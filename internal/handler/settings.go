@@ -0,0 +1,459 @@
+package handler
+
+import (
+	"fmt"
+	"html"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"linkbio/internal/i18n"
+	"linkbio/internal/middleware"
+	"linkbio/internal/model"
+	"linkbio/internal/pkg/response"
+	"linkbio/internal/pkg/templates"
+	"linkbio/internal/repository"
+	"linkbio/internal/social"
+	"linkbio/internal/storage"
+	"linkbio/internal/theme"
+	"linkbio/internal/username"
+
+	"log/slog"
+
+	"github.com/gorilla/sessions"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// SettingsHandler handles the profile settings page
+type SettingsHandler struct {
+	log        *slog.Logger
+	resp       *response.Responder
+	userRepo   *repository.UserRepository
+	mediaRepo  *repository.MediaRepository
+	domainRepo *repository.DomainRepository
+	socialRepo *repository.SocialRepository
+	pageRepo   *repository.PageRepository
+	blob       storage.Blob
+	store      *sessions.CookieStore
+	usernames  usernameRules
+	locales    *i18n.Bundle
+	feeds      *feedCache // set by New
+}
+
+// NewSettingsHandler creates a new SettingsHandler
+func NewSettingsHandler(deps *Dependencies) *SettingsHandler {
+	return &SettingsHandler{
+		log:        deps.Log,
+		resp:       deps.Responder,
+		userRepo:   deps.UserRepo,
+		mediaRepo:  deps.MediaRepo,
+		domainRepo: deps.DomainRepo,
+		socialRepo: deps.SocialRepo,
+		pageRepo:   deps.PageRepo,
+		blob:       deps.Blob,
+		store:      deps.Store,
+		usernames:  newUsernameRules(deps.Config),
+		locales:    deps.Locales,
+	}
+}
+
+// SettingsData holds data for the settings template
+type SettingsData struct {
+	User    *model.User
+	Presets []theme.Preset
+	Custom  theme.Theme
+	Fonts   []string
+	Radii   []string
+	Buttons []string
+	Limits  map[string]int
+	Domains []DomainView
+
+	// Platforms feeds the social icon form; Socials is the current row
+	Platforms []social.Platform
+	Socials   []model.SocialProfile
+
+	Pages []PageView
+
+	// Languages the dashboard and profile can be shown in
+	Languages []i18n.Language
+
+	// Categories for the explore directory listing
+	Categories []model.Category
+
+	// UsernameNote explains the change limit, or when the next change is
+	// allowed if the limit has been reached
+	UsernameNote string
+}
+
+// Page renders the settings form
+func (h *SettingsHandler) Page(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.UserIDFromContext(r.Context())
+
+	user, err := h.userRepo.GetByID(r.Context(), userID)
+	if err != nil || user == nil {
+		h.log.Error("database error", "error", err)
+		h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+
+	domains, err := h.domainRepo.ListByUser(r.Context(), userID)
+	if err != nil {
+		h.log.Error("database error", "error", err)
+		h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+
+	socials, err := h.socialRepo.ListByUser(r.Context(), userID)
+	if err != nil {
+		h.log.Error("database error", "error", err)
+		h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+
+	pages, err := h.pageRepo.ListByUser(r.Context(), userID)
+	if err != nil {
+		h.log.Error("database error", "error", err)
+		h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+
+	next, err := h.usernames.nextChange(r.Context(), h.userRepo, userID)
+	if err != nil {
+		h.log.Error("database error", "error", err)
+		h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+
+	data := SettingsData{
+		User:    user,
+		Presets: theme.Presets,
+		// The custom editor starts from whatever the profile shows now
+		Custom:  theme.Resolve(user.Theme, user.ThemeConfig),
+		Fonts:   theme.FontNames,
+		Radii:   theme.RadiusNames,
+		Buttons: theme.ButtonStyles,
+		Limits: map[string]int{
+			"DisplayName": model.MaxDisplayNameLength,
+			"Bio":         model.MaxBioLength,
+			"PageTitle":   model.MaxPageTitleLength,
+			"PageSlug":    model.MaxPageSlugLength,
+		},
+		Domains:      domainViews(domains),
+		Platforms:    social.Platforms,
+		Socials:      socials,
+		Pages:        pageViews(user.Username, pages),
+		Languages:    h.locales.Languages(),
+		Categories:   model.Categories,
+		UsernameNote: h.usernames.note(i18n.FromContext(r.Context()), next),
+	}
+
+	if err := templates.Render(w, i18n.FromContext(r.Context()), "settings.html", data); err != nil {
+		h.log.Error("template error", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
+
+// Update validates and saves the profile, then refreshes the profile card
+// out-of-band
+func (h *SettingsHandler) Update(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.UserIDFromContext(r.Context())
+	if userID == 0 {
+		h.resp.Error(w, r, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		h.resp.Error(w, r, http.StatusBadRequest, "Invalid form data")
+		return
+	}
+
+	user, err := h.userRepo.GetByID(r.Context(), userID)
+	if err != nil || user == nil {
+		h.log.Error("database error", "error", err)
+		h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+
+	req := model.ProfileUpdateRequest{
+		DisplayName:     r.FormValue("display_name"),
+		Bio:             strings.ReplaceAll(r.FormValue("bio"), "\r\n", "\n"),
+		AvatarURL:       r.FormValue("avatar_url"),
+		Theme:           r.FormValue("theme"),
+		Visibility:      r.FormValue("visibility"),
+		ProfilePassword: r.FormValue("profile_password"),
+	}
+	if req.Visibility == "" {
+		req.Visibility = user.Visibility()
+	}
+	if req.Theme == theme.Custom {
+		req.CustomTheme = customThemeFromForm(r)
+	}
+	if err := req.Validate(user.AvatarURL); err != nil {
+		h.resp.Invalid(w, r, http.StatusUnprocessableEntity, err)
+		return
+	}
+	if req.Visibility == model.VisibilityPassword && req.ProfilePassword == "" && user.ProfilePasswordHash == "" {
+		h.resp.Error(w, r, http.StatusUnprocessableEntity, "Choose a password for your profile")
+		return
+	}
+
+	oldAvatar := user.AvatarURL
+	user.DisplayName = req.DisplayName
+	user.Bio = req.Bio
+	user.AvatarURL = req.AvatarURL
+	user.Theme = req.Theme
+	user.HideFromSearch = req.Visibility != model.VisibilityPublic
+	switch {
+	case req.Visibility != model.VisibilityPassword:
+		user.ProfilePasswordHash = ""
+	case req.ProfilePassword != "":
+		hash, err := bcrypt.GenerateFromPassword([]byte(req.ProfilePassword), bcrypt.DefaultCost)
+		if err != nil {
+			h.log.Error("password hash error", "error", err)
+			h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
+			return
+		}
+		user.ProfilePasswordHash = string(hash)
+	}
+	if req.CustomTheme != nil {
+		user.ThemeConfig = req.CustomTheme.JSON()
+	}
+
+	if err := h.userRepo.Update(r.Context(), user); err != nil {
+		h.log.Error("user update error", "error", err)
+		h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+
+	// Switching away from an uploaded avatar releases its blobs
+	if oldAvatar != user.AvatarURL {
+		h.releaseAvatar(r, userID)
+	}
+	h.feeds.forget(userID)
+
+	h.log.Info("profile updated", "user_id", userID)
+	w.Header().Set("HX-Trigger", draftChanged)
+
+	fmt.Fprintf(w, `<div class="rounded-xl px-4 py-3 text-sm bg-green-50 dark:bg-green-900/20 text-green-700 dark:text-green-400 animate-slide-in">%s</div>`, html.EscapeString(i18n.FromContext(r.Context()).T("Profile saved")))
+
+	// OOB: refresh the profile card
+	fmt.Fprint(w, `<div hx-swap-oob="innerHTML:#profile-card">`)
+	if err := templates.RenderPartial(w, i18n.FromContext(r.Context()), "profile_card.html", user); err != nil {
+		h.log.Error("template error", "error", err)
+	}
+	fmt.Fprint(w, `</div>`)
+}
+
+// UpdateLanguage saves the dashboard and profile languages. The page
+// reloads so the dashboard switches language.
+func (h *SettingsHandler) UpdateLanguage(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.UserIDFromContext(r.Context())
+	if userID == 0 {
+		h.resp.Error(w, r, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		h.resp.Error(w, r, http.StatusBadRequest, "Invalid form data")
+		return
+	}
+
+	locale, profileLocale := r.FormValue("locale"), r.FormValue("profile_locale")
+	for _, tag := range []string{locale, profileLocale} {
+		if tag != "" && !h.locales.Supported(tag) {
+			h.resp.Error(w, r, http.StatusUnprocessableEntity, "Choose one of the available languages")
+			return
+		}
+	}
+
+	user, err := h.userRepo.GetByID(r.Context(), userID)
+	if err != nil || user == nil {
+		h.log.Error("database error", "error", err)
+		h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+
+	user.Locale = locale
+	user.ProfileLocale = profileLocale
+	if err := h.userRepo.Update(r.Context(), user); err != nil {
+		h.log.Error("user update error", "error", err)
+		h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+	h.feeds.forget(userID)
+
+	// The saved setting replaces any ?lang= choice remembered so far
+	session, _ := h.store.Get(r, "session")
+	session.Values["locale"] = locale
+	session.Save(r, w)
+	http.SetCookie(w, &http.Cookie{Name: middleware.LangCookie, Path: "/", MaxAge: -1})
+
+	h.log.Info("language updated", "user_id", userID, "locale", locale, "profile_locale", profileLocale)
+
+	w.Header().Set("HX-Refresh", "true")
+	w.WriteHeader(http.StatusOK)
+}
+
+// UpdateDirectory saves whether the profile is listed in the explore
+// directory, and under which category. It applies right away.
+func (h *SettingsHandler) UpdateDirectory(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.UserIDFromContext(r.Context())
+	if userID == 0 {
+		h.resp.Error(w, r, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		h.resp.Error(w, r, http.StatusBadRequest, "Invalid form data")
+		return
+	}
+
+	req := model.DirectoryRequest{
+		Listed:   r.FormValue("listed") == "on",
+		Category: r.FormValue("category"),
+	}
+	if err := req.Validate(); err != nil {
+		h.resp.Invalid(w, r, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	user, err := h.userRepo.GetByID(r.Context(), userID)
+	if err != nil || user == nil {
+		h.log.Error("database error", "error", err)
+		h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+
+	user.Listed = req.Listed
+	user.Category = req.Category
+	if err := h.userRepo.Update(r.Context(), user); err != nil {
+		h.log.Error("user update error", "error", err)
+		h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+
+	h.log.Info("directory listing updated", "user_id", userID, "listed", user.Listed, "category", user.Category)
+
+	l := i18n.FromContext(r.Context())
+	message := l.T("Your profile is not listed in Explore")
+	switch {
+	case user.Listed && user.Visibility() != model.VisibilityPublic:
+		message = l.T("Saved. Your profile will appear in Explore once it is public.")
+	case user.Listed:
+		message = l.T("Your profile is listed in Explore")
+	}
+	fmt.Fprintf(w, `<div class="rounded-xl px-4 py-3 text-sm bg-green-50 dark:bg-green-900/20 text-green-700 dark:text-green-400 animate-slide-in">%s</div>`, html.EscapeString(message))
+}
+
+// ChangeUsername renames the current user. The old handle keeps redirecting
+// to the new one and stays reserved for this account for the hold period.
+func (h *SettingsHandler) ChangeUsername(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.UserIDFromContext(r.Context())
+	if userID == 0 {
+		h.resp.Error(w, r, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		h.resp.Error(w, r, http.StatusBadRequest, "Invalid form data")
+		return
+	}
+
+	user, err := h.userRepo.GetByID(r.Context(), userID)
+	if err != nil || user == nil {
+		h.log.Error("database error", "error", err)
+		h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+
+	name, err := username.Normalize(r.FormValue("username"))
+	if err != nil {
+		h.resp.Invalid(w, r, http.StatusUnprocessableEntity, err)
+		return
+	}
+	if name == user.Username {
+		h.resp.Error(w, r, http.StatusUnprocessableEntity, "That is already your username")
+		return
+	}
+
+	next, err := h.usernames.nextChange(r.Context(), h.userRepo, userID)
+	if err != nil {
+		h.log.Error("database error", "error", err)
+		h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+	if !next.IsZero() {
+		w.Header().Set("Retry-After", strconv.Itoa(int(time.Until(next).Seconds())+1))
+		h.resp.Error(w, r, http.StatusTooManyRequests, h.usernames.note(i18n.FromContext(r.Context()), next))
+		return
+	}
+
+	available, err := h.usernames.available(r.Context(), h.userRepo, name, userID)
+	if err != nil {
+		h.log.Error("database error", "error", err)
+		h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+	if !available {
+		h.resp.Error(w, r, http.StatusConflict, "Username already taken")
+		return
+	}
+
+	if err := h.userRepo.ChangeUsername(r.Context(), userID, name, time.Now()); err != nil {
+		h.log.Error("username change error", "error", err)
+		h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+
+	h.feeds.forget(userID)
+
+	h.log.Info("username changed", "user_id", userID, "from", user.Username, "to", name)
+
+	session, _ := h.store.Get(r, "session")
+	session.Values["username"] = name
+	session.Save(r, w)
+
+	// Links, headers and the QR code all show the username; reload the page
+	h.resp.HXRedirect(w, "/dashboard/settings")
+}
+
+// releaseAvatar drops the user's uploaded avatar records and deletes blobs
+// nobody else references
+func (h *SettingsHandler) releaseAvatar(r *http.Request, userID int64) {
+	keys, err := h.mediaRepo.KeysByUser(r.Context(), userID, "avatar")
+	if err != nil || len(keys) == 0 {
+		return
+	}
+	if err := h.mediaRepo.Replace(r.Context(), userID, "avatar", nil); err != nil {
+		h.log.Warn("failed to release avatar", "user_id", userID, "error", err)
+		return
+	}
+	deleteUnreferenced(r.Context(), h.log, h.blob, h.mediaRepo, keys)
+}
+
+// customThemeFromForm reads the custom theme editor fields. Validation
+// happens in ProfileUpdateRequest.Validate.
+func customThemeFromForm(r *http.Request) *theme.Theme {
+	angle, err := strconv.Atoi(r.FormValue("bg_angle"))
+	if err != nil {
+		angle = -1
+	}
+	return &theme.Theme{
+		Background: theme.Background{
+			Type:  r.FormValue("bg_type"),
+			From:  r.FormValue("bg_from"),
+			To:    r.FormValue("bg_to"),
+			Angle: angle,
+		},
+		Text:  r.FormValue("text_color"),
+		Muted: r.FormValue("muted_color"),
+		Button: theme.Button{
+			Style: r.FormValue("button_style"),
+			Color: r.FormValue("button_color"),
+			Text:  r.FormValue("button_text"),
+		},
+		Font:   r.FormValue("font"),
+		Radius: r.FormValue("radius"),
+	}
+}
@@ -0,0 +1,190 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"strconv"
+
+	"linkbio/internal/i18n"
+	"linkbio/internal/middleware"
+	"linkbio/internal/model"
+	"linkbio/internal/pkg/response"
+	"linkbio/internal/pkg/templates"
+	"linkbio/internal/repository"
+	"linkbio/internal/social"
+
+	"log/slog"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// SocialHandler manages the social icon row and tracks clicks on it
+type SocialHandler struct {
+	log           *slog.Logger
+	resp          *response.Responder
+	socialRepo    *repository.SocialRepository
+	analyticsRepo *repository.AnalyticsRepository
+	lock          profileLock
+}
+
+// NewSocialHandler creates a new SocialHandler
+func NewSocialHandler(deps *Dependencies) *SocialHandler {
+	return &SocialHandler{
+		log:           deps.Log,
+		resp:          deps.Responder,
+		socialRepo:    deps.SocialRepo,
+		analyticsRepo: deps.AnalyticsRepo,
+		lock:          newProfileLock(deps),
+	}
+}
+
+// Save adds a platform to the icon row or changes its handle
+func (h *SocialHandler) Save(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.UserIDFromContext(r.Context())
+	if userID == 0 {
+		h.resp.Error(w, r, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		h.resp.Error(w, r, http.StatusBadRequest, "Invalid form data")
+		return
+	}
+
+	platform := r.FormValue("platform")
+	handle, err := social.Normalize(platform, r.FormValue("handle"))
+	if err != nil {
+		h.resp.Invalid(w, r, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	s := &model.SocialProfile{UserID: userID, Platform: platform, Handle: handle}
+	if err := h.socialRepo.Save(r.Context(), s); err != nil {
+		h.log.Error("database error", "error", err)
+		h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+
+	h.log.Info("social profile saved", "user_id", userID, "platform", platform)
+	h.respond(w, r, userID, i18n.FromContext(r.Context()).T("%s saved", s.Info().Name))
+}
+
+// Delete removes a platform from the icon row
+func (h *SocialHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.UserIDFromContext(r.Context())
+	if userID == 0 {
+		h.resp.Error(w, r, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		h.resp.Error(w, r, http.StatusBadRequest, "Invalid social profile ID")
+		return
+	}
+
+	s, err := h.socialRepo.GetByID(r.Context(), id)
+	if err != nil || s == nil || s.UserID != userID {
+		h.resp.Error(w, r, http.StatusNotFound, "Social profile not found")
+		return
+	}
+
+	if err := h.socialRepo.Delete(r.Context(), id, userID); err != nil {
+		h.log.Error("database error", "error", err)
+		h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+
+	h.log.Info("social profile removed", "user_id", userID, "platform", s.Platform)
+	h.respond(w, r, userID, i18n.FromContext(r.Context()).T("%s removed", s.Info().Name))
+}
+
+// Reorder updates icon positions
+func (h *SocialHandler) Reorder(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.UserIDFromContext(r.Context())
+	if userID == 0 {
+		h.resp.Error(w, r, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var positions map[int64]int
+	if err := json.NewDecoder(r.Body).Decode(&positions); err != nil {
+		h.resp.Error(w, r, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := h.socialRepo.UpdatePositions(r.Context(), userID, positions); err != nil {
+		h.log.Error("reorder error", "error", err)
+		h.resp.Error(w, r, http.StatusInternalServerError, "Failed to reorder icons")
+		return
+	}
+
+	h.log.Info("social profiles reordered", "user_id", userID)
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// Click records a social icon click and redirects to the profile
+func (h *SocialHandler) Click(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		h.resp.Error(w, r, http.StatusBadRequest, "Invalid social profile ID")
+		return
+	}
+
+	s, err := h.socialRepo.GetByID(r.Context(), id)
+	if err != nil || s == nil || s.URL() == "" {
+		h.resp.Error(w, r, http.StatusNotFound, "Social profile not found")
+		return
+	}
+
+	// A custom domain only redirects its owner's icons
+	if d := customDomainFromContext(r.Context()); d != nil && d.UserID != s.UserID {
+		h.resp.Error(w, r, http.StatusNotFound, "Social profile not found")
+		return
+	}
+
+	if ok, err := h.lock.guardClick(w, r, s.UserID); !ok {
+		if err != nil {
+			h.log.Error("database error", "error", err)
+			h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
+		}
+		return
+	}
+
+	event := &model.Analytics{
+		UserID:    s.UserID,
+		EventType: "social_click",
+		Platform:  s.Platform,
+		Source:    model.NormalizeSource(r.URL.Query().Get("src")),
+		Referrer:  r.Referer(),
+		UserAgent: r.UserAgent(),
+	}
+	go func() {
+		if err := h.analyticsRepo.Record(context.Background(), event); err != nil {
+			h.log.Error("failed to record social click", "social_id", id, "error", err)
+		}
+	}()
+
+	http.Redirect(w, r, s.URL(), http.StatusTemporaryRedirect)
+}
+
+// respond writes a feedback message and refreshes the icon list
+// out-of-band
+func (h *SocialHandler) respond(w http.ResponseWriter, r *http.Request, userID int64, message string) {
+	profiles, err := h.socialRepo.ListByUser(r.Context(), userID)
+	if err != nil {
+		h.log.Error("database error", "error", err)
+	}
+
+	fmt.Fprintf(w, `<div class="rounded-xl px-4 py-3 text-sm bg-green-50 dark:bg-green-900/20 text-green-700 dark:text-green-400 animate-slide-in">%s</div>`, html.EscapeString(message))
+
+	fmt.Fprint(w, `<div hx-swap-oob="innerHTML:#social-list">`)
+	if err := templates.RenderPartial(w, i18n.FromContext(r.Context()), "socials.html", profiles); err != nil {
+		h.log.Error("template error", "error", err)
+	}
+	fmt.Fprint(w, `</div>`)
+}
@@ -0,0 +1,163 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"linkbio/internal/middleware"
+	"linkbio/internal/model"
+	"linkbio/internal/pkg/response"
+	"linkbio/internal/repository"
+	"linkbio/internal/social"
+	"linkbio/internal/testutil"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type socialFixture struct {
+	h             *SocialHandler
+	socialRepo    *repository.SocialRepository
+	analyticsRepo *repository.AnalyticsRepository
+	user          *model.User
+	router        http.Handler
+}
+
+func setupSocialHandler(t *testing.T) *socialFixture {
+	t.Helper()
+
+	db := testutil.TestDB(t)
+	log := testutil.TestLogger()
+	userRepo := repository.NewUserRepository(db)
+	f := &socialFixture{
+		socialRepo:    repository.NewSocialRepository(db),
+		analyticsRepo: repository.NewAnalyticsRepository(db),
+	}
+	f.h = &SocialHandler{
+		log:           log,
+		resp:          response.New(log),
+		socialRepo:    f.socialRepo,
+		analyticsRepo: f.analyticsRepo,
+		lock:          profileLock{userRepo: userRepo},
+	}
+
+	f.user = &model.User{Username: "iconic", Email: "iconic@test.com", PasswordHash: "hash", DisplayName: "Iconic", Theme: "light"}
+	if err := userRepo.Create(context.Background(), f.user); err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+
+	r := chi.NewRouter()
+	r.Post("/api/v1/socials", f.h.Save)
+	r.Delete("/api/v1/socials/{id}", f.h.Delete)
+	r.Get("/click/social/{id}", f.h.Click)
+	f.router = r
+	return f
+}
+
+// save posts the social icon form as userID
+func (f *socialFixture) save(userID int64, platform, handle string) *httptest.ResponseRecorder {
+	form := url.Values{"platform": {platform}, "handle": {handle}}
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/socials", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req = req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, userID))
+	rec := httptest.NewRecorder()
+	f.router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestSocialHandler_Save(t *testing.T) {
+	testutil.ChdirRoot(t)
+	f := setupSocialHandler(t)
+
+	rec := f.save(f.user.ID, social.Instagram, "https://www.instagram.com/iconic.art/")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	body := rec.Body.String()
+	if !strings.Contains(body, `hx-swap-oob="innerHTML:#social-list"`) || !strings.Contains(body, "https://www.instagram.com/iconic.art/") {
+		t.Errorf("response does not refresh the icon list:\n%s", body)
+	}
+
+	list, _ := f.socialRepo.ListByUser(context.Background(), f.user.ID)
+	if len(list) != 1 || list[0].Handle != "iconic.art" {
+		t.Errorf("stored profiles = %+v", list)
+	}
+
+	for _, tt := range []struct{ platform, handle string }{
+		{social.X, "not a handle"},
+		{social.Email, "nobody"},
+		{"myspace", "iconic"},
+	} {
+		if rec := f.save(f.user.ID, tt.platform, tt.handle); rec.Code != http.StatusUnprocessableEntity {
+			t.Errorf("Save(%s, %q) status = %d, want %d", tt.platform, tt.handle, rec.Code, http.StatusUnprocessableEntity)
+		}
+	}
+}
+
+func TestSocialHandler_DeleteOwnership(t *testing.T) {
+	testutil.ChdirRoot(t)
+	f := setupSocialHandler(t)
+
+	f.save(f.user.ID, social.GitHub, "iconic")
+	list, _ := f.socialRepo.ListByUser(context.Background(), f.user.ID)
+	path := "/api/v1/socials/" + strconv.FormatInt(list[0].ID, 10)
+
+	del := func(userID int64) int {
+		req := httptest.NewRequest(http.MethodDelete, path, nil)
+		req = req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, userID))
+		rec := httptest.NewRecorder()
+		f.router.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	if code := del(f.user.ID + 1); code != http.StatusNotFound {
+		t.Errorf("delete by another user status = %d, want %d", code, http.StatusNotFound)
+	}
+	if code := del(f.user.ID); code != http.StatusOK {
+		t.Errorf("delete status = %d", code)
+	}
+	if list, _ := f.socialRepo.ListByUser(context.Background(), f.user.ID); len(list) != 0 {
+		t.Errorf("profiles after delete = %+v", list)
+	}
+}
+
+func TestSocialHandler_Click(t *testing.T) {
+	f := setupSocialHandler(t)
+	ctx := context.Background()
+
+	s := &model.SocialProfile{UserID: f.user.ID, Platform: social.YouTube, Handle: "iconic"}
+	f.socialRepo.Save(ctx, s)
+
+	rec := httptest.NewRecorder()
+	f.router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/click/social/"+strconv.FormatInt(s.ID, 10), nil))
+	if rec.Code != http.StatusTemporaryRedirect || rec.Header().Get("Location") != "https://www.youtube.com/@iconic" {
+		t.Fatalf("status = %d, Location = %q", rec.Code, rec.Header().Get("Location"))
+	}
+
+	// The click is recorded in the background
+	var summary *model.AnalyticsSummary
+	for i := 0; i < 100; i++ {
+		summary, _ = f.analyticsRepo.GetSummary(ctx, f.user.ID, 1)
+		if summary != nil && len(summary.Socials) > 0 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if len(summary.Socials) != 1 || summary.Socials[0].Label() != "YouTube" || summary.Socials[0].Clicks != 1 {
+		t.Errorf("Socials = %+v", summary.Socials)
+	}
+	if summary.TotalClicks != 0 {
+		t.Errorf("TotalClicks = %d, want social clicks kept apart from link clicks", summary.TotalClicks)
+	}
+
+	rec = httptest.NewRecorder()
+	f.router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/click/social/999", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("unknown icon status = %d", rec.Code)
+	}
+}
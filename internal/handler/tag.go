@@ -0,0 +1,205 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"linkbio/internal/middleware"
+	"linkbio/internal/model"
+	"linkbio/internal/pkg/response"
+	"linkbio/internal/repository"
+
+	"log/slog"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// TagHandler manages a creator's link tags
+type TagHandler struct {
+	log      *slog.Logger
+	resp     *response.Responder
+	tagRepo  *repository.TagRepository
+	linkRepo *repository.LinkRepository
+}
+
+// NewTagHandler creates a new TagHandler
+func NewTagHandler(deps *Dependencies) *TagHandler {
+	return &TagHandler{
+		log:      deps.Log,
+		resp:     deps.Responder,
+		tagRepo:  deps.TagRepo,
+		linkRepo: deps.LinkRepo,
+	}
+}
+
+// Create adds a tag for the current user
+func (h *TagHandler) Create(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.UserIDFromContext(r.Context())
+	if userID == 0 {
+		h.resp.Error(w, r, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		h.resp.Error(w, r, http.StatusBadRequest, "Invalid form data")
+		return
+	}
+
+	req := model.TagRequest{Name: r.FormValue("name"), Color: r.FormValue("color")}
+	if err := req.Validate(); err != nil {
+		h.resp.Invalid(w, r, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	tags, err := h.tagRepo.ListByUser(r.Context(), userID)
+	if err != nil {
+		h.log.Error("database error", "error", err)
+		h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+	if len(tags) >= model.MaxTags {
+		h.resp.Errorf(w, r, http.StatusUnprocessableEntity, "You can have up to %d tags", model.MaxTags)
+		return
+	}
+	if tagNamed(tags, req.Name, 0) {
+		h.resp.Errorf(w, r, http.StatusConflict, "You already have a tag called %s", req.Name)
+		return
+	}
+
+	t := &model.Tag{UserID: userID, Name: req.Name, Color: req.Color}
+	if err := h.tagRepo.Create(r.Context(), t); err != nil {
+		h.log.Error("database error", "error", err)
+		h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+
+	h.log.Info("tag created", "user_id", userID, "tag_id", t.ID)
+	refreshDashboard(w)
+}
+
+// Update renames or recolors a tag
+func (h *TagHandler) Update(w http.ResponseWriter, r *http.Request) {
+	t, ok := h.ownTag(w, r)
+	if !ok {
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		h.resp.Error(w, r, http.StatusBadRequest, "Invalid form data")
+		return
+	}
+
+	req := model.TagRequest{Name: r.FormValue("name"), Color: r.FormValue("color")}
+	if err := req.Validate(); err != nil {
+		h.resp.Invalid(w, r, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	tags, err := h.tagRepo.ListByUser(r.Context(), t.UserID)
+	if err != nil {
+		h.log.Error("database error", "error", err)
+		h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+	if tagNamed(tags, req.Name, t.ID) {
+		h.resp.Errorf(w, r, http.StatusConflict, "You already have a tag called %s", req.Name)
+		return
+	}
+
+	t.Name, t.Color = req.Name, req.Color
+	if err := h.tagRepo.Update(r.Context(), t); err != nil {
+		h.log.Error("database error", "error", err)
+		h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+
+	h.log.Info("tag updated", "user_id", t.UserID, "tag_id", t.ID)
+	refreshDashboard(w)
+}
+
+// Delete removes a tag from every link and deletes it. The links stay.
+func (h *TagHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	t, ok := h.ownTag(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.tagRepo.Delete(r.Context(), t.ID, t.UserID); err != nil {
+		h.log.Error("database error", "error", err)
+		h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+
+	h.log.Info("tag removed", "user_id", t.UserID, "tag_id", t.ID)
+	refreshDashboard(w)
+}
+
+// ShowLinks makes every link carrying a tag visible in the draft
+func (h *TagHandler) ShowLinks(w http.ResponseWriter, r *http.Request) {
+	h.setActive(w, r, true)
+}
+
+// HideLinks hides every link carrying a tag in the draft
+func (h *TagHandler) HideLinks(w http.ResponseWriter, r *http.Request) {
+	h.setActive(w, r, false)
+}
+
+// setActive shows or hides a tag's links. Like any link edit this changes
+// the draft, to go live on publish.
+func (h *TagHandler) setActive(w http.ResponseWriter, r *http.Request, active bool) {
+	t, ok := h.ownTag(w, r)
+	if !ok {
+		return
+	}
+
+	n, err := h.linkRepo.SetActiveByTag(r.Context(), t.UserID, t.ID, active)
+	if err != nil {
+		h.log.Error("database error", "error", err)
+		h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+
+	h.log.Info("tagged links updated", "user_id", t.UserID, "tag_id", t.ID, "active", active, "links", n)
+	refreshDashboard(w)
+}
+
+// ownTag loads the tag named by the {id} URL parameter, answering 404
+// unless it belongs to the current user
+func (h *TagHandler) ownTag(w http.ResponseWriter, r *http.Request) (*model.Tag, bool) {
+	userID := middleware.UserIDFromContext(r.Context())
+	if userID == 0 {
+		h.resp.Error(w, r, http.StatusUnauthorized, "Unauthorized")
+		return nil, false
+	}
+
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		h.resp.Error(w, r, http.StatusBadRequest, "Invalid tag ID")
+		return nil, false
+	}
+
+	t, err := h.tagRepo.GetByID(r.Context(), id)
+	if err != nil || t == nil || t.UserID != userID {
+		h.resp.Error(w, r, http.StatusNotFound, "Tag not found")
+		return nil, false
+	}
+	return t, true
+}
+
+// refreshDashboard reloads the dashboard, as every link card shows its tags
+func refreshDashboard(w http.ResponseWriter) {
+	w.Header().Set("HX-Refresh", "true")
+	w.WriteHeader(http.StatusOK)
+}
+
+// tagNamed reports whether any of tags other than the one with ID except
+// is called name, ignoring case like the database does
+func tagNamed(tags []model.Tag, name string, except int64) bool {
+	for _, t := range tags {
+		if t.ID != except && strings.EqualFold(t.Name, name) {
+			return true
+		}
+	}
+	return false
+}
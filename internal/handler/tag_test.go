@@ -0,0 +1,145 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"linkbio/internal/middleware"
+	"linkbio/internal/model"
+	"linkbio/internal/pkg/response"
+	"linkbio/internal/repository"
+	"linkbio/internal/testutil"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestTagHandler(t *testing.T) {
+	testutil.ChdirRoot(t)
+
+	db := testutil.TestDB(t)
+	log := testutil.TestLogger()
+	userRepo := repository.NewUserRepository(db)
+	linkRepo := repository.NewLinkRepository(db)
+	tagRepo := repository.NewTagRepository(db)
+	ctx := context.Background()
+
+	user := &model.User{Username: "tagged", Email: "tagged@test.com", PasswordHash: "hash", Theme: "light"}
+	other := &model.User{Username: "untagged", Email: "untagged@test.com", PasswordHash: "hash", Theme: "light"}
+	for _, u := range []*model.User{user, other} {
+		if err := userRepo.Create(ctx, u); err != nil {
+			t.Fatalf("failed to create user: %v", err)
+		}
+	}
+	ad := &model.Link{UserID: user.ID, Title: "Ad", URL: "https://ad.example", IsActive: true}
+	linkRepo.Create(ctx, ad)
+
+	h := &TagHandler{log: log, resp: response.New(log), tagRepo: tagRepo, linkRepo: linkRepo}
+	links := &LinkHandler{log: log, resp: response.New(log), linkRepo: linkRepo, pageRepo: repository.NewPageRepository(db), tagRepo: tagRepo}
+	r := chi.NewRouter()
+	r.Post("/api/v1/tags", h.Create)
+	r.Put("/api/v1/tags/{id}", h.Update)
+	r.Delete("/api/v1/tags/{id}", h.Delete)
+	r.Post("/api/v1/tags/{id}/show", h.ShowLinks)
+	r.Post("/api/v1/tags/{id}/hide", h.HideLinks)
+	r.Put("/api/v1/links/{id}/tags", links.SetTags)
+
+	do := func(userID int64, method, target string, form url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req = req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, userID))
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := do(user.ID, http.MethodPost, "/api/v1/tags", url.Values{"name": {"  sponsored "}, "color": {"amber"}})
+	if rec.Code != http.StatusOK || rec.Header().Get("HX-Refresh") != "true" {
+		t.Fatalf("create status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	tags, _ := tagRepo.ListByUser(ctx, user.ID)
+	if len(tags) != 1 || tags[0].Name != "sponsored" || tags[0].Color != "amber" {
+		t.Fatalf("tags = %+v", tags)
+	}
+	sponsored := tags[0]
+	path := "/api/v1/tags/" + strconv.FormatInt(sponsored.ID, 10)
+
+	tests := []struct {
+		name, color string
+		want        int
+	}{
+		{"Sponsored", "", http.StatusConflict},
+		{"", "gray", http.StatusUnprocessableEntity},
+		{"music", "chartreuse", http.StatusUnprocessableEntity},
+		{strings.Repeat("x", model.MaxTagNameLength+1), "", http.StatusUnprocessableEntity},
+	}
+	for _, tt := range tests {
+		if rec := do(user.ID, http.MethodPost, "/api/v1/tags", url.Values{"name": {tt.name}, "color": {tt.color}}); rec.Code != tt.want {
+			t.Errorf("create(%q, %q) status = %d, want %d", tt.name, tt.color, rec.Code, tt.want)
+		}
+	}
+
+	// The name a conflict message repeats back is escaped once, whether
+	// creating or renaming
+	do(user.ID, http.MethodPost, "/api/v1/tags", url.Values{"name": {"<b>bold</b>"}, "color": {"gray"}})
+	for _, req := range []struct{ method, target string }{
+		{http.MethodPost, "/api/v1/tags"},
+		{http.MethodPut, path},
+	} {
+		rec := do(user.ID, req.method, req.target, url.Values{"name": {"<B>bold</B>"}, "color": {"gray"}})
+		if rec.Code != http.StatusConflict || !strings.HasSuffix(rec.Body.String(), "&lt;B&gt;bold&lt;/B&gt;") {
+			t.Errorf("%s duplicate markup name status = %d, body = %s", req.method, rec.Code, rec.Body.String())
+		}
+	}
+
+	// Tagging a link returns its card with the tag shown
+	rec = do(user.ID, http.MethodPut, "/api/v1/links/"+strconv.FormatInt(ad.ID, 10)+"/tags", url.Values{"tag_id": {strconv.FormatInt(sponsored.ID, 10)}})
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "bg-amber-100") {
+		t.Fatalf("tag link status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	if rec := do(other.ID, http.MethodPut, "/api/v1/links/"+strconv.FormatInt(ad.ID, 10)+"/tags", url.Values{}); rec.Code != http.StatusNotFound {
+		t.Errorf("tagging someone else's link status = %d, want 404", rec.Code)
+	}
+
+	// Bulk actions change the draft of every tagged link
+	if rec := do(user.ID, http.MethodPost, path+"/hide", nil); rec.Code != http.StatusOK {
+		t.Fatalf("hide status = %d", rec.Code)
+	}
+	if found, _ := linkRepo.GetByID(ctx, ad.ID); found.IsActive {
+		t.Error("tagged link still visible after hide")
+	}
+	do(user.ID, http.MethodPost, path+"/show", nil)
+	if found, _ := linkRepo.GetByID(ctx, ad.ID); !found.IsActive {
+		t.Error("tagged link still hidden after show")
+	}
+
+	// Another user can't touch the tag
+	for _, req := range []struct{ method, target string }{
+		{http.MethodPut, path},
+		{http.MethodDelete, path},
+		{http.MethodPost, path + "/hide"},
+	} {
+		if rec := do(other.ID, req.method, req.target, url.Values{"name": {"mine"}}); rec.Code != http.StatusNotFound {
+			t.Errorf("%s %s by another user status = %d, want 404", req.method, req.target, rec.Code)
+		}
+	}
+
+	// Renaming to its own name in another case is fine
+	if rec := do(user.ID, http.MethodPut, path, url.Values{"name": {"Sponsored"}, "color": {"green"}}); rec.Code != http.StatusOK {
+		t.Errorf("rename status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	if found, _ := tagRepo.GetByID(ctx, sponsored.ID); found.Name != "Sponsored" || found.Color != "green" {
+		t.Errorf("tag = %+v after rename", found)
+	}
+
+	if rec := do(user.ID, http.MethodDelete, path, nil); rec.Code != http.StatusOK {
+		t.Errorf("delete status = %d", rec.Code)
+	}
+	if found, _ := linkRepo.GetByID(ctx, ad.ID); found == nil {
+		t.Error("deleting the tag removed its link")
+	}
+}
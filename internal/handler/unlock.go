@@ -0,0 +1,176 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"html/template"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"linkbio/internal/i18n"
+	"linkbio/internal/model"
+	"linkbio/internal/pkg/templates"
+	"linkbio/internal/repository"
+	"linkbio/internal/theme"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/gorilla/sessions"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Unlock attempts allowed per profile and visitor address
+const (
+	unlockAttempts = 5
+	unlockWindow   = 15 * time.Minute
+)
+
+// profileLock guards password-protected profiles. Unlocking one sets a
+// signed cookie for that profile alone; it stops working when the creator
+// changes the password.
+type profileLock struct {
+	store    *sessions.CookieStore
+	userRepo *repository.UserRepository
+}
+
+// newProfileLock creates a profileLock
+func newProfileLock(deps *Dependencies) profileLock {
+	return profileLock{store: deps.Store, userRepo: deps.UserRepo}
+}
+
+// unlockCookie is the name of the cookie that unlocks user's profile
+func unlockCookie(user *model.User) string {
+	return "unlock_" + strconv.FormatInt(user.ID, 10)
+}
+
+// unlockToken identifies the profile password without revealing its hash
+func unlockToken(user *model.User) string {
+	sum := sha256.Sum256([]byte(user.ProfilePasswordHash))
+	return hex.EncodeToString(sum[:16])
+}
+
+// canView reports whether the request may see user's profile and links
+func (l profileLock) canView(r *http.Request, user *model.User) bool {
+	if user.Visibility() != model.VisibilityPassword {
+		return true
+	}
+	session, err := l.store.Get(r, unlockCookie(user))
+	if err != nil {
+		return false
+	}
+	token, _ := session.Values["token"].(string)
+	return token == unlockToken(user)
+}
+
+// guardClick lets a click on ownerID's link or icon through only if the
+// visitor can see the profile; otherwise it sends them to the password form.
+// It reports whether the caller should go ahead.
+func (l profileLock) guardClick(w http.ResponseWriter, r *http.Request, ownerID int64) (bool, error) {
+	user, err := l.userRepo.GetByID(r.Context(), ownerID)
+	if err != nil {
+		return false, err
+	}
+	if user == nil {
+		http.NotFound(w, r)
+		return false, nil
+	}
+	if !l.canView(r, user) {
+		http.Redirect(w, r, profilePath(r, user), http.StatusSeeOther)
+		return false, nil
+	}
+	return true, nil
+}
+
+// grant remembers that this visitor unlocked user's profile
+func (l profileLock) grant(w http.ResponseWriter, r *http.Request, user *model.User) error {
+	session, _ := l.store.Get(r, unlockCookie(user))
+	session.Values["token"] = unlockToken(user)
+	return session.Save(r, w)
+}
+
+// profilePath is where user's profile lives on the host the request came in on
+func profilePath(r *http.Request, user *model.User) string {
+	if customDomainFromContext(r.Context()) != nil {
+		return "/"
+	}
+	return "/u/" + url.PathEscape(user.Username)
+}
+
+// UnlockData holds data for the unlock template
+type UnlockData struct {
+	User     *model.User
+	ThemeCSS template.CSS
+	Action   string
+	Error    string
+}
+
+// Unlock checks a visitor's password for a protected profile
+func (h *ProfileHandler) Unlock(w http.ResponseWriter, r *http.Request) {
+	username := chi.URLParam(r, "username")
+
+	user, err := h.userRepo.GetPublishedByUsername(r.Context(), username)
+	if err != nil {
+		h.log.Error("database error", "error", err)
+		h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+	if user == nil {
+		h.resp.Error(w, r, http.StatusNotFound, "Profile not found")
+		return
+	}
+	r = withProfileLocale(r, h.locales, user)
+	if user.Visibility() != model.VisibilityPassword {
+		http.Redirect(w, r, profilePath(r, user), http.StatusSeeOther)
+		return
+	}
+
+	key := strconv.FormatInt(user.ID, 10) + "|" + clientIP(r)
+	if ok, retry := h.unlockAttempts.Allow(key); !ok {
+		w.Header().Set("Retry-After", strconv.Itoa(int(retry.Seconds())+1))
+		h.renderLocked(w, r, user, http.StatusTooManyRequests, "Too many attempts. Try again in a few minutes.")
+		return
+	}
+
+	if bcrypt.CompareHashAndPassword([]byte(user.ProfilePasswordHash), []byte(r.FormValue("password"))) != nil {
+		h.log.Info("profile unlock failed", "user_id", user.ID)
+		h.renderLocked(w, r, user, http.StatusUnauthorized, "Wrong password")
+		return
+	}
+
+	h.unlockAttempts.Reset(key)
+	if err := h.lock.grant(w, r, user); err != nil {
+		h.log.Error("session error", "error", err)
+		h.resp.Error(w, r, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+	http.Redirect(w, r, profilePath(r, user), http.StatusSeeOther)
+}
+
+// renderLocked shows the password form in place of a protected profile
+func (h *ProfileHandler) renderLocked(w http.ResponseWriter, r *http.Request, user *model.User, status int, message string) {
+	data := UnlockData{
+		User:     user,
+		ThemeCSS: theme.Resolve(user.Theme, user.ThemeConfig).CSS(),
+		Action:   strings.TrimSuffix(profilePath(r, user), "/") + "/unlock",
+		Error:    message,
+	}
+
+	w.Header().Set("X-Robots-Tag", "noindex")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	if err := templates.Render(w, i18n.FromContext(r.Context()), "unlock.html", data); err != nil {
+		h.log.Error("template error", "error", err)
+	}
+}
+
+// clientIP is the address a request came from, without the port
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,165 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"linkbio/internal/model"
+	"linkbio/internal/pkg/ratelimit"
+	"linkbio/internal/pkg/response"
+	"linkbio/internal/repository"
+	"linkbio/internal/testutil"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/gorilla/sessions"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestProfileHandler_PasswordProtected(t *testing.T) {
+	testutil.ChdirRoot(t)
+
+	db := testutil.TestDB(t)
+	log := testutil.TestLogger()
+	userRepo := repository.NewUserRepository(db)
+	linkRepo := repository.NewLinkRepository(db)
+	ctx := context.Background()
+
+	hash, _ := bcrypt.GenerateFromPassword([]byte("opensesame"), bcrypt.MinCost)
+	user := &model.User{Username: "vault", Email: "vault@test.com", PasswordHash: "hash", DisplayName: "Vault", Theme: "light",
+		HideFromSearch: true, ProfilePasswordHash: string(hash)}
+	if err := userRepo.Create(ctx, user); err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	link := &model.Link{UserID: user.ID, Title: "Client files", URL: "https://files.example", IsActive: true}
+	linkRepo.Create(ctx, link)
+	repository.NewDraftRepository(db).Publish(ctx, user.ID, time.Now())
+
+	lock := profileLock{store: sessions.NewCookieStore([]byte("test-secret-key-32-chars-minimum!")), userRepo: userRepo}
+	profile := &ProfileHandler{
+		log:            log,
+		resp:           response.New(log),
+		userRepo:       userRepo,
+		linkRepo:       linkRepo,
+		analyticsRepo:  repository.NewAnalyticsRepository(db),
+		domainRepo:     repository.NewDomainRepository(db),
+		socialRepo:     repository.NewSocialRepository(db),
+		pageRepo:       repository.NewPageRepository(db),
+		lock:           lock,
+		unlockAttempts: ratelimit.New(3, time.Minute),
+	}
+	links := &LinkHandler{
+		log:           log,
+		resp:          response.New(log),
+		linkRepo:      linkRepo,
+		analyticsRepo: repository.NewAnalyticsRepository(db),
+		lock:          lock,
+	}
+	r := chi.NewRouter()
+	r.Get("/u/{username}", profile.Show)
+	r.Post("/u/{username}/unlock", profile.Unlock)
+	r.Get("/click/{id}", links.Click)
+
+	var cookies []*http.Cookie
+	do := func(method, path, password string) *httptest.ResponseRecorder {
+		var req *http.Request
+		if method == http.MethodPost {
+			req = httptest.NewRequest(method, path, strings.NewReader(url.Values{"password": {password}}.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		} else {
+			req = httptest.NewRequest(method, path, nil)
+		}
+		for _, c := range cookies {
+			req.AddCookie(c)
+		}
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		return rec
+	}
+	click := "/click/" + strconv.FormatInt(link.ID, 10)
+
+	// Locked: the form replaces the profile and clicks bounce to it
+	rec := do(http.MethodGet, "/u/vault", "")
+	if rec.Code != http.StatusUnauthorized || !strings.Contains(rec.Body.String(), `action="/u/vault/unlock"`) {
+		t.Fatalf("locked status = %d", rec.Code)
+	}
+	if strings.Contains(rec.Body.String(), "Client files") {
+		t.Error("locked profile shows its links")
+	}
+	if rec := do(http.MethodGet, click, ""); rec.Code != http.StatusSeeOther || rec.Header().Get("Location") != "/u/vault" {
+		t.Errorf("locked click status = %d, Location = %q", rec.Code, rec.Header().Get("Location"))
+	}
+
+	if rec := do(http.MethodPost, "/u/vault/unlock", "guess"); rec.Code != http.StatusUnauthorized || !strings.Contains(rec.Body.String(), "Wrong password") {
+		t.Errorf("wrong password status = %d", rec.Code)
+	}
+
+	// A correct password sets the unlock cookie
+	rec = do(http.MethodPost, "/u/vault/unlock", "opensesame")
+	if rec.Code != http.StatusSeeOther || rec.Header().Get("Location") != "/u/vault" {
+		t.Fatalf("unlock status = %d, Location = %q", rec.Code, rec.Header().Get("Location"))
+	}
+	cookies = rec.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != "unlock_"+strconv.FormatInt(user.ID, 10) {
+		t.Fatalf("cookies = %v", cookies)
+	}
+	if rec := do(http.MethodGet, "/u/vault", ""); rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "Client files") {
+		t.Errorf("unlocked status = %d", rec.Code)
+	}
+	if rec := do(http.MethodGet, click, ""); rec.Code != http.StatusTemporaryRedirect {
+		t.Errorf("unlocked click status = %d", rec.Code)
+	}
+
+	// Changing the password locks existing visitors out again
+	hash, _ = bcrypt.GenerateFromPassword([]byte("newsecret"), bcrypt.MinCost)
+	user.ProfilePasswordHash = string(hash)
+	userRepo.Update(ctx, user)
+	if rec := do(http.MethodGet, "/u/vault", ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("status after password change = %d", rec.Code)
+	}
+}
+
+func TestProfileHandler_UnlockRateLimit(t *testing.T) {
+	testutil.ChdirRoot(t)
+
+	db := testutil.TestDB(t)
+	log := testutil.TestLogger()
+	userRepo := repository.NewUserRepository(db)
+
+	hash, _ := bcrypt.GenerateFromPassword([]byte("opensesame"), bcrypt.MinCost)
+	user := &model.User{Username: "guarded", Email: "guarded@test.com", PasswordHash: "hash", DisplayName: "Guarded", Theme: "light",
+		HideFromSearch: true, ProfilePasswordHash: string(hash)}
+	if err := userRepo.Create(context.Background(), user); err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+
+	h := &ProfileHandler{
+		log:            log,
+		resp:           response.New(log),
+		userRepo:       userRepo,
+		lock:           profileLock{store: sessions.NewCookieStore([]byte("test-secret-key-32-chars-minimum!")), userRepo: userRepo},
+		unlockAttempts: ratelimit.New(2, time.Minute),
+	}
+	r := chi.NewRouter()
+	r.Post("/u/{username}/unlock", h.Unlock)
+
+	try := func(password string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/u/guarded/unlock", strings.NewReader("password="+password))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		return rec
+	}
+
+	try("one")
+	try("two")
+	rec := try("opensesame")
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") == "" {
+		t.Errorf("status = %d, Retry-After = %q; want the limit to hold even for the right password", rec.Code, rec.Header().Get("Retry-After"))
+	}
+}
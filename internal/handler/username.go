@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+
+	"linkbio/internal/config"
+	"linkbio/internal/i18n"
+	"linkbio/internal/repository"
+)
+
+// usernameRules limits how usernames are claimed and changed
+type usernameRules struct {
+	hold         time.Duration // how long a released name stays reserved
+	changeLimit  int           // changes allowed per changeWindow
+	changeWindow time.Duration
+}
+
+// newUsernameRules reads the limits from config
+func newUsernameRules(cfg *config.Config) usernameRules {
+	u := usernameRules{
+		hold:         cfg.UsernameHold,
+		changeLimit:  cfg.UsernameChangeLimit,
+		changeWindow: cfg.UsernameChangeWindow,
+	}
+	if u.changeLimit < 1 {
+		u.changeLimit = 1
+	}
+	return u
+}
+
+// available reports whether an account may claim name. userID is 0 for a
+// new account. Names in use, and names another account released within the
+// hold period, are unavailable; an account may always take back its own
+// old name.
+func (u usernameRules) available(ctx context.Context, repo *repository.UserRepository, name string, userID int64) (bool, error) {
+	existing, err := repo.GetByUsername(ctx, name)
+	if err != nil {
+		return false, err
+	}
+	if existing != nil && existing.ID != userID {
+		return false, nil
+	}
+
+	heldBy, err := repo.UsernameHeldBy(ctx, name, time.Now().Add(-u.hold))
+	if err != nil {
+		return false, err
+	}
+	return heldBy == 0 || heldBy == userID, nil
+}
+
+// nextChange returns when the account may next change its username, or the
+// zero time if it may change it now
+func (u usernameRules) nextChange(ctx context.Context, repo *repository.UserRepository, userID int64) (time.Time, error) {
+	changes, err := repo.UsernameChanges(ctx, userID, time.Now().Add(-u.changeWindow))
+	if err != nil || len(changes) < u.changeLimit {
+		return time.Time{}, err
+	}
+	// The oldest change in the window has to age out first
+	return changes[len(changes)-u.changeLimit].Add(u.changeWindow), nil
+}
+
+// note describes the change limit for the settings page, in l's language.
+// With a non-zero next it says when the next change is allowed instead.
+func (u usernameRules) note(l *i18n.Localizer, next time.Time) string {
+	if !next.IsZero() {
+		return l.T("You have changed your username too often recently. You can change it again on %s.", l.Date(next))
+	}
+	days := int(u.changeWindow.Hours() / 24)
+	if u.changeLimit == 1 {
+		return l.N("You can change your username once a day.", "You can change your username once every %d days.", days)
+	}
+	return l.N("You can change your username %[2]d times a day.", "You can change your username %[2]d times every %[1]d days.", days, u.changeLimit)
+}
+
+// redirectRenamed answers a request for a username that is no longer in
+// use with a permanent redirect to the account's current profile. suffix is
+// appended after the new username and the query string is kept, so printed
+// QR codes still count as scans. It reports whether a redirect was written.
+func redirectRenamed(w http.ResponseWriter, r *http.Request, repo *repository.UserRepository, username, suffix string) (bool, error) {
+	user, err := repo.GetByPreviousUsername(r.Context(), username)
+	if err != nil || user == nil {
+		return false, err
+	}
+
+	target := "/u/" + url.PathEscape(user.Username) + suffix
+	if r.URL.RawQuery != "" {
+		target += "?" + r.URL.RawQuery
+	}
+	http.Redirect(w, r, target, http.StatusMovedPermanently)
+	return true, nil
+}
@@ -0,0 +1,142 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"linkbio/internal/middleware"
+	"linkbio/internal/model"
+	"linkbio/internal/pkg/response"
+	"linkbio/internal/repository"
+	"linkbio/internal/testutil"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/gorilla/sessions"
+)
+
+type usernameFixture struct {
+	settings *SettingsHandler
+	auth     *AuthHandler
+	profile  *ProfileHandler
+	userRepo *repository.UserRepository
+	user     *model.User
+}
+
+func setupUsernameHandlers(t *testing.T) *usernameFixture {
+	t.Helper()
+
+	db := testutil.TestDB(t)
+	log := testutil.TestLogger()
+	userRepo := repository.NewUserRepository(db)
+	store := sessions.NewCookieStore([]byte("test-secret-key-32-chars-minimum!"))
+	rules := usernameRules{hold: 24 * time.Hour, changeLimit: 2, changeWindow: 24 * time.Hour}
+
+	user := &model.User{Username: "before", Email: "before@test.com", PasswordHash: "hash", DisplayName: "Before", Theme: "light"}
+	if err := userRepo.Create(context.Background(), user); err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+
+	return &usernameFixture{
+		settings: &SettingsHandler{log: log, resp: response.New(log), userRepo: userRepo, store: store, usernames: rules},
+		auth:     &AuthHandler{log: log, resp: response.New(log), userRepo: userRepo, store: store, usernames: rules},
+		profile: &ProfileHandler{
+			log:           log,
+			resp:          response.New(log),
+			userRepo:      userRepo,
+			linkRepo:      repository.NewLinkRepository(db),
+			analyticsRepo: repository.NewAnalyticsRepository(db),
+			domainRepo:    repository.NewDomainRepository(db),
+			socialRepo:    repository.NewSocialRepository(db),
+			pageRepo:      repository.NewPageRepository(db),
+		},
+		userRepo: userRepo,
+		user:     user,
+	}
+}
+
+func (f *usernameFixture) change(userID int64, username string) *httptest.ResponseRecorder {
+	form := url.Values{"username": {username}}
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/profile/username", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req = req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, userID))
+	rec := httptest.NewRecorder()
+	f.settings.ChangeUsername(rec, req)
+	return rec
+}
+
+func TestSettingsHandler_ChangeUsername(t *testing.T) {
+	f := setupUsernameHandlers(t)
+
+	rec := f.change(f.user.ID, "after")
+	if rec.Code != http.StatusOK || rec.Header().Get("HX-Redirect") == "" {
+		t.Fatalf("status = %d, HX-Redirect = %q, body = %s", rec.Code, rec.Header().Get("HX-Redirect"), rec.Body.String())
+	}
+	if got, _ := f.userRepo.GetByID(context.Background(), f.user.ID); got.Username != "after" {
+		t.Errorf("Username = %q", got.Username)
+	}
+
+	// The old handle answers with a permanent redirect that keeps the query
+	r := chi.NewRouter()
+	r.Get("/u/{username}", f.profile.Show)
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/u/before?src=qr", nil))
+	if rec.Code != http.StatusMovedPermanently {
+		t.Fatalf("old handle status = %d, want %d", rec.Code, http.StatusMovedPermanently)
+	}
+	if loc := rec.Header().Get("Location"); loc != "/u/after?src=qr" {
+		t.Errorf("Location = %q", loc)
+	}
+}
+
+func TestSettingsHandler_ChangeUsername_HoldsOldHandle(t *testing.T) {
+	f := setupUsernameHandlers(t)
+	other := &model.User{Username: "other", Email: "other@test.com", PasswordHash: "hash", Theme: "light"}
+	f.userRepo.Create(context.Background(), other)
+
+	f.change(f.user.ID, "after")
+
+	// Nobody else can take the released name during the hold...
+	if rec := f.change(other.ID, "before"); rec.Code != http.StatusConflict {
+		t.Errorf("other user change status = %d, want %d", rec.Code, http.StatusConflict)
+	}
+	form := url.Values{"username": {"before"}, "email": {"new@test.com"}, "password": {"secret1"}}
+	req := httptest.NewRequest(http.MethodPost, "/auth/register", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec := httptest.NewRecorder()
+	f.auth.Register(rec, req)
+	if rec.Code != http.StatusConflict {
+		t.Errorf("register with held name status = %d, want %d", rec.Code, http.StatusConflict)
+	}
+
+	// ...but the previous owner can take it back
+	if rec := f.change(f.user.ID, "before"); rec.Code != http.StatusOK {
+		t.Errorf("reclaim status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestSettingsHandler_ChangeUsername_RateLimited(t *testing.T) {
+	f := setupUsernameHandlers(t)
+
+	f.change(f.user.ID, "second")
+	f.change(f.user.ID, "third")
+
+	rec := f.change(f.user.ID, "fourth")
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusTooManyRequests)
+	}
+	if rec.Header().Get("Retry-After") == "" {
+		t.Error("missing Retry-After")
+	}
+	if !strings.Contains(rec.Body.String(), "change it again on") {
+		t.Errorf("body = %q", rec.Body.String())
+	}
+
+	if rec := f.change(f.user.ID, "third"); rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("unchanged name status = %d, want %d", rec.Code, http.StatusUnprocessableEntity)
+	}
+}
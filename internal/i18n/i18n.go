@@ -0,0 +1,250 @@
+// Package i18n translates the site's interface. Messages are identified by
+// their English text, so English needs no catalog and an untranslated
+// message falls back to it. Other languages are JSON files named after
+// their language tag, such as es.json:
+//
+//	{
+//	  "name": "Español",
+//	  "messages": {
+//	    "Sign In": "Iniciar sesión",
+//	    "%d view": {"one": "%d visita", "other": "%d visitas"}
+//	  }
+//	}
+//
+// A plural message is keyed by its English singular and has one entry per
+// plural category the language uses (see Plural).
+package i18n
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Source is the language messages are written in
+const Source = "en"
+
+// Language is a language the interface can be shown in
+type Language struct {
+	Tag  string // lowercase BCP 47 tag, e.g. "es" or "pt-br"
+	Name string // the language's name for itself
+}
+
+// message is a translation; plural messages have forms instead of text
+type message struct {
+	text  string
+	forms map[string]string
+}
+
+// Bundle holds the message catalogs for every supported language. A nil
+// Bundle supports only the source language.
+type Bundle struct {
+	languages []Language
+	catalogs  map[string]map[string]message
+}
+
+// catalogFile is the layout of a catalog on disk
+type catalogFile struct {
+	Name     string                     `json:"name"`
+	Messages map[string]json.RawMessage `json:"messages"`
+}
+
+// Load reads every *.json catalog in fsys
+func Load(fsys fs.FS) (*Bundle, error) {
+	files, err := fs.Glob(fsys, "*.json")
+	if err != nil {
+		return nil, err
+	}
+
+	b := &Bundle{
+		languages: []Language{{Tag: Source, Name: "English"}},
+		catalogs:  make(map[string]map[string]message),
+	}
+	for _, file := range files {
+		tag := strings.ToLower(strings.TrimSuffix(path.Base(file), ".json"))
+		if tag == Source {
+			continue
+		}
+
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+		var cf catalogFile
+		if err := json.Unmarshal(data, &cf); err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		if cf.Name == "" {
+			return nil, fmt.Errorf("%s: missing language name", file)
+		}
+
+		catalog := make(map[string]message, len(cf.Messages))
+		for id, raw := range cf.Messages {
+			m, err := parseMessage(raw)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %q: %w", file, id, err)
+			}
+			catalog[id] = m
+		}
+		b.catalogs[tag] = catalog
+		b.languages = append(b.languages, Language{Tag: tag, Name: cf.Name})
+	}
+
+	sort.Slice(b.languages[1:], func(i, j int) bool {
+		return b.languages[i+1].Tag < b.languages[j+1].Tag
+	})
+	return b, nil
+}
+
+// parseMessage reads a translation: a string, or an object of plural forms
+func parseMessage(raw json.RawMessage) (message, error) {
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		return message{text: text}, nil
+	}
+
+	var forms map[string]string
+	if err := json.Unmarshal(raw, &forms); err != nil {
+		return message{}, errors.New("want a string or an object of plural forms")
+	}
+	if forms["other"] == "" {
+		return message{}, errors.New(`plural forms need "other"`)
+	}
+	for category := range forms {
+		if !validCategory(category) {
+			return message{}, fmt.Errorf("unknown plural category %q", category)
+		}
+	}
+	return message{forms: forms}, nil
+}
+
+// Languages lists the supported languages, the source language first
+func (b *Bundle) Languages() []Language {
+	if b == nil {
+		return []Language{{Tag: Source, Name: "English"}}
+	}
+	return b.languages
+}
+
+// Supported reports whether tag is one of the bundle's languages
+func (b *Bundle) Supported(tag string) bool {
+	if tag == Source {
+		return true
+	}
+	if b == nil {
+		return false
+	}
+	_, ok := b.catalogs[tag]
+	return ok
+}
+
+// Localizer returns a Localizer for tag, falling back to the source
+// language when tag isn't supported
+func (b *Bundle) Localizer(tag string) *Localizer {
+	if tag == Source || !b.Supported(tag) {
+		return nil
+	}
+	return &Localizer{lang: tag, messages: b.catalogs[tag]}
+}
+
+// Localizer translates messages into one language. A nil Localizer
+// returns messages in the source language.
+type Localizer struct {
+	lang     string
+	messages map[string]message
+}
+
+// Lang returns the language tag, e.g. for the html lang attribute
+func (l *Localizer) Lang() string {
+	if l == nil {
+		return Source
+	}
+	return l.lang
+}
+
+// T translates msg. With args, the translation is a fmt format for them.
+func (l *Localizer) T(msg string, args ...any) string {
+	if l != nil {
+		if m, ok := l.messages[msg]; ok && m.text != "" {
+			msg = m.text
+		}
+	}
+	if len(args) == 0 {
+		return msg
+	}
+	return fmt.Sprintf(msg, args...)
+}
+
+// N translates a message that depends on a count. singular and plural are
+// the English forms; the chosen form is a fmt format for n followed by
+// args.
+func (l *Localizer) N(singular, plural string, n int, args ...any) string {
+	form := plural
+	if n == 1 {
+		form = singular
+	}
+	if l != nil {
+		if m, ok := l.messages[singular]; ok && m.forms != nil {
+			if f, ok := m.forms[Plural(l.lang, n)]; ok {
+				form = f
+			} else {
+				form = m.forms["other"]
+			}
+		}
+	}
+	if !strings.Contains(form, "%") {
+		return form
+	}
+	return fmt.Sprintf(form, append([]any{n}, args...)...)
+}
+
+// Date formats t as a long date, e.g. "January 2, 2006"
+func (l *Localizer) Date(t time.Time) string {
+	return l.T("%[1]s %[2]d, %[3]d", l.T(t.Month().String()), t.Day(), t.Year())
+}
+
+// Err translates an error's message for showing to the user
+func (l *Localizer) Err(err error) string {
+	var e *Error
+	if errors.As(err, &e) {
+		return l.T(e.format, e.args...)
+	}
+	return l.T(err.Error())
+}
+
+// Error is a user-facing error with values in its message. Its format is
+// the message ID, so the message can still be translated.
+type Error struct {
+	format string
+	args   []any
+}
+
+// Errorf returns an Error for a message like fmt.Errorf would
+func Errorf(format string, args ...any) error {
+	return &Error{format: format, args: args}
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf(e.format, e.args...)
+}
+
+// contextKey is the context key for the request's Localizer
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying l
+func NewContext(ctx context.Context, l *Localizer) context.Context {
+	return context.WithValue(ctx, contextKey{}, l)
+}
+
+// FromContext returns the request's Localizer, nil (the source language)
+// if none was set
+func FromContext(ctx context.Context) *Localizer {
+	l, _ := ctx.Value(contextKey{}).(*Localizer)
+	return l
+}
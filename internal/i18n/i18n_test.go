@@ -0,0 +1,168 @@
+package i18n
+
+import (
+	"os"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func testBundle(t *testing.T) *Bundle {
+	t.Helper()
+	b, err := Load(fstest.MapFS{
+		"es.json": {Data: []byte(`{"name": "Español", "messages": {
+			"Sign In": "Iniciar sesión",
+			"Continue to %s": "Continuar a %s",
+			"%d view": {"one": "%d visita", "other": "%d visitas"},
+			"%[1]s %[2]d, %[3]d": "%[2]d de %[1]s de %[3]d",
+			"March": "marzo"
+		}}`)},
+		"ru.json": {Data: []byte(`{"name": "Русский", "messages": {
+			"%d view": {"one": "%d просмотр", "few": "%d просмотра", "many": "%d просмотров", "other": "%d просмотра"}
+		}}`)},
+		"en.json":   {Data: []byte(`not read`)},
+		"README.md": {Data: []byte(`ignored`)},
+	})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	return b
+}
+
+func TestLoad(t *testing.T) {
+	b := testBundle(t)
+
+	langs := b.Languages()
+	if len(langs) != 3 || langs[0].Tag != "en" || langs[1].Tag != "es" || langs[2].Name != "Русский" {
+		t.Errorf("Languages() = %+v", langs)
+	}
+	if !b.Supported("es") || !b.Supported("en") || b.Supported("fr") {
+		t.Error("Supported() wrong")
+	}
+
+	bad := map[string]string{
+		"no name":        `{"messages": {}}`,
+		"no other form":  `{"name": "X", "messages": {"%d view": {"one": "%d"}}}`,
+		"bad category":   `{"name": "X", "messages": {"%d view": {"single": "%d", "other": "%d"}}}`,
+		"number message": `{"name": "X", "messages": {"Sign In": 1}}`,
+	}
+	for name, data := range bad {
+		if _, err := Load(fstest.MapFS{"xx.json": {Data: []byte(data)}}); err == nil {
+			t.Errorf("Load() accepted a catalog with %s", name)
+		}
+	}
+}
+
+func TestLocalizer(t *testing.T) {
+	b := testBundle(t)
+	es := b.Localizer("es")
+
+	if got := es.T("Sign In"); got != "Iniciar sesión" {
+		t.Errorf("T() = %q", got)
+	}
+	if got := es.T("Continue to %s", "example.com"); got != "Continuar a example.com" {
+		t.Errorf("T() with args = %q", got)
+	}
+	if got := es.T("Untranslated"); got != "Untranslated" {
+		t.Errorf("T() of a missing message = %q", got)
+	}
+	if got := es.N("%d view", "%d views", 5); got != "5 visitas" {
+		t.Errorf("N(5) = %q", got)
+	}
+	if got := es.Date(time.Date(2026, 3, 14, 0, 0, 0, 0, time.UTC)); got != "14 de marzo de 2026" {
+		t.Errorf("Date() = %q", got)
+	}
+	if got := es.Err(Errorf("Continue to %s", "x.test")); got != "Continuar a x.test" {
+		t.Errorf("Err() = %q", got)
+	}
+
+	// English and unsupported languages need no catalog
+	var en *Localizer
+	if b.Localizer("en") != nil || b.Localizer("fr") != nil {
+		t.Error("Localizer() for en or an unsupported tag should be nil")
+	}
+	if got := en.N("%d view", "%d views", 1); got != "1 view" {
+		t.Errorf("nil N(1) = %q", got)
+	}
+	if got := en.Date(time.Date(2026, 3, 14, 0, 0, 0, 0, time.UTC)); got != "March 14, 2026" {
+		t.Errorf("nil Date() = %q", got)
+	}
+
+	ru := b.Localizer("ru")
+	for n, want := range map[int]string{1: "1 просмотр", 3: "3 просмотра", 11: "11 просмотров", 22: "22 просмотра", 25: "25 просмотров"} {
+		if got := ru.N("%d view", "%d views", n); got != want {
+			t.Errorf("ru N(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
+
+func TestPlural(t *testing.T) {
+	tests := []struct {
+		lang string
+		n    int
+		want string
+	}{
+		{"en", 0, Other},
+		{"en", 1, One},
+		{"es-MX", 1, One},
+		{"fr", 0, One},
+		{"fr", 2, Other},
+		{"ja", 1, Other},
+		{"ru", 21, One},
+		{"ru", 12, Many},
+		{"uk", 104, Few},
+		{"pl", 21, Many},
+		{"pl", 24, Few},
+		{"cs", 3, Few},
+		{"cs", 5, Other},
+		{"ar", 0, Zero},
+		{"ar", 2, Two},
+		{"ar", 105, Few},
+		{"ar", 111, Many},
+		{"ar", 100, Other},
+		{"en", -1, One},
+	}
+	for _, tt := range tests {
+		if got := Plural(tt.lang, tt.n); got != tt.want {
+			t.Errorf("Plural(%q, %d) = %q, want %q", tt.lang, tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestMatch(t *testing.T) {
+	b := testBundle(t)
+
+	tests := map[string]string{
+		"":                               "en",
+		"es":                             "es",
+		"es-MX,es;q=0.9,en;q=0.8":        "es",
+		"fr-CH, fr;q=0.9, *;q=0.5":       "en",
+		"de;q=0.9, ru;q=0.95":            "ru",
+		"RU-ru":                          "ru",
+		"es;q=0, en":                     "en",
+		"en-US,en;q=0.9,es;q=0.8":        "en",
+		"garbage;;q=x, es;q=0.1":         "es",
+		"en-GB;q=0.2, es-AR;q=0.3, ru-x": "ru",
+	}
+	for header, want := range tests {
+		if got := b.Match(header); got != want {
+			t.Errorf("Match(%q) = %q, want %q", header, got, want)
+		}
+	}
+
+	var none *Bundle
+	if got := none.Match("es"); got != "en" {
+		t.Errorf("nil Match() = %q", got)
+	}
+}
+
+// The shipped catalogs must load
+func TestShippedCatalogs(t *testing.T) {
+	b, err := Load(os.DirFS("../../web/locales"))
+	if err != nil {
+		t.Fatalf("Load(web/locales) error = %v", err)
+	}
+	if !b.Supported("es") {
+		t.Error("web/locales has no Spanish catalog")
+	}
+}
@@ -0,0 +1,128 @@
+package i18n
+
+import "strings"
+
+// Plural categories, as named by CLDR
+const (
+	Zero  = "zero"
+	One   = "one"
+	Two   = "two"
+	Few   = "few"
+	Many  = "many"
+	Other = "other"
+)
+
+func validCategory(c string) bool {
+	switch c {
+	case Zero, One, Two, Few, Many, Other:
+		return true
+	}
+	return false
+}
+
+// pluralRule picks the plural category for a non-negative whole number
+type pluralRule func(n int) string
+
+// pluralRules follows the CLDR cardinal rules for whole numbers. Languages
+// not listed use oneOther, the rule of English.
+var pluralRules = map[string]pluralRule{
+	"fr": zeroOneOther,
+	"pt": zeroOneOther,
+	"hi": zeroOneOther,
+
+	"ja": otherOnly,
+	"ko": otherOnly,
+	"zh": otherOnly,
+	"th": otherOnly,
+	"vi": otherOnly,
+	"id": otherOnly,
+
+	"ru": eastSlavic,
+	"uk": eastSlavic,
+	"be": eastSlavic,
+
+	"pl": polish,
+	"cs": westSlavic,
+	"sk": westSlavic,
+	"ar": arabic,
+}
+
+// Plural returns the plural category lang uses for n
+func Plural(lang string, n int) string {
+	if n < 0 {
+		n = -n
+	}
+	base, _, _ := strings.Cut(lang, "-")
+	if rule, ok := pluralRules[base]; ok {
+		return rule(n)
+	}
+	return oneOther(n)
+}
+
+func oneOther(n int) string {
+	if n == 1 {
+		return One
+	}
+	return Other
+}
+
+func zeroOneOther(n int) string {
+	if n <= 1 {
+		return One
+	}
+	return Other
+}
+
+func otherOnly(int) string {
+	return Other
+}
+
+// eastSlavic is Russian, Ukrainian and Belarusian: 1, 21, 31 are "one";
+// 2-4, 22-24 are "few"; the rest, including 11-14, are "many"
+func eastSlavic(n int) string {
+	switch mod10, mod100 := n%10, n%100; {
+	case mod10 == 1 && mod100 != 11:
+		return One
+	case mod10 >= 2 && mod10 <= 4 && (mod100 < 12 || mod100 > 14):
+		return Few
+	}
+	return Many
+}
+
+// polish is like eastSlavic except only 1 itself is "one"
+func polish(n int) string {
+	switch mod10, mod100 := n%10, n%100; {
+	case n == 1:
+		return One
+	case mod10 >= 2 && mod10 <= 4 && (mod100 < 12 || mod100 > 14):
+		return Few
+	}
+	return Many
+}
+
+// westSlavic is Czech and Slovak
+func westSlavic(n int) string {
+	switch {
+	case n == 1:
+		return One
+	case n >= 2 && n <= 4:
+		return Few
+	}
+	return Other
+}
+
+func arabic(n int) string {
+	switch mod100 := n % 100; {
+	case n == 0:
+		return Zero
+	case n == 1:
+		return One
+	case n == 2:
+		return Two
+	case mod100 >= 3 && mod100 <= 10:
+		return Few
+	case mod100 >= 11:
+		return Many
+	}
+	return Other
+}
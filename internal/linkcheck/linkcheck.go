@@ -0,0 +1,276 @@
+package linkcheck
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"linkbio/internal/model"
+	"linkbio/internal/preview"
+)
+
+// Store is the part of LinkRepository the checker needs
+type Store interface {
+	GetAllActive(ctx context.Context) ([]model.Link, error)
+	UpdateHealth(ctx context.Context, id int64, status int, checkedAt time.Time) error
+}
+
+// Options configures a Checker. Zero values fall back to sensible defaults.
+type Options struct {
+	Interval    time.Duration // time between full passes
+	Timeout     time.Duration // per-request timeout
+	HostDelay   time.Duration // minimum gap between requests to the same host
+	Concurrency int           // parallel requests across different hosts
+	UserAgent   string
+	Client      *http.Client
+	// AllowPrivate permits loopback and private addresses. Tests only; it is
+	// ignored when Client is set.
+	AllowPrivate bool
+}
+
+// Checker periodically checks that active links still resolve
+type Checker struct {
+	store Store
+	log   *slog.Logger
+	opts  Options
+	hosts *hostLimiter
+}
+
+// New creates a new Checker
+func New(store Store, log *slog.Logger, opts Options) *Checker {
+	if opts.Interval <= 0 {
+		opts.Interval = 6 * time.Hour
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 10 * time.Second
+	}
+	if opts.HostDelay <= 0 {
+		opts.HostDelay = time.Second
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 4
+	}
+	if opts.UserAgent == "" {
+		opts.UserAgent = "LinkBio-LinkChecker/1.0"
+	}
+	if opts.Client == nil {
+		opts.Client = newClient(opts)
+	}
+
+	return &Checker{
+		store: store,
+		log:   log,
+		opts:  opts,
+		hosts: newHostLimiter(opts.HostDelay),
+	}
+}
+
+// Run checks all links every Interval until ctx is cancelled
+func (c *Checker) Run(ctx context.Context) {
+	c.log.Info("link checker started", "interval", c.opts.Interval.String())
+
+	ticker := time.NewTicker(c.opts.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := c.CheckAll(ctx); err != nil && ctx.Err() == nil {
+			c.log.Error("link check pass failed", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			c.log.Info("link checker stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// CheckAll checks every active link once and stores the results
+func (c *Checker) CheckAll(ctx context.Context) error {
+	links, err := c.store.GetAllActive(ctx)
+	if err != nil {
+		return err
+	}
+
+	jobs := make(chan model.Link)
+	var wg sync.WaitGroup
+	var broken atomic.Int64
+
+	for i := 0; i < c.opts.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for link := range jobs {
+				status := c.Check(ctx, link.URL)
+				if ctx.Err() != nil {
+					continue
+				}
+				if status == 0 || status >= 400 {
+					broken.Add(1)
+				}
+				if err := c.store.UpdateHealth(ctx, link.ID, status, time.Now()); err != nil {
+					c.log.Error("failed to store link health", "link_id", link.ID, "error", err)
+				}
+			}
+		}()
+	}
+
+	for _, link := range links {
+		if !checkable(link.URL) {
+			continue
+		}
+		select {
+		case jobs <- link:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	c.hosts.prune()
+
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	c.log.Info("link check pass completed", "links", len(links), "broken", broken.Load())
+	return nil
+}
+
+// Check requests a URL and returns its status code, or 0 if the request failed.
+// HEAD is tried first; servers that reject it get a GET instead.
+func (c *Checker) Check(ctx context.Context, rawURL string) int {
+	u, err := url.Parse(rawURL)
+	if err != nil || !checkable(rawURL) {
+		return 0
+	}
+
+	if err := c.hosts.wait(ctx, u.Host); err != nil {
+		return 0
+	}
+	status := c.do(ctx, http.MethodHead, rawURL)
+	if status != 0 && status < 400 {
+		return status
+	}
+
+	if err := c.hosts.wait(ctx, u.Host); err != nil {
+		return 0
+	}
+	return c.do(ctx, http.MethodGet, rawURL)
+}
+
+// do sends a single request and returns the status code (0 on error)
+func (c *Checker) do(ctx context.Context, method, rawURL string) int {
+	ctx, cancel := context.WithTimeout(ctx, c.opts.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, nil)
+	if err != nil {
+		return 0
+	}
+	req.Header.Set("User-Agent", c.opts.UserAgent)
+
+	resp, err := c.opts.Client.Do(req)
+	if err != nil {
+		c.log.Debug("link check failed", "url", rawURL, "method", method, "error", err)
+		return 0
+	}
+	defer resp.Body.Close()
+
+	// Drain a little of the body so the connection can be reused
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	return resp.StatusCode
+}
+
+// newClient builds the default HTTP client. Like link previews, it refuses
+// to dial private addresses so creators can't probe the internal network.
+func newClient(opts Options) *http.Client {
+	dialer := &net.Dialer{Timeout: opts.Timeout}
+	if !opts.AllowPrivate {
+		dialer.Control = preview.DialControl
+	}
+	return &http.Client{
+		Transport: &http.Transport{
+			Proxy:                 nil, // a proxy would dial on our behalf and bypass the check
+			DialContext:           dialer.DialContext,
+			TLSHandshakeTimeout:   opts.Timeout,
+			ResponseHeaderTimeout: opts.Timeout,
+			MaxIdleConns:          opts.Concurrency * 2,
+			IdleConnTimeout:       30 * time.Second,
+		},
+	}
+}
+
+// checkable reports whether a link points at something we can request
+func checkable(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	return (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// hostLimiter spaces out requests to the same host
+type hostLimiter struct {
+	mu    sync.Mutex
+	delay time.Duration
+	next  map[string]time.Time
+}
+
+func newHostLimiter(delay time.Duration) *hostLimiter {
+	return &hostLimiter{
+		delay: delay,
+		next:  make(map[string]time.Time),
+	}
+}
+
+// prune forgets hosts whose next slot has already passed, so the map only
+// holds hosts seen in the current pass
+func (l *hostLimiter) prune() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	for host, at := range l.next {
+		if !at.After(now) {
+			delete(l.next, host)
+		}
+	}
+}
+
+// wait blocks until a request to host is allowed, reserving the slot
+func (l *hostLimiter) wait(ctx context.Context, host string) error {
+	l.mu.Lock()
+	now := time.Now()
+	at := l.next[host]
+	if at.Before(now) {
+		at = now
+	}
+	l.next[host] = at.Add(l.delay)
+	l.mu.Unlock()
+
+	d := time.Until(at)
+	if d <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
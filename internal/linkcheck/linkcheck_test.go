@@ -0,0 +1,194 @@
+package linkcheck
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"linkbio/internal/model"
+	"linkbio/internal/testutil"
+)
+
+// fakeStore records health updates in memory
+type fakeStore struct {
+	mu      sync.Mutex
+	links   []model.Link
+	results map[int64]int
+}
+
+func (s *fakeStore) GetAllActive(ctx context.Context) ([]model.Link, error) {
+	return s.links, nil
+}
+
+func (s *fakeStore) UpdateHealth(ctx context.Context, id int64, status int, checkedAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.results[id] = status
+	return nil
+}
+
+func newTestChecker(store Store) *Checker {
+	return New(store, testutil.TestLogger(), Options{
+		Timeout:      time.Second,
+		HostDelay:    time.Millisecond,
+		AllowPrivate: true,
+	})
+}
+
+func TestChecker_Check_OK(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodHead {
+			t.Errorf("Method = %s, want HEAD", r.Method)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	c := newTestChecker(&fakeStore{})
+	if status := c.Check(context.Background(), srv.URL); status != http.StatusOK {
+		t.Errorf("Check() = %d, want %d", status, http.StatusOK)
+	}
+}
+
+func TestChecker_Check_FallsBackToGet(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	c := newTestChecker(&fakeStore{})
+	if status := c.Check(context.Background(), srv.URL); status != http.StatusOK {
+		t.Errorf("Check() = %d, want %d", status, http.StatusOK)
+	}
+}
+
+func TestChecker_Check_NotFound(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+
+	c := newTestChecker(&fakeStore{})
+	if status := c.Check(context.Background(), srv.URL+"/gone"); status != http.StatusNotFound {
+		t.Errorf("Check() = %d, want %d", status, http.StatusNotFound)
+	}
+}
+
+func TestChecker_Check_Timeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer srv.Close()
+
+	c := New(&fakeStore{}, testutil.TestLogger(), Options{
+		Timeout:      20 * time.Millisecond,
+		HostDelay:    time.Millisecond,
+		AllowPrivate: true,
+	})
+	if status := c.Check(context.Background(), srv.URL); status != 0 {
+		t.Errorf("Check() = %d, want 0 for timed out request", status)
+	}
+}
+
+func TestChecker_Check_BlocksPrivateAddresses(t *testing.T) {
+	var hits atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+	}))
+	defer srv.Close()
+
+	c := New(&fakeStore{}, testutil.TestLogger(), Options{Timeout: time.Second, HostDelay: time.Millisecond})
+	if status := c.Check(context.Background(), srv.URL); status != 0 {
+		t.Errorf("Check() = %d, want 0 for a loopback address", status)
+	}
+	if hits.Load() != 0 {
+		t.Errorf("server got %d requests, want none", hits.Load())
+	}
+}
+
+func TestChecker_CheckAll(t *testing.T) {
+	ok := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ok.Close()
+	gone := httptest.NewServer(http.NotFoundHandler())
+	defer gone.Close()
+
+	store := &fakeStore{
+		links: []model.Link{
+			{ID: 1, URL: ok.URL},
+			{ID: 2, URL: gone.URL},
+			{ID: 3, URL: "mailto:someone@example.com"},
+		},
+		results: make(map[int64]int),
+	}
+
+	c := newTestChecker(store)
+	if err := c.CheckAll(context.Background()); err != nil {
+		t.Fatalf("CheckAll() error = %v", err)
+	}
+
+	if store.results[1] != http.StatusOK {
+		t.Errorf("link 1 status = %d, want %d", store.results[1], http.StatusOK)
+	}
+	if store.results[2] != http.StatusNotFound {
+		t.Errorf("link 2 status = %d, want %d", store.results[2], http.StatusNotFound)
+	}
+	if _, checked := store.results[3]; checked {
+		t.Error("mailto link should not be checked")
+	}
+}
+
+func TestChecker_HostRateLimit(t *testing.T) {
+	var hits atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+	}))
+	defer srv.Close()
+
+	store := &fakeStore{
+		links: []model.Link{
+			{ID: 1, URL: srv.URL + "/a"},
+			{ID: 2, URL: srv.URL + "/b"},
+			{ID: 3, URL: srv.URL + "/c"},
+		},
+		results: make(map[int64]int),
+	}
+
+	delay := 50 * time.Millisecond
+	c := New(store, testutil.TestLogger(), Options{Timeout: time.Second, HostDelay: delay, AllowPrivate: true})
+
+	// The third request can't start until two full gaps have passed, however
+	// long each connection takes to reach the server
+	start := time.Now()
+	if err := c.CheckAll(context.Background()); err != nil {
+		t.Fatalf("CheckAll() error = %v", err)
+	}
+	elapsed := time.Since(start)
+
+	if hits.Load() != 3 {
+		t.Fatalf("server got %d requests, want 3", hits.Load())
+	}
+	if elapsed < 2*delay {
+		t.Errorf("CheckAll() took %v, want at least %v for three requests to one host", elapsed, 2*delay)
+	}
+}
+
+func TestHostLimiter_Prune(t *testing.T) {
+	l := newHostLimiter(time.Hour)
+	l.next["old.example"] = time.Now().Add(-time.Minute)
+	l.next["busy.example"] = time.Now().Add(time.Minute)
+
+	l.prune()
+
+	if _, ok := l.next["old.example"]; ok {
+		t.Error("prune() kept a host whose slot has passed")
+	}
+	if _, ok := l.next["busy.example"]; !ok {
+		t.Error("prune() dropped a host that is still being spaced out")
+	}
+}
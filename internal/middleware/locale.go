@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"net/http"
+
+	"linkbio/internal/i18n"
+)
+
+// LangCookie remembers a language picked with the ?lang= query parameter
+const LangCookie = "lang"
+
+// Locale picks the language pages and messages are shown in: a ?lang=
+// override, which is remembered in a cookie, then the signed-in user's
+// setting, then the browser's Accept-Language
+func (m *Middleware) Locale(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := i18n.NewContext(r.Context(), m.locales.Localizer(m.locale(w, r)))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// locale negotiates the request's language tag
+func (m *Middleware) locale(w http.ResponseWriter, r *http.Request) string {
+	if lang := r.URL.Query().Get("lang"); lang != "" && m.locales.Supported(lang) {
+		http.SetCookie(w, &http.Cookie{
+			Name:     LangCookie,
+			Value:    lang,
+			Path:     "/",
+			MaxAge:   86400 * 365,
+			HttpOnly: true,
+			SameSite: http.SameSiteLaxMode,
+		})
+		return lang
+	}
+
+	if c, err := r.Cookie(LangCookie); err == nil && m.locales.Supported(c.Value) {
+		return c.Value
+	}
+
+	if session, err := m.store.Get(r, "session"); err == nil {
+		if lang, _ := session.Values["locale"].(string); lang != "" && m.locales.Supported(lang) {
+			return lang
+		}
+	}
+
+	return m.locales.Match(r.Header.Get("Accept-Language"))
+}
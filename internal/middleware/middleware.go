@@ -4,6 +4,8 @@ import (
 	"log/slog"
 	"net/http"
 
+	"linkbio/internal/i18n"
+
 	"github.com/gorilla/sessions"
 )
 
@@ -11,10 +13,11 @@ import (
 type Middleware struct {
 	log     *slog.Logger
 	store   *sessions.CookieStore
+	locales *i18n.Bundle
 }
 
 // New creates a new Middleware instance
-func New(log *slog.Logger, sessionSecret, encryptionKey string) *Middleware {
+func New(log *slog.Logger, sessionSecret, encryptionKey string, locales *i18n.Bundle) *Middleware {
 	var store *sessions.CookieStore
 	if len(encryptionKey) == 32 {
 		store = sessions.NewCookieStore([]byte(sessionSecret), []byte(encryptionKey))
@@ -32,8 +35,9 @@ func New(log *slog.Logger, sessionSecret, encryptionKey string) *Middleware {
 	}
 
 	return &Middleware{
-		log:   log,
-		store: store,
+		log:     log,
+		store:   store,
+		locales: locales,
 	}
 }
 
@@ -1,13 +1,43 @@
 package model
 
-import "time"
+import (
+	"time"
+
+	"linkbio/internal/social"
+)
+
+// Traffic sources recorded from the src query parameter. Anything else is
+// stored as direct traffic ("").
+const (
+	SourceQR    = "qr"    // scanned from a printed QR code
+	SourceEmbed = "embed" // the /embed widget on another site
+	SourceFeed  = "feed"  // an entry in the profile's Atom or JSON feed
+)
+
+// knownSources maps each accepted source to its dashboard label
+var knownSources = map[string]string{
+	SourceQR:    "QR code",
+	SourceEmbed: "Embedded widget",
+	SourceFeed:  "Feed reader",
+}
+
+// NormalizeSource returns src if it is a known traffic source, or ""
+func NormalizeSource(src string) string {
+	if _, ok := knownSources[src]; ok {
+		return src
+	}
+	return ""
+}
 
 // Analytics represents a tracking event
 type Analytics struct {
 	ID        int64     `json:"id"`
 	UserID    int64     `json:"user_id"`
-	LinkID    *int64    `json:"link_id,omitempty"` // nil for page views
-	EventType string    `json:"event_type"`        // "page_view" or "link_click"
+	LinkID    *int64    `json:"link_id,omitempty"`  // nil for page views
+	EventType string    `json:"event_type"`         // "page_view", "link_click", "interstitial_view" or "social_click"
+	Source    string    `json:"source,omitempty"`   // e.g. "qr"; empty for direct traffic
+	Platform  string    `json:"platform,omitempty"` // social icon clicks only
+	PageID    *int64    `json:"page_id,omitempty"`  // the sub-page viewed or clicked on; nil for the main page
 	Referrer  string    `json:"referrer"`
 	UserAgent string    `json:"user_agent"`
 	CreatedAt time.Time `json:"created_at"`
@@ -15,9 +45,14 @@ type Analytics struct {
 
 // AnalyticsSummary holds aggregated analytics data
 type AnalyticsSummary struct {
-	TotalViews  int `json:"total_views"`
-	TotalClicks int `json:"total_clicks"`
+	TotalViews  int              `json:"total_views"`
+	TotalClicks int              `json:"total_clicks"`
 	LinkClicks  []LinkClickCount `json:"link_clicks"`
+	Sources     []SourceCount    `json:"sources"`  // non-direct traffic only
+	Socials     []SocialCount    `json:"socials"`  // social icon clicks per platform
+	Warnings    []WarningCount   `json:"warnings"` // sensitive link warnings shown
+	Pages       []PageCount      `json:"pages"`    // views and clicks per page, main page first
+	Tags        []TagCount       `json:"tags"`     // clicks on the links carrying each tag
 }
 
 // LinkClickCount holds click count for a specific link
@@ -26,3 +61,67 @@ type LinkClickCount struct {
 	Title  string `json:"title"`
 	Clicks int    `json:"clicks"`
 }
+
+// WarningCount holds how often a sensitive link's warning was shown and how
+// often visitors continued past it
+type WarningCount struct {
+	LinkID    int64  `json:"link_id"`
+	Title     string `json:"title"`
+	Views     int    `json:"views"`
+	Continued int    `json:"continued"`
+}
+
+// PageCount holds the views of one page and the clicks on the links it
+// shows. PageID is nil for the main profile page.
+type PageCount struct {
+	PageID *int64 `json:"page_id,omitempty"`
+	Title  string `json:"title"`
+	Views  int    `json:"views"`
+	Clicks int    `json:"clicks"`
+}
+
+// Label names the page for the dashboard
+func (p PageCount) Label() string {
+	if p.PageID == nil {
+		return "Main page"
+	}
+	return p.Title
+}
+
+// TagCount holds clicks on the links that carry a tag now, so a tag added
+// later counts their earlier clicks too
+type TagCount struct {
+	TagID  int64  `json:"tag_id"`
+	Name   string `json:"name"`
+	Color  string `json:"color"`
+	Clicks int    `json:"clicks"`
+}
+
+// SourceCount holds views and clicks that arrived through one source
+type SourceCount struct {
+	Source string `json:"source"`
+	Views  int    `json:"views"`
+	Clicks int    `json:"clicks"`
+}
+
+// Label is the human-readable name of the source
+func (s SourceCount) Label() string {
+	if label, ok := knownSources[s.Source]; ok {
+		return label
+	}
+	return s.Source
+}
+
+// SocialCount holds clicks on one platform's social icon
+type SocialCount struct {
+	Platform string `json:"platform"`
+	Clicks   int    `json:"clicks"`
+}
+
+// Label is the platform's display name
+func (s SocialCount) Label() string {
+	if p, ok := social.Lookup(s.Platform); ok {
+		return p.Name
+	}
+	return s.Platform
+}
@@ -0,0 +1,68 @@
+package model
+
+import "errors"
+
+// Category is a tag creators file their profile under in the explore
+// directory
+type Category struct {
+	Slug string
+	Name string // English; templates translate it
+}
+
+// Categories lists the directory's categories in display order
+var Categories = []Category{
+	{"art", "Art & design"},
+	{"music", "Music"},
+	{"writing", "Writing"},
+	{"video", "Video & streaming"},
+	{"tech", "Tech"},
+	{"business", "Business"},
+	{"education", "Education"},
+	{"fitness", "Health & fitness"},
+	{"food", "Food & drink"},
+	{"fashion", "Fashion & beauty"},
+	{"gaming", "Gaming"},
+	{"travel", "Travel"},
+	{"nonprofit", "Nonprofit"},
+}
+
+// CategoryName returns the name of the category with slug, or "" if there
+// is none
+func CategoryName(slug string) string {
+	for _, c := range Categories {
+		if c.Slug == slug {
+			return c.Name
+		}
+	}
+	return ""
+}
+
+// DirectoryProfile is one published profile listed in the explore
+// directory
+type DirectoryProfile struct {
+	Username    string
+	DisplayName string
+	Bio         string
+	AvatarURL   string
+	Category    string
+}
+
+// CategoryName is the name of the profile's category, "" if it has none
+func (p DirectoryProfile) CategoryName() string {
+	return CategoryName(p.Category)
+}
+
+// DirectoryRequest is the input for the explore directory settings
+type DirectoryRequest struct {
+	Listed   bool   `json:"listed"`
+	Category string `json:"category"` // empty for none
+}
+
+// Validate checks the request and returns a message suitable for showing
+// to the user
+func (d *DirectoryRequest) Validate() error {
+	if d.Category != "" && CategoryName(d.Category) == "" {
+		return errors.New("Choose one of the categories")
+	}
+	return nil
+}
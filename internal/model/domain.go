@@ -0,0 +1,22 @@
+package model
+
+import "time"
+
+// CustomDomain is a hostname a user wants to serve their profile on. It is
+// only routed once VerifiedAt is set.
+type CustomDomain struct {
+	ID         int64      `json:"id"`
+	UserID     int64      `json:"user_id"`
+	Domain     string     `json:"domain"`
+	Token      string     `json:"-"`
+	VerifiedAt *time.Time `json:"verified_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+
+	// Username is filled in by lookups that join the owner
+	Username string `json:"-"`
+}
+
+// IsVerified reports whether DNS ownership has been confirmed
+func (d CustomDomain) IsVerified() bool {
+	return d.VerifiedAt != nil
+}
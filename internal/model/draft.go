@@ -0,0 +1,19 @@
+package model
+
+import "time"
+
+// DraftStatus compares a creator's working copy with the published profile
+// visitors see. Links, pages and the display name, bio and theme are
+// drafted; the avatar, username, search visibility, languages and social
+// icons apply immediately.
+type DraftStatus struct {
+	LinkChanges    int        // links added, edited, moved or removed
+	PageChanges    int        // pages added, reordered or removed
+	ProfileChanged bool       // display name, bio or theme edited
+	PublishedAt    *time.Time // last publish, nil if never
+}
+
+// HasChanges reports whether there is anything to publish
+func (s DraftStatus) HasChanges() bool {
+	return s.LinkChanges > 0 || s.PageChanges > 0 || s.ProfileChanged
+}
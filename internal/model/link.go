@@ -1,21 +1,112 @@
 package model
 
-import "time"
+import (
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Link kinds. A plain link redirects to its URL; the others are files the
+// server builds when a visitor clicks.
+const (
+	LinkKindURL     = "url"
+	LinkKindContact = "contact" // a vCard of the creator's profile
+	LinkKindEvent   = "event"   // an iCalendar event
+)
+
+// Link status filters for the dashboard's link search
+const (
+	LinkStatusActive   = "active"
+	LinkStatusInactive = "inactive" // shown as hidden in the dashboard
+)
+
+// MaxLocationLength limits an event's location, in characters
+const MaxLocationLength = 200
 
 // Link represents a user's link
 type Link struct {
-	ID        int64     `json:"id"`
-	UserID    int64     `json:"user_id"`
-	Title     string    `json:"title"`
-	URL       string    `json:"url"`
-	Icon      string    `json:"icon"`
-	Position  int       `json:"position"`
-	IsActive  bool      `json:"is_active"`
-	CreatedAt time.Time `json:"created_at"`
+	ID            int64      `json:"id"`
+	UserID        int64      `json:"user_id"`
+	PageID        *int64     `json:"page_id,omitempty"` // nil for the main profile page
+	Kind          string     `json:"kind"`              // one of the LinkKind constants
+	Title         string     `json:"title"`
+	URL           string     `json:"url"` // empty for contact and event links
+	Icon          string     `json:"icon"`
+	Description   string     `json:"description"` // filled from og:description
+	ImageURL      string     `json:"image_url"`   // og:image, shown on featured cards
+	Position      int        `json:"position"`
+	IsActive      bool       `json:"is_active"`
+	IsFeatured    bool       `json:"is_featured"`
+	IsSensitive   bool       `json:"is_sensitive"`              // visitors confirm a warning before leaving
+	StartsAt      *time.Time `json:"starts_at,omitempty"`       // events only, UTC
+	EndsAt        *time.Time `json:"ends_at,omitempty"`         // events only, UTC; nil when open-ended
+	Location      string     `json:"location,omitempty"`        // events only
+	TimeZone      string     `json:"time_zone,omitempty"`       // events only, IANA name the times were entered in
+	LastStatus    int        `json:"last_status"`               // 0 when the last check failed to connect
+	LastCheckedAt *time.Time `json:"last_checked_at,omitempty"` // nil until the health checker has run
+	PublishedAt   *time.Time `json:"published_at,omitempty"`    // nil while the link only exists in the draft
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// IsBroken reports whether the last health check failed
+func (l Link) IsBroken() bool {
+	return l.LastCheckedAt != nil && (l.LastStatus == 0 || l.LastStatus >= 400)
+}
+
+// IconIsImage reports whether Icon is an image URL, uploaded or hosted
+// elsewhere, rather than an emoji
+func (l Link) IconIsImage() bool {
+	return strings.HasPrefix(l.Icon, "http") || strings.HasPrefix(l.Icon, "/media/")
+}
+
+// IsDownload reports whether clicking the link downloads a file built from
+// the profile instead of redirecting
+func (l Link) IsDownload() bool {
+	return l.Kind == LinkKindContact || l.Kind == LinkKindEvent
+}
+
+// Zone is the time zone the event was entered in, UTC if unknown
+func (l Link) Zone() *time.Location {
+	if loc, err := time.LoadLocation(l.TimeZone); err == nil {
+		return loc
+	}
+	return time.UTC
+}
+
+// LocalStart is the event's start in its own time zone
+func (l Link) LocalStart() time.Time {
+	if l.StartsAt == nil {
+		return time.Time{}
+	}
+	return l.StartsAt.In(l.Zone())
+}
+
+// LocalEnd is the event's end in its own time zone
+func (l Link) LocalEnd() time.Time {
+	if l.EndsAt == nil {
+		return time.Time{}
+	}
+	return l.EndsAt.In(l.Zone())
+}
+
+// OnPage reports whether the link is on the page with ID pageID, nil
+// meaning the main page
+func (l Link) OnPage(pageID *int64) bool {
+	if l.PageID == nil || pageID == nil {
+		return l.PageID == nil && pageID == nil
+	}
+	return *l.PageID == *pageID
+}
+
+// IsPublished reports whether visitors can see a version of the link
+func (l Link) IsPublished() bool {
+	return l.PublishedAt != nil
 }
 
 // LinkCreateRequest is the input for creating a link
 type LinkCreateRequest struct {
+	Kind  string `json:"kind"` // LinkKindURL when empty
 	Title string `json:"title"`
 	URL   string `json:"url"`
 	Icon  string `json:"icon"`
@@ -23,8 +114,63 @@ type LinkCreateRequest struct {
 
 // LinkUpdateRequest is the input for updating a link
 type LinkUpdateRequest struct {
-	Title    string `json:"title"`
-	URL      string `json:"url"`
-	Icon     string `json:"icon"`
-	IsActive bool   `json:"is_active"`
+	Title       string `json:"title"`
+	URL         string `json:"url"`
+	Icon        string `json:"icon"`
+	IsActive    bool   `json:"is_active"`
+	IsFeatured  bool   `json:"is_featured"`
+	IsSensitive bool   `json:"is_sensitive"`
+}
+
+// EventRequest is the form input for an event link. Start and End are
+// datetime-local values ("2006-01-02T15:04") in TimeZone.
+type EventRequest struct {
+	Start    string
+	End      string // optional
+	Location string
+	TimeZone string // IANA name; UTC when empty
+}
+
+// datetimeLocal is the layout of an HTML datetime-local input
+const datetimeLocal = "2006-01-02T15:04"
+
+// Apply validates the request and stores the event details on link. It
+// returns a message suitable for showing to the user.
+func (e EventRequest) Apply(link *Link) error {
+	loc := time.UTC
+	if tz := strings.TrimSpace(e.TimeZone); tz != "" {
+		var err error
+		if loc, err = time.LoadLocation(tz); err != nil {
+			return errors.New("Choose a valid time zone")
+		}
+	}
+
+	start, err := time.ParseInLocation(datetimeLocal, strings.TrimSpace(e.Start), loc)
+	if err != nil {
+		return errors.New("Enter when the event starts")
+	}
+	var end *time.Time
+	if v := strings.TrimSpace(e.End); v != "" {
+		t, err := time.ParseInLocation(datetimeLocal, v, loc)
+		if err != nil {
+			return errors.New("Enter a valid end time")
+		}
+		if !t.After(start) {
+			return errors.New("The event must end after it starts")
+		}
+		t = t.UTC()
+		end = &t
+	}
+
+	location := strings.TrimSpace(e.Location)
+	if utf8.RuneCountInString(location) > MaxLocationLength {
+		return errors.New("Location must be 200 characters or fewer")
+	}
+
+	start = start.UTC()
+	link.StartsAt = &start
+	link.EndsAt = end
+	link.Location = location
+	link.TimeZone = loc.String()
+	return nil
 }
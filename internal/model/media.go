@@ -0,0 +1,21 @@
+package model
+
+import "time"
+
+// Media records that a user (and optionally one of their links) owns a
+// stored blob. Several rows may share a key since keys are content-addressed.
+type Media struct {
+	ID          int64     `json:"id"`
+	UserID      int64     `json:"user_id"`
+	LinkID      *int64    `json:"link_id,omitempty"`
+	Kind        string    `json:"kind"` // e.g. "avatar"
+	Key         string    `json:"key"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// URL is the public path the blob is served from
+func (m Media) URL() string {
+	return "/media/" + m.Key
+}
@@ -0,0 +1,110 @@
+package model
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Page is a sub-page of a profile, such as /u/alice/merch, with its own
+// title and links. The main profile page is not a Page; its links have no
+// PageID. Pages are drafted like links: adding, reordering and removing
+// one reaches visitors when the creator publishes.
+type Page struct {
+	ID        int64     `json:"id"`
+	UserID    int64     `json:"user_id"`
+	Slug      string    `json:"slug"` // the last path segment
+	Title     string    `json:"title"`
+	Position  int       `json:"position"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Page limits; lengths are counted in characters
+const (
+	MaxPages           = 10
+	MaxPageTitleLength = 30
+	MaxPageSlugLength  = 30
+)
+
+// reservedPageSlugs are taken by routes under a profile's address
+var reservedPageSlugs = map[string]bool{
+	"click":  true,
+	"media":  true,
+	"static": true,
+	"unlock": true,
+}
+
+// PageCreateRequest is the input for adding a page
+type PageCreateRequest struct {
+	Title string `json:"title"`
+	Slug  string `json:"slug"` // derived from Title when empty
+}
+
+// Validate checks the request and returns a message suitable for showing
+// to the user. It fills in Slug from the title when none was given.
+func (p *PageCreateRequest) Validate() error {
+	p.Title = strings.TrimSpace(p.Title)
+	p.Slug = strings.ToLower(strings.TrimSpace(p.Slug))
+
+	switch {
+	case p.Title == "":
+		return errors.New("Page title is required")
+	case utf8.RuneCountInString(p.Title) > MaxPageTitleLength:
+		return errors.New("Page title must be 30 characters or fewer")
+	case strings.ContainsAny(p.Title, "\r\n"):
+		return errors.New("Page title must be a single line")
+	}
+
+	if p.Slug == "" {
+		p.Slug = slugify(p.Title)
+		if p.Slug == "" {
+			return errors.New("Choose an address for the page")
+		}
+	}
+	switch {
+	case len(p.Slug) > MaxPageSlugLength:
+		return errors.New("Page address must be 30 characters or fewer")
+	case !validSlug(p.Slug):
+		return errors.New("Page addresses can only use letters a-z, numbers and single hyphens")
+	case reservedPageSlugs[p.Slug]:
+		return errors.New("That page address is reserved")
+	}
+	return nil
+}
+
+// validSlug accepts runs of a-z and 0-9 joined by single hyphens
+func validSlug(s string) bool {
+	if s == "" || s[0] == '-' || s[len(s)-1] == '-' || strings.Contains(s, "--") {
+		return false
+	}
+	for _, r := range s {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '-' {
+			return false
+		}
+	}
+	return true
+}
+
+// slugify turns a title into a page address, dropping anything that isn't
+// a-z or 0-9 and joining the words with hyphens
+func slugify(title string) string {
+	var b strings.Builder
+	hyphen := false
+	for _, r := range strings.ToLower(title) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			if hyphen && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			hyphen = false
+			continue
+		}
+		hyphen = true
+	}
+	s := b.String()
+	if len(s) > MaxPageSlugLength {
+		s = strings.TrimRight(s[:MaxPageSlugLength], "-")
+	}
+	return s
+}
@@ -0,0 +1,33 @@
+package model
+
+import (
+	"time"
+
+	"linkbio/internal/social"
+)
+
+// SocialProfile is one icon in the row under a creator's bio. Each user has
+// at most one per platform.
+type SocialProfile struct {
+	ID        int64     `json:"id"`
+	UserID    int64     `json:"user_id"`
+	Platform  string    `json:"platform"` // a social.Platforms ID
+	Handle    string    `json:"handle"`   // bare handle, or the address for email
+	Position  int       `json:"position"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Info describes the profile's platform
+func (s SocialProfile) Info() social.Platform {
+	p, _ := social.Lookup(s.Platform)
+	return p
+}
+
+// URL is where the icon points
+func (s SocialProfile) URL() string {
+	p, ok := social.Lookup(s.Platform)
+	if !ok {
+		return ""
+	}
+	return p.URL(s.Handle)
+}
@@ -0,0 +1,76 @@
+package model
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Tag is a creator's label for links, such as "sponsored" or "seasonal",
+// for finding, bulk-editing and comparing them in the dashboard. Tags are
+// never shown on the profile and apply immediately.
+type Tag struct {
+	ID        int64     `json:"id"`
+	UserID    int64     `json:"user_id"`
+	Name      string    `json:"name"` // unique per user, ignoring case
+	Color     string    `json:"color"`
+	Links     int       `json:"links"` // links carrying the tag, when listed
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Tag limits; lengths are counted in characters
+const (
+	MaxTags          = 50
+	MaxTagNameLength = 30
+)
+
+// TagColor is a color a tag can have. Slug is a Tailwind color name.
+type TagColor struct {
+	Slug string
+	Name string // English; templates translate it
+}
+
+// TagColors lists the tag colors in picker order. The first is the default.
+var TagColors = []TagColor{
+	{"gray", "Gray"},
+	{"red", "Red"},
+	{"orange", "Orange"},
+	{"amber", "Amber"},
+	{"green", "Green"},
+	{"teal", "Teal"},
+	{"sky", "Sky blue"},
+	{"indigo", "Indigo"},
+	{"purple", "Purple"},
+	{"pink", "Pink"},
+}
+
+// TagRequest is the input for adding or editing a tag
+type TagRequest struct {
+	Name  string `json:"name"`
+	Color string `json:"color"` // the default color when empty
+}
+
+// Validate checks the request and returns a message suitable for showing
+// to the user
+func (t *TagRequest) Validate() error {
+	t.Name = strings.TrimSpace(t.Name)
+	if t.Color == "" {
+		t.Color = TagColors[0].Slug
+	}
+
+	switch {
+	case t.Name == "":
+		return errors.New("Tag name is required")
+	case utf8.RuneCountInString(t.Name) > MaxTagNameLength:
+		return errors.New("Tag name must be 30 characters or fewer")
+	case strings.ContainsAny(t.Name, "\r\n"):
+		return errors.New("Tag name must be a single line")
+	}
+	for _, c := range TagColors {
+		if t.Color == c.Slug {
+			return nil
+		}
+	}
+	return errors.New("Choose one of the tag colors")
+}
@@ -1,16 +1,144 @@
 package model
 
-import "time"
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"linkbio/internal/theme"
+)
 
 // User represents a registered user
 type User struct {
-	ID           int64     `json:"id"`
-	Username     string    `json:"username"`
-	Email        string    `json:"email"`
-	PasswordHash string    `json:"-"`
-	DisplayName  string    `json:"display_name"`
-	Bio          string    `json:"bio"`
-	AvatarURL    string    `json:"avatar_url"`
-	Theme        string    `json:"theme"`
-	CreatedAt    time.Time `json:"created_at"`
+	ID           int64  `json:"id"`
+	Username     string `json:"username"`
+	Email        string `json:"email"`
+	PasswordHash string `json:"-"`
+	DisplayName  string `json:"display_name"`
+	Bio          string `json:"bio"`
+	AvatarURL    string `json:"avatar_url"`
+	Theme        string `json:"theme"`
+	ThemeConfig  string `json:"theme_config,omitempty"`
+	// HideFromSearch asks search engines not to index the profile
+	HideFromSearch bool `json:"hide_from_search"`
+	// ProfilePasswordHash is set when visitors need a password to see the
+	// profile
+	ProfilePasswordHash string `json:"-"`
+	// Locale is the language of the dashboard and ProfileLocale that of
+	// the public profile's buttons and notices. Empty follows the
+	// browser's language.
+	Locale        string `json:"locale"`
+	ProfileLocale string `json:"profile_locale"`
+	// Listed opts the profile into the explore directory under Category
+	// (empty for none). Unlisted and password-protected profiles are never
+	// shown there.
+	Listed    bool      `json:"listed"`
+	Category  string    `json:"category"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Profile visibility modes
+const (
+	VisibilityPublic   = "public"
+	VisibilityUnlisted = "unlisted" // hidden from search and the sitemap
+	VisibilityPassword = "password" // unlisted, and visitors need the password
+)
+
+// Visibility reports the profile's visibility mode
+func (u *User) Visibility() string {
+	switch {
+	case u.ProfilePasswordHash != "":
+		return VisibilityPassword
+	case u.HideFromSearch:
+		return VisibilityUnlisted
+	}
+	return VisibilityPublic
+}
+
+// Profile field limits, counted in characters
+const (
+	MaxDisplayNameLength = 50
+	MaxBioLength         = 160
+	MaxAvatarURLLength   = 2048
+
+	MinProfilePasswordLength = 4
+	MaxProfilePasswordLength = 72 // bcrypt's limit, in bytes
+)
+
+// ProfileUpdateRequest is the input for editing a profile
+type ProfileUpdateRequest struct {
+	DisplayName string `json:"display_name"`
+	Bio         string `json:"bio"`
+	AvatarURL   string `json:"avatar_url"`
+	Theme       string `json:"theme"`
+
+	// Visibility is one of the Visibility* modes. ProfilePassword sets a new
+	// password for VisibilityPassword; empty keeps the current one.
+	Visibility      string `json:"visibility"`
+	ProfilePassword string `json:"profile_password"`
+
+	// CustomTheme is required when Theme is theme.Custom
+	CustomTheme *theme.Theme `json:"custom_theme,omitempty"`
+}
+
+// Validate checks the request and returns a message suitable for showing
+// to the user. currentAvatar is accepted as-is so uploaded avatars, which
+// live under /media/, survive a save.
+func (p *ProfileUpdateRequest) Validate(currentAvatar string) error {
+	p.DisplayName = strings.TrimSpace(p.DisplayName)
+	p.Bio = strings.TrimSpace(p.Bio)
+	p.AvatarURL = strings.TrimSpace(p.AvatarURL)
+
+	switch {
+	case p.DisplayName == "":
+		return errors.New("Display name is required")
+	case utf8.RuneCountInString(p.DisplayName) > MaxDisplayNameLength:
+		return errors.New("Display name must be 50 characters or fewer")
+	case strings.ContainsAny(p.DisplayName, "\r\n"):
+		return errors.New("Display name must be a single line")
+	case utf8.RuneCountInString(p.Bio) > MaxBioLength:
+		return errors.New("Bio must be 160 characters or fewer")
+	}
+
+	if p.AvatarURL != "" && p.AvatarURL != currentAvatar && !validAvatarURL(p.AvatarURL) {
+		return errors.New("Avatar URL must be an http(s) link to an image")
+	}
+
+	switch p.Visibility {
+	case VisibilityPublic, VisibilityUnlisted:
+	case VisibilityPassword:
+		if p.ProfilePassword != "" && utf8.RuneCountInString(p.ProfilePassword) < MinProfilePasswordLength {
+			return errors.New("Profile password must be at least 4 characters")
+		}
+		if len(p.ProfilePassword) > MaxProfilePasswordLength {
+			return errors.New("Profile password is too long")
+		}
+	default:
+		return errors.New("Choose who can see your profile")
+	}
+
+	if !theme.Valid(p.Theme) {
+		return errors.New("Choose one of the available themes")
+	}
+	if p.Theme == theme.Custom {
+		if p.CustomTheme == nil {
+			return errors.New("Custom theme settings are missing")
+		}
+		return p.CustomTheme.Validate()
+	}
+	return nil
+}
+
+// validAvatarURL accepts absolute http(s) URLs without credentials
+func validAvatarURL(raw string) bool {
+	if len(raw) > MaxAvatarURLLength {
+		return false
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" || u.User != nil {
+		return false
+	}
+	return u.Scheme == "https" || u.Scheme == "http"
 }
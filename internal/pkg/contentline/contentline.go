@@ -0,0 +1,47 @@
+// Package contentline writes the content lines shared by the vCard
+// (RFC 2426) and iCalendar (RFC 5545) formats: text values escaped and
+// long lines folded.
+package contentline
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// maxLine is the longest a line may be in octets, not counting the CRLF
+const maxLine = 75
+
+// escaper backslash-escapes the characters a text value can't hold as is
+var escaper = strings.NewReplacer(
+	`\`, `\\`,
+	",", `\,`,
+	";", `\;`,
+	"\n", `\n`,
+	"\r", `\n`,
+)
+
+// Escape escapes a text value: backslashes, commas and semicolons are
+// backslash-escaped and line breaks become \n
+func Escape(s string) string {
+	return escaper.Replace(strings.ReplaceAll(s, "\r\n", "\n"))
+}
+
+// Write writes name:value as one content line, breaking it every 75 octets
+// with CRLF and a space. Breaks never split a UTF-8 sequence. value must
+// already be escaped where the property needs it.
+func Write(b *strings.Builder, name, value string) {
+	line := name + ":" + value
+	limit := maxLine
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		limit = maxLine - 1 // the leading space counts
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+}
@@ -0,0 +1,58 @@
+package contentline
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestEscape(t *testing.T) {
+	tests := map[string]string{
+		`plain`:        `plain`,
+		`C:\path`:      `C:\\path`,
+		"one\r\ntwo":   `one\ntwo`,
+		"one\rtwo":     `one\ntwo`,
+		`a,b;c`:        `a\,b\;c`,
+		`\,`:           `\\\,`,
+		`"quoted"`:     `"quoted"`,
+		"Zoë · Café ☕": "Zoë · Café ☕",
+	}
+	for in, want := range tests {
+		if got := Escape(in); got != want {
+			t.Errorf("Escape(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestWrite(t *testing.T) {
+	var b strings.Builder
+	Write(&b, "SHORT", "value")
+	if b.String() != "SHORT:value\r\n" {
+		t.Errorf("short line = %q", b.String())
+	}
+
+	// Multi-byte characters straddle every 75-octet boundary
+	b.Reset()
+	value := strings.Repeat("日本語のメモ", 40)
+	Write(&b, "NOTE", value)
+	out := b.String()
+	if !strings.HasSuffix(out, "\r\n") {
+		t.Fatal("line must end in CRLF")
+	}
+	unfolded := ""
+	for i, line := range strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n") {
+		if len(line) > 75 {
+			t.Errorf("line is %d octets: %q", len(line), line)
+		}
+		if !utf8.ValidString(line) {
+			t.Errorf("line splits a character: %q", line)
+		}
+		if i > 0 {
+			line = strings.TrimPrefix(line, " ")
+		}
+		unfolded += line
+	}
+	if unfolded != "NOTE:"+value {
+		t.Errorf("unfolded = %q", unfolded)
+	}
+}
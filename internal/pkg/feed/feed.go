@@ -0,0 +1,184 @@
+// Package feed writes a list of items as an Atom feed (RFC 4287) and as a
+// JSON Feed (https://jsonfeed.org/version/1.1), so feed readers can follow
+// a profile's links.
+package feed
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"time"
+)
+
+// Media types of the two formats
+const (
+	AtomContentType = "application/atom+xml; charset=utf-8"
+	JSONContentType = "application/feed+json; charset=utf-8"
+)
+
+// generator names the program in Atom feeds
+const generator = "LinkBio"
+
+// Feed is a titled list of items, newest first
+type Feed struct {
+	ID        string // permanent, e.g. a tag URI from TagURI
+	Title     string
+	Subtitle  string
+	HomeURL   string // the page the feed belongs to
+	Author    string
+	AuthorURL string
+	Icon      string // absolute URL of a square image, optional
+	Language  string // optional
+	Updated   time.Time
+	Items     []Item
+}
+
+// Item is one feed entry
+type Item struct {
+	ID        string // permanent and unique within the feed
+	Title     string
+	URL       string
+	Summary   string
+	Published time.Time
+}
+
+// TagURI returns a tag URI (RFC 4151) such as
+// tag:example.com,2026-03-14:links/42. It stays the same as long as the
+// authority's host and the date it minted the name do.
+func TagURI(host string, date time.Time, specific string) string {
+	return "tag:" + host + "," + date.UTC().Format("2006-01-02") + ":" + specific
+}
+
+type atomFeed struct {
+	XMLName   xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Lang      string      `xml:"xml:lang,attr,omitempty"`
+	ID        string      `xml:"id"`
+	Title     string      `xml:"title"`
+	Subtitle  string      `xml:"subtitle,omitempty"`
+	Updated   string      `xml:"updated"`
+	Links     []atomLink  `xml:"link"`
+	Author    atomAuthor  `xml:"author"`
+	Icon      string      `xml:"icon,omitempty"`
+	Generator string      `xml:"generator"`
+	Entries   []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr,omitempty"`
+	Href string `xml:"href,attr"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+	URI  string `xml:"uri,omitempty"`
+}
+
+type atomEntry struct {
+	ID        string   `xml:"id"`
+	Title     string   `xml:"title"`
+	Link      atomLink `xml:"link"`
+	Published string   `xml:"published"`
+	Updated   string   `xml:"updated"`
+	Summary   string   `xml:"summary,omitempty"`
+}
+
+// Atom returns the feed as an Atom document served from self
+func (f Feed) Atom(self string) ([]byte, error) {
+	doc := atomFeed{
+		Lang:     f.Language,
+		ID:       f.ID,
+		Title:    f.Title,
+		Subtitle: f.Subtitle,
+		Updated:  atomTime(f.Updated),
+		Links: []atomLink{
+			{Rel: "alternate", Type: "text/html", Href: f.HomeURL},
+			{Rel: "self", Type: "application/atom+xml", Href: self},
+		},
+		Author:    atomAuthor{Name: f.Author, URI: f.AuthorURL},
+		Icon:      f.Icon,
+		Generator: generator,
+	}
+	for _, item := range f.Items {
+		doc.Entries = append(doc.Entries, atomEntry{
+			ID:        item.ID,
+			Title:     item.Title,
+			Link:      atomLink{Rel: "alternate", Href: item.URL},
+			Published: atomTime(item.Published),
+			Updated:   atomTime(item.Published),
+			Summary:   item.Summary,
+		})
+	}
+
+	out, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), append(out, '\n')...), nil
+}
+
+// atomTime formats t as an RFC 3339 timestamp in UTC
+func atomTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+type jsonFeed struct {
+	Version     string       `json:"version"`
+	Title       string       `json:"title"`
+	HomePageURL string       `json:"home_page_url"`
+	FeedURL     string       `json:"feed_url"`
+	Description string       `json:"description,omitempty"`
+	Icon        string       `json:"icon,omitempty"`
+	Authors     []jsonAuthor `json:"authors"`
+	Language    string       `json:"language,omitempty"`
+	Items       []jsonItem   `json:"items"`
+}
+
+type jsonAuthor struct {
+	Name string `json:"name"`
+	URL  string `json:"url,omitempty"`
+}
+
+type jsonItem struct {
+	ID            string `json:"id"`
+	URL           string `json:"url"`
+	Title         string `json:"title"`
+	ContentText   string `json:"content_text"`
+	Summary       string `json:"summary,omitempty"`
+	DatePublished string `json:"date_published"`
+}
+
+// JSON returns the feed as a JSON Feed document served from self
+func (f Feed) JSON(self string) ([]byte, error) {
+	doc := jsonFeed{
+		Version:     "https://jsonfeed.org/version/1.1",
+		Title:       f.Title,
+		HomePageURL: f.HomeURL,
+		FeedURL:     self,
+		Description: f.Subtitle,
+		Icon:        f.Icon,
+		Authors:     []jsonAuthor{{Name: f.Author, URL: f.AuthorURL}},
+		Language:    f.Language,
+		Items:       []jsonItem{},
+	}
+	for _, item := range f.Items {
+		// Items need content; links only have a title and maybe a summary
+		content := item.Summary
+		if content == "" {
+			content = item.Title
+		}
+		doc.Items = append(doc.Items, jsonItem{
+			ID:            item.ID,
+			URL:           item.URL,
+			Title:         item.Title,
+			ContentText:   content,
+			Summary:       item.Summary,
+			DatePublished: atomTime(item.Published),
+		})
+	}
+
+	out, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(out, '\n'), nil
+}
@@ -0,0 +1,93 @@
+package feed
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testFeed() Feed {
+	created := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	return Feed{
+		ID:        TagURI("linkbio.test", created, "users/7"),
+		Title:     "Jane <Doe>",
+		Subtitle:  "Links & more",
+		HomeURL:   "https://linkbio.test/u/jane",
+		Author:    "Jane <Doe>",
+		AuthorURL: "https://linkbio.test/u/jane",
+		Language:  "es",
+		Updated:   time.Date(2026, 3, 14, 18, 0, 0, 0, time.FixedZone("CET", 3600)),
+		Items: []Item{
+			{ID: TagURI("linkbio.test", created, "links/42"), Title: "Shop", URL: "https://linkbio.test/click/42", Summary: "Prints & posters", Published: time.Date(2026, 3, 14, 17, 0, 0, 0, time.UTC)},
+			{ID: TagURI("linkbio.test", created, "links/41"), Title: "Blog", URL: "https://linkbio.test/click/41", Published: created},
+		},
+	}
+}
+
+func TestTagURI(t *testing.T) {
+	got := TagURI("linkbio.test", time.Date(2026, 3, 14, 23, 30, 0, 0, time.FixedZone("X", -3*3600)), "links/42")
+	if got != "tag:linkbio.test,2026-03-15:links/42" {
+		t.Errorf("TagURI() = %q", got)
+	}
+}
+
+func TestFeed_Atom(t *testing.T) {
+	out, err := testFeed().Atom("https://linkbio.test/u/jane/feed.xml")
+	if err != nil {
+		t.Fatalf("Atom() error = %v", err)
+	}
+	s := string(out)
+	for _, want := range []string{
+		`<?xml version="1.0" encoding="UTF-8"?>`,
+		`<feed xmlns="http://www.w3.org/2005/Atom" xml:lang="es">`,
+		`<id>tag:linkbio.test,2026-03-01:users/7</id>`,
+		`<title>Jane &lt;Doe&gt;</title>`,
+		`<updated>2026-03-14T17:00:00Z</updated>`,
+		`<link rel="self" type="application/atom+xml" href="https://linkbio.test/u/jane/feed.xml"></link>`,
+		`<id>tag:linkbio.test,2026-03-01:links/42</id>`,
+		`<summary>Prints &amp; posters</summary>`,
+		`<published>2026-03-01T09:00:00Z</published>`,
+	} {
+		if !strings.Contains(s, want) {
+			t.Errorf("Atom() missing %s in\n%s", want, s)
+		}
+	}
+
+	var doc atomFeed
+	if err := xml.Unmarshal(out, &doc); err != nil {
+		t.Fatalf("Atom() is not valid XML: %v", err)
+	}
+	if len(doc.Entries) != 2 || doc.Entries[0].Link.Href != "https://linkbio.test/click/42" {
+		t.Errorf("entries = %+v", doc.Entries)
+	}
+}
+
+func TestFeed_JSON(t *testing.T) {
+	out, err := testFeed().JSON("https://linkbio.test/u/jane/feed.json")
+	if err != nil {
+		t.Fatalf("JSON() error = %v", err)
+	}
+	var doc jsonFeed
+	if err := json.Unmarshal(out, &doc); err != nil {
+		t.Fatalf("JSON() is not valid JSON: %v", err)
+	}
+	if doc.Version != "https://jsonfeed.org/version/1.1" || doc.FeedURL != "https://linkbio.test/u/jane/feed.json" || doc.Title != "Jane <Doe>" {
+		t.Errorf("feed = %+v", doc)
+	}
+	if len(doc.Items) != 2 {
+		t.Fatalf("items = %+v", doc.Items)
+	}
+	if doc.Items[0].ContentText != "Prints & posters" || doc.Items[1].ContentText != "Blog" {
+		t.Errorf("content_text = %q, %q", doc.Items[0].ContentText, doc.Items[1].ContentText)
+	}
+	if doc.Items[1].DatePublished != "2026-03-01T09:00:00Z" {
+		t.Errorf("date_published = %q", doc.Items[1].DatePublished)
+	}
+
+	empty, _ := Feed{Title: "Empty"}.JSON("x")
+	if !strings.Contains(string(empty), `"items": []`) {
+		t.Errorf("empty feed should list no items, got %s", empty)
+	}
+}
@@ -0,0 +1,68 @@
+// Package ical writes single events in the iCalendar format (RFC 5545),
+// which calendar apps import from .ics files. Values are escaped and long
+// lines folded as the format requires.
+package ical
+
+import (
+	"strings"
+	"time"
+
+	"linkbio/internal/pkg/contentline"
+)
+
+// ContentType is the media type of an iCalendar file
+const ContentType = "text/calendar; charset=utf-8"
+
+// prodID identifies the program that wrote the calendar
+const prodID = "-//LinkBio//Events//EN"
+
+// Event is a calendar event. Times are written in UTC.
+type Event struct {
+	UID         string // globally unique and stable across downloads
+	Summary     string
+	Description string
+	Location    string
+	URL         string
+	Start       time.Time
+	End         time.Time
+	Stamp       time.Time // when the file was created
+}
+
+// Bytes encodes the event as a calendar holding just it
+func (e Event) Bytes() []byte {
+	var b strings.Builder
+	line := func(name, value string) {
+		contentline.Write(&b, name, value)
+	}
+
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", prodID)
+	line("CALSCALE", "GREGORIAN")
+	line("METHOD", "PUBLISH")
+	line("BEGIN", "VEVENT")
+	line("UID", contentline.Escape(e.UID))
+	line("DTSTAMP", formatTime(e.Stamp))
+	line("DTSTART", formatTime(e.Start))
+	if !e.End.IsZero() {
+		line("DTEND", formatTime(e.End))
+	}
+	line("SUMMARY", contentline.Escape(e.Summary))
+	if e.Description != "" {
+		line("DESCRIPTION", contentline.Escape(e.Description))
+	}
+	if e.Location != "" {
+		line("LOCATION", contentline.Escape(e.Location))
+	}
+	if e.URL != "" {
+		line("URL", e.URL)
+	}
+	line("END", "VEVENT")
+	line("END", "VCALENDAR")
+	return []byte(b.String())
+}
+
+// formatTime writes t as a UTC date-time, e.g. 20260314T190000Z
+func formatTime(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
@@ -0,0 +1,86 @@
+package ical
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestEvent_Bytes(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("no time zone database")
+	}
+	event := Event{
+		UID:         "link-42@linkbio.test",
+		Summary:     "Launch party; drinks, snacks",
+		Description: "Bring a friend\nDoors at 7",
+		Location:    `Warehouse 5, Berlin`,
+		URL:         "https://linkbio.test/u/jane",
+		Start:       time.Date(2026, 3, 14, 19, 0, 0, 0, berlin),
+		End:         time.Date(2026, 3, 14, 23, 30, 0, 0, berlin),
+		Stamp:       time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC),
+	}
+
+	want := "BEGIN:VCALENDAR\r\n" +
+		"VERSION:2.0\r\n" +
+		"PRODID:-//LinkBio//Events//EN\r\n" +
+		"CALSCALE:GREGORIAN\r\n" +
+		"METHOD:PUBLISH\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"UID:link-42@linkbio.test\r\n" +
+		"DTSTAMP:20260301T120000Z\r\n" +
+		"DTSTART:20260314T180000Z\r\n" +
+		"DTEND:20260314T223000Z\r\n" +
+		`SUMMARY:Launch party\; drinks\, snacks` + "\r\n" +
+		`DESCRIPTION:Bring a friend\nDoors at 7` + "\r\n" +
+		`LOCATION:Warehouse 5\, Berlin` + "\r\n" +
+		"URL:https://linkbio.test/u/jane\r\n" +
+		"END:VEVENT\r\n" +
+		"END:VCALENDAR\r\n"
+	if got := string(event.Bytes()); got != want {
+		t.Errorf("Bytes() =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestEvent_OptionalFields(t *testing.T) {
+	start := time.Date(2026, 3, 14, 19, 0, 0, 0, time.UTC)
+	out := string(Event{UID: "x", Summary: "Meetup", Start: start, Stamp: start}.Bytes())
+	for _, name := range []string{"DTEND", "DESCRIPTION", "LOCATION", "URL"} {
+		if strings.Contains(out, "\r\n"+name+":") {
+			t.Errorf("empty %s was written", name)
+		}
+	}
+}
+
+func TestFolding(t *testing.T) {
+	start := time.Date(2026, 3, 14, 19, 0, 0, 0, time.UTC)
+	event := Event{UID: "x", Summary: strings.Repeat("Fête à Montréal — ", 12), Start: start, Stamp: start}
+	out := string(event.Bytes())
+
+	var unfolded []string
+	for _, line := range strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n") {
+		if len(line) > 75 {
+			t.Errorf("line is %d octets: %q", len(line), line)
+		}
+		if !utf8.ValidString(line) {
+			t.Errorf("line splits a character: %q", line)
+		}
+		if strings.HasPrefix(line, " ") {
+			unfolded[len(unfolded)-1] += line[1:]
+			continue
+		}
+		unfolded = append(unfolded, line)
+	}
+
+	found := false
+	for _, line := range unfolded {
+		if line == "SUMMARY:"+event.Summary {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("SUMMARY did not unfold to the original:\n%s", out)
+	}
+}
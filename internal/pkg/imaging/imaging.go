@@ -0,0 +1,265 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/gif" // register decoder
+	"image/jpeg"
+	"image/png"
+	"net/http"
+)
+
+var (
+	// ErrUnsupportedType is returned for anything that isn't a JPEG, PNG or GIF
+	ErrUnsupportedType = errors.New("unsupported image type")
+
+	// ErrTooLarge is returned for images whose pixel dimensions are unreasonable
+	ErrTooLarge = errors.New("image dimensions too large")
+)
+
+// MaxPixels caps decoded image size to guard against decompression bombs
+const MaxPixels = 40_000_000
+
+// allowedTypes are the sniffed content types we accept
+var allowedTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+}
+
+// Decode sniffs the content type from the bytes (never trusting the client's
+// header), decodes the image and applies any EXIF orientation. The returned
+// image carries no metadata, so re-encoding it strips EXIF entirely.
+func Decode(data []byte) (image.Image, error) {
+	contentType := http.DetectContentType(data)
+	if !allowedTypes[contentType] {
+		return nil, ErrUnsupportedType
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedType
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width*cfg.Height > MaxPixels {
+		return nil, ErrTooLarge
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	if contentType == "image/jpeg" {
+		img = applyOrientation(img, jpegOrientation(data))
+	}
+	return img, nil
+}
+
+// CenterCrop returns the largest centered square of img
+func CenterCrop(img image.Image) image.Image {
+	b := img.Bounds()
+	size := b.Dx()
+	if b.Dy() < size {
+		size = b.Dy()
+	}
+	x := b.Min.X + (b.Dx()-size)/2
+	y := b.Min.Y + (b.Dy()-size)/2
+	rect := image.Rect(x, y, x+size, y+size)
+
+	dst := image.NewRGBA(image.Rect(0, 0, size, size))
+	draw.Draw(dst, dst.Bounds(), img, rect.Min, draw.Src)
+	return dst
+}
+
+// Resize scales img to w×h. Each destination pixel averages the source
+// pixels it covers, which keeps downscaled photos smooth.
+func Resize(img image.Image, w, h int) *image.RGBA {
+	src := toRGBA(img)
+	sb := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+
+	xScale := float64(sb.Dx()) / float64(w)
+	yScale := float64(sb.Dy()) / float64(h)
+
+	for dy := 0; dy < h; dy++ {
+		y0 := int(float64(dy) * yScale)
+		y1 := int(float64(dy+1) * yScale)
+		if y1 <= y0 {
+			y1 = y0 + 1
+		}
+		for dx := 0; dx < w; dx++ {
+			x0 := int(float64(dx) * xScale)
+			x1 := int(float64(dx+1) * xScale)
+			if x1 <= x0 {
+				x1 = x0 + 1
+			}
+
+			var r, g, b, a, n uint32
+			for sy := y0; sy < y1 && sy < sb.Dy(); sy++ {
+				off := src.PixOffset(sb.Min.X+x0, sb.Min.Y+sy)
+				for sx := x0; sx < x1 && sx < sb.Dx(); sx++ {
+					r += uint32(src.Pix[off])
+					g += uint32(src.Pix[off+1])
+					b += uint32(src.Pix[off+2])
+					a += uint32(src.Pix[off+3])
+					off += 4
+					n++
+				}
+			}
+			if n == 0 {
+				continue
+			}
+			i := dst.PixOffset(dx, dy)
+			dst.Pix[i] = uint8(r / n)
+			dst.Pix[i+1] = uint8(g / n)
+			dst.Pix[i+2] = uint8(b / n)
+			dst.Pix[i+3] = uint8(a / n)
+		}
+	}
+
+	return dst
+}
+
+// EncodeJPEG flattens img onto white (JPEG has no alpha) and encodes it
+func EncodeJPEG(img image.Image, quality int) ([]byte, error) {
+	flat := image.NewRGBA(img.Bounds())
+	draw.Draw(flat, flat.Bounds(), &image.Uniform{C: color.White}, image.Point{}, draw.Src)
+	draw.Draw(flat, flat.Bounds(), img, img.Bounds().Min, draw.Over)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, flat, &jpeg.Options{Quality: quality}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// EncodePNG encodes img losslessly, keeping transparency
+func EncodePNG(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// toRGBA converts any image to *image.RGBA anchored at the origin
+func toRGBA(img image.Image) *image.RGBA {
+	if rgba, ok := img.(*image.RGBA); ok && rgba.Bounds().Min == (image.Point{}) {
+		return rgba
+	}
+	b := img.Bounds()
+	rgba := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(rgba, rgba.Bounds(), img, b.Min, draw.Src)
+	return rgba
+}
+
+// jpegOrientation returns the EXIF orientation tag (1-8) or 1 if absent
+func jpegOrientation(data []byte) int {
+	// Walk JPEG segments looking for APP1 "Exif"
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+	i := 2
+	for i+4 <= len(data) {
+		if data[i] != 0xFF {
+			return 1
+		}
+		marker := data[i+1]
+		if marker == 0xDA || marker == 0xD9 { // start of scan / end of image
+			return 1
+		}
+		length := int(binary.BigEndian.Uint16(data[i+2:]))
+		if length < 2 || i+2+length > len(data) {
+			return 1
+		}
+		segment := data[i+4 : i+2+length]
+		if marker == 0xE1 && len(segment) > 6 && string(segment[:6]) == "Exif\x00\x00" {
+			return exifOrientation(segment[6:])
+		}
+		i += 2 + length
+	}
+	return 1
+}
+
+// exifOrientation reads tag 0x0112 from the first IFD of a TIFF block
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd+2 > len(tiff) {
+		return 1
+	}
+	entries := int(order.Uint16(tiff[ifd:]))
+	for e := 0; e < entries; e++ {
+		off := ifd + 2 + e*12
+		if off+12 > len(tiff) {
+			return 1
+		}
+		if order.Uint16(tiff[off:]) == 0x0112 {
+			v := int(order.Uint16(tiff[off+8:]))
+			if v >= 1 && v <= 8 {
+				return v
+			}
+			return 1
+		}
+	}
+	return 1
+}
+
+// applyOrientation rotates/flips img so it displays upright without EXIF
+func applyOrientation(img image.Image, orientation int) image.Image {
+	if orientation <= 1 || orientation > 8 {
+		return img
+	}
+
+	src := toRGBA(img)
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+
+	// Orientations 5-8 swap width and height
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var nx, ny int
+			switch orientation {
+			case 2: // mirror horizontal
+				nx, ny = w-1-x, y
+			case 3: // rotate 180
+				nx, ny = w-1-x, h-1-y
+			case 4: // mirror vertical
+				nx, ny = x, h-1-y
+			case 5: // transpose
+				nx, ny = y, x
+			case 6: // rotate 90 clockwise
+				nx, ny = h-1-y, x
+			case 7: // transverse
+				nx, ny = h-1-y, w-1-x
+			case 8: // rotate 90 counter-clockwise
+				nx, ny = y, w-1-x
+			}
+			si := src.PixOffset(x, y)
+			di := dst.PixOffset(nx, ny)
+			copy(dst.Pix[di:di+4], src.Pix[si:si+4])
+		}
+	}
+	return dst
+}
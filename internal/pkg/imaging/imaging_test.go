@@ -0,0 +1,106 @@
+package imaging
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+func solid(w, h int, c color.Color) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+func TestDecode_SniffsContent(t *testing.T) {
+	var buf bytes.Buffer
+	png.Encode(&buf, solid(4, 4, color.White))
+
+	if _, err := Decode(buf.Bytes()); err != nil {
+		t.Errorf("Decode(png) error = %v", err)
+	}
+
+	// An HTML file renamed to .png must be rejected regardless of its name
+	if _, err := Decode([]byte("<html><body>not an image</body></html>")); err != ErrUnsupportedType {
+		t.Errorf("Decode(html) error = %v, want ErrUnsupportedType", err)
+	}
+}
+
+func TestCenterCrop(t *testing.T) {
+	img := solid(300, 100, color.White)
+	// Mark the center so we can check it survived the crop
+	img.Set(150, 50, color.Black)
+
+	cropped := CenterCrop(img)
+	b := cropped.Bounds()
+	if b.Dx() != 100 || b.Dy() != 100 {
+		t.Fatalf("CenterCrop() size = %dx%d, want 100x100", b.Dx(), b.Dy())
+	}
+	if r, _, _, _ := cropped.At(50, 50).RGBA(); r != 0 {
+		t.Error("CenterCrop() did not keep the center of the image")
+	}
+}
+
+func TestResize(t *testing.T) {
+	img := solid(400, 400, color.RGBA{R: 200, G: 100, B: 50, A: 255})
+
+	small := Resize(img, 64, 64)
+	if small.Bounds().Dx() != 64 || small.Bounds().Dy() != 64 {
+		t.Fatalf("Resize() size = %v, want 64x64", small.Bounds())
+	}
+	if got := small.RGBAAt(10, 10); got.R != 200 || got.G != 100 || got.B != 50 {
+		t.Errorf("Resize() color = %v, want averaged solid color", got)
+	}
+
+	big := Resize(solid(2, 2, color.Black), 10, 10)
+	if big.Bounds().Dx() != 10 {
+		t.Errorf("Resize() upscale width = %d, want 10", big.Bounds().Dx())
+	}
+}
+
+func TestDecode_AppliesExifOrientation(t *testing.T) {
+	var buf bytes.Buffer
+	jpeg.Encode(&buf, solid(40, 20, color.White), nil)
+	data := withOrientation(buf.Bytes(), 6)
+
+	img, err := Decode(data)
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	// Orientation 6 rotates 90°, so a 40x20 image comes out 20x40
+	if b := img.Bounds(); b.Dx() != 20 || b.Dy() != 40 {
+		t.Errorf("Decode() size = %dx%d, want 20x40", b.Dx(), b.Dy())
+	}
+
+	// Re-encoding drops the EXIF block
+	out, _ := EncodeJPEG(img, 85)
+	if bytes.Contains(out, []byte("Exif\x00\x00")) {
+		t.Error("EncodeJPEG() output still contains EXIF data")
+	}
+}
+
+// withOrientation splices a minimal EXIF APP1 segment after the SOI marker
+func withOrientation(jpg []byte, orientation byte) []byte {
+	tiff := []byte{
+		'M', 'M', 0x00, 0x2A, 0x00, 0x00, 0x00, 0x08, // header, IFD at offset 8
+		0x00, 0x01, // one entry
+		0x01, 0x12, 0x00, 0x03, 0x00, 0x00, 0x00, 0x01, 0x00, orientation, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, // no next IFD
+	}
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+	length := len(payload) + 2
+
+	seg := []byte{0xFF, 0xE1, byte(length >> 8), byte(length)}
+	seg = append(seg, payload...)
+
+	out := append([]byte{}, jpg[:2]...)
+	out = append(out, seg...)
+	return append(out, jpg[2:]...)
+}
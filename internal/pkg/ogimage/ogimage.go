@@ -0,0 +1,283 @@
+// Package ogimage renders the 1200×630 share cards social networks show
+// next to a profile link. Everything is drawn with the standard library: a
+// built-in bitmap font, a circular avatar and pill-shaped link buttons.
+package ogimage
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"math"
+	"strings"
+
+	"linkbio/internal/pkg/imaging"
+)
+
+// Card dimensions, the size Open Graph and Twitter recommend
+const (
+	Width  = 1200
+	Height = 630
+)
+
+// MaxItems is how many link titles the card shows
+const MaxItems = 3
+
+// version changes whenever the layout does, so cached cards are redrawn
+const version = "og1"
+
+// Style holds the colors a card is drawn with
+type Style struct {
+	Background   color.NRGBA
+	BackgroundTo color.NRGBA // equal to Background for a solid fill
+	Angle        int         // gradient direction in degrees, CSS convention
+	Text         color.NRGBA
+	Muted        color.NRGBA
+	Button       color.NRGBA
+	ButtonText   color.NRGBA
+	Outline      bool // buttons are drawn as outlines rather than filled
+}
+
+// Card is the content of a share image
+type Card struct {
+	Title    string   // display name
+	Subtitle string   // handle
+	Items    []string // link titles; the first MaxItems are drawn
+	Footer   string   // site name, bottom right
+	Initial  string   // drawn in place of a missing avatar
+
+	// AvatarRef identifies the avatar passed to Render, e.g. its URL. It
+	// only feeds Hash; an empty ref means no avatar.
+	AvatarRef string
+
+	Style Style
+}
+
+// Hash identifies the rendered image. Cards with the same hash render the
+// same pixels, so it can key a cache and version URLs.
+func (c Card) Hash() string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%s\x00%s\x00%s\x00%s\x00%s\x00%+v", version, c.Title, c.Subtitle, c.Footer, c.Initial, c.AvatarRef, c.Style)
+	for i, item := range c.Items {
+		if i == MaxItems {
+			break
+		}
+		fmt.Fprintf(h, "\x00%s", item)
+	}
+	return hex.EncodeToString(h.Sum(nil))[:32]
+}
+
+// Render draws the card as a PNG. avatar may be nil, in which case the
+// initial is drawn on a button-colored disc.
+func Render(c Card, avatar image.Image) ([]byte, error) {
+	img := image.NewRGBA(image.Rect(0, 0, Width, Height))
+	fillBackground(img, c.Style)
+
+	// Avatar on the left, vertically centered
+	const avatarSize, avatarX = 240, 100
+	avatarY := (Height - avatarSize) / 2
+	if avatar != nil {
+		face := imaging.Resize(imaging.CenterCrop(avatar), avatarSize, avatarSize)
+		drawCircle(img, avatarX, avatarY, avatarSize, face, color.NRGBA{})
+	} else {
+		drawCircle(img, avatarX, avatarY, avatarSize, nil, c.Style.Button)
+		initial := strings.ToUpper(c.Initial)
+		scale := 16
+		w := textWidth(initial, scale)
+		drawText(img, initial, avatarX+(avatarSize-w)/2, avatarY+(avatarSize-glyphHeight*scale)/2, scale, c.Style.ButtonText)
+	}
+
+	// Text column
+	const textX, right = 420, Width - 80
+	maxWidth := right - textX
+
+	title, titleScale := fit(c.Title, maxWidth, 10, 5)
+	subtitle, subScale := fit(c.Subtitle, maxWidth, 5, 3)
+	itemScale := 4
+	const itemHeight, itemGap = 64, 18
+
+	items := c.Items
+	if len(items) > MaxItems {
+		items = items[:MaxItems]
+	}
+
+	// Center the block of text vertically
+	blockHeight := glyphHeight*titleScale + 28 + glyphHeight*subScale
+	if len(items) > 0 {
+		blockHeight += 44 + len(items)*itemHeight + (len(items)-1)*itemGap
+	}
+	y := (Height - blockHeight) / 2
+
+	drawText(img, title, textX, y, titleScale, c.Style.Text)
+	y += glyphHeight*titleScale + 28
+	drawText(img, subtitle, textX, y, subScale, c.Style.Muted)
+	y += glyphHeight * subScale
+
+	if len(items) > 0 {
+		y += 44
+	}
+	for _, item := range items {
+		label, _ := fit(item, maxWidth-64, itemScale, itemScale)
+		drawPill(img, image.Rect(textX, y, right, y+itemHeight), c.Style)
+		w := textWidth(label, itemScale)
+		drawText(img, label, textX+(maxWidth-w)/2, y+(itemHeight-glyphHeight*itemScale)/2, itemScale, c.Style.ButtonText)
+		y += itemHeight + itemGap
+	}
+
+	if c.Footer != "" {
+		footerScale := 3
+		drawText(img, c.Footer, Width-40-textWidth(c.Footer, footerScale), Height-40-glyphHeight*footerScale, footerScale, c.Style.Muted)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// fillBackground paints a solid color or a linear gradient
+func fillBackground(img *image.RGBA, s Style) {
+	if s.Background == s.BackgroundTo {
+		draw.Draw(img, img.Bounds(), &image.Uniform{C: s.Background}, image.Point{}, draw.Src)
+		return
+	}
+
+	// CSS angles: 0deg points up, 90deg points right
+	rad := float64(s.Angle) * math.Pi / 180
+	dx, dy := math.Sin(rad), -math.Cos(rad)
+	// Half the gradient line length, so the corners reach 0 and 1
+	half := (math.Abs(Width*dx) + math.Abs(Height*dy)) / 2
+
+	for y := 0; y < Height; y++ {
+		for x := 0; x < Width; x++ {
+			t := ((float64(x)-Width/2)*dx + (float64(y)-Height/2)*dy + half) / (2 * half)
+			// Opaque, so the straight and premultiplied values agree
+			img.SetRGBA(x, y, color.RGBA(mix(s.Background, s.BackgroundTo, t)))
+		}
+	}
+}
+
+// drawCircle fills a circle with src, or with fill when src is nil. Edges
+// are antialiased by coverage.
+func drawCircle(img *image.RGBA, x, y, size int, src *image.RGBA, fill color.NRGBA) {
+	r := float64(size) / 2
+	for py := 0; py < size; py++ {
+		for px := 0; px < size; px++ {
+			d := math.Hypot(float64(px)+0.5-r, float64(py)+0.5-r)
+			cover := r - d + 0.5
+			if cover <= 0 {
+				continue
+			}
+			if cover > 1 {
+				cover = 1
+			}
+			c := fill
+			if src != nil {
+				c = color.NRGBAModel.Convert(src.RGBAAt(px, py)).(color.NRGBA)
+			}
+			blend(img, x+px, y+py, c, cover)
+		}
+	}
+}
+
+// drawPill draws a fully rounded button, filled or outlined
+func drawPill(img *image.RGBA, rect image.Rectangle, s Style) {
+	r := float64(rect.Dy()) / 2
+	const border = 4.0
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+		for x := rect.Min.X; x < rect.Max.X; x++ {
+			// Distance from the pill's center line segment
+			cx := math.Min(math.Max(float64(x)+0.5, float64(rect.Min.X)+r), float64(rect.Max.X)-r)
+			d := math.Hypot(float64(x)+0.5-cx, float64(y)+0.5-(float64(rect.Min.Y)+r))
+
+			cover := math.Min(r-d+0.5, 1)
+			if s.Outline {
+				cover = math.Min(cover, d-(r-border)+0.5)
+			}
+			if cover > 0 {
+				blend(img, x, y, s.Button, cover)
+			}
+		}
+	}
+}
+
+// drawText draws s with its top-left corner at (x, y), each font pixel
+// scale×scale image pixels
+func drawText(img *image.RGBA, s string, x, y, scale int, c color.NRGBA) {
+	fill := &image.Uniform{C: c}
+	for _, r := range s {
+		g := glyph(r)
+		for col := 0; col < glyphWidth; col++ {
+			for row := 0; row < glyphHeight; row++ {
+				if g[col]&(1<<row) == 0 {
+					continue
+				}
+				px := image.Rect(x+col*scale, y+row*scale, x+(col+1)*scale, y+(row+1)*scale)
+				draw.Draw(img, px, fill, image.Point{}, draw.Over)
+			}
+		}
+		x += glyphAdvance * scale
+	}
+}
+
+// textWidth is the width of s drawn at scale, without trailing spacing
+func textWidth(s string, scale int) int {
+	n := len([]rune(s))
+	if n == 0 {
+		return 0
+	}
+	return (n*glyphAdvance - 1) * scale
+}
+
+// fit picks the largest scale from hi down to lo at which s fits in width,
+// truncating with "..." if it doesn't fit even at lo
+func fit(s string, width, hi, lo int) (string, int) {
+	for scale := hi; scale >= lo; scale-- {
+		if textWidth(s, scale) <= width {
+			return s, scale
+		}
+	}
+	runes := []rune(s)
+	for len(runes) > 0 && textWidth(string(runes)+"...", lo) > width {
+		runes = runes[:len(runes)-1]
+	}
+	return strings.TrimSpace(string(runes)) + "...", lo
+}
+
+// blend draws c over the pixel at (x, y) with the given coverage
+func blend(img *image.RGBA, x, y int, c color.NRGBA, cover float64) {
+	if !(image.Point{x, y}.In(img.Rect)) {
+		return
+	}
+	a := cover * float64(c.A) / 255
+	dst := img.RGBAAt(x, y)
+	img.SetRGBA(x, y, color.RGBA{
+		R: uint8(float64(c.R)*a + float64(dst.R)*(1-a)),
+		G: uint8(float64(c.G)*a + float64(dst.G)*(1-a)),
+		B: uint8(float64(c.B)*a + float64(dst.B)*(1-a)),
+		A: 255,
+	})
+}
+
+// mix interpolates between two colors, clamping t to [0, 1]
+func mix(a, b color.NRGBA, t float64) color.NRGBA {
+	t = math.Max(0, math.Min(1, t))
+	lerp := func(x, y uint8) uint8 { return uint8(float64(x) + (float64(y)-float64(x))*t + 0.5) }
+	return color.NRGBA{lerp(a.R, b.R), lerp(a.G, b.G), lerp(a.B, b.B), 255}
+}
+
+// ParseHex reads a #rrggbb color. Anything else yields black.
+func ParseHex(s string) color.NRGBA {
+	var r, g, b uint8
+	if len(s) == 7 && s[0] == '#' {
+		if _, err := fmt.Sscanf(s[1:], "%02x%02x%02x", &r, &g, &b); err == nil {
+			return color.NRGBA{r, g, b, 255}
+		}
+	}
+	return color.NRGBA{A: 255}
+}
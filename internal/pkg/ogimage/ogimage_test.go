@@ -0,0 +1,105 @@
+package ogimage
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+func testCard() Card {
+	return Card{
+		Title:    "Alice Example",
+		Subtitle: "@alice",
+		Items:    []string{"Portfolio", "Newsletter", "Shop", "Hidden fourth"},
+		Footer:   "LinkBio",
+		Initial:  "A",
+		Style: Style{
+			Background:   ParseHex("#0ea5e9"),
+			BackgroundTo: ParseHex("#1e3a8a"),
+			Angle:        160,
+			Text:         ParseHex("#ffffff"),
+			Muted:        ParseHex("#bae6fd"),
+			Button:       ParseHex("#ffffff"),
+			ButtonText:   ParseHex("#1f2937"),
+		},
+	}
+}
+
+func TestRender(t *testing.T) {
+	avatar := image.NewRGBA(image.Rect(0, 0, 40, 30))
+	for i := range avatar.Pix {
+		avatar.Pix[i] = 0xff
+	}
+
+	for _, a := range []image.Image{nil, avatar} {
+		data, err := Render(testCard(), a)
+		if err != nil {
+			t.Fatalf("Render() error = %v", err)
+		}
+		img, err := png.Decode(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("output is not a PNG: %v", err)
+		}
+		if b := img.Bounds(); b.Dx() != Width || b.Dy() != Height {
+			t.Errorf("size = %v, want %dx%d", b, Width, Height)
+		}
+
+		// The corners show the gradient's two ends
+		top := color.NRGBAModel.Convert(img.At(Width-1, 0)).(color.NRGBA)
+		bottom := color.NRGBAModel.Convert(img.At(0, Height-1)).(color.NRGBA)
+		if top.B < bottom.B {
+			t.Errorf("gradient runs the wrong way: top %v, bottom %v", top, bottom)
+		}
+	}
+}
+
+func TestHash(t *testing.T) {
+	base := testCard()
+	if base.Hash() != testCard().Hash() {
+		t.Fatal("Hash is not stable")
+	}
+
+	changed := []func(*Card){
+		func(c *Card) { c.Title = "Alice" },
+		func(c *Card) { c.Subtitle = "@alice2" },
+		func(c *Card) { c.Items = c.Items[:2] },
+		func(c *Card) { c.Items = append([]string{"New"}, c.Items...) },
+		func(c *Card) { c.AvatarRef = "/media/avatars/x.jpg" },
+		func(c *Card) { c.Style.Button = ParseHex("#000000") },
+	}
+	for i, change := range changed {
+		c := testCard()
+		change(&c)
+		if c.Hash() == base.Hash() {
+			t.Errorf("change %d did not alter the hash", i)
+		}
+	}
+
+	// Only the drawn items count
+	c := testCard()
+	c.Items[3] = "Different fourth"
+	if c.Hash() != base.Hash() {
+		t.Error("an item that is not drawn altered the hash")
+	}
+}
+
+func TestFit(t *testing.T) {
+	if s, scale := fit("short", 1000, 10, 5); s != "short" || scale != 10 {
+		t.Errorf("fit(short) = %q, %d", s, scale)
+	}
+	s, scale := fit("a very long display name that cannot fit", 300, 10, 5)
+	if scale != 5 || textWidth(s, scale) > 300 || s[len(s)-3:] != "..." {
+		t.Errorf("fit(long) = %q, %d", s, scale)
+	}
+}
+
+func TestParseHex(t *testing.T) {
+	if c := ParseHex("#0a0b0c"); c != (color.NRGBA{10, 11, 12, 255}) {
+		t.Errorf("ParseHex = %v", c)
+	}
+	if c := ParseHex("red"); c != (color.NRGBA{A: 255}) {
+		t.Errorf("ParseHex(invalid) = %v, want black", c)
+	}
+}
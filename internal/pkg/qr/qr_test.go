@@ -0,0 +1,261 @@
+package qr
+
+import (
+	"bytes"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+func TestFormatAndVersionBits(t *testing.T) {
+	// Reference values from the format and version information tables
+	formats := []struct {
+		level Level
+		mask  int
+		want  int
+	}{
+		{Low, 0, 0b111011111000100},
+		{Low, 1, 0b111001011110011},
+		{Medium, 0, 0b101010000010010},
+		{Quartile, 0, 0b011010101011111},
+		{High, 0, 0b001011010001001},
+	}
+	for _, f := range formats {
+		if got := formatBits(f.level, f.mask); got != f.want {
+			t.Errorf("formatBits(%v, %d) = %015b, want %015b", f.level, f.mask, got, f.want)
+		}
+	}
+
+	if got := versionBits(7); got != 0x07C94 {
+		t.Errorf("versionBits(7) = %#x, want 0x07c94", got)
+	}
+	if got := versionBits(40); got != 0x28C69 {
+		t.Errorf("versionBits(40) = %#x, want 0x28c69", got)
+	}
+}
+
+func TestReedSolomon(t *testing.T) {
+	// "HELLO WORLD" at 1-M, the worked example from the standard's annex
+	data := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+	want := []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23}
+
+	if got := rsRemainder(data, rsDivisor(10)); !bytes.Equal(got, want) {
+		t.Errorf("rsRemainder() = %v, want %v", got, want)
+	}
+}
+
+func TestCapacity(t *testing.T) {
+	cases := []struct {
+		version int
+		level   Level
+		want    int
+	}{
+		{1, Low, 19}, {1, High, 9}, {5, Quartile, 62}, {10, Medium, 216}, {40, Low, 2956}, {40, High, 1276},
+	}
+	for _, c := range cases {
+		if got := dataCodewords(c.version, c.level); got != c.want {
+			t.Errorf("dataCodewords(%d, %v) = %d, want %d", c.version, c.level, got, c.want)
+		}
+	}
+}
+
+func TestEncode_RoundTrip(t *testing.T) {
+	texts := []string{
+		"https://linkbio.example/u/alice?src=qr",
+		"",
+		strings.Repeat("linkbio ", 40), // multi-block version
+		strings.Repeat("x", 400),       // version 7+ carries version info
+		"héllo wörld ✓",                // UTF-8 bytes
+	}
+	for _, text := range texts {
+		for level := Low; level <= High; level++ {
+			code, err := Encode(text, level)
+			if err != nil {
+				t.Fatalf("Encode(%d bytes, %v) error = %v", len(text), level, err)
+			}
+			if code.Size() != code.Version*4+17 {
+				t.Errorf("Size() = %d for version %d", code.Size(), code.Version)
+			}
+			if got := decode(t, code); got != text {
+				t.Errorf("decode(Encode(%q, %v)) = %q", text, level, got)
+			}
+		}
+	}
+}
+
+func TestEncode_PicksSmallestVersion(t *testing.T) {
+	code, _ := Encode("https://example.com", Medium)
+	if code.Version != 2 {
+		t.Errorf("Version = %d, want 2", code.Version)
+	}
+
+	// 2953 bytes is the byte-mode capacity of 40-L
+	if _, err := Encode(strings.Repeat("a", 2953), Low); err != nil {
+		t.Errorf("Encode(2953 bytes) error = %v", err)
+	}
+	if _, err := Encode(strings.Repeat("a", 2954), Low); err != ErrTooLong {
+		t.Errorf("Encode(2954 bytes) error = %v, want ErrTooLong", err)
+	}
+}
+
+func TestRender(t *testing.T) {
+	code, _ := Encode("https://example.com", Medium)
+	total := code.Size() + 8
+
+	data, err := code.PNG(Options{Size: 300, Margin: 4})
+	if err != nil {
+		t.Fatalf("PNG() error = %v", err)
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("PNG output does not decode: %v", err)
+	}
+	scale := 300 / total
+	if img.Bounds().Dx() != total*scale {
+		t.Errorf("PNG width = %d, want %d", img.Bounds().Dx(), total*scale)
+	}
+	// Top-left finder corner is dark, the quiet zone light
+	if r, _, _, _ := img.At(4*scale, 4*scale).RGBA(); r != 0 {
+		t.Error("finder corner is not dark")
+	}
+	if r, _, _, _ := img.At(0, 0).RGBA(); r == 0 {
+		t.Error("quiet zone is not light")
+	}
+
+	svg := string(code.SVG(Options{
+		Size:       200,
+		Margin:     2,
+		Foreground: color.RGBA{R: 0x11, G: 0x22, B: 0x33, A: 0xff},
+		Background: color.RGBA{},
+	}))
+	if !strings.HasPrefix(svg, "<svg") || !strings.Contains(svg, `fill="#112233"`) {
+		t.Errorf("SVG() = %.120s…", svg)
+	}
+	if strings.Contains(svg, "<rect") {
+		t.Error("SVG() drew a background for a transparent color")
+	}
+}
+
+// decode reads a symbol back using the same geometry as the encoder, checks
+// every block's Reed-Solomon syndromes and parses the byte-mode segment
+func decode(t *testing.T, c *Code) string {
+	t.Helper()
+
+	// Format information from the copy around the top-left finder
+	bits := 0
+	read := func(x, y, i int) {
+		if c.Dark(x, y) {
+			bits |= 1 << i
+		}
+	}
+	for i := 0; i <= 5; i++ {
+		read(8, i, i)
+	}
+	read(8, 7, 6)
+	read(8, 8, 7)
+	read(7, 8, 8)
+	for i := 9; i < 15; i++ {
+		read(14-i, 8, i)
+	}
+	level, mask := Level(-1), -1
+	for l := Low; l <= High; l++ {
+		for m := 0; m < 8; m++ {
+			if formatBits(l, m) == bits {
+				level, mask = l, m
+			}
+		}
+	}
+	if mask < 0 {
+		t.Fatalf("format bits %015b match no level/mask", bits)
+	}
+
+	version := (c.Size() - 17) / 4
+	ref := newCode(version, level)
+	ref.drawFunctionPatterns()
+
+	// Unmask and read codewords in zigzag order
+	raw := make([]byte, rawDataModules(version)/8)
+	i := 0
+	for right := c.size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		for vert := 0; vert < c.size; vert++ {
+			for j := 0; j < 2; j++ {
+				x, y := right-j, vert
+				if (right+1)&2 == 0 {
+					y = c.size - 1 - vert
+				}
+				if ref.function[y*c.size+x] || i >= len(raw)*8 {
+					continue
+				}
+				if c.Dark(x, y) != maskAt(mask, x, y) {
+					raw[i>>3] |= 1 << (7 - i&7)
+				}
+				i++
+			}
+		}
+	}
+
+	// De-interleave into blocks
+	blocks := numBlocks[level][version]
+	ecc := eccPerBlock[level][version]
+	short := blocks - len(raw)%blocks
+	shortLen := len(raw) / blocks
+	parts := make([][]byte, blocks)
+	k := 0
+	for i := 0; i <= shortLen-ecc; i++ {
+		for b := range parts {
+			if i < shortLen-ecc || b >= short {
+				parts[b] = append(parts[b], raw[k])
+				k++
+			}
+		}
+	}
+	for i := 0; i < ecc; i++ {
+		for b := range parts {
+			parts[b] = append(parts[b], raw[k])
+			k++
+		}
+	}
+
+	var data []byte
+	for b, block := range parts {
+		// A valid codeword evaluates to zero at every generator root
+		root := byte(1)
+		for s := 0; s < ecc; s++ {
+			var sum byte
+			for _, v := range block {
+				sum = gfMul(sum, root) ^ v
+			}
+			if sum != 0 {
+				t.Fatalf("block %d syndrome %d = %d, want 0", b, s, sum)
+			}
+			root = gfMul(root, 2)
+		}
+		data = append(data, block[:len(block)-ecc]...)
+	}
+
+	// Byte mode segment
+	bit := func(n int) bool { return data[n>>3]>>(7-n&7)&1 == 1 }
+	field := func(start, width int) int {
+		v := 0
+		for n := start; n < start+width; n++ {
+			v <<= 1
+			if bit(n) {
+				v |= 1
+			}
+		}
+		return v
+	}
+	if mode := field(0, 4); mode != 0b0100 {
+		t.Fatalf("mode = %04b, want byte mode", mode)
+	}
+	length := field(4, countBits(version))
+	out := make([]byte, length)
+	for n := range out {
+		out[n] = byte(field(4+countBits(version)+8*n, 8))
+	}
+	return string(out)
+}
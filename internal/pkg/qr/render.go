@@ -0,0 +1,113 @@
+package qr
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+)
+
+// Options controls how a Code is drawn
+type Options struct {
+	// Size is the requested width in pixels. Modules are whole pixels, so
+	// PNGs come out at the largest multiple that fits (never below one pixel
+	// per module). SVGs scale to exactly Size.
+	Size int
+
+	// Margin is the quiet zone in modules. Scanners expect 4.
+	Margin int
+
+	// Foreground and Background default to black on white
+	Foreground color.Color
+	Background color.Color
+}
+
+func (o Options) colors() (fg, bg color.Color) {
+	fg, bg = o.Foreground, o.Background
+	if fg == nil {
+		fg = color.Black
+	}
+	if bg == nil {
+		bg = color.White
+	}
+	return fg, bg
+}
+
+// Image draws the code as a two-color paletted image
+func (c *Code) Image(opts Options) image.Image {
+	fg, bg := opts.colors()
+	total := c.size + 2*opts.Margin
+	scale := opts.Size / total
+	if scale < 1 {
+		scale = 1
+	}
+
+	img := image.NewPaletted(image.Rect(0, 0, total*scale, total*scale), color.Palette{bg, fg})
+	for y := 0; y < c.size; y++ {
+		for x := 0; x < c.size; x++ {
+			if !c.Dark(x, y) {
+				continue
+			}
+			px, py := (x+opts.Margin)*scale, (y+opts.Margin)*scale
+			for dy := 0; dy < scale; dy++ {
+				row := img.Pix[(py+dy)*img.Stride+px:]
+				for dx := 0; dx < scale; dx++ {
+					row[dx] = 1
+				}
+			}
+		}
+	}
+	return img
+}
+
+// PNG encodes the code as a PNG image
+func (c *Code) PNG(opts Options) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, c.Image(opts)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// SVG renders the code as a standalone SVG document. Dark modules are one
+// path so the file stays small and scales cleanly.
+func (c *Code) SVG(opts Options) []byte {
+	fg, bg := opts.colors()
+	total := c.size + 2*opts.Margin
+	size := opts.Size
+	if size <= 0 {
+		size = total * 8
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`, size, size, total, total)
+	if _, _, _, a := bg.RGBA(); a > 0 {
+		fmt.Fprintf(&buf, `<rect width="%d" height="%d" fill="%s"%s/>`, total, total, hex(bg), opacity(bg))
+	}
+	fmt.Fprintf(&buf, `<path fill="%s"%s d="`, hex(fg), opacity(fg))
+	for y := 0; y < c.size; y++ {
+		for x := 0; x < c.size; x++ {
+			if c.Dark(x, y) {
+				fmt.Fprintf(&buf, "M%d %dh1v1h-1z", x+opts.Margin, y+opts.Margin)
+			}
+		}
+	}
+	buf.WriteString(`"/></svg>`)
+	return buf.Bytes()
+}
+
+// hex formats a color as #rrggbb
+func hex(c color.Color) string {
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+	return fmt.Sprintf("#%02x%02x%02x", n.R, n.G, n.B)
+}
+
+// opacity returns a fill-opacity attribute for translucent colors
+func opacity(c color.Color) string {
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+	if n.A == 0xff {
+		return ""
+	}
+	return fmt.Sprintf(` fill-opacity="%.3g"`, float64(n.A)/255)
+}
@@ -0,0 +1,71 @@
+// Package ratelimit counts attempts per key in a sliding window. State is
+// kept in memory, so limits apply per server process.
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// sweepSize is how many keys may be tracked before expired ones are swept
+const sweepSize = 10000
+
+// Limiter allows up to max attempts per key within window
+type Limiter struct {
+	mu     sync.Mutex
+	max    int
+	window time.Duration
+	hits   map[string][]time.Time
+	now    func() time.Time
+}
+
+// New creates a Limiter
+func New(max int, window time.Duration) *Limiter {
+	return &Limiter{
+		max:    max,
+		window: window,
+		hits:   make(map[string][]time.Time),
+		now:    time.Now,
+	}
+}
+
+// Allow records an attempt for key. When the key is over its limit the
+// attempt is not recorded, and the returned duration says how long until
+// the oldest attempt expires.
+func (l *Limiter) Allow(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if len(l.hits) >= sweepSize {
+		for k, hits := range l.hits {
+			if len(l.recent(hits, now)) == 0 {
+				delete(l.hits, k)
+			}
+		}
+	}
+
+	hits := l.recent(l.hits[key], now)
+	if len(hits) >= l.max {
+		l.hits[key] = hits
+		return false, hits[0].Add(l.window).Sub(now)
+	}
+	l.hits[key] = append(hits, now)
+	return true, 0
+}
+
+// Reset forgets a key's attempts, e.g. after a successful login
+func (l *Limiter) Reset(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.hits, key)
+}
+
+// recent drops attempts that have left the window
+func (l *Limiter) recent(hits []time.Time, now time.Time) []time.Time {
+	cutoff := now.Add(-l.window)
+	for len(hits) > 0 && !hits[0].After(cutoff) {
+		hits = hits[1:]
+	}
+	return hits
+}
@@ -0,0 +1,44 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLimiter(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	l := New(3, time.Minute)
+	l.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		if ok, _ := l.Allow("a"); !ok {
+			t.Fatalf("attempt %d denied", i+1)
+		}
+		now = now.Add(10 * time.Second)
+	}
+
+	ok, retry := l.Allow("a")
+	if ok {
+		t.Fatal("fourth attempt allowed")
+	}
+	if retry != 30*time.Second {
+		t.Errorf("retry = %v, want 30s", retry)
+	}
+	if ok, _ := l.Allow("b"); !ok {
+		t.Error("limit leaked to another key")
+	}
+
+	// The oldest attempt leaves the window
+	now = now.Add(30 * time.Second)
+	if ok, _ := l.Allow("a"); !ok {
+		t.Error("attempt denied after the window moved")
+	}
+	if ok, _ := l.Allow("a"); ok {
+		t.Error("attempt allowed while still at the limit")
+	}
+
+	l.Reset("a")
+	if ok, _ := l.Allow("a"); !ok {
+		t.Error("attempt denied after Reset")
+	}
+}
@@ -2,8 +2,12 @@ package response
 
 import (
 	"encoding/json"
+	"fmt"
+	"html"
 	"log/slog"
 	"net/http"
+
+	"linkbio/internal/i18n"
 )
 
 // Responder handles HTTP responses
@@ -28,11 +32,33 @@ func (r *Responder) JSON(w http.ResponseWriter, status int, data any) {
 	}
 }
 
-// Error sends an error response (always HTML for simplicity since all clients are HTMX/browser)
-func (r *Responder) Error(w http.ResponseWriter, status int, message string) {
+// Error sends an error response (always HTML for simplicity since all clients are HTMX/browser).
+// The message is translated into the request's language.
+func (r *Responder) Error(w http.ResponseWriter, req *http.Request, status int, message string) {
 	r.log.Warn("error response", "status", status, "message", message)
+	writeMessage(w, status, i18n.FromContext(req.Context()).T(message))
+}
+
+// Errorf sends an error response whose message has values in it. format
+// is translated before the values are filled in.
+func (r *Responder) Errorf(w http.ResponseWriter, req *http.Request, status int, format string, args ...any) {
+	r.log.Warn("error response", "status", status, "message", fmt.Sprintf(format, args...))
+	writeMessage(w, status, i18n.FromContext(req.Context()).T(format, args...))
+}
+
+// Invalid sends a validation error's message as an error response
+func (r *Responder) Invalid(w http.ResponseWriter, req *http.Request, status int, err error) {
+	r.log.Warn("error response", "status", status, "message", err.Error())
+	writeMessage(w, status, i18n.FromContext(req.Context()).Err(err))
+}
+
+// writeMessage writes an error message as HTML. The client inserts it into
+// the page as is, and messages may repeat what the user typed, so it is
+// always escaped here.
+func writeMessage(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 	w.WriteHeader(status)
-	w.Write([]byte(message))
+	w.Write([]byte(html.EscapeString(message)))
 }
 
 // HXRedirect sends an HTMX redirect header
@@ -0,0 +1,37 @@
+package response
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"linkbio/internal/testutil"
+)
+
+func TestResponder_EscapesMessages(t *testing.T) {
+	r := New(testutil.TestLogger())
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+
+	tests := []struct {
+		name  string
+		write func(w http.ResponseWriter)
+	}{
+		{"Error", func(w http.ResponseWriter) { r.Error(w, req, http.StatusNotFound, "<b>gone</b>") }},
+		{"Errorf", func(w http.ResponseWriter) { r.Errorf(w, req, http.StatusConflict, "You have %s", "<b>gone</b>") }},
+		{"Invalid", func(w http.ResponseWriter) {
+			r.Invalid(w, req, http.StatusUnprocessableEntity, errors.New("<b>gone</b>"))
+		}},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		tt.write(rec)
+		if got := rec.Header().Get("Content-Type"); got != "text/html; charset=utf-8" {
+			t.Errorf("%s Content-Type = %q", tt.name, got)
+		}
+		if body := rec.Body.String(); body == "" || strings.ContainsAny(body, "<>") {
+			t.Errorf("%s body = %q, want the markup escaped", tt.name, body)
+		}
+	}
+}
@@ -3,11 +3,19 @@ package templates
 import (
 	"html/template"
 	"io"
+	"reflect"
 	"strings"
+
+	"linkbio/internal/i18n"
 )
 
-// FuncMap returns the standard template functions
-func FuncMap() template.FuncMap {
+// FuncMap returns the standard template functions. t and tn translate into
+// l's language:
+//
+//	{{t "Add Link"}}
+//	{{t "Hi, %s" .Name}}
+//	{{tn "%d click" "%d clicks" .Clicks}}
+func FuncMap(l *i18n.Localizer) template.FuncMap {
 	return template.FuncMap{
 		"multiply": func(a, b int) int { return a * b },
 		"slice": func(s string, start, end int) string {
@@ -22,18 +30,49 @@ func FuncMap() template.FuncMap {
 		"upper": func(s string) string {
 			return strings.ToUpper(s)
 		},
+		"hasPrefix": strings.HasPrefix,
+		"t":         l.T,
+		"tn": func(singular, plural string, n any, args ...any) string {
+			return l.N(singular, plural, toInt(n), args...)
+		},
+		"date": l.Date,
+		"lang": l.Lang,
+	}
+}
+
+// toInt accepts any integer type, since counts in view data vary
+func toInt(n any) int {
+	v := reflect.ValueOf(n)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return int(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int(v.Uint())
 	}
+	return 0
 }
 
-// Render parses and executes the base layout with a page template
-func Render(w io.Writer, page string, data interface{}) error {
-	tmpl, err := template.New("base.html").Funcs(FuncMap()).ParseFiles(
+// Render parses and executes the base layout with a page template.
+// Partials are parsed too, so pages can embed them with {{template "link.html" .}}.
+func Render(w io.Writer, l *i18n.Localizer, page string, data interface{}) error {
+	tmpl, err := template.New("base.html").Funcs(FuncMap(l)).ParseFiles(
 		"web/templates/layouts/base.html",
 		"web/templates/pages/"+page,
 	)
 	if err != nil {
 		return err
 	}
+	if _, err := tmpl.ParseGlob("web/templates/partials/*.html"); err != nil {
+		return err
+	}
 	return tmpl.ExecuteTemplate(w, "base", data)
 }
 
+// RenderPartial executes a single partial template (for HTMX swaps)
+func RenderPartial(w io.Writer, l *i18n.Localizer, name string, data interface{}) error {
+	tmpl, err := template.New(name).Funcs(FuncMap(l)).ParseGlob("web/templates/partials/*.html")
+	if err != nil {
+		return err
+	}
+	return tmpl.ExecuteTemplate(w, name, data)
+}
@@ -0,0 +1,71 @@
+// Package vcard writes contact cards in the vCard 3.0 format (RFC 2426),
+// which every address book imports. Values are escaped and long lines
+// folded as the format requires.
+package vcard
+
+import (
+	"strings"
+
+	"linkbio/internal/pkg/contentline"
+)
+
+// ContentType is the media type of a vCard file
+const ContentType = "text/vcard; charset=utf-8"
+
+// Social is a profile on a social network
+type Social struct {
+	Type string // network name, e.g. "instagram"
+	URL  string
+}
+
+// Card is a person's contact card. Only Name is required.
+type Card struct {
+	Name     string // formatted name
+	Note     string
+	URL      string // the person's page
+	PhotoURL string
+	Email    string
+	Socials  []Social
+}
+
+// Bytes encodes the card
+func (c Card) Bytes() []byte {
+	var b strings.Builder
+	line := func(name, value string) {
+		contentline.Write(&b, name, value)
+	}
+
+	line("BEGIN", "VCARD")
+	line("VERSION", "3.0")
+	line("FN", contentline.Escape(c.Name))
+	// N is required; a display name has no reliable family/given split
+	line("N", contentline.Escape(c.Name)+";;;;")
+	if c.Note != "" {
+		line("NOTE", contentline.Escape(c.Note))
+	}
+	if c.URL != "" {
+		line("URL", c.URL)
+	}
+	if c.PhotoURL != "" {
+		line("PHOTO;VALUE=uri", c.PhotoURL)
+	}
+	if c.Email != "" {
+		line("EMAIL;TYPE=INTERNET", contentline.Escape(c.Email))
+	}
+	for _, s := range c.Socials {
+		line("X-SOCIALPROFILE;TYPE="+paramValue(s.Type), s.URL)
+	}
+	line("END", "VCARD")
+	return []byte(b.String())
+}
+
+// paramValue keeps the characters a bare parameter value may hold
+func paramValue(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-':
+			return r
+		}
+		return -1
+	}, s)
+}
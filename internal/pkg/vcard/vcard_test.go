@@ -0,0 +1,59 @@
+package vcard
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestCard_Bytes(t *testing.T) {
+	card := Card{
+		Name:     "Doe, Jane; PhD",
+		Note:     "Sales lead\nCall me: mornings",
+		URL:      "https://linkbio.test/u/jane",
+		PhotoURL: "https://linkbio.test/media/avatar.jpg",
+		Email:    "jane@example.com",
+		Socials:  []Social{{Type: "instagram", URL: "https://instagram.com/jane"}},
+	}
+
+	want := "BEGIN:VCARD\r\n" +
+		"VERSION:3.0\r\n" +
+		`FN:Doe\, Jane\; PhD` + "\r\n" +
+		`N:Doe\, Jane\; PhD;;;;` + "\r\n" +
+		`NOTE:Sales lead\nCall me: mornings` + "\r\n" +
+		"URL:https://linkbio.test/u/jane\r\n" +
+		"PHOTO;VALUE=uri:https://linkbio.test/media/avatar.jpg\r\n" +
+		"EMAIL;TYPE=INTERNET:jane@example.com\r\n" +
+		"X-SOCIALPROFILE;TYPE=instagram:https://instagram.com/jane\r\n" +
+		"END:VCARD\r\n"
+	if got := string(card.Bytes()); got != want {
+		t.Errorf("Bytes() =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestFolding(t *testing.T) {
+	// Multi-byte characters straddle every 75-octet boundary
+	card := Card{Name: "Zoë", Note: strings.Repeat("日本語のメモ", 40)}
+	out := string(card.Bytes())
+
+	if !strings.HasSuffix(out, "\r\n") || strings.Contains(strings.ReplaceAll(out, "\r\n", ""), "\n") {
+		t.Fatal("lines must end in CRLF")
+	}
+	var unfolded []string
+	for _, line := range strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n") {
+		if len(line) > 75 {
+			t.Errorf("line is %d octets: %q", len(line), line)
+		}
+		if !utf8.ValidString(line) {
+			t.Errorf("line splits a character: %q", line)
+		}
+		if strings.HasPrefix(line, " ") {
+			unfolded[len(unfolded)-1] += line[1:]
+			continue
+		}
+		unfolded = append(unfolded, line)
+	}
+	if unfolded[4] != "NOTE:"+card.Note {
+		t.Errorf("unfolded NOTE = %q", unfolded[4])
+	}
+}
@@ -0,0 +1,279 @@
+package preview
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// ErrBlockedAddress is returned when a URL resolves to a private or reserved IP
+var ErrBlockedAddress = errors.New("preview: address not allowed")
+
+// Metadata is what we could learn about a page
+type Metadata struct {
+	Title       string
+	Description string
+	Image       string // absolute URL of og:image
+	Favicon     string // absolute URL of the site icon
+}
+
+// Options configures a Fetcher. Zero values fall back to sensible defaults.
+type Options struct {
+	Timeout      time.Duration // whole request, including redirects
+	MaxBytes     int64         // how much of the body we are willing to read
+	UserAgent    string
+	AllowPrivate bool // tests only: permit loopback/private addresses
+}
+
+// Fetcher downloads pages and extracts Open Graph metadata
+type Fetcher struct {
+	client *http.Client
+	opts   Options
+}
+
+// New creates a new Fetcher with SSRF protection
+func New(opts Options) *Fetcher {
+	if opts.Timeout <= 0 {
+		opts.Timeout = 5 * time.Second
+	}
+	if opts.MaxBytes <= 0 {
+		opts.MaxBytes = 512 << 10
+	}
+	if opts.UserAgent == "" {
+		opts.UserAgent = "LinkBio-Preview/1.0"
+	}
+
+	dialer := &net.Dialer{Timeout: opts.Timeout}
+	if !opts.AllowPrivate {
+		dialer.Control = DialControl
+	}
+
+	transport := &http.Transport{
+		Proxy:                 nil, // a proxy would dial on our behalf and bypass the check
+		DialContext:           dialer.DialContext,
+		TLSHandshakeTimeout:   opts.Timeout,
+		ResponseHeaderTimeout: opts.Timeout,
+		MaxIdleConns:          10,
+		IdleConnTimeout:       30 * time.Second,
+	}
+
+	client := &http.Client{
+		Transport: transport,
+		Timeout:   opts.Timeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 5 {
+				return errors.New("preview: too many redirects")
+			}
+			if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+				return errors.New("preview: unsupported redirect scheme")
+			}
+			return nil
+		},
+	}
+
+	return &Fetcher{client: client, opts: opts}
+}
+
+// Fetch downloads a page and extracts its metadata
+func (f *Fetcher) Fetch(ctx context.Context, rawURL string) (*Metadata, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("preview: unsupported scheme %q", u.Scheme)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", f.opts.UserAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return nil, fmt.Errorf("preview: unexpected status %d", resp.StatusCode)
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		return nil, fmt.Errorf("preview: unsupported content type %q", mediaType)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, f.opts.MaxBytes))
+	if err != nil {
+		return nil, err
+	}
+
+	// Relative URLs resolve against the final URL after redirects
+	return Parse(string(body), resp.Request.URL), nil
+}
+
+var (
+	tagPattern   = regexp.MustCompile(`(?is)<(meta|link)\b[^>]*>`)
+	attrPattern  = regexp.MustCompile(`(?s)([a-zA-Z_:-]+)\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'>]+))`)
+	titlePattern = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+)
+
+// Parse extracts metadata from an HTML document. base resolves relative URLs.
+func Parse(doc string, base *url.URL) *Metadata {
+	// Only the head matters; skip the rest of large documents
+	if i := strings.Index(strings.ToLower(doc), "</head>"); i >= 0 {
+		doc = doc[:i]
+	}
+
+	meta := &Metadata{}
+	var fallbackTitle, fallbackDescription string
+
+	for _, tag := range tagPattern.FindAllStringSubmatch(doc, -1) {
+		attrs := parseAttrs(tag[0])
+		switch strings.ToLower(tag[1]) {
+		case "meta":
+			key := strings.ToLower(attrs["property"])
+			if key == "" {
+				key = strings.ToLower(attrs["name"])
+			}
+			content := strings.TrimSpace(attrs["content"])
+			switch key {
+			case "og:title":
+				meta.Title = content
+			case "og:description":
+				meta.Description = content
+			case "og:image", "og:image:url", "og:image:secure_url":
+				if meta.Image == "" {
+					meta.Image = resolve(base, content)
+				}
+			case "twitter:title":
+				fallbackTitle = content
+			case "description", "twitter:description":
+				if fallbackDescription == "" {
+					fallbackDescription = content
+				}
+			}
+		case "link":
+			for _, rel := range strings.Fields(strings.ToLower(attrs["rel"])) {
+				if (rel == "icon" || rel == "apple-touch-icon") && meta.Favicon == "" {
+					meta.Favicon = resolve(base, attrs["href"])
+				}
+			}
+		}
+	}
+
+	if meta.Title == "" {
+		meta.Title = fallbackTitle
+	}
+	if meta.Title == "" {
+		if m := titlePattern.FindStringSubmatch(doc); m != nil {
+			meta.Title = strings.Join(strings.Fields(html.UnescapeString(m[1])), " ")
+		}
+	}
+	if meta.Description == "" {
+		meta.Description = fallbackDescription
+	}
+	if meta.Favicon == "" && base != nil {
+		meta.Favicon = resolve(base, "/favicon.ico")
+	}
+
+	return meta
+}
+
+// parseAttrs returns the lowercased attribute names of a tag with unescaped values
+func parseAttrs(tag string) map[string]string {
+	attrs := make(map[string]string)
+	for _, m := range attrPattern.FindAllStringSubmatch(tag, -1) {
+		attrs[strings.ToLower(m[1])] = html.UnescapeString(m[2] + m[3] + m[4])
+	}
+	return attrs
+}
+
+// resolve makes ref absolute against base, keeping only http(s) results
+func resolve(base *url.URL, ref string) string {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return ""
+	}
+	u, err := url.Parse(ref)
+	if err != nil {
+		return ""
+	}
+	if base != nil {
+		u = base.ResolveReference(u)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return ""
+	}
+	return u.String()
+}
+
+// DialControl is a net.Dialer Control func that refuses private, local and
+// reserved addresses. It runs after DNS resolution, so every dial (including
+// redirects and rebinding tricks) is checked against the real IP.
+func DialControl(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || isBlocked(ip) {
+		return ErrBlockedAddress
+	}
+	return nil
+}
+
+// Ranges that are not covered by the net.IP helpers
+var reservedNets = mustParseCIDRs(
+	"0.0.0.0/8",       // "this" network
+	"100.64.0.0/10",   // carrier-grade NAT
+	"192.0.0.0/24",    // IETF protocol assignments
+	"192.0.2.0/24",    // TEST-NET-1
+	"198.18.0.0/15",   // benchmarking
+	"198.51.100.0/24", // TEST-NET-2
+	"203.0.113.0/24",  // TEST-NET-3
+	"240.0.0.0/4",     // reserved
+	"64:ff9b::/96",    // NAT64
+)
+
+// isBlocked reports whether ip is private, local or otherwise not routable
+func isBlocked(ip net.IP) bool {
+	if v4 := ip.To4(); v4 != nil {
+		ip = v4
+	}
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return true
+	}
+	for _, n := range reservedNets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	nets := make([]*net.IPNet, 0, len(cidrs))
+	for _, c := range cidrs {
+		_, n, err := net.ParseCIDR(c)
+		if err != nil {
+			panic(err)
+		}
+		nets = append(nets, n)
+	}
+	return nets
+}
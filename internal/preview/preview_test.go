@@ -0,0 +1,156 @@
+package preview
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testPage = `<!DOCTYPE html>
+<html><head>
+<title>Fallback Title</title>
+<meta property="og:title" content="Tom &amp; Jerry">
+<meta property='og:description' content='Cat and mouse'>
+<meta property="og:image" content="/img/cover.png">
+<link rel="shortcut icon" href="/static/icon.png">
+</head><body><meta property="og:title" content="Ignored body tag"></body></html>`
+
+func TestParse(t *testing.T) {
+	base, _ := url.Parse("https://example.com/page")
+	meta := Parse(testPage, base)
+
+	if meta.Title != "Tom & Jerry" {
+		t.Errorf("Title = %q, want %q", meta.Title, "Tom & Jerry")
+	}
+	if meta.Description != "Cat and mouse" {
+		t.Errorf("Description = %q, want %q", meta.Description, "Cat and mouse")
+	}
+	if meta.Image != "https://example.com/img/cover.png" {
+		t.Errorf("Image = %q", meta.Image)
+	}
+	if meta.Favicon != "https://example.com/static/icon.png" {
+		t.Errorf("Favicon = %q", meta.Favicon)
+	}
+}
+
+func TestParse_Fallbacks(t *testing.T) {
+	base, _ := url.Parse("https://example.com/")
+	meta := Parse(`<head><title>
+		Plain   Title </title><meta name="description" content="Plain description"></head>`, base)
+
+	if meta.Title != "Plain Title" {
+		t.Errorf("Title = %q, want %q", meta.Title, "Plain Title")
+	}
+	if meta.Description != "Plain description" {
+		t.Errorf("Description = %q", meta.Description)
+	}
+	if meta.Favicon != "https://example.com/favicon.ico" {
+		t.Errorf("Favicon = %q, want default /favicon.ico", meta.Favicon)
+	}
+}
+
+func TestParse_RejectsNonHTTPImage(t *testing.T) {
+	base, _ := url.Parse("https://example.com/")
+	meta := Parse(`<meta property="og:image" content="javascript:alert(1)">`, base)
+	if meta.Image != "" {
+		t.Errorf("Image = %q, want empty", meta.Image)
+	}
+}
+
+func TestFetcher_Fetch(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(testPage))
+	}))
+	defer srv.Close()
+
+	f := New(Options{AllowPrivate: true, Timeout: time.Second})
+	meta, err := f.Fetch(context.Background(), srv.URL)
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if meta.Title != "Tom & Jerry" {
+		t.Errorf("Title = %q", meta.Title)
+	}
+	if !strings.HasPrefix(meta.Image, srv.URL) {
+		t.Errorf("Image = %q, want it resolved against %s", meta.Image, srv.URL)
+	}
+}
+
+func TestFetcher_Fetch_BlocksPrivateAddresses(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request reached a loopback server")
+	}))
+	defer srv.Close()
+
+	f := New(Options{Timeout: time.Second})
+	_, err := f.Fetch(context.Background(), srv.URL)
+	if !errors.Is(err, ErrBlockedAddress) {
+		t.Errorf("Fetch() error = %v, want ErrBlockedAddress", err)
+	}
+}
+
+func TestFetcher_Fetch_SizeLimit(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte("<head>" + strings.Repeat(" ", 1024)))
+		w.Write([]byte(`<meta property="og:title" content="Too far"></head>`))
+	}))
+	defer srv.Close()
+
+	f := New(Options{AllowPrivate: true, MaxBytes: 512})
+	meta, err := f.Fetch(context.Background(), srv.URL)
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if meta.Title != "" {
+		t.Errorf("Title = %q, want nothing past the size limit", meta.Title)
+	}
+}
+
+func TestFetcher_Fetch_NonHTML(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/pdf")
+	}))
+	defer srv.Close()
+
+	f := New(Options{AllowPrivate: true})
+	if _, err := f.Fetch(context.Background(), srv.URL); err == nil {
+		t.Error("Fetch() should reject non-HTML responses")
+	}
+}
+
+func TestIsBlocked(t *testing.T) {
+	tests := []struct {
+		ip      string
+		blocked bool
+	}{
+		{"127.0.0.1", true},
+		{"10.1.2.3", true},
+		{"172.16.0.1", true},
+		{"192.168.1.1", true},
+		{"169.254.169.254", true}, // cloud metadata
+		{"100.64.0.1", true},
+		{"0.0.0.0", true},
+		{"::1", true},
+		{"fc00::1", true},
+		{"fe80::1", true},
+		{"::ffff:127.0.0.1", true},
+		{"93.184.216.34", false},
+		{"2606:4700::1111", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ip, func(t *testing.T) {
+			if got := isBlocked(net.ParseIP(tt.ip)); got != tt.blocked {
+				t.Errorf("isBlocked(%s) = %v, want %v", tt.ip, got, tt.blocked)
+			}
+		})
+	}
+}
@@ -18,18 +18,32 @@ func NewAnalyticsRepository(db *sql.DB) *AnalyticsRepository {
 	return &AnalyticsRepository{db: db}
 }
 
+// Record stores a tracking event
+func (r *AnalyticsRepository) Record(ctx context.Context, e *model.Analytics) error {
+	query := `INSERT INTO analytics (user_id, link_id, event_type, source, platform, page_id, referrer, user_agent) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+	_, err := r.db.ExecContext(ctx, query, e.UserID, e.LinkID, e.EventType, e.Source, e.Platform, e.PageID, e.Referrer, e.UserAgent)
+	return err
+}
+
 // RecordPageView records a page view event
 func (r *AnalyticsRepository) RecordPageView(ctx context.Context, userID int64, referrer, userAgent string) error {
-	query := `INSERT INTO analytics (user_id, event_type, referrer, user_agent) VALUES (?, 'page_view', ?, ?)`
-	_, err := r.db.ExecContext(ctx, query, userID, referrer, userAgent)
-	return err
+	return r.Record(ctx, &model.Analytics{
+		UserID:    userID,
+		EventType: "page_view",
+		Referrer:  referrer,
+		UserAgent: userAgent,
+	})
 }
 
 // RecordLinkClick records a link click event
 func (r *AnalyticsRepository) RecordLinkClick(ctx context.Context, userID, linkID int64, referrer, userAgent string) error {
-	query := `INSERT INTO analytics (user_id, link_id, event_type, referrer, user_agent) VALUES (?, ?, 'link_click', ?, ?)`
-	_, err := r.db.ExecContext(ctx, query, userID, linkID, referrer, userAgent)
-	return err
+	return r.Record(ctx, &model.Analytics{
+		UserID:    userID,
+		LinkID:    &linkID,
+		EventType: "link_click",
+		Referrer:  referrer,
+		UserAgent: userAgent,
+	})
 }
 
 // GetSummary retrieves analytics summary for a user within the given days
@@ -77,6 +91,145 @@ func (r *AnalyticsRepository) GetSummary(ctx context.Context, userID int64, days
 		}
 		summary.LinkClicks = append(summary.LinkClicks, lc)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Views and clicks per traffic source (QR scans etc.)
+	rows, err = r.db.QueryContext(ctx, `
+		SELECT source,
+		       SUM(CASE WHEN event_type = 'page_view' THEN 1 ELSE 0 END),
+		       SUM(CASE WHEN event_type = 'link_click' THEN 1 ELSE 0 END)
+		FROM analytics
+		WHERE user_id = ? AND source != '' AND created_at >= ?
+		GROUP BY source
+		ORDER BY source
+	`, userID, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var sc model.SourceCount
+		if err := rows.Scan(&sc.Source, &sc.Views, &sc.Clicks); err != nil {
+			return nil, err
+		}
+		summary.Sources = append(summary.Sources, sc)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Clicks on social icons per platform
+	rows, err = r.db.QueryContext(ctx, `
+		SELECT platform, COUNT(*) as clicks
+		FROM analytics
+		WHERE user_id = ? AND event_type = 'social_click' AND created_at >= ?
+		GROUP BY platform
+		ORDER BY clicks DESC, platform
+	`, userID, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var sc model.SocialCount
+		if err := rows.Scan(&sc.Platform, &sc.Clicks); err != nil {
+			return nil, err
+		}
+		summary.Socials = append(summary.Socials, sc)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Sensitive link warnings and how many visitors continued
+	rows, err = r.db.QueryContext(ctx, `
+		SELECT a.link_id, l.title,
+		       SUM(CASE WHEN a.event_type = 'interstitial_view' THEN 1 ELSE 0 END) as views,
+		       SUM(CASE WHEN a.event_type = 'link_click' THEN 1 ELSE 0 END)
+		FROM analytics a
+		JOIN links l ON a.link_id = l.id
+		WHERE a.user_id = ? AND a.event_type IN ('interstitial_view', 'link_click') AND a.created_at >= ?
+		GROUP BY a.link_id
+		HAVING views > 0
+		ORDER BY views DESC
+	`, userID, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var wc model.WarningCount
+		if err := rows.Scan(&wc.LinkID, &wc.Title, &wc.Views, &wc.Continued); err != nil {
+			return nil, err
+		}
+		summary.Warnings = append(summary.Warnings, wc)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Views and clicks per page, counting each click for the page the link
+	// was on when it was clicked
+	rows, err = r.db.QueryContext(ctx, `
+		SELECT s.page_id, COALESCE(p.title, ''), s.views, s.clicks
+		FROM (
+			SELECT a.page_id,
+			       SUM(CASE WHEN a.event_type = 'page_view' THEN 1 ELSE 0 END) as views,
+			       SUM(CASE WHEN a.event_type = 'link_click' THEN 1 ELSE 0 END) as clicks
+			FROM analytics a
+			WHERE a.user_id = ? AND a.event_type IN ('page_view', 'link_click') AND a.created_at >= ?
+			GROUP BY a.page_id
+		) s
+		LEFT JOIN pages p ON s.page_id = p.id
+		ORDER BY COALESCE(p.position, 0), s.page_id
+	`, userID, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var pc model.PageCount
+		var pageID sql.NullInt64
+		if err := rows.Scan(&pageID, &pc.Title, &pc.Views, &pc.Clicks); err != nil {
+			return nil, err
+		}
+		if pageID.Valid {
+			pc.PageID = &pageID.Int64
+		}
+		summary.Pages = append(summary.Pages, pc)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Clicks per tag on the links carrying it, such as all sponsored links
+	rows, err = r.db.QueryContext(ctx, `
+		SELECT t.id, t.name, t.color, COUNT(*) as clicks
+		FROM analytics a
+		JOIN link_tags lt ON lt.link_id = a.link_id
+		JOIN tags t ON t.id = lt.tag_id
+		WHERE a.user_id = ? AND a.event_type = 'link_click' AND a.created_at >= ?
+		GROUP BY t.id
+		ORDER BY clicks DESC, t.name
+	`, userID, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var tc model.TagCount
+		if err := rows.Scan(&tc.TagID, &tc.Name, &tc.Color, &tc.Clicks); err != nil {
+			return nil, err
+		}
+		summary.Tags = append(summary.Tags, tc)
+	}
 
 	return summary, rows.Err()
 }
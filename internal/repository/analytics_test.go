@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"testing"
+	"time"
 
 	"linkbio/internal/model"
 	"linkbio/internal/testutil"
@@ -128,6 +129,163 @@ func TestAnalyticsRepository_GetSummary(t *testing.T) {
 	}
 }
 
+func TestAnalyticsRepository_GetSummary_Sources(t *testing.T) {
+	db := testutil.TestDB(t)
+	userRepo := NewUserRepository(db)
+	linkRepo := NewLinkRepository(db)
+	analyticsRepo := NewAnalyticsRepository(db)
+	ctx := context.Background()
+
+	user := createTestUser(t, userRepo, "sourcetest")
+	link := &model.Link{UserID: user.ID, Title: "Test Link", URL: "https://example.com", IsActive: true}
+	linkRepo.Create(ctx, link)
+
+	// Two QR scans of the profile, one scanned link QR, plus direct traffic
+	analyticsRepo.Record(ctx, &model.Analytics{UserID: user.ID, EventType: "page_view", Source: model.SourceQR})
+	analyticsRepo.Record(ctx, &model.Analytics{UserID: user.ID, EventType: "page_view", Source: model.SourceQR})
+	analyticsRepo.Record(ctx, &model.Analytics{UserID: user.ID, LinkID: &link.ID, EventType: "link_click", Source: model.SourceQR})
+	analyticsRepo.RecordPageView(ctx, user.ID, "", "")
+	analyticsRepo.RecordLinkClick(ctx, user.ID, link.ID, "", "")
+
+	summary, err := analyticsRepo.GetSummary(ctx, user.ID, 7)
+	if err != nil {
+		t.Fatalf("GetSummary() error = %v", err)
+	}
+	if summary.TotalViews != 3 || summary.TotalClicks != 2 {
+		t.Errorf("totals = %d views, %d clicks; want 3, 2", summary.TotalViews, summary.TotalClicks)
+	}
+	if len(summary.Sources) != 1 {
+		t.Fatalf("Sources = %+v, want only qr", summary.Sources)
+	}
+	if got := summary.Sources[0]; got.Source != model.SourceQR || got.Views != 2 || got.Clicks != 1 {
+		t.Errorf("qr source = %+v, want 2 views and 1 click", got)
+	}
+}
+
+func TestAnalyticsRepository_GetSummary_Warnings(t *testing.T) {
+	db := testutil.TestDB(t)
+	userRepo := NewUserRepository(db)
+	linkRepo := NewLinkRepository(db)
+	analyticsRepo := NewAnalyticsRepository(db)
+	ctx := context.Background()
+
+	user := createTestUser(t, userRepo, "warningtest")
+	sensitive := &model.Link{UserID: user.ID, Title: "Spoilers", URL: "https://spoilers.example", IsActive: true, IsSensitive: true}
+	plain := &model.Link{UserID: user.ID, Title: "Plain", URL: "https://example.com", IsActive: true}
+	linkRepo.Create(ctx, sensitive)
+	linkRepo.Create(ctx, plain)
+
+	// Three warnings shown, one visitor continued
+	for i := 0; i < 3; i++ {
+		analyticsRepo.Record(ctx, &model.Analytics{UserID: user.ID, LinkID: &sensitive.ID, EventType: "interstitial_view"})
+	}
+	analyticsRepo.RecordLinkClick(ctx, user.ID, sensitive.ID, "", "")
+	analyticsRepo.RecordLinkClick(ctx, user.ID, plain.ID, "", "")
+
+	summary, err := analyticsRepo.GetSummary(ctx, user.ID, 7)
+	if err != nil {
+		t.Fatalf("GetSummary() error = %v", err)
+	}
+	if summary.TotalClicks != 2 {
+		t.Errorf("TotalClicks = %d, want warnings left out", summary.TotalClicks)
+	}
+	if len(summary.Warnings) != 1 {
+		t.Fatalf("Warnings = %+v, want only the sensitive link", summary.Warnings)
+	}
+	if got := summary.Warnings[0]; got.LinkID != sensitive.ID || got.Views != 3 || got.Continued != 1 {
+		t.Errorf("warning = %+v, want 3 views and 1 continued", got)
+	}
+}
+
+func TestAnalyticsRepository_GetSummary_Pages(t *testing.T) {
+	db := testutil.TestDB(t)
+	userRepo := NewUserRepository(db)
+	linkRepo := NewLinkRepository(db)
+	pageRepo := NewPageRepository(db)
+	analyticsRepo := NewAnalyticsRepository(db)
+	ctx := context.Background()
+
+	user := createTestUser(t, userRepo, "pagestats")
+	tour := &model.Page{UserID: user.ID, Slug: "tour", Title: "Tour"}
+	pageRepo.Create(ctx, tour)
+	home := &model.Link{UserID: user.ID, Title: "Home", URL: "https://home.example", IsActive: true}
+	tickets := &model.Link{UserID: user.ID, PageID: &tour.ID, Title: "Tickets", URL: "https://tickets.example", IsActive: true}
+	linkRepo.Create(ctx, home)
+	linkRepo.Create(ctx, tickets)
+	NewDraftRepository(db).Publish(ctx, user.ID, time.Now())
+
+	analyticsRepo.RecordPageView(ctx, user.ID, "", "")
+	analyticsRepo.Record(ctx, &model.Analytics{UserID: user.ID, EventType: "page_view", PageID: &tour.ID})
+	analyticsRepo.Record(ctx, &model.Analytics{UserID: user.ID, EventType: "page_view", PageID: &tour.ID})
+	analyticsRepo.RecordLinkClick(ctx, user.ID, home.ID, "", "")
+	analyticsRepo.Record(ctx, &model.Analytics{UserID: user.ID, LinkID: &tickets.ID, EventType: "link_click", PageID: &tour.ID})
+
+	// Moving a link later doesn't move the clicks it already had
+	tickets.PageID = nil
+	linkRepo.Update(ctx, tickets)
+	NewDraftRepository(db).Publish(ctx, user.ID, time.Now())
+
+	summary, err := analyticsRepo.GetSummary(ctx, user.ID, 7)
+	if err != nil {
+		t.Fatalf("GetSummary() error = %v", err)
+	}
+	if summary.TotalViews != 3 || summary.TotalClicks != 2 {
+		t.Errorf("totals = %d views, %d clicks; want 3, 2", summary.TotalViews, summary.TotalClicks)
+	}
+	if len(summary.Pages) != 2 {
+		t.Fatalf("Pages = %+v, want main and tour", summary.Pages)
+	}
+	if got := summary.Pages[0]; got.PageID != nil || got.Label() != "Main page" || got.Views != 1 || got.Clicks != 1 {
+		t.Errorf("main page = %+v, want 1 view and 1 click", got)
+	}
+	if got := summary.Pages[1]; got.PageID == nil || got.Label() != "Tour" || got.Views != 2 || got.Clicks != 1 {
+		t.Errorf("tour page = %+v, want 2 views and 1 click", got)
+	}
+}
+
+func TestAnalyticsRepository_GetSummary_Tags(t *testing.T) {
+	db := testutil.TestDB(t)
+	userRepo := NewUserRepository(db)
+	linkRepo := NewLinkRepository(db)
+	tagRepo := NewTagRepository(db)
+	analyticsRepo := NewAnalyticsRepository(db)
+	ctx := context.Background()
+
+	user := createTestUser(t, userRepo, "tagstats")
+	sponsored := &model.Tag{UserID: user.ID, Name: "sponsored", Color: "amber"}
+	music := &model.Tag{UserID: user.ID, Name: "music", Color: "pink"}
+	tagRepo.Create(ctx, sponsored)
+	tagRepo.Create(ctx, music)
+	ad := &model.Link{UserID: user.ID, Title: "Ad", URL: "https://ad.example", IsActive: true}
+	album := &model.Link{UserID: user.ID, Title: "Album", URL: "https://album.example", IsActive: true}
+	plain := &model.Link{UserID: user.ID, Title: "Plain", URL: "https://plain.example", IsActive: true}
+	linkRepo.Create(ctx, ad)
+	linkRepo.Create(ctx, album)
+	linkRepo.Create(ctx, plain)
+	tagRepo.SetLinkTags(ctx, user.ID, ad.ID, []int64{sponsored.ID})
+	tagRepo.SetLinkTags(ctx, user.ID, album.ID, []int64{sponsored.ID, music.ID})
+
+	analyticsRepo.RecordLinkClick(ctx, user.ID, ad.ID, "", "")
+	analyticsRepo.RecordLinkClick(ctx, user.ID, ad.ID, "", "")
+	analyticsRepo.RecordLinkClick(ctx, user.ID, album.ID, "", "")
+	analyticsRepo.RecordLinkClick(ctx, user.ID, plain.ID, "", "")
+	analyticsRepo.RecordPageView(ctx, user.ID, "", "")
+
+	summary, err := analyticsRepo.GetSummary(ctx, user.ID, 7)
+	if err != nil {
+		t.Fatalf("GetSummary() error = %v", err)
+	}
+	if len(summary.Tags) != 2 {
+		t.Fatalf("Tags = %+v, want sponsored and music", summary.Tags)
+	}
+	if got := summary.Tags[0]; got.TagID != sponsored.ID || got.Name != "sponsored" || got.Color != "amber" || got.Clicks != 3 {
+		t.Errorf("sponsored = %+v, want 3 clicks", got)
+	}
+	if got := summary.Tags[1]; got.TagID != music.ID || got.Clicks != 1 {
+		t.Errorf("music = %+v, want 1 click", got)
+	}
+}
+
 func TestAnalyticsRepository_UserIsolation(t *testing.T) {
 	db := testutil.TestDB(t)
 	userRepo := NewUserRepository(db)
@@ -165,3 +323,34 @@ func TestAnalyticsRepository_UserIsolation(t *testing.T) {
 		t.Errorf("User2 TotalViews = %d, want 3", summary2.TotalViews)
 	}
 }
+
+// A deleted page's views stay in the totals and move to the main page
+func TestAnalyticsRepository_GetSummary_DeletedPage(t *testing.T) {
+	db := testutil.TestDB(t)
+	userRepo := NewUserRepository(db)
+	pageRepo := NewPageRepository(db)
+	analyticsRepo := NewAnalyticsRepository(db)
+	ctx := context.Background()
+
+	user := createTestUser(t, userRepo, "gonepage")
+	tour := &model.Page{UserID: user.ID, Slug: "tour", Title: "Tour"}
+	pageRepo.Create(ctx, tour)
+	analyticsRepo.RecordPageView(ctx, user.ID, "", "")
+	analyticsRepo.Record(ctx, &model.Analytics{UserID: user.ID, EventType: "page_view", PageID: &tour.ID})
+	analyticsRepo.Record(ctx, &model.Analytics{UserID: user.ID, EventType: "page_view", PageID: &tour.ID})
+
+	if err := pageRepo.Delete(ctx, tour.ID, user.ID); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+
+	summary, err := analyticsRepo.GetSummary(ctx, user.ID, 7)
+	if err != nil {
+		t.Fatalf("GetSummary() error = %v", err)
+	}
+	if summary.TotalViews != 3 {
+		t.Errorf("TotalViews = %d, want 3", summary.TotalViews)
+	}
+	if len(summary.Pages) != 1 || summary.Pages[0].PageID != nil || summary.Pages[0].Views != 3 {
+		t.Errorf("Pages = %+v, want all 3 views on the main page", summary.Pages)
+	}
+}
@@ -175,7 +175,7 @@ func BenchmarkLinkRepository_GetActiveByUserID(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		linkRepo.GetActiveByUserID(ctx, user.ID)
+		linkRepo.GetActiveByUserID(ctx, user.ID, nil)
 	}
 }
 
@@ -55,8 +55,37 @@ func Migrate(db *sql.DB, log *slog.Logger) error {
 			title TEXT NOT NULL,
 			url TEXT NOT NULL,
 			icon TEXT DEFAULT '',
+			description TEXT NOT NULL DEFAULT '',
+			image_url TEXT NOT NULL DEFAULT '',
 			position INTEGER DEFAULT 0,
 			is_active INTEGER DEFAULT 1,
+			is_featured INTEGER NOT NULL DEFAULT 0,
+			is_sensitive INTEGER NOT NULL DEFAULT 0,
+			page_id INTEGER REFERENCES pages(id) ON DELETE SET NULL,
+			kind TEXT NOT NULL DEFAULT 'url',
+			starts_at DATETIME,
+			ends_at DATETIME,
+			location TEXT NOT NULL DEFAULT '',
+			time_zone TEXT NOT NULL DEFAULT '',
+			last_status INTEGER NOT NULL DEFAULT 0,
+			last_checked_at DATETIME,
+			pub_title TEXT NOT NULL DEFAULT '',
+			pub_url TEXT NOT NULL DEFAULT '',
+			pub_icon TEXT NOT NULL DEFAULT '',
+			pub_description TEXT NOT NULL DEFAULT '',
+			pub_image_url TEXT NOT NULL DEFAULT '',
+			pub_position INTEGER NOT NULL DEFAULT 0,
+			pub_is_active INTEGER NOT NULL DEFAULT 0,
+			pub_is_featured INTEGER NOT NULL DEFAULT 0,
+			pub_is_sensitive INTEGER NOT NULL DEFAULT 0,
+			pub_page_id INTEGER REFERENCES pages(id) ON DELETE SET NULL,
+			pub_kind TEXT NOT NULL DEFAULT 'url',
+			pub_starts_at DATETIME,
+			pub_ends_at DATETIME,
+			pub_location TEXT NOT NULL DEFAULT '',
+			pub_time_zone TEXT NOT NULL DEFAULT '',
+			published_at DATETIME,
+			deleted_at DATETIME,
 			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
 			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
 		)`,
@@ -67,6 +96,9 @@ func Migrate(db *sql.DB, log *slog.Logger) error {
 			user_id INTEGER NOT NULL,
 			link_id INTEGER,
 			event_type TEXT NOT NULL,
+			source TEXT NOT NULL DEFAULT '',
+			platform TEXT NOT NULL DEFAULT '',
+			page_id INTEGER REFERENCES pages(id) ON DELETE SET NULL,
 			referrer TEXT DEFAULT '',
 			user_agent TEXT DEFAULT '',
 			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
@@ -74,11 +106,104 @@ func Migrate(db *sql.DB, log *slog.Logger) error {
 			FOREIGN KEY (link_id) REFERENCES links(id) ON DELETE CASCADE
 		)`,
 
+		// Media table: ownership of stored blobs
+		`CREATE TABLE IF NOT EXISTS media (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id INTEGER NOT NULL,
+			link_id INTEGER,
+			kind TEXT NOT NULL,
+			key TEXT NOT NULL,
+			content_type TEXT NOT NULL DEFAULT '',
+			size INTEGER NOT NULL DEFAULT 0,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+			FOREIGN KEY (link_id) REFERENCES links(id) ON DELETE CASCADE
+		)`,
+
+		// Custom domains: a domain may be claimed by several users but
+		// only one claim can be verified
+		`CREATE TABLE IF NOT EXISTS custom_domains (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id INTEGER NOT NULL,
+			domain TEXT NOT NULL,
+			token TEXT NOT NULL,
+			verified_at DATETIME,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE (user_id, domain),
+			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+		)`,
+
+		// Previous usernames, for redirects and holding old handles
+		`CREATE TABLE IF NOT EXISTS username_history (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id INTEGER NOT NULL,
+			username TEXT NOT NULL,
+			changed_at DATETIME NOT NULL,
+			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+		)`,
+
+		// Social icons shown under the bio, one per platform
+		`CREATE TABLE IF NOT EXISTS social_profiles (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id INTEGER NOT NULL,
+			platform TEXT NOT NULL,
+			handle TEXT NOT NULL,
+			position INTEGER NOT NULL DEFAULT 0,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE (user_id, platform),
+			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+		)`,
+
+		// Sub-pages of a profile, each with its own links
+		`CREATE TABLE IF NOT EXISTS pages (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id INTEGER NOT NULL,
+			slug TEXT NOT NULL,
+			title TEXT NOT NULL,
+			position INTEGER NOT NULL DEFAULT 0,
+			pub_slug TEXT NOT NULL DEFAULT '',
+			pub_title TEXT NOT NULL DEFAULT '',
+			pub_position INTEGER NOT NULL DEFAULT 0,
+			deleted_at DATETIME,
+			published_at DATETIME,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE (user_id, slug),
+			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+		)`,
+
+		// Creators' private labels for links, many-to-many
+		`CREATE TABLE IF NOT EXISTS tags (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id INTEGER NOT NULL,
+			name TEXT NOT NULL COLLATE NOCASE,
+			color TEXT NOT NULL DEFAULT 'gray',
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE (user_id, name),
+			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+		)`,
+		`CREATE TABLE IF NOT EXISTS link_tags (
+			link_id INTEGER NOT NULL,
+			tag_id INTEGER NOT NULL,
+			PRIMARY KEY (link_id, tag_id),
+			FOREIGN KEY (link_id) REFERENCES links(id) ON DELETE CASCADE,
+			FOREIGN KEY (tag_id) REFERENCES tags(id) ON DELETE CASCADE
+		)`,
+
 		// Indexes for performance
 		`CREATE INDEX IF NOT EXISTS idx_links_user_id ON links(user_id)`,
 		`CREATE INDEX IF NOT EXISTS idx_links_position ON links(user_id, position)`,
 		`CREATE INDEX IF NOT EXISTS idx_analytics_user_id ON analytics(user_id)`,
 		`CREATE INDEX IF NOT EXISTS idx_analytics_created_at ON analytics(created_at)`,
+		`CREATE INDEX IF NOT EXISTS idx_media_user_id ON media(user_id, kind)`,
+		`CREATE INDEX IF NOT EXISTS idx_media_link_id ON media(link_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_media_key ON media(key)`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_custom_domains_verified ON custom_domains(domain) WHERE verified_at IS NOT NULL`,
+		`CREATE INDEX IF NOT EXISTS idx_username_history_username ON username_history(username, changed_at)`,
+		`CREATE INDEX IF NOT EXISTS idx_username_history_user_id ON username_history(user_id, changed_at)`,
+		`CREATE INDEX IF NOT EXISTS idx_users_username_nocase ON users(username COLLATE NOCASE)`,
+		`CREATE INDEX IF NOT EXISTS idx_pages_user_id ON pages(user_id, position)`,
+		`CREATE INDEX IF NOT EXISTS idx_username_history_username_nocase ON username_history(username COLLATE NOCASE, changed_at)`,
+		`CREATE INDEX IF NOT EXISTS idx_link_tags_tag_id ON link_tags(tag_id)`,
 	}
 
 	for i, migration := range migrations {
@@ -88,6 +213,245 @@ func Migrate(db *sql.DB, log *slog.Logger) error {
 		}
 	}
 
+	// Columns added after a table was first created. CREATE TABLE IF NOT EXISTS
+	// won't touch existing databases, so these are applied one by one.
+	columns := []struct {
+		table, column, definition string
+	}{
+		{"links", "last_status", "INTEGER NOT NULL DEFAULT 0"},
+		{"links", "last_checked_at", "DATETIME"},
+		{"links", "description", "TEXT NOT NULL DEFAULT ''"},
+		{"links", "image_url", "TEXT NOT NULL DEFAULT ''"},
+		{"links", "is_featured", "INTEGER NOT NULL DEFAULT 0"},
+		{"analytics", "source", "TEXT NOT NULL DEFAULT ''"},
+		{"analytics", "platform", "TEXT NOT NULL DEFAULT ''"},
+		{"users", "theme_config", "TEXT NOT NULL DEFAULT ''"},
+		{"users", "hide_from_search", "INTEGER NOT NULL DEFAULT 0"},
+
+		// Published copies of the editable fields. Everything that existed
+		// before drafts was live, so it starts out published.
+		{"links", "pub_title", "TEXT NOT NULL DEFAULT ''"},
+		{"links", "pub_url", "TEXT NOT NULL DEFAULT ''"},
+		{"links", "pub_icon", "TEXT NOT NULL DEFAULT ''"},
+		{"links", "pub_position", "INTEGER NOT NULL DEFAULT 0"},
+		{"links", "pub_is_active", "INTEGER NOT NULL DEFAULT 0"},
+		{"links", "pub_is_featured", "INTEGER NOT NULL DEFAULT 0"},
+		{"links", "deleted_at", "DATETIME"},
+		{"links", "published_at", "DATETIME"},
+		{"users", "pub_display_name", "TEXT NOT NULL DEFAULT ''"},
+		{"users", "pub_bio", "TEXT NOT NULL DEFAULT ''"},
+		{"users", "pub_theme", "TEXT NOT NULL DEFAULT ''"},
+		{"users", "pub_theme_config", "TEXT NOT NULL DEFAULT ''"},
+		{"users", "published_at", "DATETIME"},
+		{"users", "profile_password_hash", "TEXT NOT NULL DEFAULT ''"},
+		{"links", "is_sensitive", "INTEGER NOT NULL DEFAULT 0"},
+		{"links", "pub_is_sensitive", "INTEGER NOT NULL DEFAULT 0"},
+		{"links", "page_id", "INTEGER REFERENCES pages(id) ON DELETE SET NULL"},
+		{"links", "pub_page_id", "INTEGER REFERENCES pages(id) ON DELETE SET NULL"},
+		{"analytics", "page_id", "INTEGER REFERENCES pages(id) ON DELETE SET NULL"},
+		{"users", "locale", "TEXT NOT NULL DEFAULT ''"},
+		{"users", "profile_locale", "TEXT NOT NULL DEFAULT ''"},
+		{"links", "kind", "TEXT NOT NULL DEFAULT 'url'"},
+		{"links", "starts_at", "DATETIME"},
+		{"links", "ends_at", "DATETIME"},
+		{"links", "location", "TEXT NOT NULL DEFAULT ''"},
+		{"links", "time_zone", "TEXT NOT NULL DEFAULT ''"},
+		{"links", "pub_kind", "TEXT NOT NULL DEFAULT 'url'"},
+		{"links", "pub_starts_at", "DATETIME"},
+		{"links", "pub_ends_at", "DATETIME"},
+		{"links", "pub_location", "TEXT NOT NULL DEFAULT ''"},
+		{"links", "pub_time_zone", "TEXT NOT NULL DEFAULT ''"},
+		{"users", "listed", "INTEGER NOT NULL DEFAULT 0"},
+		{"users", "category", "TEXT NOT NULL DEFAULT ''"},
+		{"links", "pub_description", "TEXT NOT NULL DEFAULT ''"},
+		{"links", "pub_image_url", "TEXT NOT NULL DEFAULT ''"},
+		{"pages", "pub_slug", "TEXT NOT NULL DEFAULT ''"},
+		{"pages", "pub_title", "TEXT NOT NULL DEFAULT ''"},
+		{"pages", "pub_position", "INTEGER NOT NULL DEFAULT 0"},
+		{"pages", "deleted_at", "DATETIME"},
+		{"pages", "published_at", "DATETIME"},
+	}
+
+	// Run once, in the same transaction as adding the column they are keyed by
+	backfills := map[string]string{
+		"links.published_at": `UPDATE links SET
+			pub_title = title, pub_url = url, pub_icon = COALESCE(icon, ''), pub_position = COALESCE(position, 0),
+			pub_is_active = COALESCE(is_active, 0), pub_is_featured = is_featured, published_at = CURRENT_TIMESTAMP`,
+		"users.published_at": `UPDATE users SET
+			pub_display_name = COALESCE(display_name, ''), pub_bio = COALESCE(bio, ''),
+			pub_theme = COALESCE(theme, ''), pub_theme_config = theme_config, published_at = CURRENT_TIMESTAMP`,
+		"links.pub_description": `UPDATE links SET pub_description = description`,
+		"links.pub_image_url":   `UPDATE links SET pub_image_url = image_url`,
+		"pages.published_at": `UPDATE pages SET
+			pub_slug = slug, pub_title = title, pub_position = position, published_at = created_at`,
+	}
+
+	for _, c := range columns {
+		if err := addColumn(db, c.table, c.column, c.definition, backfills[c.table+"."+c.column]); err != nil {
+			log.Error("migration failed", "table", c.table, "column", c.column, "error", err)
+			return err
+		}
+	}
+
+	if err := keepPageAnalytics(db); err != nil {
+		log.Error("migration failed", "table", "analytics", "error", err)
+		return err
+	}
+
+	if _, err := db.Exec(`CREATE INDEX IF NOT EXISTS idx_users_listed ON users(listed, category)`); err != nil {
+		log.Error("migration failed", "index", "idx_users_listed", "error", err)
+		return err
+	}
+	if err := createSearchIndex(db, "profile_search", profileSearchIndex); err != nil {
+		log.Error("migration failed", "table", "profile_search", "error", err)
+		return err
+	}
+	if err := createSearchIndex(db, "link_search", linkSearchIndex); err != nil {
+		log.Error("migration failed", "table", "link_search", "error", err)
+		return err
+	}
+
 	log.Info("database migrations completed")
 	return nil
 }
+
+// profileSearchIndex is the explore directory's full-text index over the
+// published name and bio. It reads its content from users and the
+// triggers keep it in step.
+var profileSearchIndex = []string{
+	`CREATE VIRTUAL TABLE IF NOT EXISTS profile_search USING fts5(
+		username, pub_display_name, pub_bio,
+		content='users', content_rowid='id', tokenize='unicode61 remove_diacritics 2'
+	)`,
+	`CREATE TRIGGER IF NOT EXISTS users_search_insert AFTER INSERT ON users BEGIN
+		INSERT INTO profile_search(rowid, username, pub_display_name, pub_bio)
+		VALUES (new.id, new.username, new.pub_display_name, new.pub_bio);
+	END`,
+	`CREATE TRIGGER IF NOT EXISTS users_search_delete AFTER DELETE ON users BEGIN
+		INSERT INTO profile_search(profile_search, rowid, username, pub_display_name, pub_bio)
+		VALUES ('delete', old.id, old.username, old.pub_display_name, old.pub_bio);
+	END`,
+	`CREATE TRIGGER IF NOT EXISTS users_search_update AFTER UPDATE OF username, pub_display_name, pub_bio ON users BEGIN
+		INSERT INTO profile_search(profile_search, rowid, username, pub_display_name, pub_bio)
+		VALUES ('delete', old.id, old.username, old.pub_display_name, old.pub_bio);
+		INSERT INTO profile_search(rowid, username, pub_display_name, pub_bio)
+		VALUES (new.id, new.username, new.pub_display_name, new.pub_bio);
+	END`,
+}
+
+// linkSearchIndex is the dashboard's full-text index over the working copy
+// of link titles and URLs, kept in step the same way
+var linkSearchIndex = []string{
+	`CREATE VIRTUAL TABLE IF NOT EXISTS link_search USING fts5(
+		title, url,
+		content='links', content_rowid='id', tokenize='unicode61 remove_diacritics 2'
+	)`,
+	`CREATE TRIGGER IF NOT EXISTS links_search_insert AFTER INSERT ON links BEGIN
+		INSERT INTO link_search(rowid, title, url) VALUES (new.id, new.title, new.url);
+	END`,
+	`CREATE TRIGGER IF NOT EXISTS links_search_delete AFTER DELETE ON links BEGIN
+		INSERT INTO link_search(link_search, rowid, title, url) VALUES ('delete', old.id, old.title, old.url);
+	END`,
+	`CREATE TRIGGER IF NOT EXISTS links_search_update AFTER UPDATE OF title, url ON links BEGIN
+		INSERT INTO link_search(link_search, rowid, title, url) VALUES ('delete', old.id, old.title, old.url);
+		INSERT INTO link_search(rowid, title, url) VALUES (new.id, new.title, new.url);
+	END`,
+}
+
+// createSearchIndex runs the statements creating the search index name and
+// its triggers, indexing the existing rows the first time
+func createSearchIndex(db *sql.DB, name string, stmts []string) error {
+	var exists int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE name = ?`, name).Scan(&exists); err != nil {
+		return err
+	}
+	for _, stmt := range stmts {
+		if _, err := db.Exec(stmt); err != nil {
+			return err
+		}
+	}
+	if exists == 0 {
+		_, err := db.Exec(`INSERT INTO ` + name + `(` + name + `) VALUES ('rebuild')`)
+		return err
+	}
+	return nil
+}
+
+// keepPageAnalytics rebuilds the analytics table on databases where page_id
+// was added with ON DELETE CASCADE, which threw away a sub-page's views when
+// the page was deleted. SQLite can't change a foreign key in place.
+func keepPageAnalytics(db *sql.DB) error {
+	var cascades int
+	err := db.QueryRow(
+		`SELECT COUNT(*) FROM pragma_foreign_key_list('analytics') WHERE "from" = 'page_id' AND on_delete = 'CASCADE'`,
+	).Scan(&cascades)
+	if err != nil || cascades == 0 {
+		return err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	const columns = `id, user_id, link_id, event_type, source, platform, page_id, referrer, user_agent, created_at`
+	steps := []string{
+		`CREATE TABLE analytics_new (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id INTEGER NOT NULL,
+			link_id INTEGER,
+			event_type TEXT NOT NULL,
+			source TEXT NOT NULL DEFAULT '',
+			platform TEXT NOT NULL DEFAULT '',
+			page_id INTEGER REFERENCES pages(id) ON DELETE SET NULL,
+			referrer TEXT DEFAULT '',
+			user_agent TEXT DEFAULT '',
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+			FOREIGN KEY (link_id) REFERENCES links(id) ON DELETE CASCADE
+		)`,
+		`INSERT INTO analytics_new (` + columns + `) SELECT ` + columns + ` FROM analytics`,
+		`DROP TABLE analytics`,
+		`ALTER TABLE analytics_new RENAME TO analytics`,
+		`CREATE INDEX IF NOT EXISTS idx_analytics_user_id ON analytics(user_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_analytics_created_at ON analytics(created_at)`,
+	}
+	for _, stmt := range steps {
+		if _, err := tx.Exec(stmt); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// addColumn adds a column to a table unless it already exists. A backfill
+// runs in the same transaction, so a crash can't leave the column added but
+// never filled in.
+func addColumn(db *sql.DB, table, column, definition, backfill string) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var count int
+	err = tx.QueryRow(
+		`SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?`, table, column,
+	).Scan(&count)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	if _, err := tx.Exec("ALTER TABLE " + table + " ADD COLUMN " + column + " " + definition); err != nil {
+		return err
+	}
+	if backfill != "" {
+		if _, err := tx.Exec(backfill); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
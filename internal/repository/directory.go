@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"unicode"
+
+	"linkbio/internal/model"
+)
+
+// listedProfiles limits a users query to profiles shown in the explore
+// directory: opted in, and neither unlisted nor password-protected
+const listedProfiles = `u.listed = 1 AND u.hide_from_search = 0 AND u.profile_password_hash = ''`
+
+// DirectoryQuery selects a page of the explore directory
+type DirectoryQuery struct {
+	Text     string // free-text search; empty lists every profile
+	Category string // empty for all categories
+	Limit    int
+	Offset   int
+}
+
+// DirectoryRepository searches the explore directory
+type DirectoryRepository struct {
+	db *sql.DB
+}
+
+// NewDirectoryRepository creates a new DirectoryRepository
+func NewDirectoryRepository(db *sql.DB) *DirectoryRepository {
+	return &DirectoryRepository{db: db}
+}
+
+// Search returns listed profiles matching q. Text matches words in the
+// username, published display name and bio, by prefix so results update
+// as a visitor types, best matches first. Without text the newest
+// profiles come first.
+func (r *DirectoryRepository) Search(ctx context.Context, q DirectoryQuery) ([]model.DirectoryProfile, error) {
+	var (
+		query string
+		args  []any
+	)
+	if match := ftsQuery(q.Text); match != "" {
+		query = `
+			SELECT u.username, u.pub_display_name, u.pub_bio, u.avatar_url, u.category
+			FROM profile_search s
+			JOIN users u ON u.id = s.rowid
+			WHERE profile_search MATCH ? AND ` + listedProfiles + `
+				AND (? = '' OR u.category = ?)
+			ORDER BY bm25(profile_search, 10.0, 5.0, 1.0), u.id
+			LIMIT ? OFFSET ?
+		`
+		args = []any{match}
+	} else {
+		query = `
+			SELECT u.username, u.pub_display_name, u.pub_bio, u.avatar_url, u.category
+			FROM users u
+			WHERE ` + listedProfiles + `
+				AND (? = '' OR u.category = ?)
+			ORDER BY u.id DESC
+			LIMIT ? OFFSET ?
+		`
+	}
+	args = append(args, q.Category, q.Category, q.Limit, q.Offset)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var profiles []model.DirectoryProfile
+	for rows.Next() {
+		var p model.DirectoryProfile
+		if err := rows.Scan(&p.Username, &p.DisplayName, &p.Bio, &p.AvatarURL, &p.Category); err != nil {
+			return nil, err
+		}
+		profiles = append(profiles, p)
+	}
+	return profiles, rows.Err()
+}
+
+// ftsQuery turns what a visitor typed into an FTS5 query matching every
+// word as a prefix. Words are quoted, so FTS5 operators and punctuation in
+// the input are taken literally.
+func ftsQuery(text string) string {
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	terms := make([]string, 0, len(words))
+	for _, w := range words {
+		terms = append(terms, `"`+w+`"*`)
+	}
+	return strings.Join(terms, " ")
+}
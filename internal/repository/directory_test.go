@@ -0,0 +1,242 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"linkbio/internal/model"
+	"linkbio/internal/testutil"
+)
+
+// usernames lists the profiles' usernames in order
+func usernames(profiles []model.DirectoryProfile) []string {
+	names := make([]string, len(profiles))
+	for i, p := range profiles {
+		names[i] = p.Username
+	}
+	return names
+}
+
+func TestDirectoryRepository_Search(t *testing.T) {
+	db := testutil.TestDB(t)
+	userRepo := NewUserRepository(db)
+	repo := NewDirectoryRepository(db)
+	ctx := context.Background()
+
+	create := func(username, name, bio, category string, edit func(*model.User)) *model.User {
+		t.Helper()
+		u := &model.User{Username: username, Email: username + "@test.com", PasswordHash: "hash", DisplayName: name, Bio: bio, Theme: "light"}
+		if err := userRepo.Create(ctx, u); err != nil {
+			t.Fatalf("Create(%s) error = %v", username, err)
+		}
+		u.Listed, u.Category = true, category
+		if edit != nil {
+			edit(u)
+		}
+		if err := userRepo.Update(ctx, u); err != nil {
+			t.Fatalf("Update(%s) error = %v", username, err)
+		}
+		return u
+	}
+	create("cafebar", "Bar Crawl", "Cocktails downtown", "food", nil)
+	create("lena", "Lena Sings", "Indie music from the Café Royal", "music", nil)
+	create("musicbot", "Robot", "Beeps", "tech", nil)
+	create("hidden", "Music Hidden", "Not opted in", "music", func(u *model.User) { u.Listed = false })
+	create("quiet", "Music Unlisted", "Opted in but unlisted", "music", func(u *model.User) { u.HideFromSearch = true })
+	create("locked", "Music Locked", "Opted in but protected", "music", func(u *model.User) { u.HideFromSearch, u.ProfilePasswordHash = true, "x" })
+
+	search := func(q DirectoryQuery) []string {
+		t.Helper()
+		if q.Limit == 0 {
+			q.Limit = 10
+		}
+		profiles, err := repo.Search(ctx, q)
+		if err != nil {
+			t.Fatalf("Search(%+v) error = %v", q, err)
+		}
+		return usernames(profiles)
+	}
+	equal := func(got []string, want ...string) bool {
+		if len(got) != len(want) {
+			return false
+		}
+		for i := range got {
+			if got[i] != want[i] {
+				return false
+			}
+		}
+		return true
+	}
+
+	tests := []struct {
+		query DirectoryQuery
+		want  []string
+	}{
+		// Username matches outrank bio matches; accents are ignored
+		{DirectoryQuery{Text: "cafe"}, []string{"cafebar", "lena"}},
+		{DirectoryQuery{Text: "mus"}, []string{"musicbot", "lena"}},
+		{DirectoryQuery{Text: "indie CAFÉ"}, []string{"lena"}},
+		{DirectoryQuery{Text: "music", Category: "tech"}, []string{"musicbot"}},
+		{DirectoryQuery{Text: `") OR username:* NEAR(`}, nil},
+		{DirectoryQuery{Text: "nothing here"}, nil},
+		// Without text, newest first
+		{DirectoryQuery{}, []string{"musicbot", "lena", "cafebar"}},
+		{DirectoryQuery{Category: "music"}, []string{"lena"}},
+		{DirectoryQuery{Limit: 2, Offset: 1}, []string{"lena", "cafebar"}},
+	}
+	for _, tt := range tests {
+		if got := search(tt.query); !equal(got, tt.want...) {
+			t.Errorf("Search(%+v) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+
+	// Only published names and bios are searchable
+	lena, _ := userRepo.GetByUsername(ctx, "lena")
+	lena.DisplayName = "Lena Jazz"
+	if err := userRepo.Update(ctx, lena); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if got := search(DirectoryQuery{Text: "jazz"}); len(got) != 0 {
+		t.Errorf("draft name is searchable: %v", got)
+	}
+	if _, err := NewDraftRepository(db).Publish(ctx, lena.ID, time.Now()); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	if got := search(DirectoryQuery{Text: "jazz"}); !equal(got, "lena") {
+		t.Errorf("published name not searchable: %v", got)
+	}
+	if got := search(DirectoryQuery{Text: "sings"}); len(got) != 0 {
+		t.Errorf("old name still matches: %v", got)
+	}
+
+	// Renames and deletions reach the index
+	if err := userRepo.ChangeUsername(ctx, lena.ID, "lenamusic", time.Now()); err != nil {
+		t.Fatalf("ChangeUsername() error = %v", err)
+	}
+	if got := search(DirectoryQuery{Text: "lenamus"}); !equal(got, "lenamusic") {
+		t.Errorf("renamed profile = %v", got)
+	}
+	if _, err := db.Exec(`DELETE FROM users WHERE id = ?`, lena.ID); err != nil {
+		t.Fatalf("delete user: %v", err)
+	}
+	if got := search(DirectoryQuery{Text: "jazz"}); len(got) != 0 {
+		t.Errorf("deleted profile still found: %v", got)
+	}
+}
+
+// Migrating an existing database indexes the users already in it
+func TestMigrate_SearchIndex(t *testing.T) {
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	db.SetMaxOpenConns(1)
+	defer db.Close()
+
+	if _, err := db.Exec(`CREATE TABLE users (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		username TEXT UNIQUE NOT NULL,
+		email TEXT UNIQUE NOT NULL,
+		password_hash TEXT NOT NULL,
+		display_name TEXT DEFAULT '',
+		bio TEXT DEFAULT '',
+		avatar_url TEXT DEFAULT '',
+		theme TEXT DEFAULT 'light',
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	)`); err != nil {
+		t.Fatalf("create users: %v", err)
+	}
+	if _, err := db.Exec(`INSERT INTO users (username, email, password_hash, display_name, bio) VALUES ('veteran', 'v@test.com', 'hash', 'Old Timer', 'Here since day one')`); err != nil {
+		t.Fatalf("insert user: %v", err)
+	}
+
+	if err := Migrate(db, testutil.TestLogger()); err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+	if err := Migrate(db, testutil.TestLogger()); err != nil {
+		t.Fatalf("second Migrate() error = %v", err)
+	}
+	if _, err := db.Exec(`UPDATE users SET listed = 1`); err != nil {
+		t.Fatalf("opt in: %v", err)
+	}
+
+	profiles, err := NewDirectoryRepository(db).Search(context.Background(), DirectoryQuery{Text: "timer", Limit: 10})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if got := usernames(profiles); len(got) != 1 || got[0] != "veteran" {
+		t.Errorf("Search() = %v, want the existing user", got)
+	}
+}
+
+// Migrating a database whose analytics.page_id cascades keeps its rows and
+// stops deleting them with the page
+func TestMigrate_KeepPageAnalytics(t *testing.T) {
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	db.SetMaxOpenConns(1)
+	defer db.Close()
+
+	for _, stmt := range []string{
+		`PRAGMA foreign_keys = ON`,
+		`CREATE TABLE users (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			username TEXT UNIQUE NOT NULL,
+			email TEXT UNIQUE NOT NULL,
+			password_hash TEXT NOT NULL,
+			display_name TEXT DEFAULT '',
+			bio TEXT DEFAULT '',
+			avatar_url TEXT DEFAULT '',
+			theme TEXT DEFAULT 'light',
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE TABLE pages (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			slug TEXT NOT NULL,
+			title TEXT NOT NULL,
+			position INTEGER NOT NULL DEFAULT 0,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE TABLE analytics (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id INTEGER NOT NULL,
+			link_id INTEGER,
+			event_type TEXT NOT NULL,
+			referrer TEXT DEFAULT '',
+			user_agent TEXT DEFAULT '',
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+		)`,
+		`ALTER TABLE analytics ADD COLUMN page_id INTEGER REFERENCES pages(id) ON DELETE CASCADE`,
+		`INSERT INTO users (username, email, password_hash) VALUES ('veteran', 'v@test.com', 'hash')`,
+		`INSERT INTO pages (user_id, slug, title) VALUES (1, 'tour', 'Tour')`,
+		`INSERT INTO analytics (user_id, event_type, page_id) VALUES (1, 'page_view', 1), (1, 'page_view', 1)`,
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("setup %q: %v", stmt, err)
+		}
+	}
+
+	if err := Migrate(db, testutil.TestLogger()); err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+	if err := Migrate(db, testutil.TestLogger()); err != nil {
+		t.Fatalf("second Migrate() error = %v", err)
+	}
+	if _, err := db.Exec(`DELETE FROM pages WHERE id = 1`); err != nil {
+		t.Fatalf("delete page: %v", err)
+	}
+
+	var views int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM analytics WHERE page_id IS NULL`).Scan(&views); err != nil {
+		t.Fatalf("count: %v", err)
+	}
+	if views != 2 {
+		t.Errorf("views after deleting the page = %d, want 2", views)
+	}
+}
@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"linkbio/internal/model"
+)
+
+// DomainRepository handles custom domain database operations
+type DomainRepository struct {
+	db *sql.DB
+}
+
+// NewDomainRepository creates a new DomainRepository
+func NewDomainRepository(db *sql.DB) *DomainRepository {
+	return &DomainRepository{db: db}
+}
+
+// Create adds an unverified domain claim
+func (r *DomainRepository) Create(ctx context.Context, d *model.CustomDomain) error {
+	query := `INSERT INTO custom_domains (user_id, domain, token) VALUES (?, ?, ?)`
+	result, err := r.db.ExecContext(ctx, query, d.UserID, d.Domain, d.Token)
+	if err != nil {
+		return err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	d.ID = id
+	return nil
+}
+
+// GetByID retrieves a domain claim by ID
+func (r *DomainRepository) GetByID(ctx context.Context, id int64) (*model.CustomDomain, error) {
+	query := `
+		SELECT id, user_id, domain, token, verified_at, created_at
+		FROM custom_domains WHERE id = ?
+	`
+	d := &model.CustomDomain{}
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&d.ID, &d.UserID, &d.Domain, &d.Token, &d.VerifiedAt, &d.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// GetByUserAndDomain retrieves a user's claim on a domain
+func (r *DomainRepository) GetByUserAndDomain(ctx context.Context, userID int64, domain string) (*model.CustomDomain, error) {
+	query := `
+		SELECT id, user_id, domain, token, verified_at, created_at
+		FROM custom_domains WHERE user_id = ? AND domain = ?
+	`
+	d := &model.CustomDomain{}
+	err := r.db.QueryRowContext(ctx, query, userID, domain).Scan(
+		&d.ID, &d.UserID, &d.Domain, &d.Token, &d.VerifiedAt, &d.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// GetVerified retrieves the verified claim on a domain with its owner's
+// username, or nil when nobody has verified it
+func (r *DomainRepository) GetVerified(ctx context.Context, domain string) (*model.CustomDomain, error) {
+	query := `
+		SELECT d.id, d.user_id, d.domain, d.token, d.verified_at, d.created_at, u.username
+		FROM custom_domains d
+		JOIN users u ON u.id = d.user_id
+		WHERE d.domain = ? AND d.verified_at IS NOT NULL
+	`
+	d := &model.CustomDomain{}
+	err := r.db.QueryRowContext(ctx, query, domain).Scan(
+		&d.ID, &d.UserID, &d.Domain, &d.Token, &d.VerifiedAt, &d.CreatedAt, &d.Username,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// ListByUser returns a user's domain claims, oldest first
+func (r *DomainRepository) ListByUser(ctx context.Context, userID int64) ([]model.CustomDomain, error) {
+	query := `
+		SELECT id, user_id, domain, token, verified_at, created_at
+		FROM custom_domains WHERE user_id = ?
+		ORDER BY id
+	`
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var domains []model.CustomDomain
+	for rows.Next() {
+		var d model.CustomDomain
+		if err := rows.Scan(&d.ID, &d.UserID, &d.Domain, &d.Token, &d.VerifiedAt, &d.CreatedAt); err != nil {
+			return nil, err
+		}
+		domains = append(domains, d)
+	}
+	return domains, rows.Err()
+}
+
+// MarkVerified records a successful ownership check. It returns false when
+// another account has already verified the same domain.
+func (r *DomainRepository) MarkVerified(ctx context.Context, id int64) (bool, error) {
+	query := `
+		UPDATE custom_domains SET verified_at = COALESCE(verified_at, CURRENT_TIMESTAMP)
+		WHERE id = ? AND NOT EXISTS (
+			SELECT 1 FROM custom_domains other
+			WHERE other.domain = custom_domains.domain
+				AND other.verified_at IS NOT NULL
+				AND other.id != custom_domains.id
+		)
+	`
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+// Delete removes a user's domain claim
+func (r *DomainRepository) Delete(ctx context.Context, id, userID int64) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM custom_domains WHERE id = ? AND user_id = ?`, id, userID)
+	return err
+}
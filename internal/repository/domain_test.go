@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"linkbio/internal/model"
+	"linkbio/internal/testutil"
+)
+
+func TestDomainRepository_Verification(t *testing.T) {
+	db := testutil.TestDB(t)
+	userRepo := NewUserRepository(db)
+	domainRepo := NewDomainRepository(db)
+	ctx := context.Background()
+
+	alice := createTestUser(t, userRepo, "domainalice")
+	bob := createTestUser(t, userRepo, "domainbob")
+
+	// Both may claim the domain while it is unverified
+	a := &model.CustomDomain{UserID: alice.ID, Domain: "links.example.com", Token: "a"}
+	b := &model.CustomDomain{UserID: bob.ID, Domain: "links.example.com", Token: "b"}
+	if err := domainRepo.Create(ctx, a); err != nil {
+		t.Fatalf("Create(alice) error = %v", err)
+	}
+	if err := domainRepo.Create(ctx, b); err != nil {
+		t.Fatalf("Create(bob) error = %v", err)
+	}
+	if err := domainRepo.Create(ctx, &model.CustomDomain{UserID: alice.ID, Domain: "links.example.com", Token: "c"}); err == nil {
+		t.Error("duplicate claim by the same user was accepted")
+	}
+
+	if d, _ := domainRepo.GetVerified(ctx, "links.example.com"); d != nil {
+		t.Fatal("GetVerified() returned an unverified claim")
+	}
+
+	ok, err := domainRepo.MarkVerified(ctx, a.ID)
+	if err != nil || !ok {
+		t.Fatalf("MarkVerified(alice) = %v, %v", ok, err)
+	}
+	// Verifying again is a no-op that still succeeds
+	if ok, _ := domainRepo.MarkVerified(ctx, a.ID); !ok {
+		t.Error("re-verifying the owner's claim failed")
+	}
+	if ok, _ := domainRepo.MarkVerified(ctx, b.ID); ok {
+		t.Error("second account verified an already verified domain")
+	}
+
+	d, err := domainRepo.GetVerified(ctx, "links.example.com")
+	if err != nil || d == nil {
+		t.Fatalf("GetVerified() = %v, %v", d, err)
+	}
+	if d.UserID != alice.ID || d.Username != "domainalice" || !d.IsVerified() {
+		t.Errorf("GetVerified() = %+v", d)
+	}
+
+	// Once the owner lets it go, the other claim can be verified
+	domainRepo.Delete(ctx, a.ID, bob.ID)
+	if got, _ := domainRepo.GetByID(ctx, a.ID); got == nil {
+		t.Fatal("Delete() removed another user's claim")
+	}
+	domainRepo.Delete(ctx, a.ID, alice.ID)
+	if ok, _ := domainRepo.MarkVerified(ctx, b.ID); !ok {
+		t.Error("claim could not be verified after the owner deleted theirs")
+	}
+
+	list, _ := domainRepo.ListByUser(ctx, bob.ID)
+	if len(list) != 1 || !list[0].IsVerified() {
+		t.Errorf("ListByUser(bob) = %+v", list)
+	}
+}
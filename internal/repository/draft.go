@@ -0,0 +1,185 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"linkbio/internal/model"
+)
+
+// linkChanged matches links whose working copy differs from what is live
+const linkChanged = `(published_at IS NULL OR deleted_at IS NOT NULL
+	OR title IS NOT pub_title OR url IS NOT pub_url OR COALESCE(icon, '') IS NOT pub_icon
+	OR description IS NOT pub_description OR image_url IS NOT pub_image_url
+	OR position IS NOT pub_position OR is_active IS NOT pub_is_active OR is_featured IS NOT pub_is_featured
+	OR is_sensitive IS NOT pub_is_sensitive OR page_id IS NOT pub_page_id OR kind IS NOT pub_kind
+	OR starts_at IS NOT pub_starts_at OR ends_at IS NOT pub_ends_at OR location IS NOT pub_location
+	OR time_zone IS NOT pub_time_zone)`
+
+// pageChanged matches pages whose working copy differs from what is live
+const pageChanged = `(published_at IS NULL OR deleted_at IS NOT NULL
+	OR slug IS NOT pub_slug OR title IS NOT pub_title OR position IS NOT pub_position)`
+
+// profileChanged is true when the user's drafted fields differ from what is
+// live
+const profileChanged = `(COALESCE(display_name, '') IS NOT pub_display_name OR COALESCE(bio, '') IS NOT pub_bio
+	OR COALESCE(theme, '') IS NOT pub_theme OR theme_config IS NOT pub_theme_config)`
+
+// DraftRepository publishes and reverts a creator's drafted changes. The
+// working copy lives in the ordinary columns of users, links and pages; the pub_*
+// columns hold what visitors see.
+type DraftRepository struct {
+	db *sql.DB
+}
+
+// NewDraftRepository creates a new DraftRepository
+func NewDraftRepository(db *sql.DB) *DraftRepository {
+	return &DraftRepository{db: db}
+}
+
+// Status compares a user's draft with their published profile
+func (r *DraftRepository) Status(ctx context.Context, userID int64) (model.DraftStatus, error) {
+	var s model.DraftStatus
+	var changed int
+	var publishedAt sql.NullTime
+	err := r.db.QueryRowContext(ctx, `
+		SELECT `+profileChanged+`, published_at,
+			(SELECT COUNT(*) FROM links WHERE user_id = users.id AND `+linkChanged+`),
+			(SELECT COUNT(*) FROM pages WHERE user_id = users.id AND `+pageChanged+`)
+		FROM users WHERE id = ?
+	`, userID).Scan(&changed, &publishedAt, &s.LinkChanges, &s.PageChanges)
+	if err != nil {
+		return s, err
+	}
+	s.ProfileChanged = changed == 1
+	if publishedAt.Valid {
+		s.PublishedAt = &publishedAt.Time
+	}
+	return s, nil
+}
+
+// Publish makes the user's draft live in one transaction. It returns the
+// media keys owned by links the publish removed and of icons it replaced,
+// for the caller to clean up once nothing else references them.
+func (r *DraftRepository) Publish(ctx context.Context, userID int64, at time.Time) ([]string, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	keys, err := removedMediaKeys(ctx, tx, `SELECT id FROM links WHERE user_id = ? AND deleted_at IS NOT NULL`, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// A newly published URL hasn't been checked yet, so its health starts over
+	steps := []struct {
+		query string
+		args  []any
+	}{
+		{`DELETE FROM links WHERE user_id = ? AND deleted_at IS NOT NULL`, []any{userID}},
+		{`UPDATE links SET
+			last_status = CASE WHEN pub_url = url THEN last_status ELSE 0 END,
+			last_checked_at = CASE WHEN pub_url = url THEN last_checked_at ELSE NULL END,
+			pub_title = title, pub_url = url, pub_icon = COALESCE(icon, ''), pub_position = position,
+			pub_description = description, pub_image_url = image_url,
+			pub_is_active = is_active, pub_is_featured = is_featured, pub_is_sensitive = is_sensitive,
+			pub_page_id = page_id, pub_kind = kind, pub_starts_at = starts_at, pub_ends_at = ends_at,
+			pub_location = location, pub_time_zone = time_zone, published_at = COALESCE(published_at, ?)
+		WHERE user_id = ?`, []any{at.UTC(), userID}},
+		{`DELETE FROM pages WHERE user_id = ? AND deleted_at IS NOT NULL`, []any{userID}},
+		{`UPDATE pages SET
+			pub_slug = slug, pub_title = title, pub_position = position, published_at = COALESCE(published_at, ?)
+		WHERE user_id = ?`, []any{at.UTC(), userID}},
+		{`UPDATE users SET
+			pub_display_name = COALESCE(display_name, ''), pub_bio = COALESCE(bio, ''),
+			pub_theme = COALESCE(theme, ''), pub_theme_config = theme_config, published_at = ?
+		WHERE id = ?`, []any{at.UTC(), userID}},
+	}
+	for _, step := range steps {
+		if _, err := tx.ExecContext(ctx, step.query, step.args...); err != nil {
+			return nil, err
+		}
+	}
+
+	// Uploaded icons the draft replaced are no longer shown anywhere
+	stale, err := queryKeys(ctx, tx, staleLinkIcons, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(keys, stale...), tx.Commit()
+}
+
+// Revert throws the user's draft away, restoring the published profile as
+// the working copy. Like Publish it returns the media keys of links and
+// icons it dropped.
+func (r *DraftRepository) Revert(ctx context.Context, userID int64) ([]string, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	keys, err := removedMediaKeys(ctx, tx, `SELECT id FROM links WHERE user_id = ? AND published_at IS NULL`, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	steps := []string{
+		`DELETE FROM pages WHERE user_id = ? AND published_at IS NULL`,
+		`UPDATE pages SET
+			slug = pub_slug, title = pub_title, position = pub_position, deleted_at = NULL
+		WHERE user_id = ?`,
+		`DELETE FROM links WHERE user_id = ? AND published_at IS NULL`,
+		`UPDATE links SET
+			title = pub_title, url = pub_url, icon = pub_icon, position = pub_position,
+			description = pub_description, image_url = pub_image_url,
+			is_active = pub_is_active, is_featured = pub_is_featured, is_sensitive = pub_is_sensitive,
+			page_id = pub_page_id, kind = pub_kind, starts_at = pub_starts_at, ends_at = pub_ends_at,
+			location = pub_location, time_zone = pub_time_zone, deleted_at = NULL
+		WHERE user_id = ?`,
+		`UPDATE users SET
+			display_name = pub_display_name, bio = pub_bio, theme = pub_theme, theme_config = pub_theme_config
+		WHERE id = ?`,
+	}
+	for _, query := range steps {
+		if _, err := tx.ExecContext(ctx, query, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	// Icons uploaded for the discarded draft are no longer shown anywhere
+	stale, err := queryKeys(ctx, tx, staleLinkIcons, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(keys, stale...), tx.Commit()
+}
+
+// removedMediaKeys lists the media keys owned by the links linkIDs selects
+func removedMediaKeys(ctx context.Context, tx *sql.Tx, linkIDs string, userID int64) ([]string, error) {
+	return queryKeys(ctx, tx, `SELECT DISTINCT key FROM media WHERE link_id IN (`+linkIDs+`)`, userID)
+}
+
+// queryKeys runs a query returning one media key per row inside tx
+func queryKeys(ctx context.Context, tx *sql.Tx, query string, args ...any) ([]string, error) {
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []string
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
@@ -0,0 +1,206 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"linkbio/internal/model"
+	"linkbio/internal/testutil"
+)
+
+func TestDraftRepository_PublishAndRevert(t *testing.T) {
+	db := testutil.TestDB(t)
+	userRepo := NewUserRepository(db)
+	linkRepo := NewLinkRepository(db)
+	draftRepo := NewDraftRepository(db)
+	ctx := context.Background()
+
+	user := createTestUser(t, userRepo, "drafter")
+
+	status, err := draftRepo.Status(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("Status() error = %v", err)
+	}
+	if status.HasChanges() {
+		t.Errorf("new account Status() = %+v, want no changes", status)
+	}
+
+	kept := &model.Link{UserID: user.ID, Title: "Kept", URL: "https://kept.com", IsActive: true}
+	gone := &model.Link{UserID: user.ID, Title: "Gone", URL: "https://gone.com", IsActive: true}
+	linkRepo.Create(ctx, kept)
+	linkRepo.Create(ctx, gone)
+
+	if status, _ = draftRepo.Status(ctx, user.ID); status.LinkChanges != 2 {
+		t.Errorf("LinkChanges = %d, want 2", status.LinkChanges)
+	}
+	if _, err := draftRepo.Publish(ctx, user.ID, time.Now()); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	if status, _ = draftRepo.Status(ctx, user.ID); status.HasChanges() {
+		t.Errorf("Status() after publish = %+v", status)
+	}
+
+	// Stage an edit, a delete and a profile change
+	kept.Title = "Renamed"
+	linkRepo.Update(ctx, kept)
+	linkRepo.Delete(ctx, gone.ID)
+	user.DisplayName = "Draft Name"
+	userRepo.Update(ctx, user)
+
+	status, _ = draftRepo.Status(ctx, user.ID)
+	if status.LinkChanges != 2 || !status.ProfileChanged {
+		t.Errorf("Status() = %+v, want 2 link changes and a profile change", status)
+	}
+
+	live, _ := linkRepo.GetActiveByUserID(ctx, user.ID, nil)
+	if len(live) != 2 || live[0].Title != "Kept" {
+		t.Errorf("published links changed before publish: %+v", live)
+	}
+	if u, _ := userRepo.GetPublishedByUsername(ctx, "drafter"); u.DisplayName == "Draft Name" {
+		t.Error("published profile changed before publish")
+	}
+	if l, _ := linkRepo.GetByID(ctx, gone.ID); l != nil {
+		t.Error("deleted link still in the draft")
+	}
+
+	// Revert restores the published copy
+	if _, err := draftRepo.Revert(ctx, user.ID); err != nil {
+		t.Fatalf("Revert() error = %v", err)
+	}
+	if l, _ := linkRepo.GetByID(ctx, kept.ID); l.Title != "Kept" {
+		t.Errorf("reverted title = %q", l.Title)
+	}
+	if l, _ := linkRepo.GetByID(ctx, gone.ID); l == nil {
+		t.Error("Revert() did not restore the deleted link")
+	}
+	if status, _ = draftRepo.Status(ctx, user.ID); status.HasChanges() {
+		t.Errorf("Status() after revert = %+v", status)
+	}
+
+	// Publish makes staged changes live together
+	kept.Title = "Renamed"
+	linkRepo.Update(ctx, kept)
+	linkRepo.Delete(ctx, gone.ID)
+	user.DisplayName = "Draft Name"
+	userRepo.Update(ctx, user)
+	if _, err := draftRepo.Publish(ctx, user.ID, time.Now()); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	live, _ = linkRepo.GetActiveByUserID(ctx, user.ID, nil)
+	if len(live) != 1 || live[0].Title != "Renamed" {
+		t.Errorf("published links = %+v", live)
+	}
+	if u, _ := userRepo.GetPublishedByUsername(ctx, "drafter"); u.DisplayName != "Draft Name" {
+		t.Errorf("published display name = %q", u.DisplayName)
+	}
+	if l, _ := linkRepo.GetPublishedByID(ctx, gone.ID); l != nil {
+		t.Error("publish kept the deleted link")
+	}
+}
+
+func TestDraftRepository_RevertDropsNewLinks(t *testing.T) {
+	db := testutil.TestDB(t)
+	userRepo := NewUserRepository(db)
+	linkRepo := NewLinkRepository(db)
+	draftRepo := NewDraftRepository(db)
+	ctx := context.Background()
+
+	user := createTestUser(t, userRepo, "reverter")
+
+	link := &model.Link{UserID: user.ID, Title: "Unpublished", URL: "https://new.com", IsActive: true}
+	linkRepo.Create(ctx, link)
+	if l, _ := linkRepo.GetPublishedByID(ctx, link.ID); l != nil {
+		t.Error("GetPublishedByID() returned a draft-only link")
+	}
+
+	if _, err := draftRepo.Revert(ctx, user.ID); err != nil {
+		t.Fatalf("Revert() error = %v", err)
+	}
+	if l, _ := linkRepo.GetByID(ctx, link.ID); l != nil {
+		t.Error("Revert() kept a link that was never published")
+	}
+}
+
+func TestDraftRepository_EventDetails(t *testing.T) {
+	db := testutil.TestDB(t)
+	userRepo := NewUserRepository(db)
+	linkRepo := NewLinkRepository(db)
+	draftRepo := NewDraftRepository(db)
+	ctx := context.Background()
+
+	user := createTestUser(t, userRepo, "eventful")
+	start := time.Date(2026, 6, 2, 2, 0, 0, 0, time.UTC)
+	event := &model.Link{UserID: user.ID, Kind: model.LinkKindEvent, Title: "Launch", StartsAt: &start, Location: "Pier 70", TimeZone: "America/Los_Angeles", IsActive: true}
+	if err := linkRepo.Create(ctx, event); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	draftRepo.Publish(ctx, user.ID, time.Now())
+
+	live, _ := linkRepo.GetPublishedByID(ctx, event.ID)
+	if live == nil || live.Kind != model.LinkKindEvent || live.StartsAt == nil || !live.StartsAt.Equal(start) || live.EndsAt != nil || live.TimeZone != "America/Los_Angeles" {
+		t.Fatalf("published event = %+v", live)
+	}
+
+	// Moving the event is a drafted change
+	later := start.Add(24 * time.Hour)
+	event.StartsAt, event.Location = &later, "Pier 48"
+	linkRepo.Update(ctx, event)
+	if s, _ := draftRepo.Status(ctx, user.ID); s.LinkChanges != 1 {
+		t.Errorf("LinkChanges = %d, want 1", s.LinkChanges)
+	}
+	if live, _ := linkRepo.GetPublishedByID(ctx, event.ID); !live.StartsAt.Equal(start) || live.Location != "Pier 70" {
+		t.Errorf("published event changed before publishing: %+v", live)
+	}
+
+	draftRepo.Revert(ctx, user.ID)
+	if got, _ := linkRepo.GetByID(ctx, event.ID); !got.StartsAt.Equal(start) || got.Location != "Pier 70" {
+		t.Errorf("event after revert = %+v", got)
+	}
+	if s, _ := draftRepo.Status(ctx, user.ID); s.LinkChanges != 0 {
+		t.Errorf("LinkChanges after revert = %d, want 0", s.LinkChanges)
+	}
+}
+
+func TestDraftRepository_LinkPreviewAndHealth(t *testing.T) {
+	db := testutil.TestDB(t)
+	userRepo := NewUserRepository(db)
+	linkRepo := NewLinkRepository(db)
+	draftRepo := NewDraftRepository(db)
+	ctx := context.Background()
+
+	user := createTestUser(t, userRepo, "previewer")
+
+	link := &model.Link{UserID: user.ID, Title: "Shop", URL: "https://old.example", IsActive: true}
+	linkRepo.Create(ctx, link)
+	linkRepo.ApplyPreview(ctx, link.ID, link.URL, "", "", "Old shop", "https://old.example/og.png")
+	draftRepo.Publish(ctx, user.ID, time.Now())
+	linkRepo.UpdateHealth(ctx, link.ID, 404, time.Now())
+
+	// A drafted URL's preview replaces the working copy only
+	link.URL = "https://new.example"
+	linkRepo.Update(ctx, link)
+	linkRepo.ApplyPreview(ctx, link.ID, link.URL, "", "", "New shop", "https://new.example/og.png")
+
+	live, _ := linkRepo.GetPublishedByID(ctx, link.ID)
+	if live.Description != "Old shop" || live.ImageURL != "https://old.example/og.png" {
+		t.Errorf("live preview = %q, %q before publish, want the old one", live.Description, live.ImageURL)
+	}
+
+	// The live URL is still broken; editing the draft doesn't change that
+	if !live.IsBroken() {
+		t.Error("live link lost its broken status after a draft-only URL edit")
+	}
+
+	// Publishing the new URL starts its health over
+	if _, err := draftRepo.Publish(ctx, user.ID, time.Now()); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	live, _ = linkRepo.GetPublishedByID(ctx, link.ID)
+	if live.Description != "New shop" || live.ImageURL != "https://new.example/og.png" {
+		t.Errorf("live preview = %q, %q after publish, want the new one", live.Description, live.ImageURL)
+	}
+	if live.LastCheckedAt != nil || live.IsBroken() {
+		t.Error("a newly published URL should start out unchecked")
+	}
+}
@@ -3,10 +3,77 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"time"
 
 	"linkbio/internal/model"
 )
 
+// linkColumns is the column list shared by every link SELECT. It reads the
+// working copy the creator edits in the dashboard.
+const linkColumns = `id, user_id, page_id, kind, title, url, icon, description, image_url, position, is_active, is_featured, is_sensitive, starts_at, ends_at, location, time_zone, last_status, last_checked_at, published_at, created_at`
+
+// publishedLinkColumns reads the published copy in the same order, so rows
+// scan with scanLink too. Only health is shared between the two copies.
+const publishedLinkColumns = `id, user_id, pub_page_id, pub_kind, pub_title, pub_url, pub_icon, pub_description, pub_image_url, pub_position, pub_is_active, pub_is_featured, pub_is_sensitive, pub_starts_at, pub_ends_at, pub_location, pub_time_zone, last_status, last_checked_at, published_at, created_at`
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanLink reads one row selected with linkColumns
+func scanLink(s rowScanner) (model.Link, error) {
+	var link model.Link
+	var isActive, isFeatured, isSensitive int // SQLite stores bool as int
+	var pageID sql.NullInt64
+	var startsAt, endsAt, checkedAt, publishedAt sql.NullTime
+	err := s.Scan(
+		&link.ID,
+		&link.UserID,
+		&pageID,
+		&link.Kind,
+		&link.Title,
+		&link.URL,
+		&link.Icon,
+		&link.Description,
+		&link.ImageURL,
+		&link.Position,
+		&isActive,
+		&isFeatured,
+		&isSensitive,
+		&startsAt,
+		&endsAt,
+		&link.Location,
+		&link.TimeZone,
+		&link.LastStatus,
+		&checkedAt,
+		&publishedAt,
+		&link.CreatedAt,
+	)
+	if err != nil {
+		return link, err
+	}
+	link.IsActive = isActive == 1
+	link.IsFeatured = isFeatured == 1
+	link.IsSensitive = isSensitive == 1
+	if pageID.Valid {
+		link.PageID = &pageID.Int64
+	}
+	if startsAt.Valid {
+		link.StartsAt = &startsAt.Time
+	}
+	if endsAt.Valid {
+		link.EndsAt = &endsAt.Time
+	}
+	if checkedAt.Valid {
+		link.LastCheckedAt = &checkedAt.Time
+	}
+	if publishedAt.Valid {
+		link.PublishedAt = &publishedAt.Time
+	}
+	return link, nil
+}
+
 // LinkRepository handles link database operations
 type LinkRepository struct {
 	db *sql.DB
@@ -17,24 +84,39 @@ func NewLinkRepository(db *sql.DB) *LinkRepository {
 	return &LinkRepository{db: db}
 }
 
-// Create inserts a new link
+// Create inserts a new link into the draft. It goes live on the next
+// publish.
 func (r *LinkRepository) Create(ctx context.Context, link *model.Link) error {
 	// Get next position
 	var maxPos int
 	r.db.QueryRowContext(ctx, "SELECT COALESCE(MAX(position), 0) FROM links WHERE user_id = ?", link.UserID).Scan(&maxPos)
 	link.Position = maxPos + 1
+	if link.Kind == "" {
+		link.Kind = model.LinkKindURL
+	}
 
 	query := `
-		INSERT INTO links (user_id, title, url, icon, position, is_active)
-		VALUES (?, ?, ?, ?, ?, ?)
+		INSERT INTO links (user_id, page_id, kind, title, url, icon, description, image_url, position, is_active, is_featured, is_sensitive,
+			starts_at, ends_at, location, time_zone)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 	result, err := r.db.ExecContext(ctx, query,
 		link.UserID,
+		link.PageID,
+		link.Kind,
 		link.Title,
 		link.URL,
 		link.Icon,
+		link.Description,
+		link.ImageURL,
 		link.Position,
 		link.IsActive,
+		link.IsFeatured,
+		link.IsSensitive,
+		link.StartsAt,
+		link.EndsAt,
+		link.Location,
+		link.TimeZone,
 	)
 	if err != nil {
 		return err
@@ -49,78 +131,144 @@ func (r *LinkRepository) Create(ctx context.Context, link *model.Link) error {
 	return nil
 }
 
-// GetByID retrieves a link by ID
+// GetByID retrieves the working copy of a link by ID. Links removed in the
+// draft are not found.
 func (r *LinkRepository) GetByID(ctx context.Context, id int64) (*model.Link, error) {
-	query := `
-		SELECT id, user_id, title, url, icon, position, is_active, created_at
-		FROM links WHERE id = ?
-	`
-	link := &model.Link{}
-	var isActive int
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&link.ID,
-		&link.UserID,
-		&link.Title,
-		&link.URL,
-		&link.Icon,
-		&link.Position,
-		&isActive,
-		&link.CreatedAt,
-	)
+	query := `SELECT ` + linkColumns + ` FROM links WHERE id = ? AND deleted_at IS NULL`
+	return r.get(ctx, query, id)
+}
+
+// GetPublishedByID retrieves the published copy of a link, or nil if it has
+// never been published
+func (r *LinkRepository) GetPublishedByID(ctx context.Context, id int64) (*model.Link, error) {
+	query := `SELECT ` + publishedLinkColumns + ` FROM links WHERE id = ? AND published_at IS NOT NULL`
+	return r.get(ctx, query, id)
+}
+
+// get runs a single-row link SELECT, returning nil if nothing matched
+func (r *LinkRepository) get(ctx context.Context, query string, args ...any) (*model.Link, error) {
+	link, err := scanLink(r.db.QueryRowContext(ctx, query, args...))
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
 	if err != nil {
 		return nil, err
 	}
-	link.IsActive = isActive == 1
-	return link, nil
+	return &link, nil
 }
 
-// GetByUserID retrieves all links for a user ordered by position
+// GetByUserID retrieves all links in a user's draft ordered by position
 func (r *LinkRepository) GetByUserID(ctx context.Context, userID int64) ([]model.Link, error) {
 	query := `
-		SELECT id, user_id, title, url, icon, position, is_active, created_at
-		FROM links WHERE user_id = ?
+		SELECT ` + linkColumns + `
+		FROM links WHERE user_id = ? AND deleted_at IS NULL
 		ORDER BY position ASC
 	`
-	rows, err := r.db.QueryContext(ctx, query, userID)
-	if err != nil {
-		return nil, err
+	return r.query(ctx, query, userID)
+}
+
+// LinkQuery selects a page of a creator's links in the dashboard's search
+type LinkQuery struct {
+	Text   string // free-text search; empty matches every link
+	Status string // model.LinkStatusActive, model.LinkStatusInactive or empty for both
+	TagID  int64  // links carrying this tag; 0 for any
+	Limit  int
+	Offset int
+}
+
+// Search returns a user's draft links across all pages matching q. Text
+// matches words in the title and URL by prefix, best matches first.
+// Without text the newest links come first.
+func (r *LinkRepository) Search(ctx context.Context, userID int64, q LinkQuery) ([]model.Link, error) {
+	filter := ``
+	switch q.Status {
+	case model.LinkStatusActive:
+		filter = ` AND is_active = 1`
+	case model.LinkStatusInactive:
+		filter = ` AND is_active = 0`
+	}
+	var args []any
+	if q.TagID != 0 {
+		filter += ` AND id IN (SELECT link_id FROM link_tags WHERE tag_id = ?)`
+		args = append(args, q.TagID)
 	}
-	defer rows.Close()
 
-	var links []model.Link
-	for rows.Next() {
-		var link model.Link
-		var isActive int
-		if err := rows.Scan(
-			&link.ID,
-			&link.UserID,
-			&link.Title,
-			&link.URL,
-			&link.Icon,
-			&link.Position,
-			&isActive,
-			&link.CreatedAt,
-		); err != nil {
-			return nil, err
-		}
-		link.IsActive = isActive == 1
-		links = append(links, link)
+	if match := ftsQuery(q.Text); match != "" {
+		query := `
+			SELECT ` + linkColumns + `
+			FROM links
+			JOIN (
+				SELECT rowid, bm25(link_search, 5.0, 1.0) AS score
+				FROM link_search WHERE link_search MATCH ?
+					AND rowid IN (SELECT id FROM links WHERE user_id = ?)
+			) s ON s.rowid = links.id
+			WHERE user_id = ? AND deleted_at IS NULL` + filter + `
+			ORDER BY s.score, position, id
+			LIMIT ? OFFSET ?
+		`
+		return r.query(ctx, query, append(append([]any{match, userID, userID}, args...), q.Limit, q.Offset)...)
 	}
+	query := `
+		SELECT ` + linkColumns + `
+		FROM links
+		WHERE user_id = ? AND deleted_at IS NULL` + filter + `
+		ORDER BY created_at DESC, id DESC
+		LIMIT ? OFFSET ?
+	`
+	return r.query(ctx, query, append(append([]any{userID}, args...), q.Limit, q.Offset)...)
+}
 
-	return links, rows.Err()
+// SetActiveByTag shows or hides all of a user's draft links carrying a
+// tag, returning how many changed
+func (r *LinkRepository) SetActiveByTag(ctx context.Context, userID, tagID int64, active bool) (int64, error) {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE links SET is_active = ?
+		WHERE user_id = ? AND deleted_at IS NULL AND is_active != ?
+			AND id IN (SELECT link_id FROM link_tags WHERE tag_id = ?)
+	`, active, userID, active, tagID)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
 }
 
-// GetActiveByUserID retrieves active links for a user (for public profile)
-func (r *LinkRepository) GetActiveByUserID(ctx context.Context, userID int64) ([]model.Link, error) {
+// GetActiveByUserID retrieves the published active links on one of a user's
+// pages (for public profile). pageID is nil for the main page.
+func (r *LinkRepository) GetActiveByUserID(ctx context.Context, userID int64, pageID *int64) ([]model.Link, error) {
 	query := `
-		SELECT id, user_id, title, url, icon, position, is_active, created_at
-		FROM links WHERE user_id = ? AND is_active = 1
+		SELECT ` + publishedLinkColumns + `
+		FROM links WHERE user_id = ? AND pub_page_id IS ? AND published_at IS NOT NULL AND pub_is_active = 1
+		ORDER BY pub_position ASC, id ASC
+	`
+	return r.query(ctx, query, userID, pageID)
+}
+
+// GetActiveDraftByUserID retrieves the active links on one of the pages in
+// a user's draft (for the preview). pageID is nil for the main page.
+func (r *LinkRepository) GetActiveDraftByUserID(ctx context.Context, userID int64, pageID *int64) ([]model.Link, error) {
+	query := `
+		SELECT ` + linkColumns + `
+		FROM links WHERE user_id = ? AND page_id IS ? AND deleted_at IS NULL AND is_active = 1
 		ORDER BY position ASC
 	`
-	rows, err := r.db.QueryContext(ctx, query, userID)
+	return r.query(ctx, query, userID, pageID)
+}
+
+// GetAllActive retrieves every link visitors can see, across all users, as
+// they see it (for the health checker). Broken links are hidden from the
+// published profile, so that is the version that gets checked.
+func (r *LinkRepository) GetAllActive(ctx context.Context) ([]model.Link, error) {
+	query := `
+		SELECT ` + publishedLinkColumns + `
+		FROM links WHERE published_at IS NOT NULL AND pub_is_active = 1
+		ORDER BY id ASC
+	`
+	return r.query(ctx, query)
+}
+
+// query runs a link SELECT and scans all rows
+func (r *LinkRepository) query(ctx context.Context, query string, args ...any) ([]model.Link, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -128,50 +276,86 @@ func (r *LinkRepository) GetActiveByUserID(ctx context.Context, userID int64) ([
 
 	var links []model.Link
 	for rows.Next() {
-		var link model.Link
-		var isActive int // SQLite stores bool as int
-		if err := rows.Scan(
-			&link.ID,
-			&link.UserID,
-			&link.Title,
-			&link.URL,
-			&link.Icon,
-			&link.Position,
-			&isActive,
-			&link.CreatedAt,
-		); err != nil {
+		link, err := scanLink(rows)
+		if err != nil {
 			return nil, err
 		}
-		link.IsActive = isActive == 1
 		links = append(links, link)
 	}
 
 	return links, rows.Err()
 }
 
-// Update updates a link
+// Update updates a link's working copy. Health describes the published
+// URL, so it is kept until Publish makes a new URL live.
 func (r *LinkRepository) Update(ctx context.Context, link *model.Link) error {
 	query := `
 		UPDATE links 
-		SET title = ?, url = ?, icon = ?, is_active = ?
+		SET page_id = ?, title = ?, url = ?, icon = ?, is_active = ?, is_featured = ?, is_sensitive = ?,
+			starts_at = ?, ends_at = ?, location = ?, time_zone = ?
 		WHERE id = ?
 	`
 	_, err := r.db.ExecContext(ctx, query,
+		link.PageID,
 		link.Title,
 		link.URL,
 		link.Icon,
 		link.IsActive,
+		link.IsFeatured,
+		link.IsSensitive,
+		link.StartsAt,
+		link.EndsAt,
+		link.Location,
+		link.TimeZone,
 		link.ID,
 	)
 	return err
 }
 
-// Delete removes a link
-func (r *LinkRepository) Delete(ctx context.Context, id int64) error {
-	_, err := r.db.ExecContext(ctx, "DELETE FROM links WHERE id = ?", id)
+// ApplyPreview fills in fetched page metadata without overwriting anything
+// the creator has typed. Title and icon are only set while still empty, and
+// nothing is written if the URL changed while the fetch was in flight.
+func (r *LinkRepository) ApplyPreview(ctx context.Context, id int64, url, title, icon, description, imageURL string) error {
+	query := `
+		UPDATE links
+		SET title = CASE WHEN title = '' THEN ? ELSE title END,
+		    icon = CASE WHEN icon = '' THEN ? ELSE icon END,
+		    description = ?,
+		    image_url = ?
+		WHERE id = ? AND url = ?
+	`
+	_, err := r.db.ExecContext(ctx, query, title, icon, description, imageURL, id, url)
+	return err
+}
+
+// UpdateHealth stores the result of a link health check
+func (r *LinkRepository) UpdateHealth(ctx context.Context, id int64, status int, checkedAt time.Time) error {
+	_, err := r.db.ExecContext(ctx,
+		"UPDATE links SET last_status = ?, last_checked_at = ? WHERE id = ?",
+		status, checkedAt, id,
+	)
 	return err
 }
 
+// Delete removes a link from the draft. A link that was never published is
+// deleted outright; a published one stays live, marked for removal on the
+// next publish.
+func (r *LinkRepository) Delete(ctx context.Context, id int64) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM links WHERE id = ? AND published_at IS NULL", id); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "UPDATE links SET deleted_at = CURRENT_TIMESTAMP WHERE id = ?", id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
 // UpdatePositions updates link positions (for drag-reorder)
 func (r *LinkRepository) UpdatePositions(ctx context.Context, userID int64, positions map[int64]int) error {
 	tx, err := r.db.BeginTx(ctx, nil)
@@ -195,8 +379,10 @@ func (r *LinkRepository) UpdatePositions(ctx context.Context, userID int64, posi
 	return tx.Commit()
 }
 
-func (r *LinkRepository) CountByUserID(ctx context.Context, userID int64) (int, error) {
+// CountByPage counts the links on one of the pages in a user's draft.
+// pageID is nil for the main page.
+func (r *LinkRepository) CountByPage(ctx context.Context, userID int64, pageID *int64) (int, error) {
 	var count int
-	err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM links WHERE user_id = ?", userID).Scan(&count)
+	err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM links WHERE user_id = ? AND page_id IS ? AND deleted_at IS NULL", userID, pageID).Scan(&count)
 	return count, err
 }
@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"testing"
+	"time"
 
 	"linkbio/internal/model"
 	"linkbio/internal/testutil"
@@ -172,8 +173,16 @@ func TestLinkRepository_GetActiveByUserID(t *testing.T) {
 	linkRepo.Create(ctx, activeLink2)
 	linkRepo.Create(ctx, inactiveLink)
 
+	// New links stay in the draft until published
+	if links, _ := linkRepo.GetActiveByUserID(ctx, user.ID, nil); len(links) != 0 {
+		t.Fatalf("GetActiveByUserID() returned %d unpublished links", len(links))
+	}
+	if _, err := NewDraftRepository(db).Publish(ctx, user.ID, time.Now()); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+
 	// Test: Get active links only
-	activeLinks, err := linkRepo.GetActiveByUserID(ctx, user.ID)
+	activeLinks, err := linkRepo.GetActiveByUserID(ctx, user.ID, nil)
 	if err != nil {
 		t.Fatalf("GetActiveByUserID() error = %v", err)
 	}
@@ -290,3 +299,253 @@ func TestLinkRepository_UserIsolation(t *testing.T) {
 		t.Error("User2 got wrong link")
 	}
 }
+
+func TestLinkRepository_UpdateHealth(t *testing.T) {
+	db := testutil.TestDB(t)
+	userRepo := NewUserRepository(db)
+	linkRepo := NewLinkRepository(db)
+	ctx := context.Background()
+
+	user := createTestUser(t, userRepo, "healthtest")
+
+	link := &model.Link{UserID: user.ID, Title: "Dead", URL: "https://dead.example", IsActive: true}
+	linkRepo.Create(ctx, link)
+
+	// Unchecked links are never broken
+	found, _ := linkRepo.GetByID(ctx, link.ID)
+	if found.LastCheckedAt != nil || found.IsBroken() {
+		t.Error("new link should be unchecked")
+	}
+
+	if err := linkRepo.UpdateHealth(ctx, link.ID, 404, time.Now()); err != nil {
+		t.Fatalf("UpdateHealth() error = %v", err)
+	}
+
+	found, _ = linkRepo.GetByID(ctx, link.ID)
+	if found.LastStatus != 404 {
+		t.Errorf("LastStatus = %d, want 404", found.LastStatus)
+	}
+	if found.LastCheckedAt == nil {
+		t.Fatal("LastCheckedAt was not set")
+	}
+	if !found.IsBroken() {
+		t.Error("IsBroken() = false, want true for 404")
+	}
+
+	// Editing anything but the URL keeps the result
+	found.Title = "Still dead"
+	if err := linkRepo.Update(ctx, found); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	found, _ = linkRepo.GetByID(ctx, link.ID)
+	if !found.IsBroken() {
+		t.Error("IsBroken() = false after a title edit, want true")
+	}
+
+	// Health describes the live URL, so a drafted URL edit keeps it
+	found.URL = "https://alive.example"
+	if err := linkRepo.Update(ctx, found); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	found, _ = linkRepo.GetByID(ctx, link.ID)
+	if !found.IsBroken() {
+		t.Error("IsBroken() = false after a drafted URL edit, want true until publish")
+	}
+}
+
+func TestLinkRepository_GetAllActive(t *testing.T) {
+	db := testutil.TestDB(t)
+	userRepo := NewUserRepository(db)
+	linkRepo := NewLinkRepository(db)
+	ctx := context.Background()
+
+	user1 := createTestUser(t, userRepo, "allactive1")
+	user2 := createTestUser(t, userRepo, "allactive2")
+
+	linkRepo.Create(ctx, &model.Link{UserID: user1.ID, Title: "A", URL: "https://a.com", IsActive: true})
+	b := &model.Link{UserID: user2.ID, Title: "B", URL: "https://b.com", IsActive: true}
+	linkRepo.Create(ctx, b)
+	linkRepo.Create(ctx, &model.Link{UserID: user2.ID, Title: "C", URL: "https://c.com", IsActive: false})
+	drafts := NewDraftRepository(db)
+	drafts.Publish(ctx, user1.ID, time.Now())
+	drafts.Publish(ctx, user2.ID, time.Now())
+
+	// Drafted edits aren't live, so the published URL is what gets checked
+	b.URL = "https://b.example"
+	linkRepo.Update(ctx, b)
+	linkRepo.Create(ctx, &model.Link{UserID: user1.ID, Title: "D", URL: "https://d.com", IsActive: true})
+
+	links, err := linkRepo.GetAllActive(ctx)
+	if err != nil {
+		t.Fatalf("GetAllActive() error = %v", err)
+	}
+	if len(links) != 2 {
+		t.Fatalf("GetAllActive() returned %d links, want 2", len(links))
+	}
+	if links[1].URL != "https://b.com" {
+		t.Errorf("GetAllActive() URL = %q, want the published https://b.com", links[1].URL)
+	}
+}
+
+func TestLinkRepository_ApplyPreview(t *testing.T) {
+	db := testutil.TestDB(t)
+	userRepo := NewUserRepository(db)
+	linkRepo := NewLinkRepository(db)
+	ctx := context.Background()
+
+	user := createTestUser(t, userRepo, "previewtest")
+
+	untitled := &model.Link{UserID: user.ID, URL: "https://a.com", IsActive: true}
+	titled := &model.Link{UserID: user.ID, Title: "Mine", Icon: "🎵", URL: "https://b.com", IsActive: true}
+	linkRepo.Create(ctx, untitled)
+	linkRepo.Create(ctx, titled)
+
+	linkRepo.ApplyPreview(ctx, untitled.ID, "https://a.com", "OG Title", "https://a.com/favicon.ico", "Desc", "https://a.com/og.png")
+	linkRepo.ApplyPreview(ctx, titled.ID, "https://b.com", "OG Title", "https://b.com/favicon.ico", "Desc", "https://b.com/og.png")
+
+	found, _ := linkRepo.GetByID(ctx, untitled.ID)
+	if found.Title != "OG Title" || found.Icon != "https://a.com/favicon.ico" {
+		t.Errorf("empty fields not filled: title=%q icon=%q", found.Title, found.Icon)
+	}
+	if found.ImageURL != "https://a.com/og.png" || found.Description != "Desc" {
+		t.Errorf("preview not stored: image=%q description=%q", found.ImageURL, found.Description)
+	}
+
+	found, _ = linkRepo.GetByID(ctx, titled.ID)
+	if found.Title != "Mine" || found.Icon != "🎵" {
+		t.Errorf("creator values overwritten: title=%q icon=%q", found.Title, found.Icon)
+	}
+
+	// A stale fetch for an old URL must not land
+	linkRepo.ApplyPreview(ctx, titled.ID, "https://old.com", "", "", "Stale", "")
+	found, _ = linkRepo.GetByID(ctx, titled.ID)
+	if found.Description != "Desc" {
+		t.Errorf("Description = %q, stale preview was applied", found.Description)
+	}
+}
+
+func TestLinkRepository_Search(t *testing.T) {
+	db := testutil.TestDB(t)
+	userRepo := NewUserRepository(db)
+	linkRepo := NewLinkRepository(db)
+	ctx := context.Background()
+
+	user := createTestUser(t, userRepo, "searcher")
+	other := createTestUser(t, userRepo, "bystander")
+
+	create := func(userID int64, title, url string, active bool) *model.Link {
+		t.Helper()
+		link := &model.Link{UserID: userID, Title: title, URL: url, IsActive: active}
+		if err := linkRepo.Create(ctx, link); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+		return link
+	}
+	create(user.ID, "My Podcast", "https://podcast.example.com", true)
+	video := create(user.ID, "Latest video", "https://www.youtube.com/watch?v=abc", true)
+	create(user.ID, "Café menu", "https://menu.example.com", false)
+	gone := create(user.ID, "Old podcast", "https://old.example.com", true)
+	create(other.ID, "Their podcast", "https://theirs.example.com", true)
+
+	if err := linkRepo.Delete(ctx, gone.ID); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+
+	titles := func(q LinkQuery) []string {
+		t.Helper()
+		if q.Limit == 0 {
+			q.Limit = 10
+		}
+		links, err := linkRepo.Search(ctx, user.ID, q)
+		if err != nil {
+			t.Fatalf("Search(%+v) error = %v", q, err)
+		}
+		var got []string
+		for _, l := range links {
+			got = append(got, l.Title)
+		}
+		return got
+	}
+
+	tests := []struct {
+		query LinkQuery
+		want  []string
+	}{
+		// Title matches outrank URL matches; accents are ignored
+		{LinkQuery{Text: "podc"}, []string{"My Podcast"}},
+		{LinkQuery{Text: "youtube.com"}, []string{"Latest video"}},
+		{LinkQuery{Text: "cafe"}, []string{"Café menu"}},
+		{LinkQuery{Text: "example"}, []string{"My Podcast", "Café menu"}},
+		{LinkQuery{Text: "example", Status: model.LinkStatusInactive}, []string{"Café menu"}},
+		{LinkQuery{Text: `url:* OR "`}, nil},
+		// Without text, newest first
+		{LinkQuery{}, []string{"Café menu", "Latest video", "My Podcast"}},
+		{LinkQuery{Status: model.LinkStatusActive}, []string{"Latest video", "My Podcast"}},
+		{LinkQuery{Limit: 1, Offset: 2}, []string{"My Podcast"}},
+	}
+	for _, tt := range tests {
+		got := titles(tt.query)
+		if len(got) != len(tt.want) {
+			t.Errorf("Search(%+v) = %v, want %v", tt.query, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("Search(%+v) = %v, want %v", tt.query, got, tt.want)
+				break
+			}
+		}
+	}
+
+	// Edits reach the index
+	video.Title = "Livestream"
+	if err := linkRepo.Update(ctx, video); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if got := titles(LinkQuery{Text: "latest"}); len(got) != 0 {
+		t.Errorf("old title still matches: %v", got)
+	}
+	if got := titles(LinkQuery{Text: "livestr"}); len(got) != 1 {
+		t.Errorf("new title not found: %v", got)
+	}
+}
+
+func TestLinkRepository_SetActiveByTag(t *testing.T) {
+	db := testutil.TestDB(t)
+	userRepo := NewUserRepository(db)
+	linkRepo := NewLinkRepository(db)
+	tagRepo := NewTagRepository(db)
+	ctx := context.Background()
+
+	user := createTestUser(t, userRepo, "bulkhide")
+	seasonal := &model.Tag{UserID: user.ID, Name: "seasonal", Color: "orange"}
+	tagRepo.Create(ctx, seasonal)
+
+	winter := &model.Link{UserID: user.ID, Title: "Winter sale", URL: "https://winter.example", IsActive: true}
+	summer := &model.Link{UserID: user.ID, Title: "Summer sale", URL: "https://summer.example", IsActive: true}
+	other := &model.Link{UserID: user.ID, Title: "Shop", URL: "https://shop.example", IsActive: true}
+	linkRepo.Create(ctx, winter)
+	linkRepo.Create(ctx, summer)
+	linkRepo.Create(ctx, other)
+	tagRepo.SetLinkTags(ctx, user.ID, winter.ID, []int64{seasonal.ID})
+	tagRepo.SetLinkTags(ctx, user.ID, summer.ID, []int64{seasonal.ID})
+
+	n, err := linkRepo.SetActiveByTag(ctx, user.ID, seasonal.ID, false)
+	if err != nil || n != 2 {
+		t.Fatalf("SetActiveByTag(false) = %d, %v; want 2", n, err)
+	}
+	links, _ := linkRepo.Search(ctx, user.ID, LinkQuery{Status: model.LinkStatusInactive, TagID: seasonal.ID, Limit: 10})
+	if len(links) != 2 {
+		t.Errorf("hidden seasonal links = %d, want 2", len(links))
+	}
+	if found, _ := linkRepo.GetByID(ctx, other.ID); !found.IsActive {
+		t.Error("untagged link was hidden")
+	}
+
+	if n, _ := linkRepo.SetActiveByTag(ctx, user.ID, seasonal.ID, true); n != 2 {
+		t.Errorf("SetActiveByTag(true) = %d, want 2", n)
+	}
+	if n, _ := linkRepo.SetActiveByTag(ctx, user.ID, seasonal.ID, true); n != 0 {
+		t.Errorf("SetActiveByTag(true) again = %d, want 0", n)
+	}
+}
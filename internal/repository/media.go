@@ -0,0 +1,150 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"linkbio/internal/model"
+)
+
+// MediaRepository tracks which records own which stored blobs
+type MediaRepository struct {
+	db *sql.DB
+}
+
+// NewMediaRepository creates a new MediaRepository
+func NewMediaRepository(db *sql.DB) *MediaRepository {
+	return &MediaRepository{db: db}
+}
+
+// Create records ownership of a blob
+func (r *MediaRepository) Create(ctx context.Context, m *model.Media) error {
+	query := `INSERT INTO media (user_id, link_id, kind, key, content_type, size) VALUES (?, ?, ?, ?, ?, ?)`
+	result, err := r.db.ExecContext(ctx, query, m.UserID, m.LinkID, m.Kind, m.Key, m.ContentType, m.Size)
+	if err != nil {
+		return err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	m.ID = id
+	return nil
+}
+
+// Replace swaps every blob of one kind owned by a user for a new set in a
+// single transaction
+func (r *MediaRepository) Replace(ctx context.Context, userID int64, kind string, media []*model.Media) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM media WHERE user_id = ? AND kind = ?`, userID, kind); err != nil {
+		return err
+	}
+
+	stmt, err := tx.PrepareContext(ctx, `INSERT INTO media (user_id, link_id, kind, key, content_type, size) VALUES (?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, m := range media {
+		result, err := stmt.ExecContext(ctx, userID, m.LinkID, kind, m.Key, m.ContentType, m.Size)
+		if err != nil {
+			return err
+		}
+		if m.ID, err = result.LastInsertId(); err != nil {
+			return err
+		}
+		m.UserID, m.Kind = userID, kind
+	}
+
+	return tx.Commit()
+}
+
+// KeysByUser returns the keys of one kind owned by a user, or every kind
+// when kind is empty
+func (r *MediaRepository) KeysByUser(ctx context.Context, userID int64, kind string) ([]string, error) {
+	if kind == "" {
+		return r.keys(ctx, `SELECT DISTINCT key FROM media WHERE user_id = ?`, userID)
+	}
+	return r.keys(ctx, `SELECT DISTINCT key FROM media WHERE user_id = ? AND kind = ?`, userID, kind)
+}
+
+// KeysByLink returns the keys owned by a link
+func (r *MediaRepository) KeysByLink(ctx context.Context, linkID int64) ([]string, error) {
+	return r.keys(ctx, `SELECT DISTINCT key FROM media WHERE link_id = ?`, linkID)
+}
+
+// staleLinkIcons deletes the records of uploaded link icons that neither
+// the draft nor the published version of their link shows any more, and
+// returns their keys
+const staleLinkIcons = `
+	DELETE FROM media
+	WHERE id IN (
+		SELECT m.id FROM media m
+		JOIN links l ON l.id = m.link_id
+		WHERE m.user_id = ? AND m.kind = 'icon'
+			AND '/media/' || m.key NOT IN (COALESCE(l.icon, ''), COALESCE(l.pub_icon, ''))
+	)
+	RETURNING key
+`
+
+// ReleaseLinkIcons drops a user's uploaded link icons that no version of
+// their link uses. It returns the keys, for the caller to delete once
+// nothing else references them.
+func (r *MediaRepository) ReleaseLinkIcons(ctx context.Context, userID int64) ([]string, error) {
+	return r.keys(ctx, staleLinkIcons, userID)
+}
+
+// Unreferenced filters keys down to those no media row points at any more.
+// Call it after the owning record is gone to find blobs safe to delete.
+func (r *MediaRepository) Unreferenced(ctx context.Context, keys []string) ([]string, error) {
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	args := make([]any, len(keys))
+	for i, k := range keys {
+		args[i] = k
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(keys)), ",")
+	referenced, err := r.keys(ctx, `SELECT DISTINCT key FROM media WHERE key IN (`+placeholders+`)`, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	inUse := make(map[string]bool, len(referenced))
+	for _, k := range referenced {
+		inUse[k] = true
+	}
+	var orphans []string
+	for _, k := range keys {
+		if !inUse[k] {
+			orphans = append(orphans, k)
+		}
+	}
+	return orphans, nil
+}
+
+func (r *MediaRepository) keys(ctx context.Context, query string, args ...any) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []string
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"linkbio/internal/model"
+	"linkbio/internal/testutil"
+)
+
+func TestMediaRepository_Replace(t *testing.T) {
+	db := testutil.TestDB(t)
+	userRepo := NewUserRepository(db)
+	mediaRepo := NewMediaRepository(db)
+	ctx := context.Background()
+
+	user := createTestUser(t, userRepo, "mediatest")
+
+	first := []*model.Media{{Key: "avatars/a.jpg"}, {Key: "avatars/b.jpg"}}
+	if err := mediaRepo.Replace(ctx, user.ID, "avatar", first); err != nil {
+		t.Fatalf("Replace() error = %v", err)
+	}
+	if first[0].ID == 0 {
+		t.Error("Replace() did not set IDs")
+	}
+
+	old, _ := mediaRepo.KeysByUser(ctx, user.ID, "avatar")
+	if len(old) != 2 {
+		t.Fatalf("KeysByUser() = %v, want 2 keys", old)
+	}
+
+	// Re-uploading an image keeps its key; only b becomes an orphan
+	if err := mediaRepo.Replace(ctx, user.ID, "avatar", []*model.Media{{Key: "avatars/a.jpg"}, {Key: "avatars/c.jpg"}}); err != nil {
+		t.Fatalf("Replace() error = %v", err)
+	}
+	orphans, err := mediaRepo.Unreferenced(ctx, old)
+	if err != nil {
+		t.Fatalf("Unreferenced() error = %v", err)
+	}
+	if len(orphans) != 1 || orphans[0] != "avatars/b.jpg" {
+		t.Errorf("Unreferenced() = %v, want [avatars/b.jpg]", orphans)
+	}
+}
+
+func TestMediaRepository_SharedKeysSurviveOwnerDeletion(t *testing.T) {
+	db := testutil.TestDB(t)
+	userRepo := NewUserRepository(db)
+	linkRepo := NewLinkRepository(db)
+	mediaRepo := NewMediaRepository(db)
+	ctx := context.Background()
+
+	alice := createTestUser(t, userRepo, "alice")
+	bob := createTestUser(t, userRepo, "bob")
+
+	link := &model.Link{UserID: alice.ID, Title: "Site", URL: "https://example.com", IsActive: true}
+	if err := linkRepo.Create(ctx, link); err != nil {
+		t.Fatalf("failed to create link: %v", err)
+	}
+
+	// Both users happen to upload the same icon
+	mediaRepo.Create(ctx, &model.Media{UserID: alice.ID, LinkID: &link.ID, Kind: "icon", Key: "icons/same.png"})
+	mediaRepo.Create(ctx, &model.Media{UserID: bob.ID, Kind: "icon", Key: "icons/same.png"})
+	mediaRepo.Create(ctx, &model.Media{UserID: alice.ID, LinkID: &link.ID, Kind: "icon", Key: "icons/own.png"})
+
+	keys, _ := mediaRepo.KeysByLink(ctx, link.ID)
+	if len(keys) != 2 {
+		t.Fatalf("KeysByLink() = %v, want 2 keys", keys)
+	}
+
+	// Deleting the link cascades to its media rows
+	if err := linkRepo.Delete(ctx, link.ID); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+
+	orphans, err := mediaRepo.Unreferenced(ctx, keys)
+	if err != nil {
+		t.Fatalf("Unreferenced() error = %v", err)
+	}
+	if len(orphans) != 1 || orphans[0] != "icons/own.png" {
+		t.Errorf("Unreferenced() = %v, want only icons/own.png", orphans)
+	}
+}
@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"linkbio/internal/model"
+)
+
+// pageColumns is the column list shared by every working-copy page SELECT
+const pageColumns = `id, user_id, slug, title, position, created_at`
+
+// publishedPageColumns selects the live copy of a page in the same order as
+// pageColumns, so list can scan either
+const publishedPageColumns = `id, user_id, pub_slug, pub_title, pub_position, created_at`
+
+// PageRepository handles profile sub-page database operations
+type PageRepository struct {
+	db *sql.DB
+}
+
+// NewPageRepository creates a new PageRepository
+func NewPageRepository(db *sql.DB) *PageRepository {
+	return &PageRepository{db: db}
+}
+
+// Create adds a page at the end of the user's navigation. The page is a
+// draft until the user publishes. Adding back the address of a page removed
+// in the draft restores that page under the new title. If the address is
+// already taken by a live page it returns sql.ErrNoRows.
+func (r *PageRepository) Create(ctx context.Context, p *model.Page) error {
+	query := `
+		INSERT INTO pages (user_id, slug, title, position)
+		VALUES (?, ?, ?, (SELECT COALESCE(MAX(position), 0) + 1 FROM pages WHERE user_id = ? AND deleted_at IS NULL))
+		ON CONFLICT (user_id, slug) DO UPDATE SET
+			title = excluded.title, position = excluded.position, deleted_at = NULL
+		WHERE pages.deleted_at IS NOT NULL
+		RETURNING id, position, created_at
+	`
+	return r.db.QueryRowContext(ctx, query, p.UserID, p.Slug, p.Title, p.UserID).Scan(
+		&p.ID, &p.Position, &p.CreatedAt,
+	)
+}
+
+// GetByID retrieves a page by ID
+func (r *PageRepository) GetByID(ctx context.Context, id int64) (*model.Page, error) {
+	p := &model.Page{}
+	err := r.db.QueryRowContext(ctx, `SELECT `+pageColumns+` FROM pages WHERE id = ?`, id).Scan(
+		&p.ID, &p.UserID, &p.Slug, &p.Title, &p.Position, &p.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// ListByUser returns a user's drafted pages in navigation order, for the
+// dashboard and preview
+func (r *PageRepository) ListByUser(ctx context.Context, userID int64) ([]model.Page, error) {
+	return r.list(ctx, `SELECT `+pageColumns+` FROM pages
+		WHERE user_id = ? AND deleted_at IS NULL ORDER BY position, id`, userID)
+}
+
+// ListPublished returns the pages visitors see, in their published order
+func (r *PageRepository) ListPublished(ctx context.Context, userID int64) ([]model.Page, error) {
+	return r.list(ctx, `SELECT `+publishedPageColumns+` FROM pages
+		WHERE user_id = ? AND published_at IS NOT NULL ORDER BY pub_position, id`, userID)
+}
+
+// list runs a page query that takes the user's ID
+func (r *PageRepository) list(ctx context.Context, query string, userID int64) ([]model.Page, error) {
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var pages []model.Page
+	for rows.Next() {
+		var p model.Page
+		if err := rows.Scan(&p.ID, &p.UserID, &p.Slug, &p.Title, &p.Position, &p.CreatedAt); err != nil {
+			return nil, err
+		}
+		pages = append(pages, p)
+	}
+	return pages, rows.Err()
+}
+
+// UpdatePositions updates page positions (for drag-reorder)
+func (r *PageRepository) UpdatePositions(ctx context.Context, userID int64, positions map[int64]int) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, "UPDATE pages SET position = ? WHERE id = ? AND user_id = ?")
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for id, position := range positions {
+		if _, err := stmt.ExecContext(ctx, position, id, userID); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Delete removes a user's page from the draft. The page's drafted links
+// move to the main page. A page that was never published goes at once; a
+// live one stays up, with its links, until the user publishes.
+func (r *PageRepository) Delete(ctx context.Context, id, userID int64) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	steps := []string{
+		`DELETE FROM pages WHERE id = ? AND user_id = ? AND published_at IS NULL`,
+		`UPDATE pages SET deleted_at = CURRENT_TIMESTAMP WHERE id = ? AND user_id = ?`,
+		`UPDATE links SET page_id = NULL WHERE page_id = ? AND user_id = ?`,
+	}
+	for _, query := range steps {
+		if _, err := tx.ExecContext(ctx, query, id, userID); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,185 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"linkbio/internal/model"
+	"linkbio/internal/testutil"
+)
+
+func TestPageRepository_CreateAndOrder(t *testing.T) {
+	db := testutil.TestDB(t)
+	userRepo := NewUserRepository(db)
+	pageRepo := NewPageRepository(db)
+	ctx := context.Background()
+
+	user := createTestUser(t, userRepo, "pageuser")
+
+	merch := &model.Page{UserID: user.ID, Slug: "merch", Title: "Merch"}
+	tour := &model.Page{UserID: user.ID, Slug: "tour", Title: "Tour"}
+	for _, p := range []*model.Page{merch, tour} {
+		if err := pageRepo.Create(ctx, p); err != nil {
+			t.Fatalf("Create(%s) error = %v", p.Slug, err)
+		}
+	}
+	if merch.Position != 1 || tour.Position != 2 {
+		t.Errorf("positions = %d, %d; want new pages appended", merch.Position, tour.Position)
+	}
+
+	// Another user may use the same address
+	other := createTestUser(t, userRepo, "pageother")
+	if err := pageRepo.Create(ctx, &model.Page{UserID: other.ID, Slug: "merch", Title: "Shop"}); err != nil {
+		t.Errorf("Create() for another user error = %v", err)
+	}
+	if err := pageRepo.Create(ctx, &model.Page{UserID: user.ID, Slug: "merch", Title: "Again"}); err != sql.ErrNoRows {
+		t.Errorf("Create() duplicate address error = %v, want sql.ErrNoRows", err)
+	}
+
+	if err := pageRepo.UpdatePositions(ctx, user.ID, map[int64]int{merch.ID: 2, tour.ID: 1}); err != nil {
+		t.Fatalf("UpdatePositions() error = %v", err)
+	}
+	list, err := pageRepo.ListByUser(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("ListByUser() error = %v", err)
+	}
+	if len(list) != 2 || list[0].Slug != "tour" || list[1].Slug != "merch" {
+		t.Errorf("ListByUser() = %+v", list)
+	}
+
+	got, err := pageRepo.GetByID(ctx, merch.ID)
+	if err != nil || got == nil || got.Title != "Merch" || got.UserID != user.ID {
+		t.Errorf("GetByID() = %+v, %v", got, err)
+	}
+}
+
+func TestPageRepository_Links(t *testing.T) {
+	db := testutil.TestDB(t)
+	userRepo := NewUserRepository(db)
+	linkRepo := NewLinkRepository(db)
+	pageRepo := NewPageRepository(db)
+	draftRepo := NewDraftRepository(db)
+	ctx := context.Background()
+
+	user := createTestUser(t, userRepo, "pagelinks")
+	merch := &model.Page{UserID: user.ID, Slug: "merch", Title: "Merch"}
+	pageRepo.Create(ctx, merch)
+
+	home := &model.Link{UserID: user.ID, Title: "Home", URL: "https://home.example", IsActive: true}
+	shirt := &model.Link{UserID: user.ID, PageID: &merch.ID, Title: "Shirt", URL: "https://shirt.example", IsActive: true}
+	linkRepo.Create(ctx, home)
+	linkRepo.Create(ctx, shirt)
+	draftRepo.Publish(ctx, user.ID, time.Now())
+
+	main, _ := linkRepo.GetActiveByUserID(ctx, user.ID, nil)
+	sub, _ := linkRepo.GetActiveByUserID(ctx, user.ID, &merch.ID)
+	if len(main) != 1 || main[0].ID != home.ID {
+		t.Errorf("main page links = %+v", main)
+	}
+	if len(sub) != 1 || sub[0].ID != shirt.ID || !sub[0].OnPage(&merch.ID) {
+		t.Errorf("merch links = %+v", sub)
+	}
+	if n, _ := linkRepo.CountByPage(ctx, user.ID, &merch.ID); n != 1 {
+		t.Errorf("CountByPage(merch) = %d, want 1", n)
+	}
+
+	// Moving a link is drafted like any other edit
+	home.PageID = &merch.ID
+	linkRepo.Update(ctx, home)
+	if s, _ := draftRepo.Status(ctx, user.ID); s.LinkChanges != 1 {
+		t.Errorf("LinkChanges = %d after a move, want 1", s.LinkChanges)
+	}
+	if live, _ := linkRepo.GetActiveByUserID(ctx, user.ID, nil); len(live) != 1 {
+		t.Errorf("main page lost its link before publishing: %+v", live)
+	}
+	if drafted, _ := linkRepo.GetActiveDraftByUserID(ctx, user.ID, &merch.ID); len(drafted) != 2 {
+		t.Errorf("drafted merch links = %+v, want 2", drafted)
+	}
+
+	// Deleting the page is drafted too: its links stay live on it until
+	// the user publishes, then move to the main page
+	if err := pageRepo.Delete(ctx, merch.ID, user.ID); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if live, _ := linkRepo.GetActiveByUserID(ctx, user.ID, &merch.ID); len(live) != 1 {
+		t.Errorf("live merch links after delete = %+v, want the shirt until publish", live)
+	}
+	if drafted, _ := linkRepo.GetActiveDraftByUserID(ctx, user.ID, nil); len(drafted) != 2 {
+		t.Errorf("drafted main page links after delete = %+v, want both", drafted)
+	}
+	draftRepo.Publish(ctx, user.ID, time.Now())
+	if live, _ := linkRepo.GetActiveByUserID(ctx, user.ID, nil); len(live) != 2 {
+		t.Errorf("main page links after publish = %+v, want both", live)
+	}
+	if s, _ := draftRepo.Status(ctx, user.ID); s.HasChanges() {
+		t.Errorf("Status() = %+v after publish, want no changes", s)
+	}
+}
+
+func TestPageRepository_Drafts(t *testing.T) {
+	db := testutil.TestDB(t)
+	userRepo := NewUserRepository(db)
+	pageRepo := NewPageRepository(db)
+	draftRepo := NewDraftRepository(db)
+	ctx := context.Background()
+
+	user := createTestUser(t, userRepo, "pagedrafts")
+	merch := &model.Page{UserID: user.ID, Slug: "merch", Title: "Merch"}
+	tour := &model.Page{UserID: user.ID, Slug: "tour", Title: "Tour"}
+	pageRepo.Create(ctx, merch)
+	pageRepo.Create(ctx, tour)
+
+	// New pages wait for publish
+	if live, _ := pageRepo.ListPublished(ctx, user.ID); len(live) != 0 {
+		t.Errorf("ListPublished() = %+v before publishing, want none", live)
+	}
+	if s, _ := draftRepo.Status(ctx, user.ID); s.PageChanges != 2 {
+		t.Errorf("PageChanges = %d, want 2", s.PageChanges)
+	}
+	draftRepo.Publish(ctx, user.ID, time.Now())
+
+	// Reordering and removing don't reach visitors until publish
+	pageRepo.UpdatePositions(ctx, user.ID, map[int64]int{merch.ID: 2, tour.ID: 1})
+	pageRepo.Delete(ctx, merch.ID, user.ID)
+	if s, _ := draftRepo.Status(ctx, user.ID); s.PageChanges != 2 {
+		t.Errorf("PageChanges = %d after reorder and delete, want 2", s.PageChanges)
+	}
+	if drafted, _ := pageRepo.ListByUser(ctx, user.ID); len(drafted) != 1 || drafted[0].ID != tour.ID {
+		t.Errorf("ListByUser() = %+v, want only tour", drafted)
+	}
+	if live, _ := pageRepo.ListPublished(ctx, user.ID); len(live) != 2 || live[0].ID != merch.ID {
+		t.Errorf("ListPublished() = %+v, want merch then tour", live)
+	}
+
+	// Revert brings the removed page back in its published place
+	if _, err := draftRepo.Revert(ctx, user.ID); err != nil {
+		t.Fatalf("Revert() error = %v", err)
+	}
+	if drafted, _ := pageRepo.ListByUser(ctx, user.ID); len(drafted) != 2 || drafted[0].ID != merch.ID {
+		t.Errorf("ListByUser() after revert = %+v, want merch then tour", drafted)
+	}
+
+	// Adding back a removed page's address restores that page
+	pageRepo.Delete(ctx, merch.ID, user.ID)
+	again := &model.Page{UserID: user.ID, Slug: "merch", Title: "Shop"}
+	if err := pageRepo.Create(ctx, again); err != nil {
+		t.Fatalf("Create() over a removed page error = %v", err)
+	}
+	if again.ID != merch.ID {
+		t.Errorf("Create() made page %d, want %d restored", again.ID, merch.ID)
+	}
+	draftRepo.Publish(ctx, user.ID, time.Now())
+	if live, _ := pageRepo.ListPublished(ctx, user.ID); len(live) != 2 || live[1].Title != "Shop" {
+		t.Errorf("ListPublished() = %+v, want tour then Shop", live)
+	}
+
+	// A page that was never published is removed outright
+	draft := &model.Page{UserID: user.ID, Slug: "draft", Title: "Draft"}
+	pageRepo.Create(ctx, draft)
+	pageRepo.Delete(ctx, draft.ID, user.ID)
+	if got, _ := pageRepo.GetByID(ctx, draft.ID); got != nil {
+		t.Errorf("GetByID() = %+v after deleting an unpublished page, want nil", got)
+	}
+}
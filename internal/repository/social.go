@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"linkbio/internal/model"
+)
+
+// SocialRepository handles social profile database operations
+type SocialRepository struct {
+	db *sql.DB
+}
+
+// NewSocialRepository creates a new SocialRepository
+func NewSocialRepository(db *sql.DB) *SocialRepository {
+	return &SocialRepository{db: db}
+}
+
+// Save sets the user's handle for a platform. A new platform is added at
+// the end of the row; an existing one keeps its place.
+func (r *SocialRepository) Save(ctx context.Context, s *model.SocialProfile) error {
+	query := `
+		INSERT INTO social_profiles (user_id, platform, handle, position)
+		VALUES (?, ?, ?, (SELECT COALESCE(MAX(position), 0) + 1 FROM social_profiles WHERE user_id = ?))
+		ON CONFLICT (user_id, platform) DO UPDATE SET handle = excluded.handle
+		RETURNING id, position, created_at
+	`
+	return r.db.QueryRowContext(ctx, query, s.UserID, s.Platform, s.Handle, s.UserID).Scan(
+		&s.ID, &s.Position, &s.CreatedAt,
+	)
+}
+
+// GetByID retrieves a social profile by ID
+func (r *SocialRepository) GetByID(ctx context.Context, id int64) (*model.SocialProfile, error) {
+	query := `
+		SELECT id, user_id, platform, handle, position, created_at
+		FROM social_profiles WHERE id = ?
+	`
+	s := &model.SocialProfile{}
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&s.ID, &s.UserID, &s.Platform, &s.Handle, &s.Position, &s.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// ListByUser returns a user's social profiles in display order
+func (r *SocialRepository) ListByUser(ctx context.Context, userID int64) ([]model.SocialProfile, error) {
+	query := `
+		SELECT id, user_id, platform, handle, position, created_at
+		FROM social_profiles WHERE user_id = ?
+		ORDER BY position, id
+	`
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var profiles []model.SocialProfile
+	for rows.Next() {
+		var s model.SocialProfile
+		if err := rows.Scan(&s.ID, &s.UserID, &s.Platform, &s.Handle, &s.Position, &s.CreatedAt); err != nil {
+			return nil, err
+		}
+		profiles = append(profiles, s)
+	}
+	return profiles, rows.Err()
+}
+
+// UpdatePositions updates icon positions (for drag-reorder)
+func (r *SocialRepository) UpdatePositions(ctx context.Context, userID int64, positions map[int64]int) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, "UPDATE social_profiles SET position = ? WHERE id = ? AND user_id = ?")
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for id, position := range positions {
+		if _, err := stmt.ExecContext(ctx, position, id, userID); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Delete removes a user's social profile
+func (r *SocialRepository) Delete(ctx context.Context, id, userID int64) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM social_profiles WHERE id = ? AND user_id = ?`, id, userID)
+	return err
+}
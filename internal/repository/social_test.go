@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"linkbio/internal/model"
+	"linkbio/internal/social"
+	"linkbio/internal/testutil"
+)
+
+func TestSocialRepository_SaveAndOrder(t *testing.T) {
+	db := testutil.TestDB(t)
+	userRepo := NewUserRepository(db)
+	socialRepo := NewSocialRepository(db)
+	ctx := context.Background()
+
+	user := createTestUser(t, userRepo, "socialuser")
+
+	gh := &model.SocialProfile{UserID: user.ID, Platform: social.GitHub, Handle: "octocat"}
+	ig := &model.SocialProfile{UserID: user.ID, Platform: social.Instagram, Handle: "alice"}
+	for _, s := range []*model.SocialProfile{gh, ig} {
+		if err := socialRepo.Save(ctx, s); err != nil {
+			t.Fatalf("Save(%s) error = %v", s.Platform, err)
+		}
+	}
+	if gh.Position != 1 || ig.Position != 2 {
+		t.Errorf("positions = %d, %d; want new icons appended", gh.Position, ig.Position)
+	}
+
+	// Saving a platform again changes its handle in place
+	again := &model.SocialProfile{UserID: user.ID, Platform: social.GitHub, Handle: "hubot"}
+	if err := socialRepo.Save(ctx, again); err != nil {
+		t.Fatalf("Save(again) error = %v", err)
+	}
+	if again.ID != gh.ID || again.Position != 1 {
+		t.Errorf("re-saved profile = id %d pos %d, want id %d pos 1", again.ID, again.Position, gh.ID)
+	}
+
+	if err := socialRepo.UpdatePositions(ctx, user.ID, map[int64]int{gh.ID: 2, ig.ID: 1}); err != nil {
+		t.Fatalf("UpdatePositions() error = %v", err)
+	}
+	list, err := socialRepo.ListByUser(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("ListByUser() error = %v", err)
+	}
+	if len(list) != 2 || list[0].Platform != social.Instagram || list[1].Handle != "hubot" {
+		t.Errorf("ListByUser() = %+v", list)
+	}
+
+	// Another user's delete is ignored
+	other := createTestUser(t, userRepo, "socialother")
+	socialRepo.Delete(ctx, ig.ID, other.ID)
+	if s, _ := socialRepo.GetByID(ctx, ig.ID); s == nil {
+		t.Fatal("Delete() removed another user's profile")
+	}
+	socialRepo.Delete(ctx, ig.ID, user.ID)
+	if s, _ := socialRepo.GetByID(ctx, ig.ID); s != nil {
+		t.Error("Delete() kept the profile")
+	}
+}
@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"linkbio/internal/model"
+)
+
+// tagColumns is the column list shared by every tag SELECT
+const tagColumns = `id, user_id, name, color, created_at`
+
+// TagRepository handles link tag database operations
+type TagRepository struct {
+	db *sql.DB
+}
+
+// NewTagRepository creates a new TagRepository
+func NewTagRepository(db *sql.DB) *TagRepository {
+	return &TagRepository{db: db}
+}
+
+// Create adds a tag
+func (r *TagRepository) Create(ctx context.Context, t *model.Tag) error {
+	query := `
+		INSERT INTO tags (user_id, name, color)
+		VALUES (?, ?, ?)
+		RETURNING id, created_at
+	`
+	return r.db.QueryRowContext(ctx, query, t.UserID, t.Name, t.Color).Scan(&t.ID, &t.CreatedAt)
+}
+
+// GetByID retrieves a tag by ID
+func (r *TagRepository) GetByID(ctx context.Context, id int64) (*model.Tag, error) {
+	t := &model.Tag{}
+	err := r.db.QueryRowContext(ctx, `SELECT `+tagColumns+` FROM tags WHERE id = ?`, id).Scan(
+		&t.ID, &t.UserID, &t.Name, &t.Color, &t.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// ListByUser returns a user's tags by name, each with the number of links
+// carrying it
+func (r *TagRepository) ListByUser(ctx context.Context, userID int64) ([]model.Tag, error) {
+	query := `
+		SELECT t.id, t.user_id, t.name, t.color, t.created_at, COUNT(l.id)
+		FROM tags t
+		LEFT JOIN link_tags lt ON lt.tag_id = t.id
+		LEFT JOIN links l ON l.id = lt.link_id AND l.deleted_at IS NULL
+		WHERE t.user_id = ?
+		GROUP BY t.id
+		ORDER BY t.name, t.id
+	`
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tags []model.Tag
+	for rows.Next() {
+		var t model.Tag
+		if err := rows.Scan(&t.ID, &t.UserID, &t.Name, &t.Color, &t.CreatedAt, &t.Links); err != nil {
+			return nil, err
+		}
+		tags = append(tags, t)
+	}
+	return tags, rows.Err()
+}
+
+// Update renames or recolors a user's tag
+func (r *TagRepository) Update(ctx context.Context, t *model.Tag) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE tags SET name = ?, color = ? WHERE id = ? AND user_id = ?`,
+		t.Name, t.Color, t.ID, t.UserID,
+	)
+	return err
+}
+
+// Delete removes a user's tag from all links and deletes it
+func (r *TagRepository) Delete(ctx context.Context, id, userID int64) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM tags WHERE id = ? AND user_id = ?`, id, userID)
+	return err
+}
+
+// TagIDsByLink maps each of a user's tagged links to the IDs of its tags
+func (r *TagRepository) TagIDsByLink(ctx context.Context, userID int64) (map[int64][]int64, error) {
+	query := `
+		SELECT lt.link_id, lt.tag_id
+		FROM link_tags lt
+		JOIN tags t ON t.id = lt.tag_id
+		WHERE t.user_id = ?
+		ORDER BY lt.link_id, t.name
+	`
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tagged := make(map[int64][]int64)
+	for rows.Next() {
+		var linkID, tagID int64
+		if err := rows.Scan(&linkID, &tagID); err != nil {
+			return nil, err
+		}
+		tagged[linkID] = append(tagged[linkID], tagID)
+	}
+	return tagged, rows.Err()
+}
+
+// SetLinkTags replaces the tags on one of a user's links. IDs of tags the
+// user doesn't own are ignored.
+func (r *TagRepository) SetLinkTags(ctx context.Context, userID, linkID int64, tagIDs []int64) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM link_tags WHERE link_id = ?`, linkID); err != nil {
+		return err
+	}
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT OR IGNORE INTO link_tags (link_id, tag_id)
+		SELECT ?, id FROM tags WHERE id = ? AND user_id = ?
+	`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, id := range tagIDs {
+		if _, err := stmt.ExecContext(ctx, linkID, id, userID); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
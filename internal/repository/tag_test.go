@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"linkbio/internal/model"
+	"linkbio/internal/testutil"
+)
+
+func TestTagRepository(t *testing.T) {
+	db := testutil.TestDB(t)
+	userRepo := NewUserRepository(db)
+	linkRepo := NewLinkRepository(db)
+	tagRepo := NewTagRepository(db)
+	ctx := context.Background()
+
+	user := createTestUser(t, userRepo, "tagger")
+	other := createTestUser(t, userRepo, "othertagger")
+
+	seasonal := &model.Tag{UserID: user.ID, Name: "Seasonal", Color: "orange"}
+	sponsored := &model.Tag{UserID: user.ID, Name: "sponsored", Color: "amber"}
+	theirs := &model.Tag{UserID: other.ID, Name: "sponsored", Color: "gray"}
+	for _, tag := range []*model.Tag{seasonal, sponsored, theirs} {
+		if err := tagRepo.Create(ctx, tag); err != nil {
+			t.Fatalf("Create(%s) error = %v", tag.Name, err)
+		}
+	}
+	// Names are unique per user, ignoring case
+	if err := tagRepo.Create(ctx, &model.Tag{UserID: user.ID, Name: "SPONSORED", Color: "red"}); err == nil {
+		t.Error("Create() allowed a duplicate name")
+	}
+
+	a := &model.Link{UserID: user.ID, Title: "A", URL: "https://a.example", IsActive: true}
+	b := &model.Link{UserID: user.ID, Title: "B", URL: "https://b.example", IsActive: true}
+	linkRepo.Create(ctx, a)
+	linkRepo.Create(ctx, b)
+
+	// Tags the user doesn't own are ignored
+	if err := tagRepo.SetLinkTags(ctx, user.ID, a.ID, []int64{sponsored.ID, seasonal.ID, theirs.ID}); err != nil {
+		t.Fatalf("SetLinkTags() error = %v", err)
+	}
+	if err := tagRepo.SetLinkTags(ctx, user.ID, b.ID, []int64{sponsored.ID}); err != nil {
+		t.Fatalf("SetLinkTags() error = %v", err)
+	}
+
+	tagged, err := tagRepo.TagIDsByLink(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("TagIDsByLink() error = %v", err)
+	}
+	if got := tagged[a.ID]; len(got) != 2 || got[0] != seasonal.ID || got[1] != sponsored.ID {
+		t.Errorf("link A tags = %v, want seasonal and sponsored", got)
+	}
+
+	tags, err := tagRepo.ListByUser(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("ListByUser() error = %v", err)
+	}
+	if len(tags) != 2 || tags[0].Name != "Seasonal" || tags[0].Links != 1 || tags[1].Name != "sponsored" || tags[1].Links != 2 {
+		t.Errorf("ListByUser() = %+v", tags)
+	}
+
+	// Replacing clears the old tags
+	tagRepo.SetLinkTags(ctx, user.ID, a.ID, nil)
+	tagged, _ = tagRepo.TagIDsByLink(ctx, user.ID)
+	if len(tagged[a.ID]) != 0 {
+		t.Errorf("link A tags = %v after clearing", tagged[a.ID])
+	}
+
+	sponsored.Name, sponsored.Color = "Partner", "green"
+	if err := tagRepo.Update(ctx, sponsored); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	found, _ := tagRepo.GetByID(ctx, sponsored.ID)
+	if found == nil || found.Name != "Partner" || found.Color != "green" {
+		t.Errorf("GetByID() = %+v after update", found)
+	}
+
+	// Deleting a tag keeps its links
+	if err := tagRepo.Delete(ctx, sponsored.ID, user.ID); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if found, _ := tagRepo.GetByID(ctx, sponsored.ID); found != nil {
+		t.Error("Delete() did not remove the tag")
+	}
+	tagged, _ = tagRepo.TagIDsByLink(ctx, user.ID)
+	if len(tagged) != 0 {
+		t.Errorf("TagIDsByLink() = %v after delete", tagged)
+	}
+	if found, _ := linkRepo.GetByID(ctx, b.ID); found == nil {
+		t.Error("Delete() removed a tagged link")
+	}
+
+	// Other users can't delete the tag
+	tagRepo.Delete(ctx, seasonal.ID, other.ID)
+	if found, _ := tagRepo.GetByID(ctx, seasonal.ID); found == nil {
+		t.Error("Delete() removed another user's tag")
+	}
+}
@@ -3,10 +3,57 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"time"
 
 	"linkbio/internal/model"
 )
 
+// userColumns is the column list shared by every user SELECT. It reads the
+// working copy of the drafted profile fields.
+const userColumns = `id, username, email, password_hash, display_name, bio, avatar_url, theme, theme_config, hide_from_search, profile_password_hash, locale, profile_locale, listed, category, created_at`
+
+// publishedUserColumns reads the published profile fields in the same order
+const publishedUserColumns = `id, username, email, password_hash, pub_display_name, pub_bio, avatar_url, pub_theme, pub_theme_config, hide_from_search, profile_password_hash, locale, profile_locale, listed, category, created_at`
+
+// scanUser reads one row selected with userColumns
+func scanUser(s rowScanner) (*model.User, error) {
+	user := &model.User{}
+	var hideFromSearch, listed int // SQLite stores bool as int
+	err := s.Scan(
+		&user.ID,
+		&user.Username,
+		&user.Email,
+		&user.PasswordHash,
+		&user.DisplayName,
+		&user.Bio,
+		&user.AvatarURL,
+		&user.Theme,
+		&user.ThemeConfig,
+		&hideFromSearch,
+		&user.ProfilePasswordHash,
+		&user.Locale,
+		&user.ProfileLocale,
+		&listed,
+		&user.Category,
+		&user.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	user.HideFromSearch = hideFromSearch == 1
+	user.Listed = listed == 1
+	return user, nil
+}
+
+// getUser runs a single-row user query, returning nil if nothing matched
+func (r *UserRepository) getUser(ctx context.Context, query string, args ...any) (*model.User, error) {
+	user, err := scanUser(r.db.QueryRowContext(ctx, query, args...))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return user, err
+}
+
 // UserRepository handles user database operations
 type UserRepository struct {
 	db *sql.DB
@@ -17,11 +64,12 @@ func NewUserRepository(db *sql.DB) *UserRepository {
 	return &UserRepository{db: db}
 }
 
-// Create inserts a new user
+// Create inserts a new user. The profile starts out published as given.
 func (r *UserRepository) Create(ctx context.Context, user *model.User) error {
 	query := `
-		INSERT INTO users (username, email, password_hash, display_name, bio, avatar_url, theme)
-		VALUES (?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO users (username, email, password_hash, display_name, bio, avatar_url, theme, theme_config, hide_from_search, profile_password_hash,
+			pub_display_name, pub_bio, pub_theme, pub_theme_config, published_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
 	`
 	result, err := r.db.ExecContext(ctx, query,
 		user.Username,
@@ -31,6 +79,13 @@ func (r *UserRepository) Create(ctx context.Context, user *model.User) error {
 		user.Bio,
 		user.AvatarURL,
 		user.Theme,
+		user.ThemeConfig,
+		user.HideFromSearch,
+		user.ProfilePasswordHash,
+		user.DisplayName,
+		user.Bio,
+		user.Theme,
+		user.ThemeConfig,
 	)
 	if err != nil {
 		return err
@@ -47,90 +102,64 @@ func (r *UserRepository) Create(ctx context.Context, user *model.User) error {
 
 // GetByID retrieves a user by ID
 func (r *UserRepository) GetByID(ctx context.Context, id int64) (*model.User, error) {
-	query := `
-		SELECT id, username, email, password_hash, display_name, bio, avatar_url, theme, created_at
-		FROM users WHERE id = ?
-	`
-	user := &model.User{}
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&user.ID,
-		&user.Username,
-		&user.Email,
-		&user.PasswordHash,
-		&user.DisplayName,
-		&user.Bio,
-		&user.AvatarURL,
-		&user.Theme,
-		&user.CreatedAt,
-	)
-	if err == sql.ErrNoRows {
-		return nil, nil
-	}
-	if err != nil {
-		return nil, err
-	}
-	return user, nil
+	return r.getUser(ctx, `SELECT `+userColumns+` FROM users WHERE id = ?`, id)
 }
 
-// GetByUsername retrieves a user by username
+// GetPublishedByID retrieves a user as visitors see them, with the
+// published profile fields in place of the draft
+func (r *UserRepository) GetPublishedByID(ctx context.Context, id int64) (*model.User, error) {
+	return r.getUser(ctx, `SELECT `+publishedUserColumns+` FROM users WHERE id = ?`, id)
+}
+
+// GetByUsername retrieves a user by username, ignoring case
 func (r *UserRepository) GetByUsername(ctx context.Context, username string) (*model.User, error) {
-	query := `
-		SELECT id, username, email, password_hash, display_name, bio, avatar_url, theme, created_at
-		FROM users WHERE username = ?
-	`
-	user := &model.User{}
-	err := r.db.QueryRowContext(ctx, query, username).Scan(
-		&user.ID,
-		&user.Username,
-		&user.Email,
-		&user.PasswordHash,
-		&user.DisplayName,
-		&user.Bio,
-		&user.AvatarURL,
-		&user.Theme,
-		&user.CreatedAt,
-	)
-	if err == sql.ErrNoRows {
-		return nil, nil
-	}
-	if err != nil {
-		return nil, err
-	}
-	return user, nil
+	return r.getUser(ctx, `SELECT `+userColumns+` FROM users WHERE username = ? COLLATE NOCASE`, username)
+}
+
+// GetPublishedByUsername retrieves a user as visitors see them, with the
+// published profile fields in place of the draft, ignoring case
+func (r *UserRepository) GetPublishedByUsername(ctx context.Context, username string) (*model.User, error) {
+	return r.getUser(ctx, `SELECT `+publishedUserColumns+` FROM users WHERE username = ? COLLATE NOCASE`, username)
 }
 
 // GetByEmail retrieves a user by email
 func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*model.User, error) {
-	query := `
-		SELECT id, username, email, password_hash, display_name, bio, avatar_url, theme, created_at
-		FROM users WHERE email = ?
-	`
-	user := &model.User{}
-	err := r.db.QueryRowContext(ctx, query, email).Scan(
-		&user.ID,
-		&user.Username,
-		&user.Email,
-		&user.PasswordHash,
-		&user.DisplayName,
-		&user.Bio,
-		&user.AvatarURL,
-		&user.Theme,
-		&user.CreatedAt,
-	)
-	if err == sql.ErrNoRows {
-		return nil, nil
-	}
+	return r.getUser(ctx, `SELECT `+userColumns+` FROM users WHERE email = ?`, email)
+}
+
+// ListUsernames returns the usernames of accounts that do, or don't, hide
+// their profile from search engines, alphabetically
+func (r *UserRepository) ListUsernames(ctx context.Context, hiddenFromSearch bool) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT username FROM users
+		WHERE hide_from_search = ?
+		ORDER BY username
+	`, hiddenFromSearch)
 	if err != nil {
 		return nil, err
 	}
-	return user, nil
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
 }
 
-// Update updates a user
+// Update updates a user. Display name, bio and theme change the draft;
+// everything else applies immediately, as the draft bar tells creators. For
+// visibility that is deliberate: hiding or locking a profile must not wait
+// for a publish.
 func (r *UserRepository) Update(ctx context.Context, user *model.User) error {
 	query := `
 		UPDATE users 
-		SET display_name = ?, bio = ?, avatar_url = ?, theme = ?
+		SET display_name = ?, bio = ?, avatar_url = ?, theme = ?, theme_config = ?, hide_from_search = ?,
+			profile_password_hash = ?, locale = ?, profile_locale = ?, listed = ?, category = ?
 		WHERE id = ?
 	`
 	_, err := r.db.ExecContext(ctx, query,
@@ -138,7 +167,101 @@ func (r *UserRepository) Update(ctx context.Context, user *model.User) error {
 		user.Bio,
 		user.AvatarURL,
 		user.Theme,
+		user.ThemeConfig,
+		user.HideFromSearch,
+		user.ProfilePasswordHash,
+		user.Locale,
+		user.ProfileLocale,
+		user.Listed,
+		user.Category,
 		user.ID,
 	)
 	return err
 }
+
+// ChangeUsername renames a user and records the old handle in one
+// transaction. It fails if the new name belongs to another account.
+func (r *UserRepository) ChangeUsername(ctx context.Context, userID int64, newUsername string, at time.Time) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var old string
+	if err := tx.QueryRowContext(ctx, `SELECT username FROM users WHERE id = ?`, userID).Scan(&old); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO username_history (user_id, username, changed_at) VALUES (?, ?, ?)`,
+		userID, old, at.UTC(),
+	); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE users SET username = ? WHERE id = ?`, newUsername, userID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetByPreviousUsername finds the account that most recently gave up a
+// username, or nil if nobody has
+func (r *UserRepository) GetByPreviousUsername(ctx context.Context, username string) (*model.User, error) {
+	var userID int64
+	err := r.db.QueryRowContext(ctx, `
+		SELECT user_id FROM username_history
+		WHERE username = ? COLLATE NOCASE
+		ORDER BY changed_at DESC, id DESC
+		LIMIT 1
+	`, username).Scan(&userID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return r.GetByID(ctx, userID)
+}
+
+// UsernameHeldBy returns the ID of the account holding a released username,
+// that is one that gave it up at or after since. It returns 0 when the name
+// is free.
+func (r *UserRepository) UsernameHeldBy(ctx context.Context, username string, since time.Time) (int64, error) {
+	var userID int64
+	err := r.db.QueryRowContext(ctx, `
+		SELECT user_id FROM username_history
+		WHERE username = ? COLLATE NOCASE AND changed_at >= ?
+		ORDER BY changed_at DESC, id DESC
+		LIMIT 1
+	`, username, since.UTC()).Scan(&userID)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return userID, err
+}
+
+// UsernameChanges lists when a user changed username at or after since,
+// oldest first
+func (r *UserRepository) UsernameChanges(ctx context.Context, userID int64, since time.Time) ([]time.Time, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT changed_at FROM username_history
+		WHERE user_id = ? AND changed_at >= ?
+		ORDER BY changed_at
+	`, userID, since.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var changes []time.Time
+	for rows.Next() {
+		var t time.Time
+		if err := rows.Scan(&t); err != nil {
+			return nil, err
+		}
+		changes = append(changes, t)
+	}
+	return changes, rows.Err()
+}
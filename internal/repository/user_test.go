@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"testing"
+	"time"
 
 	"linkbio/internal/model"
 	"linkbio/internal/testutil"
@@ -130,9 +131,9 @@ func TestUserRepository_GetByUsername(t *testing.T) {
 			wantFound: false,
 		},
 		{
-			name:      "case sensitive",
+			name:      "case insensitive",
 			username:  "FINDBYNAME",
-			wantFound: false, // SQLite is case-sensitive by default
+			wantFound: true, // usernames differing only by case are the same name
 		},
 	}
 
@@ -216,3 +217,77 @@ func TestUserRepository_DuplicateEmail(t *testing.T) {
 		t.Error("Create() should fail for duplicate email")
 	}
 }
+
+func TestUserRepository_ChangeUsername(t *testing.T) {
+	db := testutil.TestDB(t)
+	repo := NewUserRepository(db)
+	ctx := context.Background()
+
+	user := createTestUser(t, repo, "oldname")
+	other := createTestUser(t, repo, "taken")
+	start := time.Now().Add(-time.Hour)
+
+	if err := repo.ChangeUsername(ctx, user.ID, "newname", time.Now()); err != nil {
+		t.Fatalf("ChangeUsername() error = %v", err)
+	}
+	if got, _ := repo.GetByUsername(ctx, "newname"); got == nil || got.ID != user.ID {
+		t.Error("user is not reachable under the new name")
+	}
+
+	prev, err := repo.GetByPreviousUsername(ctx, "oldname")
+	if err != nil || prev == nil || prev.Username != "newname" {
+		t.Errorf("GetByPreviousUsername() = %+v, %v", prev, err)
+	}
+	if prev, _ := repo.GetByPreviousUsername(ctx, "never"); prev != nil {
+		t.Error("GetByPreviousUsername() found a name nobody used")
+	}
+
+	if held, _ := repo.UsernameHeldBy(ctx, "oldname", start); held != user.ID {
+		t.Errorf("UsernameHeldBy(recent) = %d, want %d", held, user.ID)
+	}
+	if held, _ := repo.UsernameHeldBy(ctx, "oldname", time.Now().Add(time.Hour)); held != 0 {
+		t.Errorf("UsernameHeldBy(after hold) = %d, want 0", held)
+	}
+
+	changes, _ := repo.UsernameChanges(ctx, user.ID, start)
+	if len(changes) != 1 {
+		t.Errorf("UsernameChanges() = %v, want one change", changes)
+	}
+
+	// A name in use cannot be taken, and nothing is recorded
+	if err := repo.ChangeUsername(ctx, user.ID, other.Username, time.Now()); err == nil {
+		t.Error("ChangeUsername() took another user's name")
+	}
+	if changes, _ := repo.UsernameChanges(ctx, user.ID, start); len(changes) != 1 {
+		t.Errorf("failed change was recorded: %v", changes)
+	}
+}
+
+func TestUserRepository_ListUsernames(t *testing.T) {
+	db := testutil.TestDB(t)
+	repo := NewUserRepository(db)
+	ctx := context.Background()
+
+	for _, u := range []*model.User{
+		{Username: "zed", Email: "zed@test.com", PasswordHash: "hash"},
+		{Username: "amy", Email: "amy@test.com", PasswordHash: "hash"},
+		{Username: "shy", Email: "shy@test.com", PasswordHash: "hash", HideFromSearch: true},
+	} {
+		if err := repo.Create(ctx, u); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+
+	visible, err := repo.ListUsernames(ctx, false)
+	if err != nil {
+		t.Fatalf("ListUsernames() error = %v", err)
+	}
+	if len(visible) != 2 || visible[0] != "amy" || visible[1] != "zed" {
+		t.Errorf("visible = %v, want [amy zed]", visible)
+	}
+
+	hidden, _ := repo.ListUsernames(ctx, true)
+	if len(hidden) != 1 || hidden[0] != "shy" {
+		t.Errorf("hidden = %v, want [shy]", hidden)
+	}
+}
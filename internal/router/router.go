@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"linkbio/internal/handler"
+	"linkbio/internal/i18n"
 	"linkbio/internal/middleware"
 	"linkbio/internal/pkg/templates"
 
@@ -15,8 +16,12 @@ func New(h *handler.Handler, mw *middleware.Middleware) *chi.Mux {
 	r := chi.NewRouter()
 
 	// Global middleware chain
-	r.Use(mw.Recovery)   // Recover from panics
-	r.Use(mw.Logger)     // Log all requests
+	r.Use(mw.Recovery) // Recover from panics
+	r.Use(mw.Logger)   // Log all requests
+	r.Use(mw.Locale)   // Pick the interface language
+
+	// Verified custom domains serve one profile; unknown hosts are rejected
+	r.Use(h.Domain.Route(customDomainRouter(h)))
 
 	// Health check (no auth required)
 	r.Get("/health", h.Health.Check)
@@ -25,11 +30,30 @@ func New(h *handler.Handler, mw *middleware.Middleware) *chi.Mux {
 	fileServer := http.FileServer(http.Dir("web/static"))
 	r.Handle("/static/*", http.StripPrefix("/static/", fileServer))
 
+	// Uploaded media (content-hashed names, cached forever)
+	r.Get("/media/*", h.Media.Serve)
+
+	// Crawler files
+	r.Get("/robots.txt", h.SEO.Robots)
+	r.Get("/sitemap.xml", h.SEO.Sitemap)
+
 	// Public routes
 	r.Group(func(r chi.Router) {
 		r.Get("/", handleHome)
+		r.Get("/explore", h.Explore.Page)
+		r.Get("/explore/search", h.Explore.Search)
 		r.Get("/u/{username}", h.Profile.Show)
+		r.Post("/u/{username}/unlock", h.Profile.Unlock)
+		r.Get("/u/{username}/qr.{format:png|svg}", h.QR.Profile)
+		r.Get("/u/{username}/og.png", h.OG.Profile)
+		r.Get("/u/{username}/feed.xml", h.Feed.Atom)
+		r.Get("/u/{username}/feed.json", h.Feed.JSON)
+		r.Get("/u/{username}/{page}", h.Profile.Show)
 		r.Get("/click/{id}", h.Link.Click)
+		r.Post("/click/{id}", h.Link.Confirm)
+		r.Get("/click/social/{id}", h.Social.Click)
+		r.Get("/embed/{username}", h.Embed.Show)
+		r.Get("/oembed", h.Embed.OEmbed)
 	})
 
 	// Auth namespace
@@ -38,6 +62,7 @@ func New(h *handler.Handler, mw *middleware.Middleware) *chi.Mux {
 		r.Post("/login", h.Auth.Login)
 		r.Get("/register", h.Auth.RegisterPage)
 		r.Post("/register", h.Auth.Register)
+		r.Get("/username", h.Auth.CheckUsername)
 		r.Post("/logout", h.Auth.Logout)
 	})
 
@@ -50,9 +75,46 @@ func New(h *handler.Handler, mw *middleware.Middleware) *chi.Mux {
 			r.Put("/{id}", h.Link.Update)
 			r.Delete("/{id}", h.Link.Delete)
 			r.Post("/reorder", h.Link.Reorder)
+			r.Get("/{id}/qr.{format:png|svg}", h.QR.Link)
+			r.Get("/{id}/download", h.Link.Download)
+			r.Put("/{id}/tags", h.Link.SetTags)
+			r.Post("/{id}/icon", h.Media.UploadIcon)
+		})
+
+		r.Post("/avatar", h.Media.UploadAvatar)
+		r.Put("/profile", h.Settings.Update)
+		r.Put("/profile/username", h.Settings.ChangeUsername)
+		r.Put("/profile/language", h.Settings.UpdateLanguage)
+		r.Put("/profile/directory", h.Settings.UpdateDirectory)
+
+		r.Route("/domains", func(r chi.Router) {
+			r.Post("/", h.Domain.Add)
+			r.Post("/{id}/verify", h.Domain.Verify)
+			r.Delete("/{id}", h.Domain.Delete)
 		})
 
+		r.Route("/socials", func(r chi.Router) {
+			r.Post("/", h.Social.Save)
+			r.Delete("/{id}", h.Social.Delete)
+			r.Post("/reorder", h.Social.Reorder)
+		})
 
+		r.Route("/pages", func(r chi.Router) {
+			r.Post("/", h.Page.Create)
+			r.Delete("/{id}", h.Page.Delete)
+			r.Post("/reorder", h.Page.Reorder)
+		})
+
+		r.Route("/tags", func(r chi.Router) {
+			r.Post("/", h.Tag.Create)
+			r.Put("/{id}", h.Tag.Update)
+			r.Delete("/{id}", h.Tag.Delete)
+			r.Post("/{id}/show", h.Tag.ShowLinks)
+			r.Post("/{id}/hide", h.Tag.HideLinks)
+		})
+
+		r.Post("/publish", h.Draft.Publish)
+		r.Post("/publish/revert", h.Draft.Revert)
 	})
 
 	// Dashboard namespace (protected)
@@ -60,14 +122,43 @@ func New(h *handler.Handler, mw *middleware.Middleware) *chi.Mux {
 		r.Use(mw.Auth)
 		r.Get("/", h.Dashboard.Index)
 		r.Get("/stats", h.Dashboard.Stats)
+		r.Get("/links", h.Dashboard.Links)
+		r.Get("/settings", h.Settings.Page)
+		r.Get("/draft", h.Draft.Bar)
+		r.Get("/preview", h.Profile.Preview)
 	})
 
 	return r
 }
 
+// customDomainRouter serves a creator's profile on their own domain. The
+// domain middleware supplies the username parameter.
+func customDomainRouter(h *handler.Handler) http.Handler {
+	r := chi.NewRouter()
+
+	fileServer := http.FileServer(http.Dir("web/static"))
+	r.Handle("/static/*", http.StripPrefix("/static/", fileServer))
+	r.Get("/media/*", h.Media.Serve)
+	r.Get("/robots.txt", h.SEO.Robots)
+	r.Get("/sitemap.xml", h.SEO.Sitemap)
+
+	r.Get("/", h.Profile.Show)
+	r.Post("/unlock", h.Profile.Unlock)
+	r.Get("/qr.{format:png|svg}", h.QR.Profile)
+	r.Get("/og.png", h.OG.Profile)
+	r.Get("/feed.xml", h.Feed.Atom)
+	r.Get("/feed.json", h.Feed.JSON)
+	r.Get("/click/{id}", h.Link.Click)
+	r.Post("/click/{id}", h.Link.Confirm)
+	r.Get("/click/social/{id}", h.Social.Click)
+	r.Get("/{page}", h.Profile.Show)
+
+	return r
+}
+
 // handleHome renders the landing page
 func handleHome(w http.ResponseWriter, r *http.Request) {
-	if err := templates.Render(w, "home.html", nil); err != nil {
+	if err := templates.Render(w, i18n.FromContext(r.Context()), "home.html", nil); err != nil {
 		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 	}
 }
@@ -0,0 +1,160 @@
+// Package seo builds the metadata search engines and social networks read
+// from a profile: Open Graph and Twitter Card tags, JSON-LD structured data,
+// the sitemap and robots.txt.
+package seo
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+
+	"linkbio/internal/model"
+	"linkbio/internal/pkg/ogimage"
+)
+
+// SiteName is used for og:site_name and in generated descriptions
+const SiteName = "LinkBio"
+
+// Meta describes a profile page for the <head>
+type Meta struct {
+	Title       string
+	Description string
+	URL         string // canonical URL
+	Image       string // absolute, or empty
+	ImageWidth  int
+	ImageHeight int
+	ImageAlt    string
+	TwitterCard string
+	NoIndex     bool
+	JSONLD      map[string]any // rendered by html/template as JSON
+}
+
+// Profile builds the metadata for a profile page from the user and the
+// links shown on it. canonical is the profile's preferred absolute URL and
+// image the absolute URL of its ogimage share card.
+func Profile(user *model.User, links []model.Link, canonical, image string) Meta {
+	name := user.DisplayName
+	if name == "" {
+		name = user.Username
+	}
+
+	description := user.Bio
+	if description == "" {
+		description = fmt.Sprintf("Links from %s (@%s) on %s", name, user.Username, SiteName)
+	}
+
+	m := Meta{
+		Title:       fmt.Sprintf("%s (@%s)", name, user.Username),
+		Description: description,
+		URL:         canonical,
+		Image:       image,
+		ImageWidth:  ogimage.Width,
+		ImageHeight: ogimage.Height,
+		ImageAlt:    fmt.Sprintf("%s on %s", name, SiteName),
+		TwitterCard: "summary_large_image",
+		NoIndex:     user.HideFromSearch,
+	}
+
+	person := map[string]any{
+		"@type":         "Person",
+		"name":          name,
+		"alternateName": "@" + user.Username,
+		"url":           canonical,
+	}
+	if user.Bio != "" {
+		person["description"] = user.Bio
+	}
+	if user.AvatarURL != "" {
+		person["image"] = absolute(canonical, user.AvatarURL)
+	}
+
+	m.JSONLD = map[string]any{
+		"@context":   "https://schema.org",
+		"@type":      "ProfilePage",
+		"url":        canonical,
+		"name":       m.Title,
+		"mainEntity": person,
+	}
+	if !user.CreatedAt.IsZero() {
+		m.JSONLD["dateCreated"] = user.CreatedAt.UTC().Format(time.RFC3339)
+	}
+	if related := linkURLs(links); len(related) > 0 {
+		m.JSONLD["relatedLink"] = related
+	}
+	return m
+}
+
+// linkURLs lists the http(s) destinations of links
+func linkURLs(links []model.Link) []string {
+	var urls []string
+	for _, l := range links {
+		if u, err := url.Parse(l.URL); err == nil && (u.Scheme == "http" || u.Scheme == "https") {
+			urls = append(urls, l.URL)
+		}
+	}
+	return urls
+}
+
+// absolute resolves ref against base. Unparseable refs are returned as-is.
+func absolute(base, ref string) string {
+	b, err := url.Parse(base)
+	if err != nil {
+		return ref
+	}
+	r, err := url.Parse(ref)
+	if err != nil {
+		return ref
+	}
+	return b.ResolveReference(r).String()
+}
+
+// sitemapURLSet is the <urlset> document defined by sitemaps.org
+type sitemapURLSet struct {
+	XMLName xml.Name     `xml:"urlset"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+type sitemapURL struct {
+	Loc string `xml:"loc"`
+}
+
+// WriteSitemap writes a sitemap listing locs
+func WriteSitemap(w io.Writer, locs []string) error {
+	set := sitemapURLSet{XMLNS: "http://www.sitemaps.org/schemas/sitemap/0.9"}
+	for _, loc := range locs {
+		set.URLs = append(set.URLs, sitemapURL{Loc: loc})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	return enc.Encode(set)
+}
+
+// Robots describes a robots.txt file for every crawler
+type Robots struct {
+	Disallow []string
+	Sitemap  string
+}
+
+// String renders the file
+func (r Robots) String() string {
+	var b strings.Builder
+	b.WriteString("User-agent: *\n")
+	if len(r.Disallow) == 0 {
+		b.WriteString("Disallow:\n")
+	}
+	for _, path := range r.Disallow {
+		b.WriteString("Disallow: " + path + "\n")
+	}
+	if r.Sitemap != "" {
+		b.WriteString("\nSitemap: " + r.Sitemap + "\n")
+	}
+	return b.String()
+}
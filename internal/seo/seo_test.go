@@ -0,0 +1,98 @@
+package seo
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	"linkbio/internal/model"
+)
+
+func TestProfile(t *testing.T) {
+	user := &model.User{
+		Username:    "alice",
+		DisplayName: "Alice Example",
+		Bio:         "Designer & maker",
+		AvatarURL:   "/media/ab/cdef.webp",
+		CreatedAt:   time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+	}
+	links := []model.Link{
+		{URL: "https://alice.example"},
+		{URL: "mailto:alice@example.com"},
+	}
+
+	m := Profile(user, links, "https://linkbio.test/u/alice", "https://linkbio.test/u/alice/og.png?v=1")
+
+	if m.Title != "Alice Example (@alice)" || m.Description != "Designer & maker" {
+		t.Errorf("Title/Description = %q / %q", m.Title, m.Description)
+	}
+	if m.Image != "https://linkbio.test/u/alice/og.png?v=1" || m.TwitterCard != "summary_large_image" {
+		t.Errorf("Image = %q, TwitterCard = %q", m.Image, m.TwitterCard)
+	}
+	if m.NoIndex {
+		t.Error("NoIndex set for a searchable profile")
+	}
+
+	if m.JSONLD["@type"] != "ProfilePage" || m.JSONLD["dateCreated"] != "2024-05-01T12:00:00Z" {
+		t.Errorf("JSONLD = %v", m.JSONLD)
+	}
+	person := m.JSONLD["mainEntity"].(map[string]any)
+	if person["@type"] != "Person" || person["alternateName"] != "@alice" || person["image"] != "https://linkbio.test/media/ab/cdef.webp" {
+		t.Errorf("Person = %v", person)
+	}
+	related := m.JSONLD["relatedLink"].([]string)
+	if len(related) != 1 || related[0] != "https://alice.example" {
+		t.Errorf("relatedLink = %v, want only web links", related)
+	}
+}
+
+func TestProfile_Defaults(t *testing.T) {
+	user := &model.User{Username: "bob", AvatarURL: "https://cdn.example/bob.png", HideFromSearch: true}
+
+	m := Profile(user, nil, "https://bob.example/", "https://linkbio.test/u/bob/og.png")
+
+	if m.Title != "bob (@bob)" || !strings.Contains(m.Description, "@bob") {
+		t.Errorf("Title/Description = %q / %q", m.Title, m.Description)
+	}
+	if person := m.JSONLD["mainEntity"].(map[string]any); person["image"] != "https://cdn.example/bob.png" {
+		t.Errorf("Person image = %v", person["image"])
+	}
+	if !m.NoIndex {
+		t.Error("NoIndex not set for a hidden profile")
+	}
+	if _, ok := m.JSONLD["relatedLink"]; ok {
+		t.Error("relatedLink present without links")
+	}
+}
+
+func TestWriteSitemap(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteSitemap(&buf, []string{"https://linkbio.test/u/a&b", "https://linkbio.test/u/c"}); err != nil {
+		t.Fatal(err)
+	}
+
+	var set sitemapURLSet
+	if err := xml.Unmarshal(buf.Bytes(), &set); err != nil {
+		t.Fatalf("sitemap is not valid XML: %v", err)
+	}
+	if len(set.URLs) != 2 || set.URLs[0].Loc != "https://linkbio.test/u/a&b" {
+		t.Errorf("URLs = %+v", set.URLs)
+	}
+	if set.XMLNS != "http://www.sitemaps.org/schemas/sitemap/0.9" {
+		t.Errorf("xmlns = %q", set.XMLNS)
+	}
+}
+
+func TestRobots(t *testing.T) {
+	got := Robots{Disallow: []string{"/api/", "/click/"}, Sitemap: "https://linkbio.test/sitemap.xml"}.String()
+	want := "User-agent: *\nDisallow: /api/\nDisallow: /click/\n\nSitemap: https://linkbio.test/sitemap.xml\n"
+	if got != want {
+		t.Errorf("Robots =\n%s\nwant\n%s", got, want)
+	}
+
+	if got := (Robots{}).String(); got != "User-agent: *\nDisallow:\n" {
+		t.Errorf("empty Robots = %q", got)
+	}
+}
@@ -4,20 +4,28 @@ import (
 	"context"
 	"log/slog"
 	"net/http"
+	"os"
 	"time"
 
 	"linkbio/internal/config"
 	"linkbio/internal/handler"
+	"linkbio/internal/i18n"
+	"linkbio/internal/linkcheck"
 	"linkbio/internal/middleware"
 	"linkbio/internal/pkg/response"
+	"linkbio/internal/preview"
 	"linkbio/internal/repository"
 	"linkbio/internal/router"
+	"linkbio/internal/storage"
 )
 
 // Server holds the HTTP server and dependencies
 type Server struct {
 	httpServer *http.Server
 	log        *slog.Logger
+	checker    *linkcheck.Checker // nil when disabled
+	jobs       context.Context
+	stopJobs   context.CancelFunc
 }
 
 // New creates a new Server instance
@@ -37,21 +45,51 @@ func New(cfg *config.Config, log *slog.Logger) (*Server, error) {
 	userRepo := repository.NewUserRepository(db)
 	linkRepo := repository.NewLinkRepository(db)
 	analyticsRepo := repository.NewAnalyticsRepository(db)
+	mediaRepo := repository.NewMediaRepository(db)
+	domainRepo := repository.NewDomainRepository(db)
+	socialRepo := repository.NewSocialRepository(db)
+	pageRepo := repository.NewPageRepository(db)
+	tagRepo := repository.NewTagRepository(db)
+	draftRepo := repository.NewDraftRepository(db)
+	directoryRepo := repository.NewDirectoryRepository(db)
+
+	// Initialize blob storage for uploaded media
+	blob, err := storage.Open(cfg.StorageOptions())
+	if err != nil {
+		return nil, err
+	}
+
+	// Load interface translations
+	locales, err := i18n.Load(os.DirFS("web/locales"))
+	if err != nil {
+		return nil, err
+	}
 
 	// Initialize responder
 	resp := response.New(log)
 
 	// Initialize middleware
-	mw := middleware.New(log, cfg.SessionSecret, cfg.SessionEncKey)
+	mw := middleware.New(log, cfg.SessionSecret, cfg.SessionEncKey, locales)
 
 	// Initialize handlers
 	h := handler.New(&handler.Dependencies{
+		Config:        cfg,
 		Log:           log,
 		Responder:     resp,
 		Store:         mw.Store(),
 		UserRepo:      userRepo,
 		LinkRepo:      linkRepo,
 		AnalyticsRepo: analyticsRepo,
+		MediaRepo:     mediaRepo,
+		DomainRepo:    domainRepo,
+		SocialRepo:    socialRepo,
+		PageRepo:      pageRepo,
+		TagRepo:       tagRepo,
+		DraftRepo:     draftRepo,
+		DirectoryRepo: directoryRepo,
+		Blob:          blob,
+		Previewer:     preview.New(preview.Options{}),
+		Locales:       locales,
 	})
 
 	// Initialize router
@@ -66,20 +104,40 @@ func New(cfg *config.Config, log *slog.Logger) (*Server, error) {
 		IdleTimeout:  60 * time.Second,
 	}
 
+	// Background link health checker
+	var checker *linkcheck.Checker
+	if cfg.LinkCheckInterval > 0 {
+		checker = linkcheck.New(linkRepo, log, linkcheck.Options{
+			Interval: cfg.LinkCheckInterval,
+		})
+	}
+
+	// Background jobs stop when the server shuts down. The context is made
+	// here so Start, which runs on its own goroutine, never writes to s.
+	jobs, stopJobs := context.WithCancel(context.Background())
+
 	return &Server{
 		httpServer: httpServer,
 		log:        log,
+		checker:    checker,
+		jobs:       jobs,
+		stopJobs:   stopJobs,
 	}, nil
 }
 
-// Start begins listening for requests
+// Start begins listening for requests and launches background jobs
 func (s *Server) Start() error {
+	if s.checker != nil {
+		go s.checker.Run(s.jobs)
+	}
+
 	s.log.Info("server starting", "addr", s.httpServer.Addr)
 	return s.httpServer.ListenAndServe()
 }
 
-// Shutdown gracefully stops the server
+// Shutdown gracefully stops the server and background jobs
 func (s *Server) Shutdown(ctx context.Context) error {
 	s.log.Info("server shutting down")
+	s.stopJobs()
 	return s.httpServer.Shutdown(ctx)
 }
@@ -0,0 +1,179 @@
+// Package social knows the platforms creators can list in the icon row under
+// their bio, and turns what they type into a profile address.
+//
+// Creators may enter a bare handle ("alice"), an @-handle ("@alice") or a
+// pasted profile URL ("https://www.instagram.com/alice/"); all three are
+// stored as the bare handle and the URL is rebuilt from it when needed.
+package social
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"strings"
+
+	"linkbio/internal/i18n"
+)
+
+// Platform IDs, as stored in social_profiles.platform
+const (
+	Instagram = "instagram"
+	TikTok    = "tiktok"
+	YouTube   = "youtube"
+	X         = "x"
+	GitHub    = "github"
+	Email     = "email"
+)
+
+// Errors returned by Normalize. Their text is shown to users as-is.
+var (
+	ErrUnknownPlatform = errors.New("Unknown platform")
+	ErrRequired        = errors.New("Enter a handle")
+	ErrEmail           = errors.New("That isn't a valid email address")
+)
+
+// Platform describes one supported network
+type Platform struct {
+	ID          string
+	Name        string
+	Placeholder string // example input for the settings form
+	Icon        string // SVG path data for a 24x24 stroked icon
+
+	format  string   // profile URL; %s is the escaped handle
+	hosts   []string // hosts a pasted profile URL may use
+	charset string   // characters a handle may contain besides a-z and 0-9
+	maxLen  int
+}
+
+// Platforms lists the supported networks in the order the settings form
+// offers them
+var Platforms = []Platform{
+	{
+		ID: Instagram, Name: "Instagram", Placeholder: "yourname",
+		Icon:   "M7 2h10a5 5 0 015 5v10a5 5 0 01-5 5H7a5 5 0 01-5-5V7a5 5 0 015-5zm5 5a5 5 0 100 10 5 5 0 000-10zm5.5-1.5h.01",
+		format: "https://www.instagram.com/%s/", hosts: []string{"instagram.com"},
+		charset: "._", maxLen: 30,
+	},
+	{
+		ID: TikTok, Name: "TikTok", Placeholder: "@yourname",
+		Icon:   "M16 3c.4 2.4 2 4 4.5 4.3M16 3v12.5a4.5 4.5 0 11-4.5-4.5",
+		format: "https://www.tiktok.com/@%s", hosts: []string{"tiktok.com"},
+		charset: "._", maxLen: 24,
+	},
+	{
+		ID: YouTube, Name: "YouTube", Placeholder: "@yourchannel",
+		Icon:   "M2.5 7.2a2.8 2.8 0 012-2C6.3 4.7 12 4.7 12 4.7s5.7 0 7.5.5a2.8 2.8 0 012 2c.5 1.8.5 4.8.5 4.8s0 3-.5 4.8a2.8 2.8 0 01-2 2c-1.8.5-7.5.5-7.5.5s-5.7 0-7.5-.5a2.8 2.8 0 01-2-2C2 15 2 12 2 12s0-3 .5-4.8zM10 15l5-3-5-3v6z",
+		format: "https://www.youtube.com/@%s", hosts: []string{"youtube.com"},
+		charset: "._-", maxLen: 30,
+	},
+	{
+		ID: X, Name: "X", Placeholder: "@yourname",
+		Icon:   "M4 4l11.7 16H20L8.3 4H4zm0 16l6.8-7.2M20 4l-6.8 7.2",
+		format: "https://x.com/%s", hosts: []string{"x.com", "twitter.com"},
+		charset: "_", maxLen: 15,
+	},
+	{
+		ID: GitHub, Name: "GitHub", Placeholder: "yourname",
+		Icon:   "M9 19c-5 1.5-5-2.5-7-3m14 6v-3.9a3.4 3.4 0 00-.9-2.6c3.1-.4 6.4-1.5 6.4-7a5.4 5.4 0 00-1.5-3.8 5.1 5.1 0 00-.1-3.7s-1.2-.4-3.9 1.4a13.4 13.4 0 00-7 0C6.3.6 5.1 1 5.1 1A5.1 5.1 0 005 4.8a5.4 5.4 0 00-1.5 3.8c0 5.4 3.3 6.6 6.4 7a3.4 3.4 0 00-.9 2.6V22",
+		format: "https://github.com/%s", hosts: []string{"github.com"},
+		charset: "-", maxLen: 39,
+	},
+	{
+		ID: Email, Name: "Email", Placeholder: "you@example.com",
+		Icon: "M3 8l7.9 5.3a2 2 0 002.2 0L21 8M5 19h14a2 2 0 002-2V7a2 2 0 00-2-2H5a2 2 0 00-2 2v10a2 2 0 002 2z",
+	},
+}
+
+// Lookup returns the platform with the given ID
+func Lookup(id string) (Platform, bool) {
+	for _, p := range Platforms {
+		if p.ID == id {
+			return p, true
+		}
+	}
+	return Platform{}, false
+}
+
+// URL is where the icon for handle points
+func (p Platform) URL(handle string) string {
+	if p.ID == Email {
+		return "mailto:" + handle
+	}
+	return fmt.Sprintf(p.format, url.PathEscape(handle))
+}
+
+// Normalize turns what a creator typed for a platform into the handle to
+// store
+func Normalize(platform, raw string) (string, error) {
+	p, ok := Lookup(platform)
+	if !ok {
+		return "", ErrUnknownPlatform
+	}
+
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", ErrRequired
+	}
+
+	if p.ID == Email {
+		raw = strings.TrimPrefix(raw, "mailto:")
+		addr, err := mail.ParseAddress(raw)
+		if err != nil || addr.Address != raw {
+			return "", ErrEmail
+		}
+		return raw, nil
+	}
+
+	handle := raw
+	if strings.Contains(raw, "/") {
+		handle = p.fromURL(raw)
+	}
+	handle = strings.TrimPrefix(handle, "@")
+
+	if !p.valid(handle) {
+		return "", i18n.Errorf("That isn't a valid %s handle", p.Name)
+	}
+	return handle, nil
+}
+
+// fromURL extracts the handle from a pasted profile URL, or returns "" when
+// the URL belongs to another site
+func (p Platform) fromURL(raw string) string {
+	if !strings.Contains(raw, "://") {
+		raw = "https://" + raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+
+	host := strings.ToLower(u.Hostname())
+	host = strings.TrimPrefix(host, "www.")
+	host = strings.TrimPrefix(host, "m.")
+	known := false
+	for _, h := range p.hosts {
+		known = known || host == h
+	}
+	if !known {
+		return ""
+	}
+
+	segment, _, _ := strings.Cut(strings.Trim(u.Path, "/"), "/")
+	return segment
+}
+
+// valid checks a bare handle against the platform's rules
+func (p Platform) valid(handle string) bool {
+	if handle == "" || len(handle) > p.maxLen {
+		return false
+	}
+	for _, r := range handle {
+		ok := r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune(p.charset, r)
+		if !ok {
+			return false
+		}
+	}
+	// GitHub names can't start or end with a hyphen
+	return p.ID != GitHub || (handle[0] != '-' && handle[len(handle)-1] != '-')
+}
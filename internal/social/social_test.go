@@ -0,0 +1,59 @@
+package social
+
+import "testing"
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		platform, raw string
+		want          string
+		wantErr       bool
+	}{
+		{Instagram, "alice.design", "alice.design", false},
+		{Instagram, "@alice_", "alice_", false},
+		{Instagram, "https://www.instagram.com/alice/", "alice", false},
+		{Instagram, "instagram.com/alice?igsh=abc", "alice", false},
+		{Instagram, "https://evil.example/alice", "", true},
+		{Instagram, "alice smith", "", true},
+		{TikTok, "https://www.tiktok.com/@alice.makes", "alice.makes", false},
+		{YouTube, "https://m.youtube.com/@Alice-Makes/videos", "Alice-Makes", false},
+		{X, "https://twitter.com/alice_x", "alice_x", false},
+		{X, "a_handle_that_is_too_long", "", true},
+		{GitHub, "octo-cat", "octo-cat", false},
+		{GitHub, "-octocat", "", true},
+		{Email, "mailto:alice@example.com", "alice@example.com", false},
+		{Email, "Alice <alice@example.com>", "", true},
+		{Email, "not an address", "", true},
+		{"myspace", "alice", "", true},
+		{GitHub, "   ", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := Normalize(tt.platform, tt.raw)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("Normalize(%q, %q) = %q, %v; want %q, error %v", tt.platform, tt.raw, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestPlatform_URL(t *testing.T) {
+	tests := []struct {
+		platform, handle, want string
+	}{
+		{Instagram, "alice", "https://www.instagram.com/alice/"},
+		{TikTok, "alice", "https://www.tiktok.com/@alice"},
+		{YouTube, "alice", "https://www.youtube.com/@alice"},
+		{X, "alice", "https://x.com/alice"},
+		{GitHub, "alice", "https://github.com/alice"},
+		{Email, "alice@example.com", "mailto:alice@example.com"},
+	}
+
+	for _, tt := range tests {
+		p, ok := Lookup(tt.platform)
+		if !ok {
+			t.Fatalf("Lookup(%q) failed", tt.platform)
+		}
+		if got := p.URL(tt.handle); got != tt.want {
+			t.Errorf("%s URL = %q, want %q", tt.platform, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package storage
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// ErrNotFound is returned when a key does not exist
+var ErrNotFound = errors.New("blob not found")
+
+// ErrInvalidKey is returned for keys that could escape the store's namespace
+var ErrInvalidKey = errors.New("invalid blob key")
+
+// Info describes a stored blob
+type Info struct {
+	Key         string
+	Size        int64
+	ContentType string
+	ModTime     time.Time
+}
+
+// Blob stores opaque objects under slash-separated keys. Keys built with Key
+// are content-addressed, so an object is never overwritten with different
+// bytes and may be cached forever.
+type Blob interface {
+	// Put stores data under key, replacing any existing object
+	Put(ctx context.Context, key string, r io.Reader, contentType string) error
+
+	// Get opens the object for reading. The caller must close it.
+	Get(ctx context.Context, key string) (io.ReadCloser, Info, error)
+
+	// Stat returns metadata without reading the object
+	Stat(ctx context.Context, key string) (Info, error)
+
+	// Delete removes the object. Deleting a missing key is not an error.
+	Delete(ctx context.Context, key string) error
+
+	// List returns every key that starts with prefix, in lexical order
+	List(ctx context.Context, prefix string) ([]string, error)
+}
+
+// Options configures Open
+type Options struct {
+	Backend string // "local" (default) or "s3"
+	Dir     string // local backend root
+	S3      S3Options
+}
+
+// Open builds the backend named in opts
+func Open(opts Options) (Blob, error) {
+	switch opts.Backend {
+	case "", "local":
+		return NewLocal(opts.Dir), nil
+	case "s3":
+		return NewS3(opts.S3)
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q", opts.Backend)
+	}
+}
+
+// Key returns a content-addressed key: prefix/sha256(data).ext
+func Key(prefix string, data []byte, ext string) string {
+	sum := sha256.Sum256(data)
+	return prefix + "/" + hex.EncodeToString(sum[:]) + ext
+}
+
+// keyPattern allows the characters Key produces plus a few safe extras
+var keyPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*(/[A-Za-z0-9][A-Za-z0-9._-]*)*$`)
+
+// ValidKey reports whether key is safe to hand to any backend. Segments may
+// not start with a dot, which rules out "..", hidden files and temp files.
+func ValidKey(key string) bool {
+	return len(key) <= 512 && keyPattern.MatchString(key) && !strings.Contains(key, "..")
+}
+
+// Migrate copies every object under prefix from src to dst and returns how
+// many were copied. Keys already present in dst are skipped, which is safe
+// because content-addressed keys never change meaning.
+func Migrate(ctx context.Context, src, dst Blob, prefix string) (int, error) {
+	keys, err := src.List(ctx, prefix)
+	if err != nil {
+		return 0, fmt.Errorf("list source: %w", err)
+	}
+
+	copied := 0
+	for _, key := range keys {
+		if _, err := dst.Stat(ctx, key); err == nil {
+			continue
+		} else if !errors.Is(err, ErrNotFound) {
+			return copied, fmt.Errorf("stat %s: %w", key, err)
+		}
+
+		if err := copyBlob(ctx, src, dst, key); err != nil {
+			return copied, fmt.Errorf("copy %s: %w", key, err)
+		}
+		copied++
+	}
+	return copied, nil
+}
+
+func copyBlob(ctx context.Context, src, dst Blob, key string) error {
+	rc, info, err := src.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	return dst.Put(ctx, key, rc, info.ContentType)
+}
@@ -0,0 +1,132 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestKey_ContentAddressed(t *testing.T) {
+	a := Key("avatars", []byte("hello"), ".jpg")
+	b := Key("avatars", []byte("hello"), ".jpg")
+	c := Key("avatars", []byte("world"), ".jpg")
+
+	if a != b {
+		t.Errorf("Key() not stable: %q != %q", a, b)
+	}
+	if a == c {
+		t.Error("Key() collided for different content")
+	}
+	want := "avatars/2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824.jpg"
+	if a != want {
+		t.Errorf("Key() = %q, want %q", a, want)
+	}
+	if !ValidKey(a) {
+		t.Errorf("ValidKey(%q) = false", a)
+	}
+}
+
+func TestValidKey(t *testing.T) {
+	for _, key := range []string{"", "/etc/passwd", "../secret", "a/../b", "a//b", "a/.hidden", "a/b/", `a\b`, "a b"} {
+		if ValidKey(key) {
+			t.Errorf("ValidKey(%q) = true, want false", key)
+		}
+	}
+}
+
+// exercise runs the same contract checks against any backend
+func exercise(t *testing.T, b Blob) {
+	t.Helper()
+	ctx := context.Background()
+
+	if err := b.Put(ctx, "avatars/one.jpg", strings.NewReader("first"), "image/jpeg"); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if err := b.Put(ctx, "avatars/two.jpg", strings.NewReader("second"), "image/jpeg"); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if err := b.Put(ctx, "og/three.png", strings.NewReader("third"), "image/png"); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	rc, info, err := b.Get(ctx, "avatars/one.jpg")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	data, _ := io.ReadAll(rc)
+	rc.Close()
+	if string(data) != "first" {
+		t.Errorf("Get() data = %q, want %q", data, "first")
+	}
+	if info.Size != 5 || info.ContentType != "image/jpeg" {
+		t.Errorf("Get() info = %+v", info)
+	}
+
+	keys, err := b.List(ctx, "avatars/")
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if strings.Join(keys, ",") != "avatars/one.jpg,avatars/two.jpg" {
+		t.Errorf("List() = %v", keys)
+	}
+
+	if err := b.Delete(ctx, "avatars/one.jpg"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := b.Stat(ctx, "avatars/one.jpg"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Stat() after delete error = %v, want ErrNotFound", err)
+	}
+	if _, _, err := b.Get(ctx, "avatars/one.jpg"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() after delete error = %v, want ErrNotFound", err)
+	}
+	if err := b.Delete(ctx, "avatars/one.jpg"); err != nil {
+		t.Errorf("Delete() of missing key error = %v, want nil", err)
+	}
+
+	if err := b.Put(ctx, "../escape", strings.NewReader("x"), ""); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("Put(../escape) error = %v, want ErrInvalidKey", err)
+	}
+}
+
+func TestLocal(t *testing.T) {
+	exercise(t, NewLocal(t.TempDir()))
+}
+
+func TestMigrate(t *testing.T) {
+	ctx := context.Background()
+	src := NewLocal(t.TempDir())
+	dst := newFakeS3(t)
+
+	for _, s := range []string{"a", "b", "c"} {
+		key := Key("avatars", []byte(s), ".jpg")
+		if err := src.Put(ctx, key, strings.NewReader(s), "image/jpeg"); err != nil {
+			t.Fatalf("Put() error = %v", err)
+		}
+	}
+
+	n, err := Migrate(ctx, src, dst, "")
+	if err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+	if n != 3 {
+		t.Errorf("Migrate() copied %d, want 3", n)
+	}
+
+	rc, info, err := dst.Get(ctx, Key("avatars", []byte("b"), ".jpg"))
+	if err != nil {
+		t.Fatalf("Get() from destination error = %v", err)
+	}
+	data, _ := io.ReadAll(rc)
+	rc.Close()
+	if string(data) != "b" || info.ContentType != "image/jpeg" {
+		t.Errorf("migrated blob = %q (%s)", data, info.ContentType)
+	}
+
+	// A second run finds everything already in place
+	n, err = Migrate(ctx, src, dst, "")
+	if err != nil || n != 0 {
+		t.Errorf("second Migrate() = %d, %v; want 0, nil", n, err)
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"database/sql"
 	"log/slog"
 	"os"
+	"path/filepath"
+	"runtime"
 	"testing"
 
 	_ "modernc.org/sqlite"
@@ -40,6 +42,18 @@ func TestDB(t *testing.T) *sql.DB {
 			bio TEXT DEFAULT '',
 			avatar_url TEXT DEFAULT '',
 			theme TEXT DEFAULT 'light',
+			theme_config TEXT NOT NULL DEFAULT '',
+			hide_from_search INTEGER NOT NULL DEFAULT 0,
+			profile_password_hash TEXT NOT NULL DEFAULT '',
+			locale TEXT NOT NULL DEFAULT '',
+			profile_locale TEXT NOT NULL DEFAULT '',
+			listed INTEGER NOT NULL DEFAULT 0,
+			category TEXT NOT NULL DEFAULT '',
+			pub_display_name TEXT NOT NULL DEFAULT '',
+			pub_bio TEXT NOT NULL DEFAULT '',
+			pub_theme TEXT NOT NULL DEFAULT '',
+			pub_theme_config TEXT NOT NULL DEFAULT '',
+			published_at DATETIME,
 			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
 		)`,
 		`CREATE TABLE IF NOT EXISTS links (
@@ -48,8 +62,37 @@ func TestDB(t *testing.T) *sql.DB {
 			title TEXT NOT NULL,
 			url TEXT NOT NULL,
 			icon TEXT DEFAULT '',
+			description TEXT NOT NULL DEFAULT '',
+			image_url TEXT NOT NULL DEFAULT '',
 			position INTEGER DEFAULT 0,
 			is_active INTEGER DEFAULT 1,
+			is_featured INTEGER NOT NULL DEFAULT 0,
+			is_sensitive INTEGER NOT NULL DEFAULT 0,
+			page_id INTEGER REFERENCES pages(id) ON DELETE SET NULL,
+			kind TEXT NOT NULL DEFAULT 'url',
+			starts_at DATETIME,
+			ends_at DATETIME,
+			location TEXT NOT NULL DEFAULT '',
+			time_zone TEXT NOT NULL DEFAULT '',
+			last_status INTEGER NOT NULL DEFAULT 0,
+			last_checked_at DATETIME,
+			pub_title TEXT NOT NULL DEFAULT '',
+			pub_url TEXT NOT NULL DEFAULT '',
+			pub_icon TEXT NOT NULL DEFAULT '',
+			pub_description TEXT NOT NULL DEFAULT '',
+			pub_image_url TEXT NOT NULL DEFAULT '',
+			pub_position INTEGER NOT NULL DEFAULT 0,
+			pub_is_active INTEGER NOT NULL DEFAULT 0,
+			pub_is_featured INTEGER NOT NULL DEFAULT 0,
+			pub_is_sensitive INTEGER NOT NULL DEFAULT 0,
+			pub_page_id INTEGER REFERENCES pages(id) ON DELETE SET NULL,
+			pub_kind TEXT NOT NULL DEFAULT 'url',
+			pub_starts_at DATETIME,
+			pub_ends_at DATETIME,
+			pub_location TEXT NOT NULL DEFAULT '',
+			pub_time_zone TEXT NOT NULL DEFAULT '',
+			published_at DATETIME,
+			deleted_at DATETIME,
 			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
 			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
 		)`,
@@ -58,14 +101,126 @@ func TestDB(t *testing.T) *sql.DB {
 			user_id INTEGER NOT NULL,
 			link_id INTEGER,
 			event_type TEXT NOT NULL,
+			source TEXT NOT NULL DEFAULT '',
+			platform TEXT NOT NULL DEFAULT '',
+			page_id INTEGER REFERENCES pages(id) ON DELETE SET NULL,
 			referrer TEXT DEFAULT '',
 			user_agent TEXT DEFAULT '',
 			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
 			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
 			FOREIGN KEY (link_id) REFERENCES links(id) ON DELETE CASCADE
 		)`,
+		`CREATE TABLE IF NOT EXISTS media (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id INTEGER NOT NULL,
+			link_id INTEGER,
+			kind TEXT NOT NULL,
+			key TEXT NOT NULL,
+			content_type TEXT NOT NULL DEFAULT '',
+			size INTEGER NOT NULL DEFAULT 0,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+			FOREIGN KEY (link_id) REFERENCES links(id) ON DELETE CASCADE
+		)`,
+		`CREATE TABLE IF NOT EXISTS custom_domains (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id INTEGER NOT NULL,
+			domain TEXT NOT NULL,
+			token TEXT NOT NULL,
+			verified_at DATETIME,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE (user_id, domain),
+			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+		)`,
+		`CREATE TABLE IF NOT EXISTS username_history (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id INTEGER NOT NULL,
+			username TEXT NOT NULL,
+			changed_at DATETIME NOT NULL,
+			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+		)`,
+		`CREATE TABLE IF NOT EXISTS social_profiles (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id INTEGER NOT NULL,
+			platform TEXT NOT NULL,
+			handle TEXT NOT NULL,
+			position INTEGER NOT NULL DEFAULT 0,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE (user_id, platform),
+			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+		)`,
+		`CREATE TABLE IF NOT EXISTS pages (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id INTEGER NOT NULL,
+			slug TEXT NOT NULL,
+			title TEXT NOT NULL,
+			position INTEGER NOT NULL DEFAULT 0,
+			pub_slug TEXT NOT NULL DEFAULT '',
+			pub_title TEXT NOT NULL DEFAULT '',
+			pub_position INTEGER NOT NULL DEFAULT 0,
+			deleted_at DATETIME,
+			published_at DATETIME,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE (user_id, slug),
+			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+		)`,
+		`CREATE TABLE IF NOT EXISTS tags (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id INTEGER NOT NULL,
+			name TEXT NOT NULL COLLATE NOCASE,
+			color TEXT NOT NULL DEFAULT 'gray',
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE (user_id, name),
+			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+		)`,
+		`CREATE TABLE IF NOT EXISTS link_tags (
+			link_id INTEGER NOT NULL,
+			tag_id INTEGER NOT NULL,
+			PRIMARY KEY (link_id, tag_id),
+			FOREIGN KEY (link_id) REFERENCES links(id) ON DELETE CASCADE,
+			FOREIGN KEY (tag_id) REFERENCES tags(id) ON DELETE CASCADE
+		)`,
 		`CREATE INDEX IF NOT EXISTS idx_links_user_id ON links(user_id)`,
 		`CREATE INDEX IF NOT EXISTS idx_analytics_user_id ON analytics(user_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_media_key ON media(key)`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_custom_domains_verified ON custom_domains(domain) WHERE verified_at IS NOT NULL`,
+		`CREATE INDEX IF NOT EXISTS idx_username_history_username ON username_history(username, changed_at)`,
+		`CREATE INDEX IF NOT EXISTS idx_username_history_user_id ON username_history(user_id, changed_at)`,
+		`CREATE INDEX IF NOT EXISTS idx_users_username_nocase ON users(username COLLATE NOCASE)`,
+		`CREATE INDEX IF NOT EXISTS idx_username_history_username_nocase ON username_history(username COLLATE NOCASE, changed_at)`,
+		`CREATE INDEX IF NOT EXISTS idx_link_tags_tag_id ON link_tags(tag_id)`,
+		`CREATE VIRTUAL TABLE IF NOT EXISTS profile_search USING fts5(
+			username, pub_display_name, pub_bio,
+			content='users', content_rowid='id', tokenize='unicode61 remove_diacritics 2'
+		)`,
+		`CREATE TRIGGER IF NOT EXISTS users_search_insert AFTER INSERT ON users BEGIN
+			INSERT INTO profile_search(rowid, username, pub_display_name, pub_bio)
+			VALUES (new.id, new.username, new.pub_display_name, new.pub_bio);
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS users_search_delete AFTER DELETE ON users BEGIN
+			INSERT INTO profile_search(profile_search, rowid, username, pub_display_name, pub_bio)
+			VALUES ('delete', old.id, old.username, old.pub_display_name, old.pub_bio);
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS users_search_update AFTER UPDATE OF username, pub_display_name, pub_bio ON users BEGIN
+			INSERT INTO profile_search(profile_search, rowid, username, pub_display_name, pub_bio)
+			VALUES ('delete', old.id, old.username, old.pub_display_name, old.pub_bio);
+			INSERT INTO profile_search(rowid, username, pub_display_name, pub_bio)
+			VALUES (new.id, new.username, new.pub_display_name, new.pub_bio);
+		END`,
+		`CREATE VIRTUAL TABLE IF NOT EXISTS link_search USING fts5(
+			title, url,
+			content='links', content_rowid='id', tokenize='unicode61 remove_diacritics 2'
+		)`,
+		`CREATE TRIGGER IF NOT EXISTS links_search_insert AFTER INSERT ON links BEGIN
+			INSERT INTO link_search(rowid, title, url) VALUES (new.id, new.title, new.url);
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS links_search_delete AFTER DELETE ON links BEGIN
+			INSERT INTO link_search(link_search, rowid, title, url) VALUES ('delete', old.id, old.title, old.url);
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS links_search_update AFTER UPDATE OF title, url ON links BEGIN
+			INSERT INTO link_search(link_search, rowid, title, url) VALUES ('delete', old.id, old.title, old.url);
+			INSERT INTO link_search(rowid, title, url) VALUES (new.id, new.title, new.url);
+		END`,
 	}
 
 	for _, migration := range migrations {
@@ -85,3 +240,23 @@ func TestDB(t *testing.T) *sql.DB {
 func TestLogger() *slog.Logger {
 	return slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
 }
+
+// ChdirRoot switches to the repository root for the duration of a test, so
+// handlers can load templates from web/templates
+func ChdirRoot(t *testing.T) {
+	t.Helper()
+
+	_, file, _, _ := runtime.Caller(0)
+	root := filepath.Join(filepath.Dir(file), "..", "..")
+
+	prev, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("failed to get working dir: %v", err)
+	}
+	if err := os.Chdir(root); err != nil {
+		t.Fatalf("failed to chdir to repo root: %v", err)
+	}
+	t.Cleanup(func() {
+		os.Chdir(prev)
+	})
+}
@@ -0,0 +1,63 @@
+package theme
+
+import (
+	"fmt"
+	"html/template"
+	"strings"
+)
+
+// Scope is the class of the element the compiled CSS applies to. Every
+// rule is prefixed with it so a theme cannot restyle the rest of the page.
+const Scope = "lb-theme"
+
+// CSS compiles the theme into a stylesheet scoped to Scope. The theme must
+// have passed Validate; all values come from normalized colors or the
+// fixed Fonts and Radii tables.
+//
+// Within the scope, templates use these classes:
+//
+//	.lb-muted   secondary text
+//	.lb-link    a link button
+//	.lb-avatar  the avatar frame
+func (t Theme) CSS() template.CSS {
+	var b strings.Builder
+	s := "." + Scope
+
+	bg := t.Background.From
+	if t.Background.Type == BackgroundGradient {
+		bg = fmt.Sprintf("linear-gradient(%ddeg, %s, %s)", t.Background.Angle, t.Background.From, t.Background.To)
+	}
+	scheme := "light"
+	if t.Dark() {
+		scheme = "dark"
+	}
+
+	fmt.Fprintf(&b, "%s{--lb-text:%s;--lb-muted:%s;--lb-radius:%s;background:%s;background-attachment:fixed;color:var(--lb-text);font-family:%s;color-scheme:%s}\n",
+		s, t.Text, t.Muted, Radii[t.Radius], bg, Fonts[t.Font], scheme)
+	fmt.Fprintf(&b, "%s .lb-muted{color:var(--lb-muted)}\n", s)
+	fmt.Fprintf(&b, "%s .lb-avatar{border-radius:var(--lb-radius)}\n", s)
+
+	btn := t.Button
+	var link string
+	switch btn.Style {
+	case ButtonOutline:
+		link = fmt.Sprintf("background:transparent;color:%s;border:2px solid %s", btn.Text, btn.Color)
+	case ButtonSoft:
+		link = fmt.Sprintf("background:%s;color:%s;border:1px solid %s", rgba(btn.Color, 0.18), btn.Text, rgba(btn.Color, 0.3))
+	case ButtonShadow:
+		link = fmt.Sprintf("background:%s;color:%s;border:2px solid %s;box-shadow:4px 4px 0 %s", btn.Color, btn.Text, t.Text, t.Text)
+	default:
+		link = fmt.Sprintf("background:%s;color:%s;border:1px solid %s", btn.Color, btn.Text, btn.Color)
+	}
+	fmt.Fprintf(&b, "%s .lb-link{%s;border-radius:var(--lb-radius);transition:transform .15s ease,filter .15s ease}\n", s, link)
+	fmt.Fprintf(&b, "%s .lb-link:hover{transform:translateY(-2px);filter:brightness(1.05)}\n", s)
+	fmt.Fprintf(&b, "%s .lb-link .lb-muted{color:inherit;opacity:.75}\n", s)
+
+	return template.CSS(b.String())
+}
+
+// rgba formats a normalized hex color with an alpha channel
+func rgba(hex string, alpha float64) string {
+	r, g, b := rgb(hex)
+	return fmt.Sprintf("rgba(%d,%d,%d,%.2f)", r, g, b, alpha)
+}
@@ -0,0 +1,95 @@
+package theme
+
+// Preset is a built-in theme
+type Preset struct {
+	Name  string
+	Label string
+	Theme Theme
+}
+
+// Presets are the built-in themes in display order. The first is the
+// default for new profiles and the fallback for unknown names.
+var Presets = []Preset{
+	{"light", "Light", Theme{
+		Background: Background{Type: BackgroundGradient, From: "#f9fafb", To: "#f3f4f6", Angle: 180},
+		Text:       "#111827",
+		Muted:      "#6b7280",
+		Button:     Button{Style: ButtonFill, Color: "#ffffff", Text: "#1f2937"},
+		Font:       "sans",
+		Radius:     "large",
+	}},
+	{"dark", "Dark", Theme{
+		Background: Background{Type: BackgroundGradient, From: "#111827", To: "#1f2937", Angle: 180},
+		Text:       "#ffffff",
+		Muted:      "#9ca3af",
+		Button:     Button{Style: ButtonFill, Color: "#374151", Text: "#ffffff"},
+		Font:       "sans",
+		Radius:     "large",
+	}},
+	{"ocean", "Ocean", Theme{
+		Background: Background{Type: BackgroundGradient, From: "#0ea5e9", To: "#1e3a8a", Angle: 160},
+		Text:       "#ffffff",
+		Muted:      "#bae6fd",
+		Button:     Button{Style: ButtonSoft, Color: "#ffffff", Text: "#ffffff"},
+		Font:       "rounded",
+		Radius:     "pill",
+	}},
+	{"sunset", "Sunset", Theme{
+		Background: Background{Type: BackgroundGradient, From: "#f97316", To: "#db2777", Angle: 135},
+		Text:       "#ffffff",
+		Muted:      "#ffedd5",
+		Button:     Button{Style: ButtonFill, Color: "#ffffff", Text: "#9d174d"},
+		Font:       "sans",
+		Radius:     "pill",
+	}},
+	{"forest", "Forest", Theme{
+		Background: Background{Type: BackgroundSolid, From: "#14532d"},
+		Text:       "#f0fdf4",
+		Muted:      "#86efac",
+		Button:     Button{Style: ButtonOutline, Color: "#bbf7d0", Text: "#bbf7d0"},
+		Font:       "serif",
+		Radius:     "medium",
+	}},
+	{"paper", "Paper", Theme{
+		Background: Background{Type: BackgroundSolid, From: "#fffbeb"},
+		Text:       "#1c1917",
+		Muted:      "#78716c",
+		Button:     Button{Style: ButtonShadow, Color: "#ffffff", Text: "#1c1917"},
+		Font:       "mono",
+		Radius:     "none",
+	}},
+}
+
+// Lookup returns the preset with the given name
+func Lookup(name string) (Theme, bool) {
+	for _, p := range Presets {
+		if p.Name == name {
+			return p.Theme, true
+		}
+	}
+	return Theme{}, false
+}
+
+// Valid reports whether name is a preset or Custom
+func Valid(name string) bool {
+	if name == Custom {
+		return true
+	}
+	_, ok := Lookup(name)
+	return ok
+}
+
+// Resolve picks the theme a profile renders with. A custom theme that no
+// longer parses falls back to the default preset rather than failing the
+// page.
+func Resolve(name, custom string) Theme {
+	if name == Custom {
+		if t, err := Parse([]byte(custom)); err == nil {
+			return *t
+		}
+	}
+	if t, ok := Lookup(name); ok {
+		return t
+	}
+	return Presets[0].Theme
+}
@@ -0,0 +1,212 @@
+// Package theme describes how a public profile looks and compiles that
+// description into CSS.
+//
+// A Theme is plain data: every field is either a color that must parse as
+// hex or a word from a fixed list. CSS is only ever produced from the
+// validated values, never copied from user input, so a stored theme cannot
+// smuggle declarations, url() calls or a closing </style> into the page.
+package theme
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Custom is the User.Theme value that selects the user's own theme
+const Custom = "custom"
+
+// MaxConfigSize bounds the stored JSON
+const MaxConfigSize = 4096
+
+// Background types
+const (
+	BackgroundSolid    = "solid"
+	BackgroundGradient = "gradient"
+)
+
+// Button styles
+const (
+	ButtonFill    = "fill"
+	ButtonOutline = "outline"
+	ButtonSoft    = "soft"
+	ButtonShadow  = "shadow"
+)
+
+// ButtonStyles lists the accepted Button.Style values
+var ButtonStyles = []string{ButtonFill, ButtonOutline, ButtonSoft, ButtonShadow}
+
+// Fonts maps the accepted Theme.Font values to system font stacks. Only
+// locally installed fonts are used so profiles never load third-party CSS.
+var Fonts = map[string]string{
+	"sans":    `ui-sans-serif, system-ui, -apple-system, "Segoe UI", Roboto, sans-serif`,
+	"serif":   `ui-serif, Georgia, Cambria, "Times New Roman", serif`,
+	"mono":    `ui-monospace, SFMono-Regular, Menlo, Consolas, monospace`,
+	"rounded": `ui-rounded, "SF Pro Rounded", "Arial Rounded MT Bold", system-ui, sans-serif`,
+}
+
+// FontNames lists the keys of Fonts in display order
+var FontNames = []string{"sans", "serif", "mono", "rounded"}
+
+// Radii maps the accepted Theme.Radius values to corner radii
+var Radii = map[string]string{
+	"none":   "0",
+	"small":  "6px",
+	"medium": "12px",
+	"large":  "20px",
+	"pill":   "9999px",
+}
+
+// RadiusNames lists the keys of Radii in display order
+var RadiusNames = []string{"none", "small", "medium", "large", "pill"}
+
+// Theme is a complete profile look
+type Theme struct {
+	Background Background `json:"background"`
+	Text       string     `json:"text"`
+	Muted      string     `json:"muted"`
+	Button     Button     `json:"button"`
+	Font       string     `json:"font"`
+	Radius     string     `json:"radius"`
+}
+
+// Background is a solid color or a linear gradient
+type Background struct {
+	Type  string `json:"type"`
+	From  string `json:"from"`
+	To    string `json:"to,omitempty"`
+	Angle int    `json:"angle,omitempty"`
+}
+
+// Button styles the link buttons
+type Button struct {
+	Style string `json:"style"`
+	Color string `json:"color"`
+	Text  string `json:"text"`
+}
+
+// Parse decodes and validates a stored theme
+func Parse(data []byte) (*Theme, error) {
+	if len(data) > MaxConfigSize {
+		return nil, errors.New("theme is too large")
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+
+	var t Theme
+	if err := dec.Decode(&t); err != nil {
+		return nil, errors.New("theme is not valid JSON")
+	}
+	if err := t.Validate(); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// JSON encodes the theme for storage
+func (t *Theme) JSON() string {
+	data, _ := json.Marshal(t)
+	return string(data)
+}
+
+// Validate checks every field and normalizes colors to lowercase #rrggbb.
+// Messages are suitable for showing to the user.
+func (t *Theme) Validate() error {
+	// One whole message per field, so each translates as a sentence
+	colors := []struct {
+		invalid string
+		value   *string
+	}{
+		{"Background color must be a hex color like #1f2937", &t.Background.From},
+		{"Text color must be a hex color like #1f2937", &t.Text},
+		{"Secondary text color must be a hex color like #1f2937", &t.Muted},
+		{"Button color must be a hex color like #1f2937", &t.Button.Color},
+		{"Button text color must be a hex color like #1f2937", &t.Button.Text},
+	}
+
+	switch t.Background.Type {
+	case BackgroundSolid:
+		t.Background.To = ""
+		t.Background.Angle = 0
+	case BackgroundGradient:
+		colors = append(colors, struct {
+			invalid string
+			value   *string
+		}{"Gradient end color must be a hex color like #1f2937", &t.Background.To})
+		if t.Background.Angle < 0 || t.Background.Angle > 359 {
+			return errors.New("Gradient angle must be between 0 and 359")
+		}
+	default:
+		return errors.New("Background must be solid or gradient")
+	}
+
+	for _, c := range colors {
+		hex, ok := normalizeHex(*c.value)
+		if !ok {
+			return errors.New(c.invalid)
+		}
+		*c.value = hex
+	}
+
+	if !contains(ButtonStyles, t.Button.Style) {
+		return errors.New("Choose one of the available button styles")
+	}
+	if _, ok := Fonts[t.Font]; !ok {
+		return errors.New("Choose one of the available fonts")
+	}
+	if _, ok := Radii[t.Radius]; !ok {
+		return errors.New("Choose one of the available corner styles")
+	}
+	return nil
+}
+
+// Dark reports whether the background is dark, so browser UI such as
+// scrollbars and form controls can follow
+func (t *Theme) Dark() bool {
+	return luminance(t.Background.From) < 0.4
+}
+
+// normalizeHex accepts #rgb or #rrggbb and returns lowercase #rrggbb
+func normalizeHex(s string) (string, bool) {
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(s, "#") {
+		return "", false
+	}
+	s = strings.ToLower(s[1:])
+	if len(s) == 3 {
+		s = string([]byte{s[0], s[0], s[1], s[1], s[2], s[2]})
+	}
+	if len(s) != 6 {
+		return "", false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return "", false
+		}
+	}
+	return "#" + s, true
+}
+
+// rgb splits a normalized #rrggbb color
+func rgb(hex string) (r, g, b int) {
+	fmt.Sscanf(hex, "#%02x%02x%02x", &r, &g, &b)
+	return r, g, b
+}
+
+// luminance approximates perceived brightness in [0, 1]
+func luminance(hex string) float64 {
+	r, g, b := rgb(hex)
+	return (0.299*float64(r) + 0.587*float64(g) + 0.114*float64(b)) / 255
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,197 @@
+package theme
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"linkbio/internal/i18n"
+)
+
+func validTheme() Theme {
+	return Theme{
+		Background: Background{Type: BackgroundGradient, From: "#FFF", To: "#0EA5E9", Angle: 90},
+		Text:       "#111827",
+		Muted:      "#6b7280",
+		Button:     Button{Style: ButtonOutline, Color: "#123456", Text: "#abcdef"},
+		Font:       "serif",
+		Radius:     "pill",
+	}
+}
+
+func TestPresetsAreValid(t *testing.T) {
+	for _, p := range Presets {
+		th := p.Theme
+		if err := th.Validate(); err != nil {
+			t.Errorf("preset %q: %v", p.Name, err)
+		}
+		if th != p.Theme {
+			t.Errorf("preset %q is not in normalized form", p.Name)
+		}
+	}
+}
+
+func TestValidate_NormalizesColors(t *testing.T) {
+	th := validTheme()
+	if err := th.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if th.Background.From != "#ffffff" || th.Background.To != "#0ea5e9" {
+		t.Errorf("background = %+v", th.Background)
+	}
+
+	solid := validTheme()
+	solid.Background.Type = BackgroundSolid
+	solid.Validate()
+	if solid.Background.To != "" || solid.Background.Angle != 0 {
+		t.Errorf("solid background kept gradient fields: %+v", solid.Background)
+	}
+}
+
+func TestValidate_RejectsInjection(t *testing.T) {
+	payloads := []string{
+		"red",
+		"#fff;background:url(//evil)",
+		"#ffffff}body{display:none",
+		"#fff</style><script>alert(1)</script>",
+		"#ggg",
+		"#12345",
+		"expression(alert(1))",
+		"",
+	}
+	fields := map[string]func(*Theme, string){
+		"from":        func(t *Theme, v string) { t.Background.From = v },
+		"to":          func(t *Theme, v string) { t.Background.To = v },
+		"text":        func(t *Theme, v string) { t.Text = v },
+		"muted":       func(t *Theme, v string) { t.Muted = v },
+		"button":      func(t *Theme, v string) { t.Button.Color = v },
+		"button text": func(t *Theme, v string) { t.Button.Text = v },
+	}
+	for name, set := range fields {
+		for _, p := range payloads {
+			th := validTheme()
+			set(&th, p)
+			if err := th.Validate(); err == nil {
+				t.Errorf("%s = %q was accepted", name, p)
+			}
+		}
+	}
+
+	enums := map[string]func(*Theme){
+		"font":       func(t *Theme) { t.Font = `x;}*{color:red` },
+		"radius":     func(t *Theme) { t.Radius = "12px" },
+		"style":      func(t *Theme) { t.Button.Style = "glow" },
+		"background": func(t *Theme) { t.Background.Type = "image" },
+		"angle":      func(t *Theme) { t.Background.Angle = 360 },
+	}
+	for name, set := range enums {
+		th := validTheme()
+		set(&th)
+		if err := th.Validate(); err == nil {
+			t.Errorf("invalid %s was accepted", name)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	th := validTheme()
+	th.Validate()
+
+	got, err := Parse([]byte(th.JSON()))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if *got != th {
+		t.Errorf("round trip = %+v, want %+v", *got, th)
+	}
+
+	bad := []string{
+		`not json`,
+		`{"background":{"type":"solid","from":"#fff"},"css":"body{}"}`,
+		`{"background":{"type":"solid","from":"#fff"}}`,
+		`{"text":"` + strings.Repeat("a", MaxConfigSize) + `"}`,
+	}
+	for _, b := range bad {
+		if _, err := Parse([]byte(b)); err == nil {
+			t.Errorf("Parse(%.40q) succeeded", b)
+		}
+	}
+}
+
+func TestResolve(t *testing.T) {
+	dark, _ := Lookup("dark")
+	if got := Resolve("dark", ""); got != dark {
+		t.Error("Resolve(dark) did not return the preset")
+	}
+	if got := Resolve("nope", ""); got != Presets[0].Theme {
+		t.Error("unknown name did not fall back to the default")
+	}
+	if got := Resolve(Custom, `{"broken"`); got != Presets[0].Theme {
+		t.Error("broken custom theme did not fall back to the default")
+	}
+
+	th := validTheme()
+	th.Validate()
+	if got := Resolve(Custom, th.JSON()); got != th {
+		t.Errorf("Resolve(custom) = %+v", got)
+	}
+}
+
+func TestCSS(t *testing.T) {
+	th := validTheme()
+	th.Validate()
+	css := string(th.CSS())
+
+	for _, want := range []string{
+		"linear-gradient(90deg, #ffffff, #0ea5e9)",
+		"border:2px solid #123456",
+		"--lb-radius:9999px",
+		"Georgia",
+		"color-scheme:light",
+	} {
+		if !strings.Contains(css, want) {
+			t.Errorf("CSS() missing %q:\n%s", want, css)
+		}
+	}
+
+	// Every rule is scoped
+	for _, line := range strings.Split(strings.TrimSpace(css), "\n") {
+		if !strings.HasPrefix(line, "."+Scope) {
+			t.Errorf("unscoped rule: %s", line)
+		}
+	}
+
+	dark, _ := Lookup("dark")
+	if !strings.Contains(string(dark.CSS()), "color-scheme:dark") {
+		t.Error("dark preset is not marked as a dark color scheme")
+	}
+}
+
+func TestValidate_ColorMessagesTranslated(t *testing.T) {
+	b, err := i18n.Load(os.DirFS("../../web/locales"))
+	if err != nil {
+		t.Fatalf("Load(web/locales) error = %v", err)
+	}
+	es := b.Localizer("es")
+
+	fields := []func(*Theme){
+		func(th *Theme) { th.Background.From = "red" },
+		func(th *Theme) { th.Background.To = "red" },
+		func(th *Theme) { th.Text = "red" },
+		func(th *Theme) { th.Muted = "red" },
+		func(th *Theme) { th.Button.Color = "red" },
+		func(th *Theme) { th.Button.Text = "red" },
+	}
+	for i, spoil := range fields {
+		th := validTheme()
+		spoil(&th)
+		err := th.Validate()
+		if err == nil {
+			t.Errorf("field %d: Validate() accepted a bad color", i)
+			continue
+		}
+		if got := es.Err(err); got == err.Error() || strings.Contains(got, "color must") {
+			t.Errorf("field %d: Spanish message = %q, want it fully translated", i, got)
+		}
+	}
+}
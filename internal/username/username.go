@@ -0,0 +1,165 @@
+// Package username decides which usernames accounts may claim.
+//
+// Usernames are lowercase ASCII letters, digits, hyphens and underscores so
+// that /u/{username} is always a clean path segment. Input is folded before
+// it is checked: fullwidth forms become ASCII and letters become lowercase,
+// so "ＡＬＩＣＥ" and "Alice" both mean "alice". Letters from other scripts
+// that only look Latin (Cyrillic "а", Greek "ο"...) are rejected with a
+// message that says so, rather than silently replaced.
+package username
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"linkbio/internal/i18n"
+)
+
+// Length limits, in characters
+const (
+	MinLength = 3
+	MaxLength = 30
+)
+
+// Errors returned by Normalize. Their text is shown to users as-is.
+var (
+	ErrRequired = errors.New("Username is required")
+	ErrLength   = fmt.Errorf("Username must be %d to %d characters", MinLength, MaxLength)
+	ErrCharset  = errors.New("Usernames can only use letters a-z, numbers, hyphens and underscores")
+	ErrEdges    = errors.New("Usernames must start and end with a letter or number")
+	ErrRepeated = errors.New("Usernames can't have two hyphens or underscores in a row")
+	ErrReserved = errors.New("That username is reserved")
+)
+
+// Normalize folds raw into its canonical form and checks it against the
+// policy. The returned name is what should be stored and compared.
+func Normalize(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", ErrRequired
+	}
+
+	var b strings.Builder
+	for _, r := range raw {
+		// Fullwidth ASCII (U+FF01..U+FF5E) is the same character in a wider
+		// cell; treat it as what it is
+		if r >= 0xFF01 && r <= 0xFF5E {
+			r -= 0xFEE0
+		}
+		if latin, ok := confusables[r]; ok {
+			return "", i18n.Errorf("%q only looks like the letter %q. Type it with a Latin keyboard layout.", string(r), string(latin))
+		}
+		if r >= 'A' && r <= 'Z' {
+			r += 'a' - 'A'
+		}
+		if !allowed(r) {
+			return "", ErrCharset
+		}
+		b.WriteRune(r)
+	}
+	name := b.String()
+
+	if n := utf8.RuneCountInString(name); n < MinLength || n > MaxLength {
+		return "", ErrLength
+	}
+	if isSeparator(rune(name[0])) || isSeparator(rune(name[len(name)-1])) {
+		return "", ErrEdges
+	}
+	for i := 1; i < len(name); i++ {
+		if isSeparator(rune(name[i])) && isSeparator(rune(name[i-1])) {
+			return "", ErrRepeated
+		}
+	}
+	if IsReserved(name) {
+		return "", ErrReserved
+	}
+	return name, nil
+}
+
+// IsReserved reports whether name is, or could pass for, a reserved word.
+// "adm1n", "Support_" and "d-a-s-h-b-o-a-r-d" are all reserved. Only digits
+// are read as the letters they resemble; distinct spellings such as "mall"
+// (next to "mail") stay available.
+func IsReserved(name string) bool {
+	name = strings.NewReplacer("-", "", "_", "", ".", "").Replace(strings.ToLower(name))
+	if reserved[name] {
+		return true
+	}
+	if !strings.ContainsAny(name, lookalikeDigits) {
+		return false
+	}
+	// "1" passes for both "i" and "l"
+	return reserved[digitsAsLetters("i").Replace(name)] || reserved[digitsAsLetters("l").Replace(name)]
+}
+
+// Reserved lists names no account may claim: every top-level route, words
+// that suggest the account speaks for the site, and names that confuse
+// software. Route segments with a dot ("feed.json") can't be usernames
+// anyway, but their stems are listed so they stay free for future routes.
+var Reserved = []string{
+	// Top-level routes and files
+	"u", "click", "auth", "api", "dashboard", "static", "media", "health",
+	"embed", "oembed", "feed", "feeds", "sitemap", "robots", "explore",
+	"favicon", "well-known", "qr", "og", "login", "logout", "register",
+	"signup", "signin", "settings", "search", "tags", "new", "edit",
+
+	// The site itself
+	"linkbio", "admin", "administrator", "root", "system", "staff", "team",
+	"support", "help", "security", "abuse", "moderator", "official",
+	"verified", "billing", "status", "about", "terms", "privacy", "legal",
+	"contact", "blog", "docs", "jobs", "press",
+
+	// Infrastructure
+	"www", "mail", "email", "smtp", "ftp", "cdn", "assets", "app", "dev",
+	"test", "localhost", "hostmaster", "postmaster", "webmaster",
+
+	// Values that confuse code and templates
+	"null", "nil", "undefined", "none", "true", "false", "anonymous",
+	"me", "self", "user", "users", "username",
+}
+
+var reserved = func() map[string]bool {
+	m := make(map[string]bool, len(Reserved))
+	for _, name := range Reserved {
+		m[strings.ReplaceAll(name, "-", "")] = true
+	}
+	return m
+}()
+
+// lookalikeDigits are the digits commonly typed in place of a letter
+const lookalikeDigits = "0134578"
+
+// digitsAsLetters replaces lookalike digits with their letters, reading
+// "1" as one
+func digitsAsLetters(one string) *strings.Replacer {
+	return strings.NewReplacer("0", "o", "1", one, "3", "e", "4", "a", "5", "s", "7", "t", "8", "b")
+}
+
+func allowed(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || isSeparator(r)
+}
+
+func isSeparator(r rune) bool {
+	return r == '-' || r == '_'
+}
+
+// confusables maps letters from other scripts to the Latin letter they are
+// commonly mistaken for
+var confusables = map[rune]rune{
+	// Cyrillic
+	'а': 'a', 'в': 'b', 'е': 'e', 'к': 'k', 'м': 'm', 'н': 'h', 'о': 'o',
+	'р': 'p', 'с': 'c', 'т': 't', 'у': 'y', 'х': 'x', 'ѕ': 's', 'і': 'i',
+	'ј': 'j', 'ԁ': 'd', 'ԛ': 'q', 'ԝ': 'w', 'һ': 'h', 'ӏ': 'l',
+	'А': 'A', 'В': 'B', 'Е': 'E', 'К': 'K', 'М': 'M', 'Н': 'H', 'О': 'O',
+	'Р': 'P', 'С': 'C', 'Т': 'T', 'У': 'Y', 'Х': 'X', 'Ѕ': 'S', 'І': 'I',
+	'Ј': 'J',
+	// Greek
+	'α': 'a', 'ο': 'o', 'ν': 'v', 'ι': 'i', 'κ': 'k', 'ρ': 'p', 'τ': 't',
+	'υ': 'u', 'χ': 'x', 'Α': 'A', 'Β': 'B', 'Ε': 'E', 'Ζ': 'Z', 'Η': 'H',
+	'Ι': 'I', 'Κ': 'K', 'Μ': 'M', 'Ν': 'N', 'Ο': 'O', 'Ρ': 'P', 'Τ': 'T',
+	'Υ': 'Y', 'Χ': 'X',
+	// Latin lookalikes outside ASCII
+	'ı': 'i', 'ȷ': 'j', 'ℓ': 'l', 'ⅰ': 'i', 'ⅼ': 'l',
+}
@@ -0,0 +1,78 @@
+package username
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	good := map[string]string{
+		"alice":         "alice",
+		"  Alice ":      "alice",
+		"ＡＬＩＣＥ":         "alice",
+		"jane_doe-2024": "jane_doe-2024",
+		"abc":           "abc",
+		"mallory":       "mallory",
+		"mall":          "mall",
+		"seif":          "seif",
+		"tearn":         "tearn",
+		"vvww":          "vvww",
+	}
+	for raw, want := range good {
+		got, err := Normalize(raw)
+		if err != nil || got != want {
+			t.Errorf("Normalize(%q) = %q, %v; want %q", raw, got, err, want)
+		}
+	}
+
+	bad := map[string]error{
+		"":                               ErrRequired,
+		"   ":                            ErrRequired,
+		"ab":                             ErrLength,
+		strings.Repeat("a", MaxLength+1): ErrLength,
+		"john doe":                       ErrCharset,
+		"a/b/c":                          ErrCharset,
+		"dot.name":                       ErrCharset,
+		"émile":                          ErrCharset,
+		"_alice":                         ErrEdges,
+		"alice-":                         ErrEdges,
+		"a__b":                           ErrRepeated,
+		"a-_b":                           ErrRepeated,
+		"admin":                          ErrReserved,
+		"Dashboard":                      ErrReserved,
+		"adm1n":                          ErrReserved,
+		"he1p":                           ErrReserved,
+		"5upp0rt":                        ErrReserved,
+		"well_known":                     ErrReserved,
+		"sup-port":                       ErrReserved,
+		"ROBOTS":                         ErrReserved,
+	}
+	for raw, want := range bad {
+		if _, err := Normalize(raw); err != want {
+			t.Errorf("Normalize(%q) error = %v, want %v", raw, err, want)
+		}
+	}
+}
+
+func TestNormalize_Confusables(t *testing.T) {
+	// Cyrillic а and Greek ο
+	for _, raw := range []string{"аlice", "bοb", "ΑDMIN"} {
+		_, err := Normalize(raw)
+		if err == nil || !strings.Contains(err.Error(), "only looks like") {
+			t.Errorf("Normalize(%q) error = %v, want a confusable error", raw, err)
+		}
+	}
+}
+
+func TestReservedCoversRoutes(t *testing.T) {
+	for _, route := range []string{"u", "click", "auth", "api", "dashboard", "static", "media", "health", "embed", "oembed", "explore"} {
+		if !IsReserved(route) {
+			t.Errorf("%q is not reserved", route)
+		}
+	}
+	for _, name := range []string{"alice", "bob", "linkbio-fan", "madmin"} {
+		if IsReserved(name) {
+			t.Errorf("%q should not be reserved", name)
+		}
+	}
+}
@@ -0,0 +1,2 @@
+ALTER TABLE links ADD COLUMN last_status INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE links ADD COLUMN last_checked_at DATETIME;
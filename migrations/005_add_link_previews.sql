@@ -0,0 +1,3 @@
+ALTER TABLE links ADD COLUMN description TEXT NOT NULL DEFAULT '';
+ALTER TABLE links ADD COLUMN image_url TEXT NOT NULL DEFAULT '';
+ALTER TABLE links ADD COLUMN is_featured INTEGER NOT NULL DEFAULT 0;
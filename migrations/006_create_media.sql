@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS media (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    link_id INTEGER,
+    kind TEXT NOT NULL,
+    key TEXT NOT NULL,
+    content_type TEXT NOT NULL DEFAULT '',
+    size INTEGER NOT NULL DEFAULT 0,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (link_id) REFERENCES links(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_media_user_id ON media(user_id, kind);
+CREATE INDEX IF NOT EXISTS idx_media_link_id ON media(link_id);
+CREATE INDEX IF NOT EXISTS idx_media_key ON media(key);
@@ -0,0 +1 @@
+ALTER TABLE analytics ADD COLUMN source TEXT NOT NULL DEFAULT '';
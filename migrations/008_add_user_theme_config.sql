@@ -0,0 +1 @@
+ALTER TABLE users ADD COLUMN theme_config TEXT NOT NULL DEFAULT '';
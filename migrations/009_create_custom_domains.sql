@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS custom_domains (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    domain TEXT NOT NULL,
+    token TEXT NOT NULL,
+    verified_at DATETIME,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_id, domain),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- A domain may be claimed by several users, but only one claim can be verified
+CREATE UNIQUE INDEX IF NOT EXISTS idx_custom_domains_verified ON custom_domains(domain) WHERE verified_at IS NOT NULL;
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS username_history (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    username TEXT NOT NULL,
+    changed_at DATETIME NOT NULL,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_username_history_username ON username_history(username, changed_at);
+CREATE INDEX IF NOT EXISTS idx_username_history_user_id ON username_history(user_id, changed_at);
@@ -0,0 +1,2 @@
+CREATE INDEX IF NOT EXISTS idx_users_username_nocase ON users(username COLLATE NOCASE);
+CREATE INDEX IF NOT EXISTS idx_username_history_username_nocase ON username_history(username COLLATE NOCASE, changed_at);
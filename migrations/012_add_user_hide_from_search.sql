@@ -0,0 +1 @@
+ALTER TABLE users ADD COLUMN hide_from_search INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,22 @@
+ALTER TABLE links ADD COLUMN pub_title TEXT NOT NULL DEFAULT '';
+ALTER TABLE links ADD COLUMN pub_url TEXT NOT NULL DEFAULT '';
+ALTER TABLE links ADD COLUMN pub_icon TEXT NOT NULL DEFAULT '';
+ALTER TABLE links ADD COLUMN pub_position INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE links ADD COLUMN pub_is_active INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE links ADD COLUMN pub_is_featured INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE links ADD COLUMN deleted_at DATETIME;
+ALTER TABLE links ADD COLUMN published_at DATETIME;
+
+UPDATE links SET
+    pub_title = title, pub_url = url, pub_icon = COALESCE(icon, ''), pub_position = COALESCE(position, 0),
+    pub_is_active = COALESCE(is_active, 0), pub_is_featured = is_featured, published_at = CURRENT_TIMESTAMP;
+
+ALTER TABLE users ADD COLUMN pub_display_name TEXT NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN pub_bio TEXT NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN pub_theme TEXT NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN pub_theme_config TEXT NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN published_at DATETIME;
+
+UPDATE users SET
+    pub_display_name = COALESCE(display_name, ''), pub_bio = COALESCE(bio, ''),
+    pub_theme = COALESCE(theme, ''), pub_theme_config = theme_config, published_at = CURRENT_TIMESTAMP;
@@ -0,0 +1 @@
+ALTER TABLE users ADD COLUMN profile_password_hash TEXT NOT NULL DEFAULT '';
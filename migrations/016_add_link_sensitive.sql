@@ -0,0 +1,2 @@
+ALTER TABLE links ADD COLUMN is_sensitive INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE links ADD COLUMN pub_is_sensitive INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS pages (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    slug TEXT NOT NULL,
+    title TEXT NOT NULL,
+    position INTEGER NOT NULL DEFAULT 0,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_id, slug),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_pages_user_id ON pages(user_id, position);
+
+ALTER TABLE links ADD COLUMN page_id INTEGER REFERENCES pages(id) ON DELETE SET NULL;
+ALTER TABLE links ADD COLUMN pub_page_id INTEGER REFERENCES pages(id) ON DELETE SET NULL;
+ALTER TABLE analytics ADD COLUMN page_id INTEGER REFERENCES pages(id) ON DELETE SET NULL;
@@ -0,0 +1,10 @@
+ALTER TABLE links ADD COLUMN kind TEXT NOT NULL DEFAULT 'url';
+ALTER TABLE links ADD COLUMN starts_at DATETIME;
+ALTER TABLE links ADD COLUMN ends_at DATETIME;
+ALTER TABLE links ADD COLUMN location TEXT NOT NULL DEFAULT '';
+ALTER TABLE links ADD COLUMN time_zone TEXT NOT NULL DEFAULT '';
+ALTER TABLE links ADD COLUMN pub_kind TEXT NOT NULL DEFAULT 'url';
+ALTER TABLE links ADD COLUMN pub_starts_at DATETIME;
+ALTER TABLE links ADD COLUMN pub_ends_at DATETIME;
+ALTER TABLE links ADD COLUMN pub_location TEXT NOT NULL DEFAULT '';
+ALTER TABLE links ADD COLUMN pub_time_zone TEXT NOT NULL DEFAULT '';
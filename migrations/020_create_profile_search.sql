@@ -0,0 +1,30 @@
+ALTER TABLE users ADD COLUMN listed INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE users ADD COLUMN category TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_users_listed ON users(listed, category);
+
+-- Full-text index of the published name and bio for the explore directory.
+-- It reads its content from users; the triggers keep it in step.
+CREATE VIRTUAL TABLE IF NOT EXISTS profile_search USING fts5(
+    username, pub_display_name, pub_bio,
+    content='users', content_rowid='id', tokenize='unicode61 remove_diacritics 2'
+);
+
+CREATE TRIGGER IF NOT EXISTS users_search_insert AFTER INSERT ON users BEGIN
+    INSERT INTO profile_search(rowid, username, pub_display_name, pub_bio)
+    VALUES (new.id, new.username, new.pub_display_name, new.pub_bio);
+END;
+
+CREATE TRIGGER IF NOT EXISTS users_search_delete AFTER DELETE ON users BEGIN
+    INSERT INTO profile_search(profile_search, rowid, username, pub_display_name, pub_bio)
+    VALUES ('delete', old.id, old.username, old.pub_display_name, old.pub_bio);
+END;
+
+CREATE TRIGGER IF NOT EXISTS users_search_update AFTER UPDATE OF username, pub_display_name, pub_bio ON users BEGIN
+    INSERT INTO profile_search(profile_search, rowid, username, pub_display_name, pub_bio)
+    VALUES ('delete', old.id, old.username, old.pub_display_name, old.pub_bio);
+    INSERT INTO profile_search(rowid, username, pub_display_name, pub_bio)
+    VALUES (new.id, new.username, new.pub_display_name, new.pub_bio);
+END;
+
+INSERT INTO profile_search(profile_search) VALUES ('rebuild');
@@ -0,0 +1,21 @@
+-- Full-text index of link titles and URLs for the dashboard's link search.
+-- It reads its content from links; the triggers keep it in step.
+CREATE VIRTUAL TABLE IF NOT EXISTS link_search USING fts5(
+    title, url,
+    content='links', content_rowid='id', tokenize='unicode61 remove_diacritics 2'
+);
+
+CREATE TRIGGER IF NOT EXISTS links_search_insert AFTER INSERT ON links BEGIN
+    INSERT INTO link_search(rowid, title, url) VALUES (new.id, new.title, new.url);
+END;
+
+CREATE TRIGGER IF NOT EXISTS links_search_delete AFTER DELETE ON links BEGIN
+    INSERT INTO link_search(link_search, rowid, title, url) VALUES ('delete', old.id, old.title, old.url);
+END;
+
+CREATE TRIGGER IF NOT EXISTS links_search_update AFTER UPDATE OF title, url ON links BEGIN
+    INSERT INTO link_search(link_search, rowid, title, url) VALUES ('delete', old.id, old.title, old.url);
+    INSERT INTO link_search(rowid, title, url) VALUES (new.id, new.title, new.url);
+END;
+
+INSERT INTO link_search(link_search) VALUES ('rebuild');
@@ -0,0 +1,6 @@
+-- Fetched link previews are drafted like the rest of the link, so a new URL's
+-- description and image only go live when it is published
+ALTER TABLE links ADD COLUMN pub_description TEXT NOT NULL DEFAULT '';
+ALTER TABLE links ADD COLUMN pub_image_url TEXT NOT NULL DEFAULT '';
+
+UPDATE links SET pub_description = description, pub_image_url = image_url;
@@ -0,0 +1,26 @@
+-- Databases that ran 017 before it was corrected delete a sub-page's views
+-- along with the page. Rebuild analytics so page_id is set to NULL instead,
+-- moving the history to the main page like the page's links.
+CREATE TABLE analytics_new (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    link_id INTEGER,
+    event_type TEXT NOT NULL,
+    source TEXT NOT NULL DEFAULT '',
+    platform TEXT NOT NULL DEFAULT '',
+    page_id INTEGER REFERENCES pages(id) ON DELETE SET NULL,
+    referrer TEXT DEFAULT '',
+    user_agent TEXT DEFAULT '',
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (link_id) REFERENCES links(id) ON DELETE CASCADE
+);
+
+INSERT INTO analytics_new (id, user_id, link_id, event_type, source, platform, page_id, referrer, user_agent, created_at)
+SELECT id, user_id, link_id, event_type, source, platform, page_id, referrer, user_agent, created_at FROM analytics;
+
+DROP TABLE analytics;
+ALTER TABLE analytics_new RENAME TO analytics;
+
+CREATE INDEX IF NOT EXISTS idx_analytics_user_id ON analytics(user_id);
+CREATE INDEX IF NOT EXISTS idx_analytics_created_at ON analytics(created_at);
@@ -0,0 +1,9 @@
+-- Pages are drafted like links: adding, reordering and removing a page only
+-- reaches visitors when the creator publishes. Existing pages were live.
+ALTER TABLE pages ADD COLUMN pub_slug TEXT NOT NULL DEFAULT '';
+ALTER TABLE pages ADD COLUMN pub_title TEXT NOT NULL DEFAULT '';
+ALTER TABLE pages ADD COLUMN pub_position INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE pages ADD COLUMN deleted_at DATETIME;
+ALTER TABLE pages ADD COLUMN published_at DATETIME;
+
+UPDATE pages SET pub_slug = slug, pub_title = title, pub_position = position, published_at = created_at;